@@ -0,0 +1,116 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseASN parses the AS number |asn|, e.g. "AS64500", "as64500", "64500", or
+// "0.64500" (asdot notation, RFC 5396).
+func ParseASN(asn string) (uint32, error) {
+	text := strings.TrimSpace(asn)
+
+	if high, low, ok := strings.Cut(strings.TrimPrefix(strings.ToUpper(text), "AS"), "."); ok {
+		h, err1 := strconv.ParseUint(high, 10, 16)
+		l, err2 := strconv.ParseUint(low, 10, 16)
+		if err1 != nil || err2 != nil {
+			return 0, fmt.Errorf("invalid AS number %q", asn)
+		}
+
+		return uint32(h)<<16 | uint32(l), nil
+	}
+
+	result, err := parseAutnum(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid AS number %q", asn)
+	}
+
+	return result, nil
+}
+
+// Range returns the AS numbers of the autnum's range.
+//
+// The range is taken from startAutnum/endAutnum. Servers omitting these are
+// covered by parsing the handle instead, e.g. "AS64500", or "AS64496 -
+// AS64511". A missing endAutnum means a single AS number.
+//
+// Returns an error if there's no valid range, or the start is after the end.
+func (a *Autnum) Range() (uint32, uint32, error) {
+	var start, end uint32
+
+	switch {
+	case a.StartAutnum != nil:
+		start = *a.StartAutnum
+		end = start
+		if a.EndAutnum != nil {
+			end = *a.EndAutnum
+		}
+	case a.Handle != "":
+		first, last, isRange := strings.Cut(a.Handle, "-")
+
+		var err error
+		if start, err = ParseASN(first); err != nil {
+			return 0, 0, fmt.Errorf("no startAutnum, and handle %q is not an AS number or range", a.Handle)
+		}
+
+		end = start
+		if isRange {
+			if end, err = ParseASN(last); err != nil {
+				return 0, 0, fmt.Errorf("no startAutnum, and handle %q is not an AS number or range", a.Handle)
+			}
+		}
+	default:
+		return 0, 0, fmt.Errorf("no startAutnum")
+	}
+
+	if start > end {
+		return 0, 0, fmt.Errorf("startAutnum %d is after endAutnum %d", start, end)
+	}
+
+	return start, end, nil
+}
+
+// Contains returns true if the AS number |asn| is within the autnum's range.
+//
+// Returns false if the range is invalid, see Range().
+func (a *Autnum) Contains(asn uint32) bool {
+	start, end, err := a.Range()
+
+	return err == nil && start <= asn && asn <= end
+}
+
+// Count returns the number of AS numbers in the autnum's range, or 0 if the
+// range is invalid, see Range().
+func (a *Autnum) Count() uint64 {
+	start, end, err := a.Range()
+	if err != nil {
+		return 0
+	}
+
+	return uint64(end) - uint64(start) + 1
+}
+
+// Each calls |fn| for each AS number in the autnum's range, in order, until
+// |fn| returns false.
+//
+// Returns an error (without calling |fn|) if the range is invalid, see
+// Range().
+func (a *Autnum) Each(fn func(asn uint32) bool) error {
+	start, end, err := a.Range()
+	if err != nil {
+		return err
+	}
+
+	for asn := uint64(start); asn <= uint64(end); asn++ {
+		if !fn(uint32(asn)) {
+			break
+		}
+	}
+
+	return nil
+}
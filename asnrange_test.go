@@ -0,0 +1,84 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseASN(t *testing.T) {
+	for text, expected := range map[string]uint32{
+		"AS64500":    64500,
+		"as64500":    64500,
+		" 64500 ":    64500,
+		"4294967295": 4294967295,
+		"1.10":       65546,
+		"AS0.64500":  64500,
+	} {
+		if got, err := ParseASN(text); err != nil || got != expected {
+			t.Errorf("ParseASN(%q) = %d, %v, expected %d", text, got, err, expected)
+		}
+	}
+
+	for _, text := range []string{"", "AS", "ASN64500", "4294967296", "1.65536", "-1"} {
+		if got, err := ParseASN(text); err == nil {
+			t.Errorf("ParseASN(%q) = %d, expected error", text, got)
+		}
+	}
+}
+
+func TestAutnumRange(t *testing.T) {
+	start, end := uint32(64496), uint32(64511)
+
+	tests := []struct {
+		Autnum *Autnum
+		Start  uint32
+		End    uint32
+		Valid  bool
+	}{
+		{&Autnum{StartAutnum: &start, EndAutnum: &end}, 64496, 64511, true},
+		{&Autnum{StartAutnum: &end}, 64511, 64511, true},
+		{&Autnum{Handle: "AS64500"}, 64500, 64500, true},
+		{&Autnum{Handle: "AS64496 - AS64511"}, 64496, 64511, true},
+		{&Autnum{Handle: "EXAMPLE-AS"}, 0, 0, false},
+		{&Autnum{StartAutnum: &end, EndAutnum: &start}, 0, 0, false},
+		{&Autnum{}, 0, 0, false},
+	}
+
+	for _, test := range tests {
+		s, e, err := test.Autnum.Range()
+		if (err == nil) != test.Valid || s != test.Start || e != test.End {
+			t.Errorf("%+v: got %d-%d (error %v), expected %d-%d", test.Autnum, s, e, err, test.Start, test.End)
+		}
+	}
+
+	a := &Autnum{StartAutnum: &start, EndAutnum: &end}
+
+	if !a.Contains(64496) || !a.Contains(64511) || a.Contains(64512) || a.Count() != 16 {
+		t.Errorf("Unexpected Contains()/Count() for %d-%d", start, end)
+	}
+
+	var asns []uint32
+	err := a.Each(func(asn uint32) bool {
+		asns = append(asns, asn)
+		return len(asns) < 3
+	})
+
+	if err != nil || !reflect.DeepEqual(asns, []uint32{64496, 64497, 64498}) {
+		t.Errorf("Unexpected Each() result %v, %v", asns, err)
+	}
+
+	last := uint32(4294967295)
+	count := 0
+	(&Autnum{StartAutnum: &last}).Each(func(asn uint32) bool {
+		count++
+		return true
+	})
+
+	if count != 1 {
+		t.Errorf("Each() at the top of the range called %d times", count)
+	}
+}
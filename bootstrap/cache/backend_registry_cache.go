@@ -0,0 +1,143 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A BackendRegistryCache caches Service Registry files in a Cache backend.
+//
+// This allows bootstrap files to be shared, e.g. by the workers of a
+// distributed crawler:
+//
+//	b := &bootstrap.Client{}
+//	b.Cache = cache.NewBackendRegistryCache(myRedisCache)
+//
+// Files are stored without expiry (so stale files remain usable, as per
+// RegistryCache), under the key Prefix+filename.
+type BackendRegistryCache struct {
+	// Cache backend.
+	Backend Cache
+
+	// Key prefix. The default is "bootstrap:".
+	Prefix string
+
+	// Duration files are stored before they're considered expired.
+	//
+	// The default is 24 hours.
+	Timeout time.Duration
+
+	mu             sync.Mutex
+	lastLoadedTime map[string]time.Time
+}
+
+// backendRegistryHeaderSize is the size of the stored file header, which
+// contains the save time (Unix nanoseconds).
+const backendRegistryHeaderSize = 8
+
+// NewBackendRegistryCache creates a new BackendRegistryCache using |backend|.
+func NewBackendRegistryCache(backend Cache) *BackendRegistryCache {
+	return &BackendRegistryCache{
+		Backend:        backend,
+		Prefix:         "bootstrap:",
+		Timeout:        time.Hour * 24,
+		lastLoadedTime: make(map[string]time.Time),
+	}
+}
+
+// SetTimeout sets the duration each Service Registry file can be stored before
+// its State() is Expired.
+func (b *BackendRegistryCache) SetTimeout(timeout time.Duration) {
+	b.Timeout = timeout
+}
+
+// Save saves the file |filename| with |data| to the backend.
+func (b *BackendRegistryCache) Save(filename string, data []byte) error {
+	now := time.Now()
+
+	value := make([]byte, backendRegistryHeaderSize+len(data))
+	binary.BigEndian.PutUint64(value, uint64(now.UnixNano()))
+	copy(value[backendRegistryHeaderSize:], data)
+
+	if err := b.Backend.Set(b.Prefix+filename, value, 0); err != nil {
+		return err
+	}
+
+	b.setLastLoaded(filename, now)
+
+	return nil
+}
+
+// Load loads the file |filename| from the backend.
+//
+// Since Service Registry files do not change much, the file is returned even
+// if its State() is Expired.
+//
+// An error is returned if the file is not in the backend.
+func (b *BackendRegistryCache) Load(filename string) ([]byte, error) {
+	savedTime, data, err := b.get(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to load %s: %s", filename, err)
+	}
+
+	b.setLastLoaded(filename, savedTime)
+
+	return data, nil
+}
+
+// State returns the cache state of the file |filename|.
+//
+// The returned state is one of: Absent, Good, ShouldReload, Expired.
+func (b *BackendRegistryCache) State(filename string) FileState {
+	savedTime, _, err := b.get(filename)
+	if err != nil {
+		return Absent
+	}
+
+	if savedTime.Add(b.Timeout).Before(time.Now()) {
+		return Expired
+	}
+
+	b.mu.Lock()
+	lastLoadedTime, haveLoaded := b.lastLoadedTime[filename]
+	b.mu.Unlock()
+
+	if haveLoaded && !savedTime.After(lastLoadedTime) {
+		return Good
+	}
+
+	return ShouldReload
+}
+
+// get returns the save time and contents of |filename|.
+func (b *BackendRegistryCache) get(filename string) (time.Time, []byte, error) {
+	value, err := b.Backend.Get(b.Prefix + filename)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	if len(value) < backendRegistryHeaderSize {
+		return time.Time{}, nil, ErrNotFound
+	}
+
+	savedTime := time.Unix(0, int64(binary.BigEndian.Uint64(value)))
+
+	return savedTime, value[backendRegistryHeaderSize:], nil
+}
+
+func (b *BackendRegistryCache) setLastLoaded(filename string, t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.lastLoadedTime == nil {
+		b.lastLoadedTime = make(map[string]time.Time)
+	}
+
+	b.lastLoadedTime[filename] = t
+}
@@ -0,0 +1,57 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package cache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestBackendRegistryCache(t *testing.T) {
+	backend := NewMemoryStore()
+
+	m1 := NewBackendRegistryCache(backend)
+	m2 := NewBackendRegistryCache(backend)
+
+	if m1.State("dns.json") != Absent {
+		t.Fatalf("dns.json expected absent")
+	}
+
+	dns := []byte("file 1")
+	if err := m1.Save("dns.json", dns); err != nil {
+		t.Fatalf("Save failed: %s", err)
+	}
+
+	if _, err := backend.Get("bootstrap:dns.json"); err != nil {
+		t.Fatalf("Expected file in backend: %s", err)
+	}
+
+	if m1.State("dns.json") != Good {
+		t.Fatalf("dns.json expected good in m1")
+	} else if m2.State("dns.json") != ShouldReload {
+		t.Fatalf("dns.json expected shouldreload in m2")
+	}
+
+	loaded, err := m2.Load("dns.json")
+	if err != nil || !bytes.Equal(loaded, dns) {
+		t.Fatalf("Load returned %q, %v", loaded, err)
+	} else if m2.State("dns.json") != Good {
+		t.Fatalf("dns.json expected good in m2")
+	}
+
+	m1.SetTimeout(-time.Second)
+	if m1.State("dns.json") != Expired {
+		t.Fatalf("dns.json expected expired")
+	}
+
+	if _, err := m1.Load("dns.json"); err != nil {
+		t.Fatalf("Expected expired file to load: %s", err)
+	}
+
+	if _, err := m1.Load("asn.json"); err == nil {
+		t.Fatalf("Unexpected load of absent file")
+	}
+}
@@ -0,0 +1,49 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Cache.Get for keys which aren't cached, or have
+// expired.
+var ErrNotFound = errors.New("cache: key not found")
+
+// A Cache is a pluggable key/value cache backend, with per-key expiry.
+//
+// A Cache can store both Service Registry files (see BackendRegistryCache),
+// and RDAP responses (see rdap.HTTPCache). Two implementations are provided:
+// MemoryStore and DiskStore. Implement this interface to use a shared cache
+// (e.g. Redis or SQLite) in a distributed crawler.
+//
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value for |key|, or ErrNotFound.
+	Get(key string) ([]byte, error)
+
+	// Set stores |value| for |key|. The value expires after |ttl|, or never if
+	// |ttl| <= 0.
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// Delete removes |key|. Deleting a missing key is not an error.
+	Delete(key string) error
+}
+
+// expiryFor returns the expiry time for a value stored at |now| with |ttl|.
+// The zero time means no expiry.
+func expiryFor(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+
+	return now.Add(ttl)
+}
+
+// expired returns true if |expiry| (see expiryFor()) is before |now|.
+func expired(expiry time.Time, now time.Time) bool {
+	return !expiry.IsZero() && !now.Before(expiry)
+}
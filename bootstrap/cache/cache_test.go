@@ -0,0 +1,89 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package cache
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func testCache(t *testing.T, c Cache) {
+	if _, err := c.Get("a"); err != ErrNotFound {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+
+	if err := c.Set("a", []byte("value a"), 0); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	if err := c.Set("https://rdap.nic.cz/domain/example.cz", []byte("value b"), time.Hour); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	if err := c.Set("c", []byte("value c"), time.Millisecond); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	if value, err := c.Get("a"); err != nil || !bytes.Equal(value, []byte("value a")) {
+		t.Errorf("Get a returned %q, %v", value, err)
+	}
+
+	if value, err := c.Get("https://rdap.nic.cz/domain/example.cz"); err != nil || !bytes.Equal(value, []byte("value b")) {
+		t.Errorf("Get b returned %q, %v", value, err)
+	}
+
+	time.Sleep(time.Millisecond * 5)
+
+	if _, err := c.Get("c"); err != ErrNotFound {
+		t.Errorf("Expected expired value, got %v", err)
+	}
+
+	if err := c.Delete("a"); err != nil {
+		t.Errorf("Delete failed: %s", err)
+	}
+
+	if err := c.Delete("missing"); err != nil {
+		t.Errorf("Delete of missing key failed: %s", err)
+	}
+
+	if _, err := c.Get("a"); err != ErrNotFound {
+		t.Errorf("Expected deleted value, got %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	m := NewMemoryStore()
+	testCache(t, m)
+
+	m.Purge()
+	if m.Len() != 1 {
+		t.Errorf("Unexpected Len() %d after Purge()", m.Len())
+	}
+}
+
+func TestDiskStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testCache(t, NewDiskStore(dir))
+
+	// Shared directory.
+	d1 := NewDiskStore(dir)
+	d2 := NewDiskStore(dir)
+
+	if err := d1.Set("shared", []byte("value"), 0); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	if value, err := d2.Get("shared"); err != nil || string(value) != "value" {
+		t.Errorf("Get returned %q, %v", value, err)
+	}
+}
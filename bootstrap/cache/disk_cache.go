@@ -11,8 +11,6 @@ import (
 	"os"
 	"path/filepath"
 	"time"
-
-	homedir "github.com/mitchellh/go-homedir"
 )
 
 const (
@@ -46,7 +44,7 @@ func NewDiskCache() *DiskCache {
 		Timeout:           time.Hour * 24,
 	}
 
-	dir, err := homedir.Dir()
+	dir, err := os.UserHomeDir()
 
 	if err != nil {
 		panic("Can't determine your home directory")
@@ -0,0 +1,118 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A DiskStore is a Cache storing values as files in a directory.
+//
+// Each value is stored in a file named after the SHA-256 hash of its key, so
+// any key is allowed. Files are written atomically, so a DiskStore directory
+// can be shared by several processes.
+//
+// Expired files are removed lazily, when accessed.
+type DiskStore struct {
+	// Directory to store files in. Created automatically as needed.
+	Dir string
+}
+
+// diskStoreHeaderSize is the size of the file header, which contains the
+// expiry time (Unix nanoseconds, 0 for no expiry).
+const diskStoreHeaderSize = 8
+
+// NewDiskStore creates a new DiskStore, storing files in |dir|.
+func NewDiskStore(dir string) *DiskStore {
+	return &DiskStore{
+		Dir: dir,
+	}
+}
+
+// Get returns the value for |key|, or ErrNotFound.
+func (d *DiskStore) Get(key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(d.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(data) < diskStoreHeaderSize {
+		// Corrupt file.
+		d.Delete(key)
+		return nil, ErrNotFound
+	}
+
+	var expiry time.Time
+	if nanos := int64(binary.BigEndian.Uint64(data)); nanos != 0 {
+		expiry = time.Unix(0, nanos)
+	}
+
+	if expired(expiry, time.Now()) {
+		d.Delete(key)
+		return nil, ErrNotFound
+	}
+
+	return data[diskStoreHeaderSize:], nil
+}
+
+// Set stores |value| for |key|, expiring after |ttl| (never if |ttl| <= 0).
+func (d *DiskStore) Set(key string, value []byte, ttl time.Duration) error {
+	if err := os.MkdirAll(d.Dir, 0775); err != nil {
+		return err
+	}
+
+	data := make([]byte, diskStoreHeaderSize+len(value))
+
+	if expiry := expiryFor(time.Now(), ttl); !expiry.IsZero() {
+		binary.BigEndian.PutUint64(data, uint64(expiry.UnixNano()))
+	}
+	copy(data[diskStoreHeaderSize:], value)
+
+	// Write to a temporary file, then rename, so readers never see partial
+	// files.
+	f, err := ioutil.TempFile(d.Dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), d.path(key))
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+	}
+
+	return err
+}
+
+// Delete removes |key|.
+func (d *DiskStore) Delete(key string) error {
+	err := os.Remove(d.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (d *DiskStore) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return filepath.Join(d.Dir, hex.EncodeToString(hash[:]))
+}
@@ -0,0 +1,104 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// A MemoryStore is an in-memory Cache.
+//
+// Expired values are removed lazily, when accessed, or by Purge().
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryStoreEntry
+}
+
+type memoryStoreEntry struct {
+	value  []byte
+	expiry time.Time
+}
+
+// NewMemoryStore creates a new, empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryStoreEntry),
+	}
+}
+
+// Get returns the value for |key|, or ErrNotFound.
+func (m *MemoryStore) Get(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if expired(entry.expiry, time.Now()) {
+		delete(m.entries, key)
+		return nil, ErrNotFound
+	}
+
+	result := make([]byte, len(entry.value))
+	copy(result, entry.value)
+
+	return result, nil
+}
+
+// Set stores |value| for |key|, expiring after |ttl| (never if |ttl| <= 0).
+func (m *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries == nil {
+		m.entries = make(map[string]memoryStoreEntry)
+	}
+
+	entry := memoryStoreEntry{
+		value:  make([]byte, len(value)),
+		expiry: expiryFor(time.Now(), ttl),
+	}
+	copy(entry.value, value)
+
+	m.entries[key] = entry
+
+	return nil
+}
+
+// Delete removes |key|.
+func (m *MemoryStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+
+	return nil
+}
+
+// Len returns the number of stored values, including expired values not yet
+// removed.
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.entries)
+}
+
+// Purge removes all expired values.
+func (m *MemoryStore) Purge() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	for key, entry := range m.entries {
+		if expired(entry.expiry, now) {
+			delete(m.entries, key)
+		}
+	}
+}
@@ -5,6 +5,11 @@
 // Package cache implements RDAP Service Registry file caching.
 //
 // There are two separate implementations: MemoryCache and DiskCache.
+//
+// The package also defines Cache, a pluggable key/value cache backend for
+// both Service Registry files and RDAP responses. MemoryStore and DiskStore
+// implement it, and BackendRegistryCache adapts any Cache into a
+// RegistryCache.
 package cache
 
 import "time"
@@ -79,12 +79,26 @@
 //
 //   dsr2 := b.DNS()  // Loads dns.json from disk cache.
 //
-// This package also implements the experimental Service Provider registry. Due
-// to the experimental nature, no Service Registry file exists on data.iana.org
-// yet, additionally the filename isn't known. The current filename used is
-// serviceprovider-draft-03.json.
+// Long running processes can have the Service Registry files managed
+// automatically: NewPersistentClient() returns a Client which persists them
+// in a directory, and refreshes expired files in the background (see
+// RefreshInBackground):
 //
-// RDAP bootstrapping is defined in https://tools.ietf.org/html/rfc7484.
+//   b := bootstrap.NewPersistentClient("/var/cache/rdap")
+//   b.StartAutoRefresh(ctx, time.Hour) // Optional, keeps all files up to date.
+//
+// With Fallback set, a compiled-in snapshot is used instead when a Service
+// Registry file can't be downloaded and isn't cached (see Snapshot()), so cold
+// starts without access to the bootstrap service still work. The snapshot is
+// only a minimal copy, so the fallback is off by default. For air-gapped
+// environments, set BaseURL to a local mirror.
+//
+// This package also implements the Service Provider (object tag) registry,
+// object-tags.json, used to bootstrap entity queries. Entity handles end with
+// a service provider tag, e.g. "ABC123-ARIN" is handled by ARIN's RDAP server.
+//
+// RDAP bootstrapping is defined in https://tools.ietf.org/html/rfc7484 (now
+// RFC 9224). Object tags are defined in https://tools.ietf.org/html/rfc8521.
 package bootstrap
 
 import (
@@ -95,6 +109,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/openrdap/rdap/bootstrap/cache"
@@ -109,6 +124,10 @@ const (
 	IPv6
 	ASN
 	ServiceProvider
+
+	// ObjectTags is an alias of ServiceProvider, after the registry's IANA
+	// filename (object-tags.json).
+	ObjectTags = ServiceProvider
 )
 
 func (r RegistryType) String() string {
@@ -145,7 +164,27 @@ type Client struct {
 	// Optional callback function for verbose messages.
 	Verbose func(text string)
 
-	registries map[RegistryType]Registry
+	// Refresh expired Service Registry files in the background.
+	//
+	// When true, Lookup() answers using an expired (but cached) Service
+	// Registry file immediately, while downloading a fresh copy in the
+	// background. When false, expired files are used until downloaded again
+	// with Download() or Refresh(). See also NewPersistentClient().
+	RefreshInBackground bool
+
+	// Enables the fallback to the compiled-in snapshot of the Service
+	// Registry files (see Snapshot()), when a file can't be downloaded and
+	// isn't cached.
+	//
+	// The snapshot covers only a few registries, so lookups of other
+	// registries fail with "no RDAP server" rather than the download error.
+	// Off by default.
+	Fallback bool
+
+	mu                 sync.Mutex
+	registries         map[RegistryType]Registry
+	refreshing         map[RegistryType]bool
+	lastRefreshAttempt map[RegistryType]time.Time
 }
 
 // A Registry implements bootstrap lookups.
@@ -155,6 +194,9 @@ type Registry interface {
 }
 
 func (c *Client) init() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.HTTP == nil {
 		c.HTTP = &http.Client{}
 	}
@@ -171,6 +213,10 @@ func (c *Client) init() {
 	if c.BaseURL == nil {
 		c.BaseURL, _ = url.Parse(DefaultBaseURL)
 	}
+
+	if c.Verbose == nil {
+		c.Verbose = func(text string) {}
+	}
 }
 
 // Download downloads a single bootstrap registry file.
@@ -195,6 +241,9 @@ func (c *Client) DownloadWithContext(ctx context.Context, registry RegistryType)
 		return err
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	err = c.Cache.Save(c.filenameFor(registry), json)
 	if err != nil {
 		return err
@@ -203,7 +252,6 @@ func (c *Client) DownloadWithContext(ctx context.Context, registry RegistryType)
 	c.registries[registry] = s
 
 	return nil
-
 }
 
 func (c *Client) download(ctx context.Context, registry RegistryType) ([]byte, Registry, error) {
@@ -301,51 +349,82 @@ func newRegistry(registry RegistryType, json []byte) (Registry, error) {
 // Lookup returns the RDAP base URLs for the bootstrap question |question|.
 func (c *Client) Lookup(question *Question) (*Answer, error) {
 	c.init()
-	if c.Verbose == nil {
-		c.Verbose = func(text string) {}
+
+	verbose := c.Verbose
+	if question.Verbose != nil {
+		verbose = question.Verbose
 	}
 
-	c.Verbose("  bootstrap: Looking up...")
-	c.Verbose(fmt.Sprintf("  bootstrap: Question type : %s", question.RegistryType))
-	c.Verbose(fmt.Sprintf("  bootstrap: Question query: %s", question.Query))
+	verbose("  bootstrap: Looking up...")
+	verbose(fmt.Sprintf("  bootstrap: Question type : %s", question.RegistryType))
+	verbose(fmt.Sprintf("  bootstrap: Question query: %s", question.Query))
 
 	registry := question.RegistryType
 
+	c.mu.Lock()
 	var state cache.FileState = c.Cache.State(c.filenameFor(registry))
-	c.Verbose(fmt.Sprintf("  bootstrap: Cache state: %s: %s", c.filenameFor(registry), state))
+	verbose(fmt.Sprintf("  bootstrap: Cache state: %s: %s", c.filenameFor(registry), state))
 
 	var forceDownload bool
-	if state == cache.ShouldReload {
+	// Expired files are only used (while refreshing) with RefreshInBackground,
+	// or offline.
+	reloadExpired := state == cache.Expired && c.registries[registry] == nil &&
+		(c.RefreshInBackground || question.Offline)
+
+	if state == cache.ShouldReload || reloadExpired {
 		if err := c.reloadFromCache(registry); err != nil {
 			forceDownload = true
 
-			c.Verbose(fmt.Sprintf("  bootstrap: Cache load error (%s), downloading...", err))
+			verbose(fmt.Sprintf("  bootstrap: Cache load error (%s), downloading...", err))
 		}
 	}
 
-	if c.registries[registry] == nil || forceDownload {
-		c.Verbose(fmt.Sprintf("  bootstrap: Downloading %s", registry.Filename()))
+	// Files loaded from the snapshot aren't cached, retry downloading them
+	// periodically.
+	retrySnapshot := state == cache.Absent && !c.attemptedRecently(registry)
+
+	download := c.registries[registry] == nil || forceDownload || retrySnapshot
+	c.mu.Unlock()
+
+	if download && question.Offline {
+		c.mu.Lock()
+		loaded := c.registries[registry] != nil
+		c.mu.Unlock()
+
+		if !loaded && !c.useSnapshot(registry, ErrNotCached, verbose) {
+			return nil, ErrNotCached
+		}
+	} else if download {
+		verbose(fmt.Sprintf("  bootstrap: Downloading %s", registry.Filename()))
 
 		err := c.DownloadWithContext(question.Context(), registry)
-		if err != nil {
+		if err != nil && !c.useSnapshot(registry, err, verbose) {
 			return nil, err
 		}
 	} else {
-		c.Verbose("  bootstrap: Using cached Service Registry file")
+		verbose("  bootstrap: Using cached Service Registry file")
+
+		if state == cache.Expired && c.RefreshInBackground && !question.Offline {
+			c.startRefresh(registry)
+		}
 	}
 
-	answer, err := c.registries[registry].Lookup(question)
+	c.mu.Lock()
+	r := c.registries[registry]
+	c.mu.Unlock()
+
+	answer, err := r.Lookup(question)
 
 	if answer != nil {
-		c.Verbose(fmt.Sprintf("  bootstrap: Looked up '%s'", answer.Query))
+		verbose(fmt.Sprintf("  bootstrap: Looked up '%s'", answer.Query))
 		if answer.Entry != "" {
-			c.Verbose(fmt.Sprintf("  bootstrap: Matching entry '%s'", answer.Entry))
+			verbose(fmt.Sprintf("  bootstrap: Matching entry '%s'", answer.Entry))
 		} else {
-			c.Verbose(fmt.Sprintf("  bootstrap: No match"))
+			verbose(fmt.Sprintf("  bootstrap: No match"))
 		}
 
 		for i, url := range answer.URLs {
-			c.Verbose(fmt.Sprintf("  bootstrap: Service URL #%d: '%s'", i+1, url))
+			verbose(fmt.Sprintf("  bootstrap: Service URL #%d: '%s'", i+1, url))
 		}
 	}
 
@@ -357,7 +436,11 @@ func (c *Client) Lookup(question *Question) (*Answer, error) {
 // This function never initiates a network transfer.
 func (c *Client) ASN() *ASNRegistry {
 	c.init()
-	c.freshenFromCache(ServiceProvider)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.freshenFromCache(ASN)
 
 	s, _ := c.registries[ASN].(*ASNRegistry)
 	return s
@@ -369,7 +452,11 @@ func (c *Client) ASN() *ASNRegistry {
 // This function never initiates a network transfer.
 func (c *Client) DNS() *DNSRegistry {
 	c.init()
-	c.freshenFromCache(ServiceProvider)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.freshenFromCache(DNS)
 
 	s, _ := c.registries[DNS].(*DNSRegistry)
 	return s
@@ -380,7 +467,11 @@ func (c *Client) DNS() *DNSRegistry {
 // This function never initiates a network transfer.
 func (c *Client) IPv4() *NetRegistry {
 	c.init()
-	c.freshenFromCache(ServiceProvider)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.freshenFromCache(IPv4)
 
 	s, _ := c.registries[IPv4].(*NetRegistry)
 	return s
@@ -391,7 +482,11 @@ func (c *Client) IPv4() *NetRegistry {
 // This function never initiates a network transfer.
 func (c *Client) IPv6() *NetRegistry {
 	c.init()
-	c.freshenFromCache(ServiceProvider)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.freshenFromCache(IPv6)
 
 	s, _ := c.registries[IPv6].(*NetRegistry)
 	return s
@@ -402,6 +497,10 @@ func (c *Client) IPv6() *NetRegistry {
 // This function never initiates a network transfer.
 func (c *Client) ServiceProvider() *ServiceProviderRegistry {
 	c.init()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.freshenFromCache(ServiceProvider)
 
 	s, _ := c.registries[ServiceProvider].(*ServiceProviderRegistry)
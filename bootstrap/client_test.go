@@ -7,6 +7,8 @@ package bootstrap
 import (
 	"testing"
 
+	"github.com/jarcoal/httpmock"
+	"github.com/openrdap/rdap/bootstrap/cache"
 	"github.com/openrdap/rdap/test"
 )
 
@@ -126,3 +128,49 @@ func TestLookupWithDownloadError(t *testing.T) {
 
 	t.Logf("Error was: %s", err)
 }
+
+func TestLookupWithSnapshotFallback(t *testing.T) {
+	test.Start(test.BootstrapHTTPError)
+	defer test.Finish()
+
+	c := &Client{
+		Fallback: true,
+	}
+
+	question := &Question{
+		RegistryType: DNS,
+		Query:        "example.br",
+	}
+
+	answer, err := c.Lookup(question)
+	if err != nil {
+		t.Fatalf("Lookup() error: %s", err)
+	} else if len(answer.URLs) == 0 || answer.URLs[0].String() != "https://rdap.registro.br/" {
+		t.Fatalf("Unexpected answer %v", answer.URLs)
+	}
+
+	if c.Cache.State(DNS.Filename()) != cache.Absent {
+		t.Errorf("Snapshot unexpectedly cached")
+	}
+
+	// The failed download isn't retried immediately.
+	if _, err := c.Lookup(question); err != nil {
+		t.Fatalf("Lookup() error: %s", err)
+	} else if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("Unexpected download count %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	for _, registry := range StandardRegistries {
+		json, err := Snapshot(registry)
+		if err != nil {
+			t.Errorf("Snapshot(%s) error: %s", registry, err)
+			continue
+		}
+
+		if _, err := newRegistry(registry, json); err != nil {
+			t.Errorf("Snapshot(%s) is invalid: %s", registry, err)
+		}
+	}
+}
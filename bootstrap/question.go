@@ -4,7 +4,14 @@
 
 package bootstrap
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotCached is returned by Client.Lookup() for Offline questions, when the
+// Service Registry file isn't available without downloading it.
+var ErrNotCached = errors.New("bootstrap: Service Registry file not cached")
 
 // Question represents a bootstrap query.
 //
@@ -19,6 +26,16 @@ type Question struct {
 	// Query text.
 	Query string
 
+	// Answer without downloading, using the cached (even if expired) or
+	// already loaded Service Registry file, or the embedded snapshot (if
+	// Client.Fallback is set). Returns ErrNotCached if none are available.
+	Offline bool
+
+	// Optional callback for verbose messages about this lookup, instead of
+	// Client.Verbose. Lets concurrent callers sharing a Client log
+	// separately.
+	Verbose func(text string)
+
 	ctx context.Context
 }
 
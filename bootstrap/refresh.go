@@ -0,0 +1,170 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openrdap/rdap/bootstrap/cache"
+)
+
+// StandardRegistries are the Service Registry files published by IANA:
+// dns.json, ipv4.json, ipv6.json, asn.json, and object-tags.json.
+var StandardRegistries = []RegistryType{DNS, IPv4, IPv6, ASN, ServiceProvider}
+
+const (
+	// Default interval between checks by StartAutoRefresh().
+	DefaultRefreshInterval = time.Hour
+
+	// Timeout for background Service Registry file downloads.
+	backgroundDownloadTimeout = time.Minute
+
+	// Minimum time between background download attempts of a Service
+	// Registry file, so failures (e.g. when offline) don't cause a download
+	// attempt per Lookup().
+	backgroundRetryInterval = time.Minute * 5
+)
+
+// NewPersistentClient creates a Client which persists the Service Registry
+// files in the directory |dir|, and refreshes them in the background when
+// they expire.
+//
+// |dir| is created as needed. If |dir| is empty, the default directory
+// ($HOME/.openrdap) is used.
+//
+// Call Refresh() to download all of the StandardRegistries up front, or
+// StartAutoRefresh() to keep them up to date in a long running process.
+func NewPersistentClient(dir string) *Client {
+	diskCache := cache.NewDiskCache()
+	if dir != "" {
+		diskCache.Dir = dir
+	}
+
+	return &Client{
+		Cache:               diskCache,
+		RefreshInBackground: true,
+	}
+}
+
+// Refresh ensures the StandardRegistries are loaded and up to date.
+//
+// Files in the cache are loaded. Missing and expired files are downloaded.
+// Returns the first error encountered, after trying all of the registries.
+func (c *Client) Refresh(ctx context.Context) error {
+	c.init()
+
+	var firstErr error
+
+	for _, registry := range StandardRegistries {
+		c.mu.Lock()
+		state := c.Cache.State(c.filenameFor(registry))
+
+		if state == cache.ShouldReload || (state == cache.Expired && c.registries[registry] == nil) {
+			c.reloadFromCache(registry)
+		}
+
+		download := state == cache.Absent || state == cache.Expired || c.registries[registry] == nil
+		c.mu.Unlock()
+
+		if !download {
+			continue
+		}
+
+		c.Verbose(fmt.Sprintf("  bootstrap: Refreshing %s (%s)", registry.Filename(), state))
+
+		if err := c.DownloadWithContext(ctx, registry); err != nil {
+			c.Verbose(fmt.Sprintf("  bootstrap: Refresh of %s failed: %s", registry.Filename(), err))
+
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// StartAutoRefresh runs Refresh() now, then every |interval| (default
+// DefaultRefreshInterval), in a background goroutine, until |ctx| is done.
+//
+// Refresh errors are reported via the Verbose callback only; expired files
+// remain usable until a download succeeds.
+func (c *Client) StartAutoRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+
+	c.init()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			c.Refresh(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// startRefresh starts a background download of the Service Registry file
+// |registry|, unless one is already running, or was attempted recently.
+func (c *Client) startRefresh(registry RegistryType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.refreshing == nil {
+		c.refreshing = make(map[RegistryType]bool)
+	}
+
+	if c.refreshing[registry] || c.attemptedRecently(registry) {
+		return
+	}
+
+	c.refreshing[registry] = true
+	c.noteRefreshAttempt(registry)
+
+	c.Verbose(fmt.Sprintf("  bootstrap: Refreshing %s in the background", registry.Filename()))
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundDownloadTimeout)
+		defer cancel()
+
+		err := c.DownloadWithContext(ctx, registry)
+
+		c.mu.Lock()
+		delete(c.refreshing, registry)
+		c.mu.Unlock()
+
+		if err != nil {
+			c.Verbose(fmt.Sprintf("  bootstrap: Background refresh of %s failed: %s", registry.Filename(), err))
+		}
+	}()
+}
+
+// attemptedRecently returns true if a download of |registry| was attempted
+// in the last backgroundRetryInterval. The caller must hold c.mu.
+func (c *Client) attemptedRecently(registry RegistryType) bool {
+	last, ok := c.lastRefreshAttempt[registry]
+
+	return ok && time.Since(last) < backgroundRetryInterval
+}
+
+// noteRefreshAttempt records a download attempt of |registry|. The caller
+// must hold c.mu.
+func (c *Client) noteRefreshAttempt(registry RegistryType) {
+	if c.lastRefreshAttempt == nil {
+		c.lastRefreshAttempt = make(map[RegistryType]time.Time)
+	}
+
+	c.lastRefreshAttempt[registry] = time.Now()
+}
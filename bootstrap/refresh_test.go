@@ -0,0 +1,110 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package bootstrap
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/openrdap/rdap/bootstrap/cache"
+	"github.com/openrdap/rdap/test"
+)
+
+func TestRefresh(t *testing.T) {
+	test.Start(test.Bootstrap)
+	defer test.Finish()
+
+	dir, err := ioutil.TempDir("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewPersistentClient(dir)
+
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error: %s", err)
+	}
+
+	for _, registry := range StandardRegistries {
+		if _, err := os.Stat(filepath.Join(dir, registry.Filename())); err != nil {
+			t.Errorf("%s not persisted: %s", registry.Filename(), err)
+		}
+	}
+
+	if httpmock.GetTotalCallCount() != len(StandardRegistries) {
+		t.Fatalf("Unexpected download count %d", httpmock.GetTotalCallCount())
+	}
+
+	// Up to date, so no further downloads.
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error: %s", err)
+	}
+
+	// New client loads the persisted files.
+	c2 := NewPersistentClient(dir)
+
+	answer, err := c2.Lookup(&Question{RegistryType: DNS, Query: "example.br"})
+	if err != nil || len(answer.URLs) == 0 {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+
+	if httpmock.GetTotalCallCount() != len(StandardRegistries) {
+		t.Errorf("Unexpected download count %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestRefreshInBackground(t *testing.T) {
+	test.Start(test.Bootstrap)
+	defer test.Finish()
+
+	// Files expire immediately.
+	memoryCache := cache.NewMemoryCache()
+	memoryCache.SetTimeout(-time.Second)
+
+	c := &Client{
+		Cache:               memoryCache,
+		RefreshInBackground: true,
+	}
+
+	question := &Question{RegistryType: DNS, Query: "example.br"}
+
+	lookup := func() {
+		answer, err := c.Lookup(question)
+		if err != nil || len(answer.URLs) == 0 {
+			t.Fatalf("Lookup() failed: %v", err)
+		}
+	}
+
+	// Initial download.
+	lookup()
+
+	// Expired, answered from the expired file, refreshed in the background.
+	lookup()
+
+	for i := 0; i < 100; i++ {
+		c.mu.Lock()
+		refreshing := c.refreshing[DNS]
+		c.mu.Unlock()
+
+		if !refreshing {
+			break
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// Refreshed recently, so not refreshed again.
+	lookup()
+
+	if httpmock.GetTotalCallCount() != 2 {
+		t.Errorf("Unexpected download count %d", httpmock.GetTotalCallCount())
+	}
+}
@@ -10,8 +10,10 @@ import (
 	"strings"
 )
 
+// ServiceProviderRegistry implements bootstrapping of entity handles, using
+// service provider object tags (RFC 8521).
 type ServiceProviderRegistry struct {
-	// Map of service tag (e.g. "VRSN") to RDAP base URLs.
+	// Map of upper case service tag (e.g. "VRSN") to RDAP base URLs.
 	services map[string][]*url.URL
 
 	// The registry's JSON document.
@@ -31,8 +33,14 @@ func NewServiceProviderRegistry(json []byte) (*ServiceProviderRegistry, error) {
 		return nil, fmt.Errorf("Error parsing Service Provider bootstrap: %s", err)
 	}
 
+	// Tags are case insensitive.
+	services := make(map[string][]*url.URL, len(r.Entries))
+	for tag, urls := range r.Entries {
+		services[strings.ToUpper(tag)] = urls
+	}
+
 	return &ServiceProviderRegistry{
-		services: r.Entries,
+		services: services,
 		file:     r,
 	}, nil
 }
@@ -40,6 +48,7 @@ func NewServiceProviderRegistry(json []byte) (*ServiceProviderRegistry, error) {
 // Lookup returns a list of RDAP base URLs for the entity question |question|.
 //
 // e.g. for the handle "53774930-VRSN", the RDAP base URLs for "VRSN" are returned.
+// Service tags are case insensitive.
 //
 // Missing/malformed/unknown service tags are not treated as errors. An empty
 // list of URLs is returned in these cases.
@@ -49,8 +58,8 @@ func NewServiceProviderRegistry(json []byte) (*ServiceProviderRegistry, error) {
 func (s *ServiceProviderRegistry) Lookup(question *Question) (*Answer, error) {
 	input := question.Query
 
-	// Valid input looks like 12345-VRSN.
-	offset := strings.LastIndexByte(input, '-')
+	// Valid input looks like 12345-VRSN (or 12345~VRSN).
+	offset := strings.LastIndexAny(input, "-~")
 
 	if offset == -1 || offset == len(input)-1 {
 		return &Answer{
@@ -58,7 +67,7 @@ func (s *ServiceProviderRegistry) Lookup(question *Question) (*Answer, error) {
 		}, nil
 	}
 
-	service := input[offset+1:]
+	service := strings.ToUpper(input[offset+1:])
 
 	urls, ok := s.services[service]
 
@@ -54,6 +54,24 @@ func TestServiceProviderRegistryLookups(t *testing.T) {
 			"FRNIC",
 			[]string{"https://rdap.nic.fr/"},
 		},
+		{
+			"abc123-arin",
+			false,
+			"ARIN",
+			[]string{"https://rdap.arin.net/registry/", "http://rdap.arin.net/registry/"},
+		},
+		{
+			"12345~RIPE",
+			false,
+			"RIPE",
+			[]string{"https://rdap.db.ripe.net/"},
+		},
+		{
+			"12345-UNKNOWN",
+			false,
+			"",
+			[]string{},
+		},
 	}
 
 	runRegistryTests(t, tests, s)
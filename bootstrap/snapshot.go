@@ -0,0 +1,66 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package bootstrap
+
+import (
+	"embed"
+	"fmt"
+)
+
+// Regenerate the snapshot from data.iana.org with "go generate".
+//
+//go:generate go run snapshot_gen.go
+
+//go:embed snapshot/*.json
+var snapshotFS embed.FS
+
+// Snapshot returns the compiled-in copy of the Service Registry file
+// |registry|.
+//
+// With Client.Fallback set, the snapshot is used when a Service Registry file
+// can't be downloaded (and isn't cached), so bootstrapping works on cold starts
+// without network access to the bootstrap service. Being compiled in, it can be
+// out of date, and it currently covers only a few registries.
+func Snapshot(registry RegistryType) ([]byte, error) {
+	return snapshotFS.ReadFile("snapshot/" + registry.Filename())
+}
+
+// useSnapshot loads the snapshot of |registry| after its download failed
+// with |downloadErr|, logging to |verbose|.
+//
+// Returns true if the registry is usable, either already loaded (e.g. from an
+// earlier fallback), or loaded from the snapshot.
+func (c *Client) useSnapshot(registry RegistryType, downloadErr error, verbose func(text string)) bool {
+	if !c.Fallback {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.noteRefreshAttempt(registry)
+
+	if c.registries[registry] != nil {
+		verbose(fmt.Sprintf("  bootstrap: Download failed (%s), using loaded Service Registry file", downloadErr))
+		return true
+	}
+
+	json, err := Snapshot(registry)
+	if err != nil {
+		return false
+	}
+
+	s, err := newRegistry(registry, json)
+	if err != nil {
+		return false
+	}
+
+	verbose(fmt.Sprintf("  bootstrap: Download failed (%s), using embedded snapshot of %s",
+		downloadErr, registry.Filename()))
+
+	c.registries[registry] = s
+
+	return true
+}
@@ -0,0 +1,65 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+//go:build ignore
+
+// snapshot_gen downloads the IANA Service Registry files into snapshot/, for
+// embedding as the bootstrap fallback snapshot.
+//
+// Run using "go generate" in the bootstrap directory.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+var files = []string{"dns.json", "ipv4.json", "ipv6.json", "asn.json", "object-tags.json"}
+
+const baseURL = "https://data.iana.org/rdap/"
+
+func main() {
+	for _, filename := range files {
+		if err := download(filename); err != nil {
+			fmt.Fprintf(os.Stderr, "snapshot_gen: %s: %s\n", filename, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("snapshot_gen: Downloaded %s\n", filename)
+	}
+}
+
+func download(filename string) error {
+	resp, err := http.Get(baseURL + filename)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// Sanity check, don't embed an error page.
+	var file struct {
+		Services []interface{} `json:"services"`
+	}
+
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	} else if len(file.Services) == 0 {
+		return fmt.Errorf("no services")
+	}
+
+	return ioutil.WriteFile(filepath.Join("snapshot", filename), data, 0644)
+}
@@ -0,0 +1,185 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"sync"
+)
+
+// BulkOptions specifies how Client.Bulk() runs queries.
+type BulkOptions struct {
+	// Maximum number of queries run concurrently. The default (0) is 1.
+	Concurrency int
+
+	// Deliver results in input order. By default, results are delivered as
+	// the queries complete.
+	Ordered bool
+}
+
+// BulkResult is the result of one query run by Client.Bulk().
+type BulkResult struct {
+	// Position of the query in the input, starting at 0.
+	Index int
+
+	Request  *Request
+	Response *Response
+	Err      error
+}
+
+// Bulk runs the queries |reqs| concurrently, returning a channel of their
+// results. The channel is closed after the last result.
+//
+//	results := client.Bulk(ctx, reqs, rdap.BulkOptions{Concurrency: 8})
+//
+//	for r := range results {
+//	  if r.Err != nil {
+//	    ...
+//	  }
+//	}
+//
+// The queries share the Client's RateLimiter and Politeness limits, so
+// queries to the same RDAP server are limited no matter how high the
+// concurrency.
+//
+// When |ctx| is done, no more queries are started, and the running queries
+// are cancelled. Each query also keeps its own Request context (e.g. a
+// per-query deadline). A nil Request results in an InputError ClientError.
+// The results channel must be read until it's closed.
+func (c *Client) Bulk(ctx context.Context, reqs []*Request, opts BulkOptions) <-chan BulkResult {
+	in := make(chan *Request)
+
+	go func() {
+		defer close(in)
+
+		for _, req := range reqs {
+			select {
+			case in <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return c.BulkStream(ctx, in, opts)
+}
+
+// BulkStream is like Bulk(), but reads the queries from the channel |reqs|,
+// until it's closed (or |ctx| is done).
+func (c *Client) BulkStream(ctx context.Context, reqs <-chan *Request, opts BulkOptions) <-chan BulkResult {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Initialise the client before it's used concurrently.
+	c.init()
+
+	results := make(chan BulkResult, concurrency)
+	out := make(chan BulkResult)
+
+	// Run the queries.
+	go func() {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, concurrency)
+
+		defer func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		for index := 0; ; index++ {
+			var req *Request
+			var ok bool
+
+			select {
+			case req, ok = <-reqs:
+			case <-ctx.Done():
+			}
+
+			if !ok {
+				return
+			}
+
+			if req == nil {
+				results <- BulkResult{
+					Index: index,
+					Err: &ClientError{
+						Type: InputError,
+						Text: "nil Request",
+					},
+				}
+
+				continue
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			wg.Add(1)
+			go func(index int, req *Request) {
+				defer wg.Done()
+
+				reqCtx, cancel := bulkContext(req.Context(), ctx)
+				defer cancel()
+
+				resp, err := c.Do(req.WithContext(reqCtx))
+				results <- BulkResult{
+					Index:    index,
+					Request:  req,
+					Response: resp,
+					Err:      err,
+				}
+
+				<-sem
+			}(index, req)
+		}
+	}()
+
+	// Deliver the results.
+	go func() {
+		defer close(out)
+
+		pending := map[int]BulkResult{}
+		next := 0
+
+		for r := range results {
+			if !opts.Ordered {
+				out <- r
+				continue
+			}
+
+			pending[r.Index] = r
+			for {
+				r, ok := pending[next]
+				if !ok {
+					break
+				}
+
+				out <- r
+				delete(pending, next)
+				next++
+			}
+		}
+	}()
+
+	return out
+}
+
+// bulkContext returns a context with the values and deadline of the Request's
+// context |reqCtx|, which is also cancelled when the Bulk() context |ctx| is
+// done.
+func bulkContext(reqCtx context.Context, ctx context.Context) (context.Context, context.CancelFunc) {
+	merged, cancel := context.WithCancel(reqCtx)
+	stop := context.AfterFunc(ctx, cancel)
+
+	return merged, func() {
+		stop()
+		cancel()
+	}
+}
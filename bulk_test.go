@@ -0,0 +1,141 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestClientBulk(t *testing.T) {
+	var running int32
+	var maxRunning int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+
+		// Complete the queries in reverse order.
+		if r.URL.Path == "/domain/0.cz" {
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		if r.URL.Path == "/domain/missing.cz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	var reqs []*Request
+	for _, domain := range []string{"0.cz", "1.cz", "missing.cz", "3.cz", "4.cz"} {
+		reqs = append(reqs, NewDomainRequest(domain).WithServer(serverURL))
+	}
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	for _, ordered := range []bool{false, true} {
+		atomic.StoreInt32(&maxRunning, 0)
+
+		var indexes []int
+		for r := range client.Bulk(context.Background(), reqs, BulkOptions{Concurrency: 2, Ordered: ordered}) {
+			indexes = append(indexes, r.Index)
+
+			if r.Request != reqs[r.Index] {
+				t.Errorf("Result %d: unexpected request %s", r.Index, r.Request.Query)
+			}
+
+			if r.Index == 2 {
+				if !isClientError(ObjectDoesNotExist, r.Err) {
+					t.Errorf("Result %d: unexpected error %v", r.Index, r.Err)
+				}
+			} else if r.Err != nil || r.Response.Object == nil {
+				t.Errorf("Result %d: unexpected error %v", r.Index, r.Err)
+			}
+		}
+
+		if len(indexes) != len(reqs) {
+			t.Errorf("Got %d results, expected %d", len(indexes), len(reqs))
+		} else if ordered && (indexes[0] != 0 || indexes[4] != 4) {
+			t.Errorf("Results out of order: %v", indexes)
+		} else if !ordered && indexes[0] == 0 {
+			t.Errorf("Results not delivered as completed: %v", indexes)
+		}
+
+		if max := atomic.LoadInt32(&maxRunning); max > 2 {
+			t.Errorf("%d queries ran concurrently, expected at most 2", max)
+		}
+	}
+}
+
+func TestClientBulkCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reqs := make(chan *Request)
+	client := &Client{}
+
+	for r := range client.BulkStream(ctx, reqs, BulkOptions{}) {
+		t.Errorf("Unexpected result %+v", r)
+	}
+}
+
+func TestClientBulkBootstrapped(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	var reqs []*Request
+	for i := 0; i < 8; i++ {
+		reqs = append(reqs, NewDomainRequest("example.cz"))
+	}
+
+	// The Request's own context is kept.
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	reqs = append(reqs, NewDomainRequest("example.cz").WithContext(cancelled), nil)
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	for r := range client.Bulk(context.Background(), reqs, BulkOptions{Concurrency: 8}) {
+		switch r.Index {
+		case 8:
+			if r.Err == nil {
+				t.Errorf("Result %d: unexpected success with cancelled Request context", r.Index)
+			}
+		case 9:
+			if !isClientError(InputError, r.Err) {
+				t.Errorf("Result %d: unexpected error %v", r.Index, r.Err)
+			}
+		default:
+			if r.Err != nil || r.Response.Object == nil {
+				t.Errorf("Result %d: unexpected error %v", r.Index, r.Err)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// CIDR0Conformance is the rdapConformance identifier of the cidr0 extension,
+// used by ARIN and other RIRs to list an IP network's CIDR prefixes.
+const CIDR0Conformance = "cidr0"
+
+func init() {
+	RegisterExtension(Extension{
+		Name:        CIDR0Conformance,
+		Conformance: CIDR0Conformance,
+		Members:     []string{"cidr0_cidrs"},
+		Decode:      decodeCIDR0,
+		Print:       printCIDR0,
+	})
+}
+
+// CIDRs returns the IP network's CIDR prefixes, from its cidr0_cidrs member
+// (cidr0 extension). Returns nil if it has none.
+//
+// The prefixes are the canonical form of the network, whose start and end
+// addresses may not be a single prefix.
+func (n *IPNetwork) CIDRs() []netip.Prefix {
+	if n.DecodeData == nil {
+		return nil
+	}
+
+	cidrs, _ := n.DecodeData.Extension(CIDR0Conformance).([]netip.Prefix)
+
+	return cidrs
+}
+
+func decodeCIDR0(src map[string]interface{}) (interface{}, error) {
+	v, ok := src["cidr0_cidrs"]
+	if !ok {
+		return nil, nil
+	}
+
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cidr0_cidrs is not an array")
+	}
+
+	var cidrs []netip.Prefix
+	for i, item := range list {
+		cidr, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cidr0_cidrs[%d] is not an object", i)
+		}
+
+		prefix, err := parseCIDR0(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("cidr0_cidrs[%d]: %s", i, err)
+		}
+
+		cidrs = append(cidrs, prefix)
+	}
+
+	return cidrs, nil
+}
+
+// parseCIDR0 parses a cidr0_cidrs entry, e.g. {"v4prefix": "192.0.2.0",
+// "length": 24}.
+func parseCIDR0(cidr map[string]interface{}) (netip.Prefix, error) {
+	address, ok := cidr["v4prefix"].(string)
+	if !ok {
+		address, ok = cidr["v6prefix"].(string)
+	}
+	if !ok {
+		return netip.Prefix{}, fmt.Errorf("missing v4prefix/v6prefix")
+	}
+
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	if _, isV4 := cidr["v4prefix"].(string); isV4 != addr.Is4() {
+		return netip.Prefix{}, fmt.Errorf("address %s doesn't match the prefix type", address)
+	}
+
+	length, ok := cidr["length"].(float64)
+	if !ok {
+		return netip.Prefix{}, fmt.Errorf("missing length")
+	}
+
+	if length != float64(int(length)) || int(length) < 0 || int(length) > addr.BitLen() {
+		return netip.Prefix{}, fmt.Errorf("invalid length %v", length)
+	}
+
+	return netip.PrefixFrom(addr, int(length)), nil
+}
+
+func printCIDR0(value interface{}, p *ExtensionPrinter) {
+	for _, prefix := range value.([]netip.Prefix) {
+		p.Value("CIDR", prefix.String())
+	}
+}
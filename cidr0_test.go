@@ -0,0 +1,54 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestCIDR0(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.arin.net/ip-192.0.2.1.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := obj.(*IPNetwork)
+
+	expected := []netip.Prefix{
+		netip.MustParsePrefix("192.0.2.0/24"),
+		netip.MustParsePrefix("192.0.3.0/24"),
+	}
+
+	if cidrs := n.CIDRs(); !reflect.DeepEqual(cidrs, expected) {
+		t.Errorf("Got CIDRs %v, expected %v", cidrs, expected)
+	}
+
+	tests := []struct {
+		JSON  string
+		Valid bool
+	}{
+		{`{"v6prefix": "2001:db8::", "length": 32}`, true},
+		{`{"v4prefix": "2001:db8::", "length": 32}`, false},
+		{`{"v4prefix": "192.0.2.0", "length": 33}`, false},
+		{`{"v4prefix": "192.0.2.0"}`, false},
+		{`{"length": 24}`, false},
+	}
+
+	for _, test := range tests {
+		obj, err := NewDecoder([]byte(`{"objectClassName": "ip network", "cidr0_cidrs": [` + test.JSON + `]}`)).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		n := obj.(*IPNetwork)
+		if valid := len(n.CIDRs()) == 1; valid != test.Valid {
+			t.Errorf("%s: got valid=%v, notes %v", test.JSON, valid, n.DecodeData.Notes(CIDR0Conformance))
+		}
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/openrdap/rdap/cli
+
+go 1.21
+
+require (
+	github.com/alecthomas/kingpin/v2 v2.3.2
+	github.com/openrdap/rdap v0.11.0
+	golang.org/x/crypto v0.28.0
+)
+
+require (
+	github.com/alecthomas/units v0.0.0-20211218093645-b94a6e3cc137 // indirect
+	github.com/mitchellh/go-homedir v1.1.0 // indirect
+	github.com/xhit/go-str2duration/v2 v2.1.0 // indirect
+	golang.org/x/net v0.30.0 // indirect
+	golang.org/x/text v0.19.0 // indirect
+)
@@ -6,10 +6,15 @@ package rdap
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/openrdap/rdap/bootstrap"
@@ -30,6 +35,14 @@ import (
 //
 // The QueryDomain(), QueryAutnum(), and QueryIP() methods all provide full contact information, and timeout after 30s.
 //
+// Context aware versions of these methods (QueryDomainContext() etc) allow the
+// query to be cancelled, or a custom deadline to be set:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//
+//	domain, err := client.QueryDomainContext(ctx, "example.cz")
+//
 // Normal usage:
 //
 //	// Query example.cz.
@@ -73,22 +86,194 @@ import (
 //	  fmt.Printf("Handle=%s Domain=%s\n", ns.Handle, ns.LDHName)
 //	}
 type Client struct {
-	HTTP      *http.Client
+	// HTTP client used for RDAP queries, e.g. for custom TLS settings, proxies,
+	// or instrumentation. The default is a plain http.Client. Requests can
+	// override it, see Request.HTTP.
+	//
+	// Bootstrap downloads use the Bootstrap client's own HTTP client.
+	HTTP *http.Client
+
+	// Optional http.RoundTripper used instead of HTTP's own Transport. This
+	// wraps or replaces the transport, without replacing the http.Client.
+	Transport http.RoundTripper
+
 	Bootstrap *bootstrap.Client
 
 	// Optional callback function for verbose messages.
 	Verbose func(text string)
 
+	// Optional structured logger, for bootstrap lookups, server selection,
+	// HTTP attempts, retries, redirects, and decode warnings. See the Log*
+	// constants for the messages logged.
+	Logger *slog.Logger
+
+	// Optional Tracer, for distributed tracing of queries. See Tracer.
+	Tracer Tracer
+
+	// Optional timeouts for each phase of a query (bootstrap, connect, and
+	// response body). See Timeouts.
+	Timeouts Timeouts
+
+	// Optional division of each query's deadline between the bootstrap
+	// lookup, HTTP requests, retries, and redirects. The default (nil) is no
+	// division. See DeadlineBudget and DefaultDeadlineBudget().
+	DeadlineBudget *DeadlineBudget
+
+	// Optional resolver for RDAP server hostnames. The default (nil) is the
+	// system resolver. See Resolver and PinnedResolver.
+	//
+	// Applies when the Client's HTTP client (or Transport) uses an
+	// *http.Transport, which is the default.
+	Resolver Resolver
+
+	// Optional connection tuning (connection pool sizes, keep-alives,
+	// HTTP/2). See TransportOptions.
+	TransportOptions TransportOptions
+
+	// Optional Metrics, receiving query, HTTP request, and cache
+	// measurements. See Metrics.
+	Metrics Metrics
+
 	UserAgent string
 
+	// Response media types accepted. The default is MediaTypeDefault
+	// (application/rdap+json and application/json). See MediaTypePolicy.
+	MediaTypes MediaTypePolicy
+
+	// Query domains and nameservers by their Unicode (U-label) names, for RDAP
+	// servers which support this (RFC 9082 section 3.1.3).
+	//
+	// Internationalized domain names are always converted to A-labels for
+	// bootstrapping. By default, they are also queried as A-labels.
+	UnicodeQueries bool
+
+	// Optional policy for retrying failed HTTP requests to each RDAP server.
+	//
+	// The default (nil) is no retries. See DefaultRetryPolicy().
+	RetryPolicy *RetryPolicy
+
+	// Optional rate limiter, limiting the rate of queries to each RDAP server.
+	//
+	// The default (nil) is no rate limiting. See NewRateLimiter().
+	RateLimiter *RateLimiter
+
+	// Optional per-registry politeness policies (rate limits, concurrency
+	// limits, retry policies). The default (nil) is no limits. See
+	// DefaultPolitenessPresets().
+	Politeness PolitenessPresets
+
+	// Optional HTTP response cache. The default (nil) is no caching. See
+	// NewHTTPCache().
+	HTTPCache *HTTPCache
+
+	// Offline (cache only) mode. Queries are answered only from the
+	// HTTPCache (including stale responses), using cached bootstrap Service
+	// Registry files (or the embedded snapshot, see bootstrap.Client.Fallback),
+	// and never touch the network.
+	// Queries which can't be answered fail with a CacheMissError.
+	//
+	// Use a persistent HTTPCache (see HTTPCache.Backend) and bootstrap cache
+	// (see bootstrap.NewPersistentClient()) for analysis of previously
+	// collected data.
+	Offline bool
+
+	// Optional terms of service acknowledgment gate, for organizations which
+	// must record acceptance of each RDAP server's terms of service before
+	// querying it.
+	//
+	// When set, the first query to each RDAP server fetches the server's terms
+	// of service (using a help query), and calls this function. Returning
+	// false fails the query with a TermsOfServiceNotAcknowledged ClientError.
+	// The function is called again if the terms of service change. See also
+	// AcknowledgeTermsOfService().
+	TermsOfServiceGate func(tos *TermsOfService) bool
+
+	// Optional proxies, per RDAP server hostname. The default (nil) uses the
+	// environment's proxy settings. See Proxies.
+	Proxies Proxies
+
+	// Optional TLS client certificates, per RDAP server hostname, for servers
+	// requiring mutual TLS authentication. See ClientCertificates.
+	ClientCertificates ClientCertificates
+
+	// Optional OpenID Connect (RFC 9560) access tokens, per RDAP server
+	// hostname, for access to non-public registration data. See
+	// OpenIDTokens.
+	OpenID OpenIDTokens
+
+	// Optional static credentials (bearer tokens, basic authentication, API
+	// key headers), per RDAP server base URL. See Credentials.
+	Credentials Credentials
+
+	// Optional middleware chain, wrapping each HTTP request to RDAP servers.
+	// The first Middleware is the outermost. See Middleware.
+	Middleware []Middleware
+
+	// Optional policy for following HTTP redirects. The default (nil) is
+	// DefaultRedirectPolicy().
+	RedirectPolicy *RedirectPolicy
+
+	// Optional WHOIS (port 43) client, for domain queries on TLDs without an
+	// RDAP service in the bootstrap registry. The default (nil) is no
+	// fallback. See Response.Whois.
+	WhoisFallback *WhoisClient
+
+	// Follow registrar referrals. For domain queries answered by a thin
+	// registry, the registrar's RDAP server is also queried (using the
+	// response's "related" link). See Response.Referral and
+	// Response.MergedDomain().
+	FollowReferrals bool
+
+	// Race the RDAP servers, when the bootstrap lists more than one, instead
+	// of trying them in turn.
+	//
+	// The servers are queried in parallel, each started RaceStagger after the
+	// previous one (or as soon as the previous one fails). The first
+	// successful response is used, and the other queries are cancelled.
+	RaceServers bool
+
+	// Delay between starting each raced query. The default (0) is
+	// DefaultRaceStagger. A negative value starts all of the queries at once.
+	RaceStagger time.Duration
+
+	// Optional field set (RFC 8982) for search requests, e.g. "brief", to
+	// reduce the size of search results. Applies to search requests without
+	// a field set, see Request.WithFieldSet(). Servers not supporting the
+	// subsetting extension ignore it, see Response.Subsetting().
+	FieldSet string
+
+	// Coalesce identical concurrent queries. When several goroutines make
+	// the same query at once, only one HTTP request is made, and its
+	// response is shared (see HTTPResponse.Shared).
+	//
+	// Requests are identical if they have the same URL (and OpenID Connect
+	// access token). Requests with their own HTTP client (Request.HTTP), or
+	// to servers with a client certificate (see ClientCertificates), are
+	// never coalesced.
+	CoalesceQueries bool
+
+	mu                sync.Mutex
+	slots             map[string]chan struct{}
+	politenessLimiter *RateLimiter
+	tos               map[string]*TermsOfService
+	transports        map[hostTransportKey]*http.Transport
+
+	inflightMu sync.Mutex
+	inflight   map[string]*inflightCall
+
 	// Service Provider support is now always enabled.
 	// This field is ignored.
 	ServiceProviderExperiment bool
 }
 
-func (c *Client) Do(req *Request) (*Response, error) {
+// Do runs the RDAP request |req|, returning the response.
+//
+// The request is cancelled (including any bootstrap download) when the
+// Request's context is done, see Request.WithContext(). Request.Timeout is
+// also enforced if set.
+func (c *Client) Do(req *Request) (resp *Response, err error) {
 	// Response struct.
-	resp := &Response{}
+	resp = &Response{}
 
 	// Bad query?
 	if req == nil {
@@ -98,21 +283,49 @@ func (c *Client) Do(req *Request) (*Response, error) {
 		}
 	}
 
-	// Init HTTP client?
-	if c.HTTP == nil {
-		c.HTTP = &http.Client{}
+	// Apply the request timeout?
+	if req.Timeout > 0 {
+		ctx, cancelFunc := context.WithTimeout(req.Context(), req.Timeout)
+		defer cancelFunc()
+
+		req = req.WithContext(ctx)
 	}
 
-	// Init Bootstrap client?
-	if c.Bootstrap == nil {
-		c.Bootstrap = &bootstrap.Client{}
+	c.init()
+
+	// Convert internationalized domain names.
+	bootstrapQuery, query, err := idnQueries(req, c.UnicodeQueries)
+	if err != nil {
+		return nil, err
+	} else if query != req.Query {
+		c.Verbose(fmt.Sprintf("client: Converted query '%s' to '%s'", req.Query, query))
+
+		r2 := new(Request)
+		*r2 = *req
+		r2.Query = query
+		req = r2
 	}
 
-	// Init Verbose callback?
-	if c.Verbose == nil {
-		c.Verbose = func(text string) {}
+	// Apply the default field set?
+	if c.FieldSet != "" && isSearchRequestType(req.Type) && req.Params.Get("fieldSet") == "" {
+		req = req.WithFieldSet(c.FieldSet)
 	}
 
+	start := time.Now()
+	defer func() {
+		c.logQuery(req, resp, err, time.Since(start))
+
+		if c.Metrics != nil {
+			c.Metrics.QueryDone(req.Type, queryOutcome(err), time.Since(start))
+		}
+	}()
+
+	ctx, span := c.startSpan(req.Context(), SpanQuery, spanAttributes(req))
+	defer func() {
+		span.End(err)
+	}()
+	req = req.WithContext(ctx)
+
 	c.Verbose("")
 	c.Verbose(fmt.Sprintf("client: Running..."))
 	c.Verbose(fmt.Sprintf("client: Request type  : %s", req.Type))
@@ -139,30 +352,58 @@ func (c *Client) Do(req *Request) (*Response, error) {
 			}
 		}
 
-		origBootstrapVerbose := c.Bootstrap.Verbose
-		c.Bootstrap.Verbose = c.Verbose
-		defer func() {
-			c.Bootstrap.Verbose = origBootstrapVerbose
-		}()
-
 		question := &bootstrap.Question{
 			RegistryType: *bootstrapType,
-			Query:        req.Query,
+			Query:        bootstrapQuery,
+			Offline:      c.Offline,
+			Verbose:      c.Verbose,
 		}
-		question = question.WithContext(req.Context())
+
+		bootstrapCtx, bootstrapSpan := c.startSpan(req.Context(), SpanBootstrap, map[string]interface{}{
+			AttrRegistry: bootstrapType.String(),
+			AttrQuery:    bootstrapQuery,
+		})
+		bootstrapCtx, cancelBootstrap, bootstrapTimedOut := withPhaseTimeout(bootstrapCtx, "bootstrap", c.bootstrapTimeout(bootstrapCtx))
+		defer cancelBootstrap()
+
+		question = question.WithContext(bootstrapCtx)
 
 		var answer *bootstrap.Answer
 		var err error
 
+		bootstrapStart := time.Now()
 		answer, err = c.Bootstrap.Lookup(question)
 		resp.BootstrapAnswer = answer
 
+		if timeoutErr := bootstrapTimedOut(); err != nil && timeoutErr != nil {
+			err = timeoutErr
+		} else if errors.Is(err, bootstrap.ErrNotCached) {
+			err = &CacheMissError{Key: bootstrapType.Filename()}
+		}
+		bootstrapSpan.End(err)
+
+		var servers int
+		if answer != nil {
+			servers = len(answer.URLs)
+		}
+
+		c.log(req.Context(), slog.LevelDebug, LogBootstrap,
+			slog.String("registry", bootstrapType.String()),
+			slog.String("query", bootstrapQuery),
+			slog.Int("servers", servers),
+			slog.Duration("duration", time.Since(bootstrapStart)),
+			errorAttr(err))
+
 		if err != nil {
 			return resp, err
 		}
 
 		// No URLs to query?
 		if len(answer.URLs) == 0 {
+			if req.Type == DomainRequest && c.WhoisFallback != nil && !c.Offline {
+				return c.queryWhois(req, resp)
+			}
+
 			return resp, &ClientError{
 				Type: BootstrapNoMatch,
 				Text: fmt.Sprintf("No RDAP servers found for '%s'", question.Query),
@@ -178,63 +419,229 @@ func (c *Client) Do(req *Request) (*Response, error) {
 		c.Verbose(fmt.Sprintf("client: RDAP URL #%d is %s", i, r.URL()))
 	}
 
-	for _, r := range reqs {
-		c.Verbose(fmt.Sprintf("client: GET %s", r.URL()))
+	if c.RaceServers && len(reqs) > 1 && !c.Offline {
+		resp, err = c.race(resp, reqs)
+		if err == nil {
+			c.followReferral(req, resp)
+		}
 
-		httpResponse := c.get(r)
-		resp.HTTP = append(resp.HTTP, httpResponse)
+		return resp, err
+	}
 
-		if httpResponse.Error != nil {
-			c.Verbose(fmt.Sprintf("client: error: %s",
-				httpResponse.Error))
+	var rateLimited *RateLimitedError
+	var cacheMiss error
+
+	for _, r := range reqs {
+		result := c.queryServer(r)
+
+		if result.httpResponse != nil {
+			resp.HTTP = append(resp.HTTP, result.httpResponse)
+		}
 
-			if r.Context().Err() == context.DeadlineExceeded {
-				return resp, httpResponse.Error
+		if result.done {
+			resp.Object = result.object
+			if result.err == nil {
+				c.followReferral(req, resp)
 			}
 
-			// Continues to the next RDAP server.
-		} else {
-			hrr := httpResponse.Response
+			return resp, result.err
+		} else if result.rateLimited != nil {
+			rateLimited = result.rateLimited
+		} else if result.httpResponse != nil && errors.Is(result.httpResponse.Error, ErrCacheMiss) {
+			cacheMiss = result.httpResponse.Error
+		}
 
-			c.Verbose(fmt.Sprintf("client: status-code=%d, content-type=%s, length=%d bytes, duration=%s",
-				hrr.StatusCode,
-				hrr.Header.Get("Content-Type"),
-				len(httpResponse.Body),
-				httpResponse.Duration))
+		// Continues to the next RDAP server.
+	}
 
-			if len(httpResponse.Body) > 0 && hrr.StatusCode >= 200 && hrr.StatusCode <= 299 {
-				// Decode the response.
-				decoder := NewDecoder(httpResponse.Body)
+	if cacheMiss != nil && rateLimited == nil {
+		return resp, cacheMiss
+	}
 
-				resp.Object, httpResponse.Error = decoder.Decode()
+	return resp, noWorkingServers(reqs, rateLimited)
+}
 
-				if httpResponse.Error != nil {
-					c.Verbose(fmt.Sprintf("client: Error decoding response: %s",
-						httpResponse.Error))
-					continue
-				}
+// serverResult is the result of querying a single RDAP server.
+type serverResult struct {
+	httpResponse *HTTPResponse
 
-				c.Verbose("client: Successfully decoded response")
+	// True if the query is finished, successfully (object is set), or with
+	// err. Otherwise, the next RDAP server should be tried.
+	done   bool
+	object RDAPObject
+	err    error
 
-				// Implement additional fetches here.
+	// Set if the server rate limited the query.
+	rateLimited *RateLimitedError
+}
 
-				return resp, nil
-			} else if hrr.StatusCode == 404 {
-				return resp, &ClientError{
-					Type: ObjectDoesNotExist,
-					Text: fmt.Sprintf("RDAP server returned 404, object does not exist."),
-				}
-			}
+// queryServer runs the RDAP request |r| on its RDAP server, and decodes the
+// response.
+func (c *Client) queryServer(r *Request) *serverResult {
+	// Timed out/cancelled before trying this server?
+	if err := r.Context().Err(); err != nil {
+		return &serverResult{done: true, err: err}
+	}
+
+	if err := c.checkTermsOfService(r); err != nil {
+		return &serverResult{done: true, err: err}
+	}
+
+	if !c.Offline {
+		var err error
+		r, err = c.withOpenIDToken(r)
+		if err != nil {
+			return &serverResult{done: true, err: err}
+		}
+	}
+
+	c.Verbose(fmt.Sprintf("client: GET %s", r.URL()))
+	c.log(r.Context(), slog.LevelDebug, LogServer,
+		slog.String("query_type", r.Type.String()),
+		slog.String("query", r.Query),
+		slog.String("url", r.URL().String()))
+
+	httpResponse := c.getCoalesced(r)
+	result := &serverResult{httpResponse: httpResponse}
+
+	if httpResponse.Error != nil {
+		c.Verbose(fmt.Sprintf("client: error: %s",
+			httpResponse.Error))
+
+		// Timed out/cancelled?
+		if r.Context().Err() != nil {
+			result.done = true
+			result.err = httpResponse.Error
+		}
+
+		return result
+	}
+
+	hrr := httpResponse.Response
+
+	c.Verbose(fmt.Sprintf("client: status-code=%d, content-type=%s, length=%d bytes, duration=%s",
+		hrr.StatusCode,
+		hrr.Header.Get("Content-Type"),
+		len(httpResponse.Body),
+		httpResponse.Duration))
+
+	if len(httpResponse.Body) > 0 && hrr.StatusCode >= 200 && hrr.StatusCode <= 299 {
+		if httpResponse.Error = c.checkMediaType(httpResponse); httpResponse.Error != nil {
+			c.Verbose(fmt.Sprintf("client: %s", httpResponse.Error))
+			return result
 		}
+
+		// Decode the response.
+		httpResponse.DecodeStats = &DecodeStats{}
+		decoder := NewDecoder(httpResponse.Body, WithDecodeStats(httpResponse.DecodeStats))
+
+		_, decodeSpan := c.startSpan(r.Context(), SpanDecode, map[string]interface{}{
+			AttrBaseURL:  r.baseURL(),
+			AttrBodySize: len(httpResponse.Body),
+		})
+
+		var obj RDAPObject
+		obj, httpResponse.Error = decoder.Decode()
+		decodeSpan.End(httpResponse.Error)
+		c.Verbose(fmt.Sprintf("client: decode stats: %s", httpResponse.DecodeStats))
+
+		if httpResponse.DecodeStats.Notes > 0 {
+			c.log(r.Context(), slog.LevelWarn, LogDecodeWarnings,
+				slog.String("url", httpResponse.URL),
+				slog.Int("notes", httpResponse.DecodeStats.Notes))
+		}
+
+		if httpResponse.Error != nil {
+			c.Verbose(fmt.Sprintf("client: Error decoding response: %s",
+				httpResponse.Error))
+			return result
+		}
+
+		c.Verbose("client: Successfully decoded response")
+
+		c.recordTermsOfService(r, obj)
+
+		// Implement additional fetches here.
+
+		result.done = true
+		result.object = obj
+	} else if hrr.StatusCode == 404 {
+		result.done = true
+		result.err = &ClientError{
+			Type:          ObjectDoesNotExist,
+			Text:          fmt.Sprintf("RDAP server returned 404, object does not exist."),
+			ErrorResponse: decodeErrorResponse(httpResponse.Body),
+		}
+	} else if e := decodeErrorResponse(httpResponse.Body); e != nil && hrr.StatusCode >= 400 && hrr.StatusCode <= 499 && hrr.StatusCode != 429 {
+		// The server answered with an RDAP error object, e.g. 400 Bad Request
+		// or 403 Forbidden. Other servers won't answer differently.
+		c.Verbose(fmt.Sprintf("client: %s", e))
+
+		result.done = true
+		result.err = clientErrorFromRDAPError(e)
+	} else if result.rateLimited = rateLimitedError(httpResponse); result.rateLimited != nil {
+		c.Verbose(fmt.Sprintf("client: %s", result.rateLimited))
 	}
 
-	return resp, &ClientError{
+	return result
+}
+
+// noWorkingServers returns the error for a query which failed on all of the
+// RDAP servers |reqs|. If any of them rate limited the query, |rateLimited|
+// is the last RateLimitedError, and is returned instead.
+func noWorkingServers(reqs []*Request, rateLimited *RateLimitedError) error {
+	if rateLimited != nil {
+		return rateLimited
+	}
+
+	return &ClientError{
 		Type: NoWorkingServers,
 		Text: fmt.Sprintf("No RDAP servers responded successfully (tried %d server(s))",
 			len(reqs)),
 	}
 }
 
+// logQuery logs the result of the RDAP request |req|.
+func (c *Client) logQuery(req *Request, resp *Response, err error, duration time.Duration) {
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+	}
+
+	var url string
+	if resp != nil && len(resp.HTTP) > 0 {
+		url = resp.HTTP[len(resp.HTTP)-1].URL
+	}
+
+	c.log(req.Context(), level, LogQuery,
+		slog.String("query_type", req.Type.String()),
+		slog.String("query", req.Query),
+		slog.String("url", url),
+		slog.Duration("duration", duration),
+		errorAttr(err))
+}
+
+// init sets default values for unset Client fields.
+func (c *Client) init() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Init HTTP client?
+	if c.HTTP == nil {
+		c.HTTP = &http.Client{}
+	}
+
+	// Init Bootstrap client?
+	if c.Bootstrap == nil {
+		c.Bootstrap = &bootstrap.Client{}
+	}
+
+	// Init Verbose callback?
+	if c.Verbose == nil {
+		c.Verbose = func(text string) {}
+	}
+}
+
 func (c *Client) get(rdapReq *Request) *HTTPResponse {
 	// HTTPResponse stores the URL, http.Response, response body...
 	httpResponse := &HTTPResponse{
@@ -243,8 +650,37 @@ func (c *Client) get(rdapReq *Request) *HTTPResponse {
 
 	start := time.Now()
 
-	// Setup the HTTP request.
-	req, err := http.NewRequest("GET", httpResponse.URL, nil)
+	ctx, span := c.startSpan(rdapReq.Context(), SpanHTTP, map[string]interface{}{
+		AttrURL:     httpResponse.URL,
+		AttrBaseURL: rdapReq.baseURL(),
+	})
+	defer func() {
+		if httpResponse.Response != nil {
+			span.SetAttribute(AttrStatusCode, httpResponse.Response.StatusCode)
+			span.SetAttribute(AttrBodySize, len(httpResponse.Body))
+		}
+		span.SetAttribute(AttrCached, httpResponse.Cached)
+		span.End(httpResponse.Error)
+
+		c.recordHTTPMetrics(rdapReq, httpResponse)
+	}()
+
+	// Enough time left?
+	if c.DeadlineBudget.exhausted(ctx, 0) {
+		httpResponse.Error = ErrDeadlineBudgetExhausted
+		return httpResponse
+	}
+
+	// Limit the request to its share of the deadline?
+	ctx, cancelAttempt, attemptTimedOut := withPhaseTimeout(ctx, "attempt", c.attemptTimeout(ctx))
+	defer cancelAttempt()
+
+	// Setup the HTTP request, with context for timeout/cancellation.
+	ctx = context.WithValue(ctx, requestContextKey{}, rdapReq)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", httpResponse.URL, nil)
 	if err != nil {
 		httpResponse.Error = err
 		httpResponse.Duration = time.Since(start)
@@ -257,13 +693,33 @@ func (c *Client) get(rdapReq *Request) *HTTPResponse {
 	}
 
 	// HTTP Accept header.
-	req.Header.Add("Accept", "application/rdap+json, application/json")
+	req.Header.Add("Accept", c.MediaTypes.accept())
+
+	// Credentials for the server?
+	c.applyCredentials(req)
+
+	// OpenID Connect access token?
+	if rdapReq.openIDToken != nil {
+		req.Header.Set("Authorization", "Bearer "+rdapReq.openIDToken.AccessToken)
+	}
 
-	// Add context for timeout.
-	req = req.WithContext(rdapReq.Context())
+	// Revalidate a stale cached response?
+	var cached *httpCacheEntry
+	if c.usesHTTPCache(rdapReq) {
+		cached, _ = c.HTTPCache.get(httpResponse.URL)
+
+		if cached != nil {
+			if cached.etag != "" {
+				req.Header.Set("If-None-Match", cached.etag)
+			}
+			if cached.lastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.lastModified)
+			}
+		}
+	}
 
 	// Make the HTTP request.
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.httpClientFor(rdapReq, httpResponse).Do(req)
 	httpResponse.Response = resp
 
 	// Handle errors such as "remote doesn't speak HTTP"...
@@ -271,12 +727,46 @@ func (c *Client) get(rdapReq *Request) *HTTPResponse {
 		httpResponse.Error = err
 		httpResponse.Duration = time.Since(start)
 
+		if timeoutErr := attemptTimedOut(); timeoutErr != nil {
+			httpResponse.Error = timeoutErr
+		}
+
 		return httpResponse
 	}
 
 	defer resp.Body.Close()
+
+	// Limit the time reading the body?
+	var bodyTimedOut atomic.Bool
+	if c.Timeouts.Body > 0 {
+		timer := time.AfterFunc(c.Timeouts.Body, func() {
+			bodyTimedOut.Store(true)
+			cancel()
+		})
+		defer timer.Stop()
+	}
+
 	httpResponse.Body, httpResponse.Error = ioutil.ReadAll(resp.Body)
 
+	if httpResponse.Error != nil && bodyTimedOut.Load() {
+		httpResponse.Error = &PhaseTimeoutError{Phase: "body", Limit: c.Timeouts.Body}
+	} else if timeoutErr := attemptTimedOut(); httpResponse.Error != nil && timeoutErr != nil {
+		httpResponse.Error = timeoutErr
+	}
+
+	if c.usesHTTPCache(rdapReq) && httpResponse.Error == nil {
+		if cached != nil && resp.StatusCode == http.StatusNotModified {
+			c.Verbose(fmt.Sprintf("client: cached response for %s revalidated", httpResponse.URL))
+
+			cached = c.HTTPCache.revalidated(cached, resp)
+			httpResponse.Response = cached.response(req)
+			httpResponse.Body = cached.body
+			httpResponse.Cached = true
+		} else {
+			c.HTTPCache.store(httpResponse.URL, resp, httpResponse.Body)
+		}
+	}
+
 	httpResponse.Duration = time.Since(start)
 
 	return httpResponse
@@ -286,12 +776,21 @@ func (c *Client) get(rdapReq *Request) *HTTPResponse {
 //
 // Full contact information (where available) is provided. The timeout is 30s.
 func (c *Client) QueryDomain(domain string) (*Domain, error) {
+	return c.QueryDomainContext(context.Background(), domain)
+}
+
+// QueryDomainContext makes an RDAP request for the |domain|, with context
+// |ctx|.
+//
+// As per QueryDomain(), but the request is cancelled when |ctx| is done. The
+// timeout is 30s, unless |ctx| has a deadline.
+func (c *Client) QueryDomainContext(ctx context.Context, domain string) (*Domain, error) {
 	req := &Request{
 		Type:  DomainRequest,
 		Query: domain,
 	}
 
-	resp, err := c.doQuickRequest(req)
+	resp, err := c.doQuickRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -308,9 +807,12 @@ func (c *Client) QueryDomain(domain string) (*Domain, error) {
 	}
 }
 
-func (c *Client) doQuickRequest(req *Request) (*Response, error) {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*30)
-	defer cancelFunc()
+func (c *Client) doQuickRequest(ctx context.Context, req *Request) (*Response, error) {
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancelFunc context.CancelFunc
+		ctx, cancelFunc = context.WithTimeout(ctx, time.Second*30)
+		defer cancelFunc()
+	}
 
 	req = req.WithContext(ctx)
 	resp, err := c.Do(req)
@@ -324,12 +826,21 @@ func (c *Client) doQuickRequest(req *Request) (*Response, error) {
 //
 // Full contact information (where available) is provided. The timeout is 30s.
 func (c *Client) QueryAutnum(autnum string) (*Autnum, error) {
+	return c.QueryAutnumContext(context.Background(), autnum)
+}
+
+// QueryAutnumContext makes an RDAP request for the Autonomous System Number
+// (ASN) |autnum|, with context |ctx|.
+//
+// As per QueryAutnum(), but the request is cancelled when |ctx| is done. The
+// timeout is 30s, unless |ctx| has a deadline.
+func (c *Client) QueryAutnumContext(ctx context.Context, autnum string) (*Autnum, error) {
 	req := &Request{
 		Type:  AutnumRequest,
 		Query: autnum,
 	}
 
-	resp, err := c.doQuickRequest(req)
+	resp, err := c.doQuickRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -350,12 +861,21 @@ func (c *Client) QueryAutnum(autnum string) (*Autnum, error) {
 //
 // Full contact information (where available) is provided. The timeout is 30s.
 func (c *Client) QueryIP(ip string) (*IPNetwork, error) {
+	return c.QueryIPContext(context.Background(), ip)
+}
+
+// QueryIPContext makes an RDAP request for the IPv4/6 address |ip|, with
+// context |ctx|.
+//
+// As per QueryIP(), but the request is cancelled when |ctx| is done. The
+// timeout is 30s, unless |ctx| has a deadline.
+func (c *Client) QueryIPContext(ctx context.Context, ip string) (*IPNetwork, error) {
 	req := &Request{
 		Type:  IPRequest,
 		Query: ip,
 	}
 
-	resp, err := c.doQuickRequest(req)
+	resp, err := c.doQuickRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -372,6 +892,111 @@ func (c *Client) QueryIP(ip string) (*IPNetwork, error) {
 	}
 }
 
+// QueryHelp makes an RDAP help request to the RDAP server |server| (a base
+// URL, e.g. "https://rdap.nic.cz").
+//
+// The help response describes the server's policies, e.g. its terms of
+// service and rate limits, see Help.TermsOfService() and
+// Help.RateLimitNotices(). The timeout is 30s.
+func (c *Client) QueryHelp(server string) (*Help, error) {
+	return c.QueryHelpContext(context.Background(), server)
+}
+
+// QueryHelpContext makes an RDAP help request to the RDAP server |server|,
+// with context |ctx|.
+//
+// As per QueryHelp(), but the request is cancelled when |ctx| is done. The
+// timeout is 30s, unless |ctx| has a deadline.
+func (c *Client) QueryHelpContext(ctx context.Context, server string) (*Help, error) {
+	u, err := parseServerURL(server)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doQuickRequest(ctx, NewHelpRequest().WithServer(u))
+	if err != nil {
+		return nil, err
+	}
+
+	if help, ok := resp.Object.(*Help); ok {
+		return help, nil
+	} else if respError, ok := resp.Object.(*Error); ok {
+		return nil, clientErrorFromRDAPError(respError)
+	}
+
+	return nil, &ClientError{
+		Type: WrongResponseType,
+		Text: "The server returned a non-Help RDAP response",
+	}
+}
+
+// QueryURL makes an RDAP request for the RDAP URL |rdapURL|, e.g. the href of
+// a "related" link, or a known registrar RDAP URL.
+//
+// Bootstrapping is skipped, but the response is decoded as usual (e.g.
+// resp.Object is a *Domain), and the Client's retries, caching, and other
+// options apply. An RDAP error response is returned as a ClientError. The
+// timeout is 30s.
+func (c *Client) QueryURL(rdapURL string) (*Response, error) {
+	return c.QueryURLContext(context.Background(), rdapURL)
+}
+
+// QueryURLContext makes an RDAP request for the RDAP URL |rdapURL|, with
+// context |ctx|.
+//
+// As per QueryURL(), but the request is cancelled when |ctx| is done. The
+// timeout is 30s, unless |ctx| has a deadline.
+func (c *Client) QueryURLContext(ctx context.Context, rdapURL string) (*Response, error) {
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancelFunc context.CancelFunc
+		ctx, cancelFunc = context.WithTimeout(ctx, time.Second*30)
+		defer cancelFunc()
+	}
+
+	return c.queryURL(ctx, rdapURL)
+}
+
+// queryURL makes an RDAP request for the RDAP URL |rdapURL|, without a default
+// timeout.
+func (c *Client) queryURL(ctx context.Context, rdapURL string) (*Response, error) {
+	u, err := url.Parse(rdapURL)
+	if err != nil || !isHTTPURL(u) {
+		return nil, &ClientError{
+			Type: InputError,
+			Text: fmt.Sprintf("Invalid RDAP URL '%s'", rdapURL),
+		}
+	}
+
+	resp, err := c.Do(NewRawRequest(u).WithContext(ctx))
+	if err != nil {
+		return resp, err
+	}
+
+	if respError, ok := resp.Object.(*Error); ok {
+		return resp, clientErrorFromRDAPError(respError)
+	}
+
+	return resp, nil
+}
+
+// parseServerURL parses the RDAP server base URL |server|.
+func parseServerURL(server string) (*url.URL, error) {
+	u, err := url.Parse(server)
+	if err != nil || !isHTTPURL(u) {
+		return nil, &ClientError{
+			Type: InputError,
+			Text: fmt.Sprintf("Invalid RDAP server URL '%s'", server),
+		}
+	}
+
+	return u, nil
+}
+
+// isHTTPURL returns true if |u| is an absolute http or https URL.
+func isHTTPURL(u *url.URL) bool {
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
 func bootstrapTypeFor(req *Request) *bootstrap.RegistryType {
 	b := new(bootstrap.RegistryType)
 
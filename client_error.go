@@ -5,8 +5,7 @@
 package rdap
 
 import (
-	"fmt"
-	"strings"
+	"errors"
 )
 
 type ClientErrorType uint
@@ -21,17 +20,38 @@ const (
 	NoWorkingServers
 	ObjectDoesNotExist
 	RDAPServerError
+	TermsOfServiceNotAcknowledged
+	RedirectLoop
+	TooManyRedirects
+	RedirectNotAllowed
+	AuthenticationFailed
+	UnsupportedMediaType
 )
 
 type ClientError struct {
 	Type ClientErrorType
 	Text string
+
+	// RDAP error response returned by the server, if any.
+	//
+	// Also available with errors.As(err, &rdapError), where rdapError is an
+	// *rdap.Error.
+	ErrorResponse *Error
 }
 
 func (c ClientError) Error() string {
 	return c.Text
 }
 
+// Unwrap returns the server's RDAP error response, or nil if there's none.
+func (c ClientError) Unwrap() error {
+	if c.ErrorResponse == nil {
+		return nil
+	}
+
+	return c.ErrorResponse
+}
+
 func isClientError(t ClientErrorType, err error) bool {
 	if ce, ok := err.(*ClientError); ok {
 		if ce.Type == t {
@@ -42,12 +62,25 @@ func isClientError(t ClientErrorType, err error) bool {
 	return false
 }
 
+// IsNotFound returns true if |err| means the queried object doesn't exist:
+// either the RDAP server returned 404 Not Found, or an RDAP error response with
+// errorCode 404.
+//
+// Transport failures, timeouts, and other server errors return false.
+func IsNotFound(err error) bool {
+	var ce *ClientError
+	if errors.As(err, &ce) && ce.Type == ObjectDoesNotExist {
+		return true
+	}
+
+	var e *Error
+	return errors.As(err, &e) && e.ErrorCode != nil && *e.ErrorCode == 404
+}
+
 func clientErrorFromRDAPError(e *Error) *ClientError {
 	return &ClientError{
-		Type: RDAPServerError,
-		Text: fmt.Sprintf("Server returned error code %d, title='%s', description='%s'",
-			e.ErrorCode,
-			e.Title,
-			strings.Join(e.Description, " ")),
+		Type:          RDAPServerError,
+		Text:          e.Error(),
+		ErrorResponse: e,
 	}
 }
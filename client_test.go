@@ -5,8 +5,15 @@
 package rdap
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/openrdap/rdap/test"
 )
@@ -60,6 +67,42 @@ func TestClientQueryDomain404(t *testing.T) {
 	}
 }
 
+func TestClientQueryDomainErrorResponse(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	_, err := client.QueryDomain("deleted.cz")
+
+	var rdapError *Error
+	if !IsNotFound(err) || !isClientError(ObjectDoesNotExist, err) {
+		t.Errorf("Unexpected err %v", err)
+	} else if !errors.As(err, &rdapError) || rdapError.Title != "Object not found" {
+		t.Errorf("Unexpected error response %+v", rdapError)
+	}
+
+	// 404 without an RDAP error response.
+	_, err = client.QueryDomain("non-existent.cz")
+	if !IsNotFound(err) || errors.As(err, &rdapError) {
+		t.Errorf("Unexpected err %v", err)
+	}
+
+	_, err = client.QueryDomain("bad-request.cz")
+	if IsNotFound(err) || !isClientError(RDAPServerError, err) {
+		t.Errorf("Unexpected err %v", err)
+	} else if !errors.As(err, &rdapError) || *rdapError.ErrorCode != 400 {
+		t.Errorf("Unexpected error response %+v", rdapError)
+	}
+
+	if IsNotFound(context.DeadlineExceeded) {
+		t.Errorf("Transport error is not found")
+	}
+}
+
 func TestClientQueryDomainWrongType(t *testing.T) {
 	test.Start(test.Bootstrap)
 	test.Start(test.Responses)
@@ -96,8 +139,352 @@ func TestClientQueryDomainMalformed(t *testing.T) {
 	}
 }
 
+func TestClientQueryHelp(t *testing.T) {
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	help, err := client.QueryHelp("https://rdap.nic.cz")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(help.Notices) != 3 {
+		t.Errorf("Unexpected notices %+v", help.Notices)
+	}
+
+	if tos := help.TermsOfService(); tos == nil || tos.URL() != "https://www.nic.cz/page/306/" {
+		t.Errorf("Unexpected terms of service %+v", tos)
+	}
+
+	if limits := help.RateLimitNotices(); len(limits) != 1 || limits[0].Title != "Query Limits" {
+		t.Errorf("Unexpected rate limit notices %+v", limits)
+	}
+
+	if _, err := client.QueryHelp("rdap.nic.cz"); !isClientError(InputError, err) {
+		t.Errorf("Unexpected err %v", err)
+	}
+}
+
+func TestClientQueryURL(t *testing.T) {
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	resp, err := client.QueryURL("https://rdap.registrar.example/domain/thin.cz")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if domain, ok := resp.Object.(*Domain); !ok || domain.LDHName != "thin.cz" {
+		t.Errorf("Unexpected object %+v", resp.Object)
+	} else if resp.BootstrapAnswer != nil {
+		t.Errorf("Unexpected bootstrap")
+	}
+
+	_, err = client.QueryURL("https://rdap.nic.cz/domain/non-existent.cz")
+	if !isClientError(ObjectDoesNotExist, err) {
+		t.Errorf("Unexpected err %v", err)
+	}
+
+	for _, u := range []string{"rdap.nic.cz/domain/example.cz", "ftp://rdap.nic.cz/", "https://"} {
+		if _, err := client.QueryURL(u); !isClientError(InputError, err) {
+			t.Errorf("URL %s: unexpected err %v", u, err)
+		}
+	}
+}
+
+func TestClientQueryEntityObjectTag(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	// Routed to ARIN's server using the object tag.
+	resp, err := client.Do(NewEntityRequest("ABC123-ARIN"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	entity, ok := resp.Object.(*Entity)
+	if !ok {
+		t.Fatalf("Unexpected response type %T", resp.Object)
+	} else if entity.Handle != "ABC123-ARIN" {
+		t.Errorf("Unexpected Handle %s", entity.Handle)
+	}
+}
+
 // test Do()
 // 1) success, 1 of each query
 // 2) bootstrap not supported
 // 3) bootstrap no match
 // test Help...
+
+func TestClientQueryDomainContextCancelled(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.QueryDomainContext(ctx, "example.cz")
+
+	if err == nil {
+		t.Errorf("Unexpected success")
+	} else if !errors.Is(err, context.Canceled) {
+		t.Errorf("Unexpected err %s", err)
+	}
+}
+
+// blockingTransport is a http.RoundTripper which waits for the request's
+// context to be done.
+type blockingTransport struct{}
+
+func (b blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+
+	return nil, req.Context().Err()
+}
+
+func TestClientDoTimeout(t *testing.T) {
+	client := &Client{
+		HTTP:    &http.Client{Transport: blockingTransport{}},
+		Verbose: verboseFunc(),
+	}
+
+	server, _ := url.Parse("https://rdap.example")
+	req := NewDomainRequest("example.cz").WithServer(server)
+	req.Timeout = time.Millisecond * 10
+
+	_, err := client.Do(req)
+
+	if err == nil {
+		t.Errorf("Unexpected success")
+	} else if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected err %s", err)
+	}
+}
+
+// sequenceTransport is a http.RoundTripper which responds with each of
+// |statuses| in turn. Error responses include the |retryAfter| header, if set.
+type sequenceTransport struct {
+	statuses   []int
+	retryAfter string
+	requests   int
+}
+
+func (s *sequenceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := s.statuses[s.requests]
+	s.requests++
+
+	body := ""
+	header := http.Header{}
+	if status == 200 {
+		body = string(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	} else if s.retryAfter != "" {
+		header.Set("Retry-After", s.retryAfter)
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestClientRetryPolicy(t *testing.T) {
+	server, _ := url.Parse("https://rdap.nic.cz")
+	req := NewDomainRequest("example.cz").WithServer(server)
+
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+
+	transport := &sequenceTransport{statuses: []int{503, 502, 200}}
+	client := &Client{
+		HTTP:        &http.Client{Transport: transport},
+		RetryPolicy: policy,
+		Verbose:     verboseFunc(),
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if _, ok := resp.Object.(*Domain); !ok {
+		t.Errorf("Unexpected response %v", resp.Object)
+	} else if resp.HTTP[0].Attempts != 3 || transport.requests != 3 {
+		t.Errorf("Unexpected number of attempts %d", resp.HTTP[0].Attempts)
+	}
+
+	// Attempts exhausted.
+	transport = &sequenceTransport{statuses: []int{503, 503, 503}}
+	client.HTTP = &http.Client{Transport: transport}
+
+	_, err = client.Do(req)
+	if !isClientError(NoWorkingServers, err) || transport.requests != 3 {
+		t.Errorf("Unexpected err %v after %d requests", err, transport.requests)
+	}
+
+	// Non-retryable status.
+	transport = &sequenceTransport{statuses: []int{500, 200}}
+	client.HTTP = &http.Client{Transport: transport}
+
+	_, err = client.Do(req)
+	if err == nil || transport.requests != 1 {
+		t.Errorf("Unexpected err %v after %d requests", err, transport.requests)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Second * 5,
+		Multiplier:     2,
+	}
+
+	expected := []time.Duration{time.Second, time.Second * 2, time.Second * 4, time.Second * 5, time.Second * 5}
+	for i, e := range expected {
+		if got := p.backoff(i + 1); got != e {
+			t.Errorf("backoff(%d) = %s, expected %s", i+1, got, e)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(1); got < time.Millisecond*500 || got > time.Second {
+			t.Errorf("backoff(1) with jitter = %s", got)
+		}
+	}
+}
+
+func TestClientRateLimited(t *testing.T) {
+	server, _ := url.Parse("https://rdap.nic.cz")
+	req := NewDomainRequest("example.cz").WithServer(server)
+
+	// No RetryPolicy, so the rate limiting is returned.
+	transport := &sequenceTransport{statuses: []int{429}, retryAfter: "120"}
+	client := &Client{
+		HTTP:    &http.Client{Transport: transport},
+		Verbose: verboseFunc(),
+	}
+
+	_, err := client.Do(req)
+
+	var rateLimited *RateLimitedError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &rateLimited) {
+		t.Fatalf("Unexpected err %v", err)
+	} else if rateLimited.RetryAfter != time.Minute*2 || rateLimited.StatusCode != 429 {
+		t.Errorf("Unexpected RateLimitedError %+v", rateLimited)
+	}
+
+	// Retried after the Retry-After delay.
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+
+	transport = &sequenceTransport{statuses: []int{429, 503, 200}, retryAfter: "0"}
+	client.HTTP = &http.Client{Transport: transport}
+	client.RetryPolicy = policy
+
+	if _, err := client.Do(req); err != nil || transport.requests != 3 {
+		t.Errorf("Unexpected err %v after %d requests", err, transport.requests)
+	}
+
+	// Retry-After exceeds the context deadline, so isn't waited for.
+	transport = &sequenceTransport{statuses: []int{503, 200}, retryAfter: "30"}
+	client.HTTP = &http.Client{Transport: transport}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	_, err = client.Do(req.WithContext(ctx))
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != time.Second*30 || transport.requests != 1 {
+		t.Errorf("Unexpected err %v after %d requests", err, transport.requests)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2015, 10, 21, 7, 27, 0, 0, time.UTC)
+
+	tests := []struct {
+		Status     int
+		RetryAfter string
+		Delay      time.Duration
+		OK         bool
+	}{
+		{429, "5", time.Second * 5, true},
+		{503, "Wed, 21 Oct 2015 07:28:00 GMT", time.Minute, true},
+		{503, "Wed, 21 Oct 2015 07:00:00 GMT", 0, true},
+		{429, "", 0, false},
+		{429, "soon", 0, false},
+		{500, "5", 0, false},
+	}
+
+	for _, test := range tests {
+		resp := &http.Response{
+			StatusCode: test.Status,
+			Header:     http.Header{"Retry-After": []string{test.RetryAfter}},
+		}
+
+		delay, ok := retryAfterOf(resp, now)
+		if delay != test.Delay || ok != test.OK {
+			t.Errorf("retryAfterOf(%d, %q) = %s, %v", test.Status, test.RetryAfter, delay, ok)
+		}
+	}
+}
+
+// countingTransport is a http.RoundTripper which counts requests, and passes
+// them to |next|.
+type countingTransport struct {
+	next     http.RoundTripper
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+
+	return c.next.RoundTrip(req)
+}
+
+func TestClientCustomTransport(t *testing.T) {
+	server, _ := url.Parse("https://rdap.nic.cz")
+
+	transport := &countingTransport{next: &sequenceTransport{statuses: []int{200, 200}}}
+	client := &Client{
+		HTTP:      &http.Client{Transport: blockingTransport{}},
+		Transport: transport,
+		Verbose:   verboseFunc(),
+	}
+
+	// Client.Transport replaces the http.Client's transport.
+	if _, err := client.Do(NewDomainRequest("example.cz").WithServer(server)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if transport.requests != 1 {
+		t.Errorf("Unexpected number of requests %d", transport.requests)
+	}
+
+	// Per request HTTP client.
+	requestTransport := &countingTransport{next: &sequenceTransport{statuses: []int{200}}}
+
+	req := NewDomainRequest("example.cz").WithServer(server)
+	req.HTTP = &http.Client{Transport: requestTransport}
+
+	if _, err := client.Do(req); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if requestTransport.requests != 1 || transport.requests != 1 {
+		t.Errorf("Unexpected number of requests %d/%d", requestTransport.requests, transport.requests)
+	}
+}
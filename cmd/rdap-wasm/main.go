@@ -0,0 +1,139 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+//go:build js && wasm
+
+// Command rdap-wasm is an example js/wasm binding of the RDAP decoder and
+// validator, for building in-browser RDAP response inspectors.
+//
+// Build with:
+//
+//	cd cmd && GOOS=js GOARCH=wasm go build -o rdap.wasm ./rdap-wasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+//
+// The binary registers a global rdap object. See index.html for an example:
+//
+//	const result = rdap.decode(responseText);
+//	// result.type       - decoded object type, e.g. "*rdap.Domain".
+//	// result.text       - human readable (WHOIS style) rendering.
+//	// result.validation - array of validation problems.
+//	// result.notes      - decode notes (minor errors, unknown fields).
+//	// result.stats      - decode statistics (see rdap.DecodeStats).
+//	// result.error      - error message, if the response couldn't be decoded.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"syscall/js"
+
+	"github.com/openrdap/rdap"
+)
+
+func main() {
+	js.Global().Set("rdap", js.ValueOf(map[string]interface{}{
+		"decode": js.FuncOf(decode),
+	}))
+
+	// Keep the Go runtime running, so the callbacks remain usable.
+	select {}
+}
+
+// decode implements rdap.decode(json [, brief]).
+func decode(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return map[string]interface{}{
+			"error": "usage: rdap.decode(json [, brief])",
+		}
+	}
+
+	brief := len(args) > 1 && args[1].Truthy()
+
+	stats := &rdap.DecodeStats{}
+	obj, err := rdap.NewDecoder([]byte(args[0].String()), rdap.WithDecodeStats(stats)).Decode()
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	text := &bytes.Buffer{}
+	printer := &rdap.Printer{
+		Writer:      text,
+		BriefOutput: brief,
+	}
+	printer.Print(obj)
+
+	return map[string]interface{}{
+		"type":       fmt.Sprintf("%T", obj),
+		"text":       text.String(),
+		"validation": toJSArray(rdap.ValidateExtensions(obj)),
+		"notes":      toJSArray(decodeNotes(obj)),
+		"stats": map[string]interface{}{
+			"bytes":          stats.Bytes,
+			"properties":     stats.Properties,
+			"maxDepth":       stats.MaxDepth,
+			"entities":       stats.Entities,
+			"maxEntityDepth": stats.MaxEntityDepth,
+			"notes":          stats.Notes,
+			"durationMs":     float64(stats.Duration.Microseconds()) / 1000,
+		},
+	}
+}
+
+// decodeNotes returns the top level object's decode notes, and unknown
+// fields.
+func decodeNotes(obj rdap.RDAPObject) []string {
+	var dd *rdap.DecodeData
+
+	switch v := obj.(type) {
+	case *rdap.Domain:
+		dd = v.DecodeData
+	case *rdap.Entity:
+		dd = v.DecodeData
+	case *rdap.Nameserver:
+		dd = v.DecodeData
+	case *rdap.Autnum:
+		dd = v.DecodeData
+	case *rdap.IPNetwork:
+		dd = v.DecodeData
+	case *rdap.Help:
+		dd = v.DecodeData
+	case *rdap.Error:
+		dd = v.DecodeData
+	}
+
+	if dd == nil {
+		return nil
+	}
+
+	fields := dd.Fields()
+	sort.Strings(fields)
+
+	unknown := dd.UnknownFields()
+	sort.Strings(unknown)
+
+	var notes []string
+	for _, field := range fields {
+		for _, note := range dd.Notes(field) {
+			notes = append(notes, fmt.Sprintf("%s: %s", field, note))
+		}
+	}
+
+	for _, field := range unknown {
+		notes = append(notes, fmt.Sprintf("%s: unknown field", field))
+	}
+
+	return notes
+}
+
+func toJSArray(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+
+	return result
+}
@@ -3,11 +3,18 @@ package main
 import (
 	"os"
 
-	"github.com/openrdap/rdap"
+	"github.com/openrdap/rdap/cli"
+	"github.com/openrdap/rdap/daemon"
 )
 
 func main() {
-	exitCode := rdap.RunCLI(os.Args[1:], os.Stdout, os.Stderr, rdap.CLIOptions{})
+	var exitCode int
+
+	if len(os.Args) > 1 && os.Args[1] == "daemon" {
+		exitCode = daemon.RunCLI(os.Args[2:], os.Stdout, os.Stderr)
+	} else {
+		exitCode = cli.RunCLI(os.Args[1:], os.Stdout, os.Stderr, cli.CLIOptions{})
+	}
 
 	os.Exit(exitCode)
 }
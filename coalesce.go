@@ -0,0 +1,100 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// inflightCall is an HTTP request shared by identical concurrent queries.
+type inflightCall struct {
+	done chan struct{}
+
+	// Copy of the result, set before done is closed.
+	resp HTTPResponse
+}
+
+// inflightKey identifies identical HTTP requests: the same URL, with the same
+// OpenID Connect access token (if any).
+func inflightKey(rdapReq *Request) string {
+	key := rdapReq.URL().String()
+
+	if rdapReq.openIDToken != nil {
+		key += "\x00" + rdapReq.openIDToken.AccessToken
+	}
+
+	return key
+}
+
+// getCoalesced is getWithRetries(), but with identical concurrent HTTP
+// requests coalesced into one if Client.CoalesceQueries is set.
+//
+// The first caller makes the HTTP request. The other callers wait for its
+// result (or until their own context is done), and receive a copy of it. If
+// the request was cancelled by the first caller's context, the waiting
+// callers try again.
+//
+// Requests made with their own identity (see hasOwnIdentity()) aren't
+// coalesced.
+func (c *Client) getCoalesced(rdapReq *Request) *HTTPResponse {
+	if !c.CoalesceQueries || c.Offline || c.hasOwnIdentity(rdapReq) {
+		return c.getWithRetries(rdapReq)
+	}
+
+	key := inflightKey(rdapReq)
+	ctx := rdapReq.Context()
+
+	for {
+		c.inflightMu.Lock()
+		call, ok := c.inflight[key]
+		if !ok {
+			call = &inflightCall{done: make(chan struct{})}
+
+			if c.inflight == nil {
+				c.inflight = map[string]*inflightCall{}
+			}
+			c.inflight[key] = call
+		}
+		c.inflightMu.Unlock()
+
+		if !ok {
+			httpResponse := c.getWithRetries(rdapReq)
+
+			call.resp = *httpResponse
+
+			c.inflightMu.Lock()
+			delete(c.inflight, key)
+			c.inflightMu.Unlock()
+
+			close(call.done)
+
+			return httpResponse
+		}
+
+		c.Verbose(fmt.Sprintf("client: waiting for identical in-flight request to %s", rdapReq.URL()))
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return &HTTPResponse{
+				URL:   rdapReq.URL().String(),
+				Error: ctx.Err(),
+			}
+		}
+
+		// The first caller's query was cancelled/timed out, try again.
+		cancelled := errors.Is(call.resp.Error, context.Canceled) || errors.Is(call.resp.Error, context.DeadlineExceeded)
+		if call.resp.Response == nil && cancelled && ctx.Err() == nil {
+			continue
+		}
+
+		httpResponse := call.resp
+		httpResponse.Shared = true
+
+		return &httpResponse
+	}
+}
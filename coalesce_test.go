@@ -0,0 +1,165 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestClientCoalesceQueries(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	const numQueries = 5
+
+	// Release the HTTP request once all of the other queries are waiting.
+	var waiting int32
+	client := &Client{
+		CoalesceQueries: true,
+		Verbose: func(text string) {
+			if strings.Contains(text, "waiting for identical in-flight request") &&
+				atomic.AddInt32(&waiting, 1) == numQueries-1 {
+				close(release)
+			}
+		},
+	}
+
+	var wg sync.WaitGroup
+	responses := make([]*Response, numQueries)
+	errs := make([]error, numQueries)
+
+	for i := 0; i < numQueries; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = client.Do(NewDomainRequest("example.cz").WithServer(serverURL))
+		}(i)
+	}
+	wg.Wait()
+
+	if requests != 1 {
+		t.Errorf("Made %d requests, expected 1", requests)
+	}
+
+	shared := 0
+	for i, resp := range responses {
+		if errs[i] != nil {
+			t.Fatalf("Unexpected error: %s", errs[i])
+		}
+
+		if d, ok := resp.Object.(*Domain); !ok || d.LDHName != "example.cz" {
+			t.Errorf("Unexpected response %v", resp.Object)
+		}
+
+		if resp.HTTP[0].Shared {
+			shared++
+		}
+	}
+
+	if shared != numQueries-1 {
+		t.Errorf("%d shared responses, expected %d", shared, numQueries-1)
+	}
+}
+
+func TestClientCoalesceQueriesBootstrapped(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	client := &Client{
+		CoalesceQueries: true,
+		Verbose:         verboseFunc(),
+	}
+
+	const numQueries = 8
+
+	var wg sync.WaitGroup
+	errs := make([]error, numQueries)
+
+	for i := 0; i < numQueries; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = client.Do(NewDomainRequest("example.cz"))
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Query %d: unexpected error %s", i, err)
+		}
+	}
+}
+
+func TestClientCoalesceQueriesOwnHTTPClient(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+
+	// Respond once both requests have arrived, so they're concurrent.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 2 {
+			close(release)
+		}
+
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	client := &Client{
+		CoalesceQueries: true,
+		Verbose:         verboseFunc(),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req := NewDomainRequest("example.cz").WithServer(serverURL)
+			req.HTTP = &http.Client{}
+
+			if resp, err := client.Do(req); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if resp.HTTP[0].Shared {
+				t.Errorf("Response shared between requests with their own HTTP clients")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if requests != 2 {
+		t.Errorf("Made %d requests, expected 2", requests)
+	}
+}
@@ -31,6 +31,7 @@ type Link struct {
 type Notice struct {
 	DecodeData *DecodeData
 
+	Common
 	Title       string
 	Type        string
 	Description []string
@@ -43,6 +44,7 @@ type Notice struct {
 type Remark struct {
 	DecodeData *DecodeData
 
+	Common
 	Title       string
 	Type        string
 	Description []string
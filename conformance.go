@@ -0,0 +1,113 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"regexp"
+)
+
+// RDAPLevel0Conformance is the rdapConformance identifier of the base RDAP
+// specification (RFC 9083), listed by every RDAP server.
+const RDAPLevel0Conformance = "rdap_level_0"
+
+// ConformanceIdentifier is a parsed rdapConformance identifier, e.g.
+// "icann_rdap_response_profile_1".
+type ConformanceIdentifier struct {
+	// Identifier as listed by the server, e.g. "icann_rdap_response_profile_1".
+	Identifier string
+
+	// Identifier without its version suffix, e.g.
+	// "icann_rdap_response_profile". Equal to Identifier if it has no
+	// version suffix.
+	Name string
+
+	// Version suffix, e.g. "1". Empty if there's none.
+	//
+	// Versions are parsed from identifiers ending in "_<n>", "_level_<n>", or
+	// "_version_<n>", e.g. "rdap_level_0" and "fred_version_0".
+	Version string
+
+	// Name of the registered Extension with this Conformance identifier, e.g.
+	// "fred". Empty if there's none.
+	Extension string
+
+	// True if the identifier is RDAPLevel0Conformance or the Conformance
+	// identifier of a registered Extension.
+	Known bool
+}
+
+var conformanceVersionRE = regexp.MustCompile(`^(.+?)(?:_level|_version)?_([0-9]+)$`)
+
+// ParseConformance parses the rdapConformance identifiers |identifiers|.
+func ParseConformance(identifiers []string) []ConformanceIdentifier {
+	registered := map[string]string{}
+	for _, ext := range registeredExtensions() {
+		if ext.Conformance != "" {
+			registered[ext.Conformance] = ext.Name
+		}
+	}
+
+	var result []ConformanceIdentifier
+	for _, id := range identifiers {
+		c := ConformanceIdentifier{
+			Identifier: id,
+			Name:       id,
+			Extension:  registered[id],
+		}
+
+		if m := conformanceVersionRE.FindStringSubmatch(id); m != nil {
+			c.Name = m[1]
+			c.Version = m[2]
+		}
+
+		c.Known = c.Extension != "" || id == RDAPLevel0Conformance
+
+		result = append(result, c)
+	}
+
+	return result
+}
+
+// HasExtension returns true if the topmost RDAP object |obj| (e.g. a *Domain)
+// lists |identifier| in its rdapConformance.
+//
+// |identifier| is matched either exactly (e.g. "icann_rdap_response_profile_1"),
+// or without the version suffix (e.g. "icann_rdap_response_profile" matches any
+// version).
+func HasExtension(obj RDAPObject, identifier string) bool {
+	for _, c := range ParseConformance(conformanceOf(obj)) {
+		if c.Identifier == identifier || c.Name == identifier {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Conformance returns the rdapConformance identifiers of the response, or nil
+// if there's no RDAP object.
+func (r *Response) Conformance() []string {
+	if r.Object == nil {
+		return nil
+	}
+
+	return conformanceOf(r.Object)
+}
+
+// ConformanceIdentifiers returns the response's parsed rdapConformance
+// identifiers.
+func (r *Response) ConformanceIdentifiers() []ConformanceIdentifier {
+	return ParseConformance(r.Conformance())
+}
+
+// HasExtension returns true if the response lists |identifier| in its
+// rdapConformance. See HasExtension().
+func (r *Response) HasExtension(identifier string) bool {
+	if r.Object == nil {
+		return false
+	}
+
+	return HasExtension(r.Object, identifier)
+}
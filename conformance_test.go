@@ -0,0 +1,57 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestParseConformance(t *testing.T) {
+	got := ParseConformance([]string{"rdap_level_0", "fred_version_0", "icann_rdap_response_profile_1", "cidr0", "example"})
+
+	expected := []ConformanceIdentifier{
+		{Identifier: "rdap_level_0", Name: "rdap", Version: "0", Known: true},
+		{Identifier: "fred_version_0", Name: "fred", Version: "0", Extension: "fred", Known: true},
+		{Identifier: "icann_rdap_response_profile_1", Name: "icann_rdap_response_profile", Version: "1"},
+		{Identifier: "cidr0", Name: "cidr0", Extension: "cidr0", Known: true},
+		{Identifier: "example", Name: "example"},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %+v, expected %+v", got, expected)
+	}
+}
+
+func TestResponseHasExtension(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.registry.example/domain-example.example.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := &Response{Object: obj}
+
+	if len(resp.Conformance()) != 4 || len(resp.ConformanceIdentifiers()) != 4 {
+		t.Errorf("Unexpected conformance %v", resp.Conformance())
+	}
+
+	for _, id := range []string{"redacted", "icann_rdap_response_profile_1", "icann_rdap_response_profile", "rdap_level_0"} {
+		if !resp.HasExtension(id) {
+			t.Errorf("HasExtension(%q) = false", id)
+		}
+	}
+
+	for _, id := range []string{"fred_version_0", "icann_rdap_response_profile_0", "rdap_level"} {
+		if resp.HasExtension(id) {
+			t.Errorf("HasExtension(%q) = true", id)
+		}
+	}
+
+	if (&Response{}).HasExtension("rdap_level_0") {
+		t.Errorf("HasExtension true without an object")
+	}
+}
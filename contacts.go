@@ -0,0 +1,106 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+// Entity roles, from the IANA "RDAP JSON Values" registry (RFC 9083 section
+// 10.2.4).
+//
+// https://www.iana.org/assignments/rdap-json-values/
+const (
+	RoleRegistrant     = "registrant"
+	RoleTechnical      = "technical"
+	RoleAdministrative = "administrative"
+	RoleAbuse          = "abuse"
+	RoleBilling        = "billing"
+	RoleRegistrar      = "registrar"
+	RoleReseller       = "reseller"
+	RoleSponsor        = "sponsor"
+	RoleProxy          = "proxy"
+	RoleNotifications  = "notifications"
+	RoleNOC            = "noc"
+)
+
+// Contact is an entity's contact data, extracted from its vCard, or its
+// JSContact Card if it has no vCard (see Entity.ContactVCard()).
+//
+// Fields are empty if not present in the vCard (e.g. redacted).
+type Contact struct {
+	// The entity.
+	Entity *Entity
+
+	Handle        string
+	Name          string
+	Org           string
+	Email         string
+	Tel           string
+	Fax           string
+	StreetAddress string
+	Locality      string
+	Region        string
+	PostalCode    string
+	Country       string
+}
+
+// NewContact returns the contact data of the entity |e|, or nil if |e| is nil.
+func NewContact(e *Entity) *Contact {
+	if e == nil {
+		return nil
+	}
+
+	c := &Contact{
+		Entity: e,
+		Handle: e.Handle,
+	}
+
+	if v := e.ContactVCard(); v != nil {
+		c.Name = v.Name()
+		c.Org = v.Org()
+		c.Email = v.Email()
+		c.Tel = v.Tel()
+		c.Fax = v.Fax()
+		c.StreetAddress = v.StreetAddress()
+		c.Locality = v.Locality()
+		c.Region = v.Region()
+		c.PostalCode = v.PostalCode()
+		c.Country = v.Country()
+	}
+
+	return c
+}
+
+// Registrant returns the domain's registrant, or nil if there's none.
+func (d *Domain) Registrant() *Contact {
+	return NewContact(findEntityByRole(RoleRegistrant, d.Entities))
+}
+
+// Registrar returns the domain's registrar, or nil if there's none.
+func (d *Domain) Registrar() *Contact {
+	return NewContact(findEntityByRole(RoleRegistrar, d.Entities))
+}
+
+// AdminContact returns the domain's administrative contact, or nil if there's
+// none.
+func (d *Domain) AdminContact() *Contact {
+	return NewContact(findEntityByRole(RoleAdministrative, d.Entities))
+}
+
+// TechContact returns the domain's technical contact, or nil if there's none.
+func (d *Domain) TechContact() *Contact {
+	return NewContact(findEntityByRole(RoleTechnical, d.Entities))
+}
+
+// AbuseContact returns the domain's abuse contact, or nil if there's none.
+//
+// The registrar's abuse contact (an entity within the registrar entity, as in
+// gTLD responses) is preferred, then any other abuse contact.
+func (d *Domain) AbuseContact() *Contact {
+	if registrar := findEntityByRole(RoleRegistrar, d.Entities); registrar != nil {
+		if abuse := findEntityByRole(RoleAbuse, registrar.Entities); abuse != nil {
+			return NewContact(abuse)
+		}
+	}
+
+	return NewContact(findEntityByRole(RoleAbuse, d.Entities))
+}
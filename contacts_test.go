@@ -0,0 +1,38 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestDomainContacts(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.registry.example/domain-example.example.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := obj.(*Domain)
+
+	registrar := d.Registrar()
+	if registrar == nil || registrar.Handle != "9999" || registrar.Name != "Example Registrar, Inc." || registrar.Entity != &d.Entities[0] {
+		t.Errorf("Unexpected registrar %+v", registrar)
+	}
+
+	abuse := d.AbuseContact()
+	if abuse == nil || abuse.Email != "abuse@registrar.example" || abuse.Tel != "tel:+1.5555555555" {
+		t.Errorf("Unexpected abuse contact %+v", abuse)
+	}
+
+	if d.Registrant() != nil || d.AdminContact() != nil || d.TechContact() != nil {
+		t.Errorf("Unexpected contacts")
+	}
+
+	if NewContact(nil) != nil {
+		t.Errorf("Unexpected contact for nil entity")
+	}
+}
@@ -0,0 +1,145 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Credential is a set of static credentials for an RDAP server.
+//
+// Any combination of the fields may be set.
+type Credential struct {
+	// Bearer token, sent as an "Authorization: Bearer" header.
+	BearerToken string
+
+	// HTTP basic authentication username and password.
+	Username string
+	Password string
+
+	// Additional HTTP headers, e.g. an API key header:
+	//
+	//	Header: http.Header{"X-Api-Key": {"secret"}}
+	Header http.Header
+}
+
+// apply adds the credentials to the HTTP request |req|.
+func (c *Credential) apply(req *http.Request) {
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	if c.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+	}
+
+	for k, v := range c.Header {
+		req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+	}
+}
+
+// remove removes the credentials from the HTTP request |req|.
+func (c *Credential) remove(req *http.Request) {
+	if c.Username != "" || c.Password != "" || c.BearerToken != "" {
+		req.Header.Del("Authorization")
+	}
+
+	for k := range c.Header {
+		req.Header.Del(k)
+	}
+}
+
+// Credentials maps RDAP server base URLs to static credentials, for RDAP
+// servers (or test environments) which require authentication.
+//
+// A base URL matches itself and all URLs beneath it, e.g.
+// "https://rdap.registry.example/v1" matches
+// "https://rdap.registry.example/v1/domain/example.tld". The scheme and host
+// are case insensitive, the path is case sensitive. The longest match is
+// used.
+//
+//	client := &rdap.Client{
+//	  Credentials: rdap.Credentials{
+//	    "https://rdap.registry.example": {BearerToken: "secret"},
+//	    "https://rdap.ote.example":      {Username: "user", Password: "password"},
+//	  },
+//	}
+//
+// Credentials are only sent to matching URLs, and are removed when a request
+// is redirected elsewhere. Responses to requests with credentials aren't
+// cached in the Client's HTTPCache.
+type Credentials map[string]*Credential
+
+// For returns the credentials for the RDAP URL |rdapURL|, or nil if there's
+// no match.
+func (c Credentials) For(rdapURL string) *Credential {
+	rdapURL = credentialsKey(rdapURL)
+
+	var result *Credential
+	longest := -1
+
+	for baseURL, credential := range c {
+		baseURL = credentialsKey(baseURL)
+
+		if !strings.HasPrefix(rdapURL, baseURL) {
+			continue
+		}
+
+		// Only match whole path segments.
+		if rest := rdapURL[len(baseURL):]; rest != "" && !strings.ContainsAny(rest[0:1], "/?#") {
+			continue
+		}
+
+		if len(baseURL) > longest {
+			result = credential
+			longest = len(baseURL)
+		}
+	}
+
+	return result
+}
+
+// credentialsKey normalises the URL |rawURL| for matching: the scheme and host
+// are lowercased, and trailing slashes are removed. The path is kept as is.
+func credentialsKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return strings.TrimRight(rawURL, "/")
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+
+	return strings.TrimRight(u.String(), "/")
+}
+
+// applyCredentials adds the Client's credentials (if any) for the HTTP
+// request |req|.
+func (c *Client) applyCredentials(req *http.Request) {
+	if credential := c.Credentials.For(req.URL.String()); credential != nil {
+		c.Verbose(fmt.Sprintf("client: using credentials for %s", req.URL.Host))
+		credential.apply(req)
+	}
+}
+
+// redirectCredentials sets the credentials of the redirected HTTP request
+// |req|, whose headers are copied from the original request |orig|.
+func (c *Client) redirectCredentials(req *http.Request, orig *http.Request) {
+	from := c.Credentials.For(orig.URL.String())
+	to := c.Credentials.For(req.URL.String())
+
+	if from != nil && from != to {
+		from.remove(req)
+	}
+
+	// Reapplied, since net/http removes the Authorization header when
+	// redirecting to another host.
+	if to != nil {
+		to.apply(req)
+	}
+}
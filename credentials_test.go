@@ -0,0 +1,102 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestCredentialsFor(t *testing.T) {
+	bearer := &Credential{BearerToken: "secret"}
+	basic := &Credential{Username: "user", Password: "password"}
+
+	credentials := Credentials{
+		"https://rdap.example":         bearer,
+		"https://RDAP.example/ote/v1/": basic,
+	}
+
+	tests := []struct {
+		URL        string
+		Credential *Credential
+	}{
+		{"https://rdap.example", bearer},
+		{"https://rdap.example/domain/example.tld", bearer},
+		{"https://rdap.example/ote/v1/domain/example.tld", basic},
+		{"https://rdap.example/ote/v1?x=y", basic},
+		{"https://rdap.example/ote/v10/domain/example.tld", bearer},
+		{"https://rdap.example.evil/domain/example.tld", nil},
+		{"http://rdap.example/domain/example.tld", nil},
+		{"HTTPS://Rdap.Example/ote/v1/domain/example.tld", basic},
+		{"https://rdap.example/OTE/v1/domain/example.tld", bearer},
+	}
+
+	for _, test := range tests {
+		if got := credentials.For(test.URL); got != test.Credential {
+			t.Errorf("For(%q) = %v, expected %v", test.URL, got, test.Credential)
+		}
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	var headers []http.Header
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header)
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	}))
+	defer other.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header)
+
+		if r.URL.Path == "/redirect/domain/example.cz" {
+			http.Redirect(w, r, other.URL+"/domain/example.cz", http.StatusFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		Credentials: Credentials{
+			server.URL: {
+				Username: "user",
+				Password: "password",
+				Header:   http.Header{"X-Api-Key": {"key"}},
+			},
+		},
+		Verbose: verboseFunc(),
+	}
+
+	serverURL, _ := url.Parse(server.URL)
+	if _, err := client.Do(NewDomainRequest("example.cz").WithServer(serverURL)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(headers) != 1 || headers[0].Get("X-Api-Key") != "key" || headers[0].Get("Authorization") != "Basic dXNlcjpwYXNzd29yZA==" {
+		t.Fatalf("Unexpected headers %v", headers)
+	}
+
+	// Credentials aren't sent to the redirect target.
+	headers = nil
+	redirectURL, _ := url.Parse(server.URL + "/redirect")
+	if _, err := client.Do(NewDomainRequest("example.cz").WithServer(redirectURL)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(headers) != 2 || headers[0].Get("X-Api-Key") != "key" {
+		t.Fatalf("Unexpected headers %v", headers)
+	} else if headers[1].Get("X-Api-Key") != "" || headers[1].Get("Authorization") != "" {
+		t.Errorf("Credentials sent to redirect target: %v", headers[1])
+	}
+}
@@ -0,0 +1,116 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	kingpin "github.com/alecthomas/kingpin/v2"
+)
+
+var usageText = `Usage: rdap daemon --config=FILE [OPTIONS]
+
+Monitors domains/IPs/etc for registration changes.
+
+Options:
+  -h, --help          Show help message.
+  -v, --verbose       Print verbose messages on STDERR.
+
+  -c, --config=FILE   YAML configuration file (required).
+      --once          Check every monitor once, then exit.
+
+`
+
+// RunCLI runs the "rdap daemon" command.
+//
+// |args| are the command line arguments following "daemon".
+// |stdout| and |stderr| are the io.Writers for STDOUT/STDERR.
+//
+// Returns the program exit code.
+func RunCLI(args []string, stdout io.Writer, stderr io.Writer) int {
+	app := kingpin.New("rdap daemon", "RDAP monitoring daemon")
+	app.HelpFlag.Short('h')
+	app.UsageTemplate(usageText)
+	app.UsageWriter(stdout)
+	app.ErrorWriter(stderr)
+
+	terminate := false
+	app.Terminate(func(int) {
+		terminate = true
+	})
+
+	verboseFlag := app.Flag("verbose", "").Short('v').Bool()
+	configFlag := app.Flag("config", "").Short('c').String()
+	onceFlag := app.Flag("once", "").Bool()
+
+	_, err := app.Parse(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %s\n\n%s", err, usageText)
+		return 1
+	} else if terminate {
+		return 1
+	}
+
+	if *configFlag == "" {
+		fmt.Fprintf(stderr, "Error: --config is required\n\n%s", usageText)
+		return 1
+	}
+
+	verbose := func(text string) {}
+	if *verboseFlag {
+		verbose = func(text string) {
+			fmt.Fprintf(stderr, "# %s\n", text)
+		}
+	}
+
+	config, err := LoadConfig(*configFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading %s: %s\n", *configFlag, err)
+		return 1
+	}
+
+	store, err := OpenStore(config.Database)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error opening database %s: %s\n", config.Database, err)
+		return 1
+	}
+	defer store.Close()
+
+	d := &Daemon{
+		Config:  config,
+		Store:   store,
+		Verbose: verbose,
+	}
+
+	if config.Stdout {
+		d.Emitters = append(d.Emitters, &WriterEmitter{Writer: stdout})
+	}
+
+	for _, u := range config.Webhooks {
+		d.Emitters = append(d.Emitters, &WebhookEmitter{URL: u})
+	}
+
+	verbose(fmt.Sprintf("daemon: Loaded %d monitor(s) from %s", len(config.Monitors), *configFlag))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *onceFlag {
+		d.RunOnce(ctx)
+		return 0
+	}
+
+	if err := d.Run(ctx); err != nil && err != context.Canceled {
+		fmt.Fprintf(stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
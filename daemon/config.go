@@ -0,0 +1,190 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultInterval = time.Hour * 6
+	defaultJitter   = time.Minute * 10
+	defaultTimeout  = time.Second * 30
+	defaultDatabase = "rdap-monitor.db"
+)
+
+// Config is the daemon configuration, normally loaded from a YAML file:
+//
+//	interval: 6h
+//	jitter: 10m
+//	database: /var/lib/rdap/monitor.db
+//	bootstrap_dir: /var/cache/rdap
+//	bootstrap_url: https://rdap-mirror.example.com/
+//	stdout: true
+//	webhooks:
+//	  - https://hooks.example.com/rdap
+//	monitors:
+//	  - query: example.cz
+//	  - query: 192.0.2.0
+//	    interval: 24h
+//	  - query: EXAMPLE-HANDLE
+//	    type: entity
+//	    server: https://rdap.example.com
+type Config struct {
+	// Default interval between re-checks of each monitor.
+	//
+	// The default is 6 hours.
+	Interval Duration `yaml:"interval"`
+
+	// Maximum random delay added to each re-check, to avoid querying servers
+	// in bursts. 0 (or a negative value) disables the delay.
+	//
+	// The default is 10 minutes.
+	Jitter Duration `yaml:"jitter"`
+
+	// Timeout for each RDAP query.
+	//
+	// The default is 30 seconds.
+	Timeout Duration `yaml:"timeout"`
+
+	// SQLite database filename to store monitor state in.
+	//
+	// The default is rdap-monitor.db.
+	Database string `yaml:"database"`
+
+	// Directory to persist the IANA bootstrap files in. They're refreshed in
+	// the background when stale.
+	//
+	// The default is to keep them in memory only.
+	BootstrapDir string `yaml:"bootstrap_dir"`
+
+	// Base URL of the bootstrap Service Registry files, e.g. a local mirror.
+	//
+	// The default is https://data.iana.org/rdap/.
+	BootstrapURL string `yaml:"bootstrap_url"`
+
+	// Print change events on stdout (as JSON, one per line).
+	Stdout bool `yaml:"stdout"`
+
+	// URLs to POST change events to (as JSON).
+	Webhooks []string `yaml:"webhooks"`
+
+	// The monitored domains/IPs/etc.
+	Monitors []Monitor `yaml:"monitors"`
+}
+
+// Monitor is a single monitored RDAP query, e.g. a domain name.
+type Monitor struct {
+	// RDAP query, e.g. "example.cz", "192.0.2.0", "AS2856".
+	Query string `yaml:"query"`
+
+	// Query type (as per the rdap --type option). Normally auto-detected.
+	Type string `yaml:"type"`
+
+	// RDAP server URL. Normally determined by bootstrapping.
+	Server string `yaml:"server"`
+
+	// Interval between re-checks, overriding Config.Interval.
+	Interval Duration `yaml:"interval"`
+}
+
+// Duration is a time.Duration, expressed in YAML as a string such as "90s" or
+// "6h".
+type Duration time.Duration
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(duration)
+
+	return nil
+}
+
+// LoadConfig loads the YAML config file |filename|.
+func LoadConfig(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseConfig(data)
+}
+
+// ParseConfig parses the YAML config |data|, and sets default values.
+func ParseConfig(data []byte) (*Config, error) {
+	// Set before parsing, as 0 is a valid jitter.
+	c := &Config{
+		Jitter: Duration(defaultJitter),
+	}
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+
+	if c.Interval <= 0 {
+		c.Interval = Duration(defaultInterval)
+	}
+
+	if c.Jitter < 0 {
+		c.Jitter = 0
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = Duration(defaultTimeout)
+	}
+
+	if c.Database == "" {
+		c.Database = defaultDatabase
+	}
+
+	if c.BootstrapURL != "" {
+		if u, err := url.Parse(c.BootstrapURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid bootstrap_url '%s'", c.BootstrapURL)
+		}
+	}
+
+	seen := map[string]bool{}
+	for i := range c.Monitors {
+		m := &c.Monitors[i]
+
+		if m.Query == "" {
+			return nil, fmt.Errorf("monitor #%d has no query", i+1)
+		}
+
+		if _, err := m.request(); err != nil {
+			return nil, fmt.Errorf("monitor '%s': %s", m.Query, err)
+		}
+
+		if seen[m.key()] {
+			return nil, fmt.Errorf("duplicate monitor '%s'", m.Query)
+		}
+		seen[m.key()] = true
+
+		if m.Interval <= 0 {
+			m.Interval = c.Interval
+		}
+	}
+
+	return c, nil
+}
+
+// key uniquely identifies the monitor in the state database.
+func (m *Monitor) key() string {
+	return m.Type + "|" + m.Server + "|" + m.Query
+}
@@ -0,0 +1,286 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+// Package daemon implements a long-running RDAP monitoring service.
+//
+// A set of monitored domains/IPs/etc is re-checked periodically, with state
+// stored in an SQLite database. Changes are reported as Events, to stdout
+// and/or webhooks.
+//
+// The daemon is normally run using the command line client:
+//
+//	rdap daemon --config monitors.yaml
+//
+// See Config for the configuration file format.
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openrdap/rdap"
+	"github.com/openrdap/rdap/bootstrap"
+)
+
+// Daemon runs the monitors in Config.
+type Daemon struct {
+	Config *Config
+
+	// RDAP client. The default bootstraps using Config.BootstrapDir.
+	Client *rdap.Client
+
+	// Monitor state storage.
+	Store *Store
+
+	// Destinations for Events.
+	Emitters []Emitter
+
+	// Optional callback function for verbose messages.
+	Verbose func(text string)
+
+	// Returns the current time. The default is time.Now.
+	Now func() time.Time
+}
+
+// Run runs the daemon until |ctx| is done.
+//
+// Each monitor is checked when due (per the stored state), then re-checked
+// every Monitor.Interval, plus a random delay of up to Config.Jitter.
+func (d *Daemon) Run(ctx context.Context) error {
+	d.init()
+
+	// Load every state before starting any checks, so an error leaves nothing
+	// running.
+	delays := make([]time.Duration, len(d.Config.Monitors))
+	for i := range d.Config.Monitors {
+		m := &d.Config.Monitors[i]
+
+		state, err := d.Store.Load(m.key())
+		if err != nil {
+			return err
+		}
+
+		delays[i] = d.jitter()
+		if state != nil {
+			if due := state.CheckedAt.Add(time.Duration(m.Interval)).Sub(d.Now()); due > 0 {
+				delays[i] += due
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	for i := range d.Config.Monitors {
+		m := &d.Config.Monitors[i]
+		delay := delays[i]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				d.Verbose(fmt.Sprintf("daemon: Next check of '%s' in %s", m.Query, delay.Round(time.Second)))
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+
+				d.check(ctx, m)
+
+				delay = time.Duration(m.Interval) + d.jitter()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// RunOnce checks every monitor once, immediately.
+func (d *Daemon) RunOnce(ctx context.Context) {
+	d.init()
+
+	for i := range d.Config.Monitors {
+		d.check(ctx, &d.Config.Monitors[i])
+	}
+}
+
+// Check checks the monitor |m|, stores the new state, and returns the
+// resulting Event (nil if nothing changed). The Event is not emitted.
+func (d *Daemon) Check(ctx context.Context, m *Monitor) (*Event, error) {
+	d.init()
+
+	state, err := d.Store.Load(m.key())
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, queryErr := d.query(ctx, m)
+
+	now := d.Now()
+	newState := &State{
+		CheckedAt: now,
+		Snapshot:  snapshot,
+	}
+
+	var event *Event
+
+	if queryErr != nil {
+		newState.Error = queryErr.Error()
+
+		// Keep the last good snapshot, to compare against on recovery.
+		if state != nil {
+			newState.Snapshot = state.Snapshot
+		}
+
+		if state == nil || state.Error == "" {
+			event = &Event{Type: EventFailed, Error: newState.Error}
+		}
+	} else if state == nil || state.Snapshot == nil {
+		event = &Event{Type: EventAdded}
+	} else {
+		changes := snapshot.Diff(state.Snapshot)
+
+		if state.Error != "" {
+			event = &Event{Type: EventRecovered, Changes: changes}
+		} else if len(changes) > 0 {
+			event = &Event{Type: EventChanged, Changes: changes}
+		}
+	}
+
+	if err := d.Store.Save(m.key(), newState); err != nil {
+		return nil, err
+	}
+
+	if event != nil {
+		event.Time = now
+		event.Query = m.Query
+	}
+
+	return event, nil
+}
+
+// check runs Check() and emits the resulting Event.
+func (d *Daemon) check(ctx context.Context, m *Monitor) {
+	d.Verbose(fmt.Sprintf("daemon: Checking '%s'", m.Query))
+
+	event, err := d.Check(ctx, m)
+	if err != nil {
+		d.Verbose(fmt.Sprintf("daemon: Error checking '%s': %s", m.Query, err))
+		return
+	} else if event == nil {
+		d.Verbose(fmt.Sprintf("daemon: No changes for '%s'", m.Query))
+		return
+	}
+
+	for _, e := range d.Emitters {
+		if err := e.Emit(ctx, event); err != nil {
+			d.Verbose(fmt.Sprintf("daemon: Error emitting event: %s", err))
+		}
+	}
+}
+
+// query runs the RDAP query for |m|, and returns a Snapshot of the result.
+func (d *Daemon) query(ctx context.Context, m *Monitor) (Snapshot, error) {
+	req, err := m.request()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Duration(d.Config.Timeout))
+	defer cancelFunc()
+
+	resp, err := d.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if respError, ok := resp.Object.(*rdap.Error); ok {
+		return nil, fmt.Errorf("RDAP server returned error: %s", respError.Title)
+	}
+
+	return NewSnapshot(resp.Object), nil
+}
+
+func (d *Daemon) init() {
+	if d.Client == nil {
+		bs := &bootstrap.Client{
+			RefreshInBackground: true,
+		}
+
+		if d.Config.BootstrapDir != "" {
+			bs = bootstrap.NewPersistentClient(d.Config.BootstrapDir)
+		}
+
+		if d.Config.BootstrapURL != "" {
+			bs.BaseURL, _ = url.Parse(d.Config.BootstrapURL)
+		}
+
+		d.Client = &rdap.Client{
+			Bootstrap: bs,
+		}
+	}
+
+	if d.Verbose == nil {
+		d.Verbose = func(text string) {}
+	}
+
+	if d.Now == nil {
+		d.Now = time.Now
+	}
+}
+
+// jitter returns a random delay of up to Config.Jitter.
+func (d *Daemon) jitter() time.Duration {
+	if d.Config.Jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(d.Config.Jitter)))
+}
+
+// request returns the RDAP request for the monitor.
+func (m *Monitor) request() (*rdap.Request, error) {
+	var req *rdap.Request
+
+	switch strings.ToLower(m.Type) {
+	case "":
+		req = rdap.NewAutoRequest(m.Query)
+	case "domain", "dns":
+		req = rdap.NewRequest(rdap.DomainRequest, m.Query)
+	case "ip":
+		req = rdap.NewRequest(rdap.IPRequest, m.Query)
+	case "autnum", "as", "asn":
+		req = rdap.NewRequest(rdap.AutnumRequest, strings.TrimPrefix(strings.ToUpper(m.Query), "AS"))
+	case "nameserver", "ns":
+		req = rdap.NewRequest(rdap.NameserverRequest, m.Query)
+	case "entity":
+		req = rdap.NewRequest(rdap.EntityRequest, m.Query)
+	default:
+		return nil, fmt.Errorf("unknown query type '%s'", m.Type)
+	}
+
+	if m.Server != "" {
+		if req.Type == rdap.RawRequest {
+			return nil, fmt.Errorf("server cannot be used with URL query '%s'", m.Query)
+		}
+
+		server, err := url.Parse(m.Server)
+		if err != nil {
+			return nil, err
+		}
+
+		req = req.WithServer(server)
+	}
+
+	return req, nil
+}
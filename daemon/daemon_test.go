@@ -0,0 +1,211 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package daemon
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openrdap/rdap"
+	"github.com/openrdap/rdap/test"
+)
+
+func TestParseConfig(t *testing.T) {
+	c, err := ParseConfig([]byte(`
+interval: 1h
+stdout: true
+monitors:
+  - query: example.cz
+  - query: 192.0.2.0
+    interval: 24h
+  - query: EXAMPLE-HANDLE
+    type: entity
+    server: https://rdap.example.com
+`))
+
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %s", err)
+	}
+
+	if c.Interval != Duration(time.Hour) || c.Jitter != Duration(defaultJitter) || c.Database != defaultDatabase || !c.Stdout {
+		t.Errorf("Bad config %+v", c)
+	}
+
+	if len(c.Monitors) != 3 || c.Monitors[0].Interval != Duration(time.Hour) || c.Monitors[1].Interval != Duration(time.Hour*24) {
+		t.Errorf("Bad monitors %+v", c.Monitors)
+	}
+
+	for _, jitter := range []string{"jitter: 0", "jitter: 0s", "jitter: -1m"} {
+		if c, err := ParseConfig([]byte(jitter)); err != nil || c.Jitter != 0 {
+			t.Errorf("%q: got jitter %v, error %v, expected jitter disabled", jitter, c, err)
+		}
+	}
+
+	bad := []string{
+		"interval: 6 hours",
+		"monitors: [{type: domain}]",
+		"monitors: [{query: example.cz, type: shoe}]",
+		"monitors: [{query: example.cz}, {query: example.cz}]",
+		"bootstrap_url: not-a-url",
+	}
+
+	for _, b := range bad {
+		if _, err := ParseConfig([]byte(b)); err == nil {
+			t.Errorf("Unexpected success parsing %q", b)
+		}
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	old := NewSnapshot(&rdap.Domain{
+		Status:      []string{"active"},
+		Nameservers: []rdap.Nameserver{{LDHName: "a.ns.example.cz"}},
+		Entities:    []rdap.Entity{{Handle: "TECH", Roles: []string{"technical"}}},
+		Events:      []rdap.Event{{Action: "expiration", Date: "2030-01-01T10:00:00+10:00"}},
+	})
+
+	s := NewSnapshot(&rdap.Domain{
+		Status:      []string{"active"},
+		Nameservers: []rdap.Nameserver{{LDHName: "b.ns.example.cz"}},
+		Events:      []rdap.Event{{Action: "expiration", Date: "2030-01-01T00:00:00Z"}},
+	})
+
+	expected := []Change{
+		{Field: "entity:technical", Old: "TECH"},
+		{Field: "nameservers", Old: "a.ns.example.cz"},
+		{Field: "nameservers", New: "b.ns.example.cz"},
+	}
+
+	if changes := s.Diff(old); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Got %v, expected %v", changes, expected)
+	}
+}
+
+func TestSnapshotEvents(t *testing.T) {
+	// The latest expiration is the UTC one, though the other sorts later as
+	// a string.
+	s := NewSnapshot(&rdap.Domain{
+		Events: []rdap.Event{
+			{Action: "expiration", Date: "2030-01-01T09:00:00+10:00"},
+			{Action: "expiration", Date: "2030-01-01T00:00:00.5Z"},
+		},
+	})
+
+	if expiration := s["event:expiration"]; len(expiration) != 1 || expiration[0] != "2030-01-01T00:00:00.5Z" {
+		t.Errorf("Unexpected expiration %v", expiration)
+	}
+}
+
+func TestParseSnapshot(t *testing.T) {
+	s, err := parseSnapshot([]byte(`{"status": ["active"]}`))
+	if err != nil || !reflect.DeepEqual(s, Snapshot{"status": {"active"}}) {
+		t.Errorf("Unexpected snapshot %v, error %v", s, err)
+	}
+
+	if _, err := parseSnapshot([]byte(`[]`)); err == nil {
+		t.Errorf("Unexpected success parsing invalid snapshot")
+	}
+}
+
+func TestDaemonCheck(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	store, err := OpenStore(":memory:")
+	if err != nil {
+		t.Fatalf("OpenStore failed: %s", err)
+	}
+	defer store.Close()
+
+	c, err := ParseConfig([]byte(`monitors: [{query: example.cz}, {query: non-existent.cz}]`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %s", err)
+	}
+
+	d := &Daemon{
+		Config: c,
+		Store:  store,
+	}
+
+	ctx := context.Background()
+	domain := &c.Monitors[0]
+	missing := &c.Monitors[1]
+
+	expectEvent := func(m *Monitor, expected EventType) *Event {
+		t.Helper()
+
+		event, err := d.Check(ctx, m)
+		if err != nil {
+			t.Fatalf("Check failed: %s", err)
+		}
+
+		if expected == "" && event != nil {
+			t.Errorf("Unexpected event %+v", event)
+		} else if expected != "" && (event == nil || event.Type != expected || event.Query != m.Query) {
+			t.Errorf("Got event %+v, expected %s", event, expected)
+		}
+
+		return event
+	}
+
+	expectEvent(domain, EventAdded)
+	expectEvent(domain, "")
+
+	// Simulate a nameserver change since the last check.
+	state, _ := store.Load(domain.key())
+	nameservers := state.Snapshot["nameservers"]
+	state.Snapshot["nameservers"] = []string{"old.ns.example.cz"}
+	store.Save(domain.key(), state)
+
+	expected := []Change{{Field: "nameservers", Old: "old.ns.example.cz"}}
+	for _, ns := range nameservers {
+		expected = append(expected, Change{Field: "nameservers", New: ns})
+	}
+
+	event := expectEvent(domain, EventChanged)
+	if event != nil && !reflect.DeepEqual(event.Changes, expected) {
+		t.Errorf("Unexpected changes %v", event.Changes)
+	}
+
+	expectEvent(missing, EventFailed)
+	expectEvent(missing, "")
+}
+
+func TestDaemonRunLoadError(t *testing.T) {
+	store, err := OpenStore(":memory:")
+	if err != nil {
+		t.Fatalf("OpenStore failed: %s", err)
+	}
+	defer store.Close()
+
+	c, err := ParseConfig([]byte(`monitors: [{query: example.cz}, {query: example.com}]`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %s", err)
+	}
+
+	// An unreadable state for the second monitor.
+	_, err = store.db.Exec("INSERT INTO monitor_state VALUES (?, 0, '[]', '')", c.Monitors[1].key())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	d := &Daemon{
+		Config:  c,
+		Store:   store,
+		Verbose: func(text string) { messages = append(messages, text) },
+	}
+
+	if err := d.Run(context.Background()); err == nil {
+		t.Errorf("Unexpected Run success")
+	}
+
+	if len(messages) != 0 {
+		t.Errorf("Unexpected checks started %v", messages)
+	}
+}
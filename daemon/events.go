@@ -0,0 +1,105 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// EventType is the type of an Event.
+type EventType string
+
+const (
+	// The monitored object was checked successfully for the first time.
+	EventAdded EventType = "added"
+
+	// The monitored object changed.
+	EventChanged EventType = "changed"
+
+	// The check failed (e.g. the object no longer exists, or the server is
+	// down). Only emitted when a check first fails, not on every retry.
+	EventFailed EventType = "failed"
+
+	// The check succeeded again after failing.
+	EventRecovered EventType = "recovered"
+)
+
+// An Event reports a change to a monitored object.
+type Event struct {
+	Type  EventType `json:"type"`
+	Time  time.Time `json:"time"`
+	Query string    `json:"query"`
+
+	// Changed fields, for EventChanged (and EventRecovered if the object
+	// changed while failing).
+	Changes []Change `json:"changes,omitempty"`
+
+	// Error message, for EventFailed.
+	Error string `json:"error,omitempty"`
+}
+
+// An Emitter delivers Events, e.g. to stdout or a webhook.
+type Emitter interface {
+	Emit(ctx context.Context, e *Event) error
+}
+
+// WriterEmitter writes Events to an io.Writer, as JSON (one per line).
+type WriterEmitter struct {
+	Writer io.Writer
+}
+
+// Emit implements Emitter.
+func (w *WriterEmitter) Emit(ctx context.Context, e *Event) error {
+	return json.NewEncoder(w.Writer).Encode(e)
+}
+
+// WebhookEmitter POSTs Events to a URL, as JSON.
+type WebhookEmitter struct {
+	URL string
+
+	// HTTP client to use. The default times out after 30 seconds, so a stuck
+	// webhook can't hold up the monitor's checks.
+	HTTP *http.Client
+}
+
+// defaultWebhookClient is the WebhookEmitter's default HTTP client.
+var defaultWebhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// Emit implements Emitter.
+func (w *WebhookEmitter) Emit(ctx context.Context, e *Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.HTTP
+	if client == nil {
+		client = defaultWebhookClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s returned status %d", w.URL, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package daemon
+
+import (
+	"encoding/json"
+
+	"github.com/openrdap/rdap"
+)
+
+// A Snapshot is a summary of the monitored fields of an RDAP object: the
+// values compared by rdap.Diff() (see rdap.DiffValues).
+//
+// Snapshots map field names to values, e.g.:
+//
+//	"status"            => ["active", "client transfer prohibited"]
+//	"nameservers"       => ["a.ns.example.cz", "b.ns.example.cz"]
+//	"event:expiration"  => ["2030-01-01T00:00:00Z"]
+//	"entity:registrar"  => ["REG-EXAMPLE"]
+type Snapshot rdap.DiffValues
+
+// A Change is a difference between two Snapshots. For list fields (e.g.
+// "status"), each added or removed item is a separate Change, with an empty
+// Old (added) or New (removed).
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// NewSnapshot summarises the RDAP object |obj|.
+func NewSnapshot(obj rdap.RDAPObject) Snapshot {
+	return Snapshot(rdap.NewDiffValues(obj))
+}
+
+// Diff returns the changes from Snapshot |old| to |s|, sorted by field name.
+// See rdap.Diff().
+func (s Snapshot) Diff(old Snapshot) []Change {
+	var changes []Change
+
+	for _, d := range rdap.DiffValues(s).Diff(rdap.DiffValues(old)) {
+		changes = append(changes, Change{Field: d.Field, Old: d.Old, New: d.New})
+	}
+
+	return changes
+}
+
+// parseSnapshot parses the stored Snapshot |data|.
+func parseSnapshot(data []byte) (Snapshot, error) {
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,17 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+//go:build cgo_sqlite
+
+package daemon
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriver is the database/sql driver used by Store.
+//
+// This is the cgo based github.com/mattn/go-sqlite3 driver, selected by the
+// cgo_sqlite build tag.
+const sqliteDriver = "sqlite3"
@@ -0,0 +1,18 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+//go:build !cgo_sqlite && !js && !wasip1
+
+package daemon
+
+import (
+	_ "modernc.org/sqlite"
+)
+
+// sqliteDriver is the database/sql driver used by Store.
+//
+// The default is the pure Go modernc.org/sqlite driver, so the rdap binary
+// cross-compiles without cgo. Build with -tags cgo_sqlite to use the
+// (faster) cgo based github.com/mattn/go-sqlite3 driver instead.
+const sqliteDriver = "sqlite"
@@ -0,0 +1,12 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+//go:build (js || wasip1) && !cgo_sqlite
+
+package daemon
+
+// sqliteDriver is the database/sql driver used by Store.
+//
+// No SQLite driver supports WebAssembly, so OpenStore() always fails.
+const sqliteDriver = ""
@@ -0,0 +1,122 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package daemon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// State is the stored state of a Monitor.
+type State struct {
+	// Time of the last check.
+	CheckedAt time.Time
+
+	// Snapshot from the last successful check, nil if none.
+	Snapshot Snapshot
+
+	// Error from the last check, empty string if it succeeded.
+	Error string
+}
+
+// A Store stores monitor state in an SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+// OpenStore opens (creating if necessary) the SQLite database |filename|.
+//
+// Use ":memory:" for a temporary in-memory database.
+func OpenStore(filename string) (*Store, error) {
+	if sqliteDriver == "" {
+		return nil, errors.New("SQLite is not supported on this platform")
+	}
+
+	db, err := sql.Open(sqliteDriver, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer; also required for ":memory:" databases to
+	// be shared.
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS monitor_state (
+			monitor    TEXT PRIMARY KEY,
+			checked_at INTEGER NOT NULL,
+			snapshot   TEXT,
+			error      TEXT NOT NULL
+		)`)
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Load returns the state of the monitor |key|, or nil if it has never been
+// checked.
+func (s *Store) Load(key string) (*State, error) {
+	var checkedAt int64
+	var snapshot sql.NullString
+	var errorText string
+
+	err := s.db.QueryRow(
+		"SELECT checked_at, snapshot, error FROM monitor_state WHERE monitor = ?",
+		key).Scan(&checkedAt, &snapshot, &errorText)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	state := &State{
+		CheckedAt: time.Unix(checkedAt, 0),
+		Error:     errorText,
+	}
+
+	if snapshot.Valid {
+		if state.Snapshot, err = parseSnapshot([]byte(snapshot.String)); err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
+// Save stores the state of the monitor |key|.
+func (s *Store) Save(key string, state *State) error {
+	var snapshot sql.NullString
+
+	if state.Snapshot != nil {
+		data, err := json.Marshal(state.Snapshot)
+		if err != nil {
+			return err
+		}
+
+		snapshot = sql.NullString{String: string(data), Valid: true}
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO monitor_state (monitor, checked_at, snapshot, error) VALUES (?, ?, ?, ?)
+		ON CONFLICT (monitor) DO UPDATE SET
+			checked_at = excluded.checked_at,
+			snapshot = excluded.snapshot,
+			error = excluded.error`,
+		key, state.CheckedAt.Unix(), snapshot, state.Error)
+
+	return err
+}
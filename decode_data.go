@@ -4,6 +4,11 @@
 
 package rdap
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // DecodeData stores a snapshot of all fields in an RDAP object (in raw
 // interface{} form), at the time of decoding. This allows the values of unknown
 // fields to be retrieved.
@@ -21,6 +26,8 @@ type DecodeData struct {
 	values             map[string]interface{}
 	overrideKnownValue map[string]bool
 	notes              map[string][]string
+	extensions         map[string]interface{}
+	warnings           []DecodeWarning
 }
 
 // TODO (temporary, using for spew output)
@@ -97,9 +104,91 @@ func (r DecodeData) UnknownFields() []string {
 	return fields
 }
 
+// UnknownMembers returns the unknown RDAP fields decoded (see
+// UnknownFields()), as raw JSON, keyed by RDAP field name. This gives access
+// to extension data the decoder doesn't support, e.g.:
+//
+//	var info RegistryInfo
+//	err := json.Unmarshal(domain.DecodeData.UnknownMembers()["example_info"], &info)
+//
+// Returns nil if there are no unknown fields.
+func (r DecodeData) UnknownMembers() map[string]json.RawMessage {
+	var members map[string]json.RawMessage
+
+	for _, f := range r.UnknownFields() {
+		raw, err := json.Marshal(r.values[f])
+		if err != nil {
+			continue
+		}
+
+		if members == nil {
+			members = map[string]json.RawMessage{}
+		}
+		members[f] = raw
+	}
+
+	return members
+}
+
+// UnmarshalValue decodes the value of the field |name| (known or unknown) into
+// |v|, using encoding/json.
+//
+// Returns an error if the field wasn't decoded, or doesn't match |v|.
+func (r DecodeData) UnmarshalValue(name string, v interface{}) error {
+	value, ok := r.values[name]
+	if !ok {
+		return fmt.Errorf("field %s not present", name)
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// Extension returns the value decoded by the registered Extension named
+// |name|, or nil if there is none. See RegisterExtension().
+func (r DecodeData) Extension(name string) interface{} {
+	if v, ok := r.extensions[name]; ok {
+		return v
+	}
+
+	return nil
+}
+
+// withExtension returns a copy of |r| with the Extension named |name| set to
+// |value|. |r| itself is left unchanged, and may be nil.
+func (r *DecodeData) withExtension(name string, value interface{}) *DecodeData {
+	d := &DecodeData{}
+	if r != nil {
+		*d = *r
+	}
+
+	d.extensions = map[string]interface{}{}
+	if r != nil {
+		for k, v := range r.extensions {
+			d.extensions[k] = v
+		}
+	}
+	d.extensions[name] = value
+
+	return d
+}
+
+// Warnings returns the problems found while decoding the whole response, in
+// lenient mode (see WithLenientDecoding()).
+//
+// Warnings are only set on the topmost RDAP object's DecodeData.
+func (r DecodeData) Warnings() []DecodeWarning {
+	return r.warnings
+}
+
 func (r *DecodeData) init() {
 	r.isKnown = map[string]bool{}
 	r.values = map[string]interface{}{}
 	r.overrideKnownValue = map[string]bool{}
 	r.notes = map[string][]string{}
+	r.extensions = map[string]interface{}{}
 }
@@ -0,0 +1,116 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"time"
+)
+
+// DecodeStats describes the size and shape of a decoded RDAP response.
+//
+// Services decoding untrusted responses can use these to detect pathological
+// responses (e.g. deeply nested entities), and to tune limits based on real
+// response distributions.
+//
+// To collect stats:
+//
+//	stats := &rdap.DecodeStats{}
+//	d := rdap.NewDecoder(jsonBlob, rdap.WithDecodeStats(stats))
+//	result, err := d.Decode()
+//
+//	fmt.Printf("%d bytes, %d entities\n", stats.Bytes, stats.Entities)
+type DecodeStats struct {
+	// Size of the JSON document.
+	Bytes int
+
+	// Number of JSON object members (properties), objects, and arrays in the
+	// document.
+	Properties int
+	Objects    int
+	Arrays     int
+
+	// Length of the longest JSON array.
+	MaxArrayLength int
+
+	// Maximum nesting depth of JSON objects/arrays. The top level object has
+	// depth 1.
+	MaxDepth int
+
+	// Number of entities (members of "entities" arrays), and the maximum
+	// entity nesting depth. Entities directly in the top level object have
+	// depth 1.
+	Entities       int
+	MaxEntityDepth int
+
+	// Number of decode notes (minor errors, type conversions), see DecodeData.
+	Notes int
+
+	// Time spent parsing the JSON, and the total decode time.
+	ParseDuration time.Duration
+	Duration      time.Duration
+}
+
+// WithDecodeStats returns a DecoderOption which stores stats about each
+// decode in |stats|.
+func WithDecodeStats(stats *DecodeStats) DecoderOption {
+	return func(d *Decoder) {
+		d.stats = stats
+	}
+}
+
+// String returns a one line summary of the stats.
+func (s *DecodeStats) String() string {
+	return fmt.Sprintf("bytes=%d properties=%d objects=%d arrays=%d max-array-length=%d "+
+		"max-depth=%d entities=%d max-entity-depth=%d notes=%d parse=%s total=%s",
+		s.Bytes, s.Properties, s.Objects, s.Arrays, s.MaxArrayLength,
+		s.MaxDepth, s.Entities, s.MaxEntityDepth, s.Notes, s.ParseDuration, s.Duration)
+}
+
+// collect walks the JSON value |v| at nesting depth |depth|, with
+// |entityDepth| entities above it.
+func (s *DecodeStats) collect(v interface{}, depth int, entityDepth int) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		s.Objects++
+		s.Properties += len(value)
+
+		if depth > s.MaxDepth {
+			s.MaxDepth = depth
+		}
+
+		for k, child := range value {
+			childEntityDepth := entityDepth
+
+			if k == "entities" {
+				childEntityDepth++
+
+				if entities, ok := child.([]interface{}); ok {
+					s.Entities += len(entities)
+
+					if len(entities) > 0 && childEntityDepth > s.MaxEntityDepth {
+						s.MaxEntityDepth = childEntityDepth
+					}
+				}
+			}
+
+			s.collect(child, depth+1, childEntityDepth)
+		}
+	case []interface{}:
+		s.Arrays++
+
+		if len(value) > s.MaxArrayLength {
+			s.MaxArrayLength = len(value)
+		}
+
+		if depth > s.MaxDepth {
+			s.MaxDepth = depth
+		}
+
+		for _, child := range value {
+			s.collect(child, depth+1, entityDepth)
+		}
+	}
+}
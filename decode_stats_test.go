@@ -0,0 +1,73 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"testing"
+)
+
+func TestDecodeStats(t *testing.T) {
+	jsonBlob := []byte(`
+		{
+			"objectClassName": "domain",
+			"ldhName": "example.cz",
+			"port43": 43,
+			"entities": [
+				{
+					"objectClassName": "entity",
+					"handle": "REG",
+					"entities": [
+						{"objectClassName": "entity", "handle": "ABUSE"}
+					]
+				},
+				{"objectClassName": "entity", "handle": "TECH"}
+			]
+		}
+	`)
+
+	stats := &DecodeStats{}
+	d := NewDecoder(jsonBlob, WithDecodeStats(stats))
+
+	if _, err := d.Decode(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := DecodeStats{
+		Bytes:          len(jsonBlob),
+		Properties:     11,
+		Objects:        4,
+		Arrays:         2,
+		MaxArrayLength: 2,
+		MaxDepth:       5,
+		Entities:       3,
+		MaxEntityDepth: 2,
+		Notes:          1,
+	}
+
+	got := *stats
+	got.ParseDuration = 0
+	got.Duration = 0
+
+	if got != expected {
+		t.Errorf("Got stats %s, expected %s", &got, &expected)
+	}
+
+	if stats.Duration < stats.ParseDuration {
+		t.Errorf("Unexpected durations %s", stats)
+	}
+}
+
+func TestDecodeStatsInvalidJSON(t *testing.T) {
+	stats := &DecodeStats{Entities: 123}
+	d := NewDecoder([]byte(`{"a": [1, 2`), WithDecodeStats(stats))
+
+	if _, err := d.Decode(); err == nil {
+		t.Fatalf("Unexpected success")
+	}
+
+	if stats.Bytes != 11 || stats.Entities != 0 || stats.Objects != 0 {
+		t.Errorf("Unexpected stats %s", stats)
+	}
+}
@@ -6,10 +6,13 @@ package rdap
 
 import (
 	"encoding/json"
+	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Decoder decodes an RDAP response (https://tools.ietf.org/html/rfc7483) into a Go value.
@@ -50,6 +53,7 @@ import (
 //	&rdap.DomainSearchResults{}     - Responses with a domainSearchResults array.
 //	&rdap.EntitySearchResults{}     - Responses with a entitySearchResults array.
 //	&rdap.NameserverSearchResults{} - Responses with a nameserverSearchResults array.
+//	&rdap.History{}                 - Responses with a records array (history extension).
 //	&rdap.Help{}                    - All other valid JSON responses.
 //
 // Note that an RDAP server may return a different response type than expected.
@@ -58,10 +62,27 @@ import (
 // documentation for accessing them.
 //
 // Decoding is performed on a best-effort basis, with "minor error"s ignored.
-// This avoids minor errors rendering a response undecodable.
+// This avoids minor errors rendering a response undecodable. For checking
+// responses (e.g. registry QA), see WithStrictDecoding(). For collecting the
+// problems as warnings, see WithLenientDecoding().
 type Decoder struct {
 	data   []byte
 	target interface{}
+
+	// rdapConformance values of the response being decoded.
+	conformance map[string]bool
+
+	// Optional stats output, see WithDecodeStats().
+	stats *DecodeStats
+
+	// Strict decoding, see WithStrictDecoding().
+	strict    bool
+	path      []string
+	strictErr *StrictDecodeError
+
+	// Lenient decoding, see WithLenientDecoding().
+	lenient  bool
+	warnings []DecodeWarning
 }
 
 // DecoderOption sets a Decoder option.
@@ -76,6 +97,36 @@ func (d DecoderError) Error() string {
 	return d.text
 }
 
+// StrictDecodeError is returned by Decoder.Decode() in strict mode (see
+// WithStrictDecoding()), for the first problem found in the response.
+type StrictDecodeError struct {
+	// JSON path of the problem, e.g. "$.entities[0].roles".
+	Path string
+
+	// Problem, e.g. "invalid JSON type, expecting string".
+	Text string
+}
+
+func (e *StrictDecodeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Text)
+}
+
+// WithStrictDecoding returns a DecoderOption which makes Decode() fail on
+// problems normally ignored:
+//   - Unknown top-level members (other than those of registered extensions
+//     active in the response).
+//   - Wrong JSON types, including type conversions (e.g. a number for a
+//     string member) and nulls.
+//   - Objects missing the objectClassName member.
+//
+// The error is a *StrictDecodeError, locating the first problem found. The
+// decoded result is still returned.
+func WithStrictDecoding() DecoderOption {
+	return func(d *Decoder) {
+		d.strict = true
+	}
+}
+
 // NewDecoder creates a new Decoder to decode the RDAP response |jsonBlob|.
 //
 // |opts| is an optional list of DecoderOptions.
@@ -92,6 +143,37 @@ func NewDecoder(jsonBlob []byte, opts ...DecoderOption) *Decoder {
 	return d
 }
 
+// DecodeWarning is a problem found while decoding in lenient mode (see
+// WithLenientDecoding()).
+type DecodeWarning struct {
+	// JSON path of the problem, e.g. "$.events[0].eventDate".
+	Path string
+
+	// Problem, e.g. "invalid RFC 3339 date".
+	Text string
+}
+
+func (w DecodeWarning) String() string {
+	return fmt.Sprintf("%s: %s", w.Path, w.Text)
+}
+
+// WithLenientDecoding returns a DecoderOption which makes Decode() salvage as
+// much of malformed responses as possible, collecting the problems as
+// warnings:
+//   - Wrong JSON types, including type conversions (e.g. numbers as strings),
+//     and nulls (e.g. null arrays, decoded as empty).
+//   - Invalid event dates (which aren't RFC 3339 timestamps).
+//   - Unrecognised objectClassName values, the response is decoded as a Help
+//     response (or search results).
+//
+// The warnings are attached to the result's DecodeData, see
+// DecodeData.Warnings(). JSON syntax errors still fail.
+func WithLenientDecoding() DecoderOption {
+	return func(d *Decoder) {
+		d.lenient = true
+	}
+}
+
 // Decode decodes the JSON document. On success, one of several values is
 // returned.
 //
@@ -106,6 +188,7 @@ func NewDecoder(jsonBlob []byte, opts ...DecoderOption) *Decoder {
 //	&rdap.DomainSearchResults{}     - Responses with a domainSearchResults array.
 //	&rdap.EntitySearchResults{}     - Responses with a entitySearchResults array.
 //	&rdap.NameserverSearchResults{} - Responses with a nameserverSearchResults array.
+//	&rdap.History{}                 - Responses with a records array (history extension).
 //	&rdap.Help{}                    - All other valid JSON responses.
 //
 // On serious errors (e.g. JSON syntax error) an error is returned. Otherwise,
@@ -119,21 +202,101 @@ func (d *Decoder) Decode() (interface{}, error) {
 	var s map[string]interface{}
 	var err error
 
+	start := time.Now()
+
+	if d.stats != nil {
+		*d.stats = DecodeStats{
+			Bytes: len(d.data),
+		}
+
+		defer func() {
+			d.stats.Duration = time.Since(start)
+		}()
+	}
+
 	// Unmarshal the JSON document.
 	err = json.Unmarshal(d.data, &s)
+
+	if d.stats != nil {
+		d.stats.ParseDuration = time.Since(start)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
+	if d.stats != nil {
+		d.stats.collect(s, 1, 0)
+	}
+
 	// Decode the RDAP response.
 	var result interface{}
 	result, err = d.decodeTopLevel(s)
 
+	if err == nil && d.strict {
+		d.checkUnknownMembers(result)
+
+		if d.strictErr != nil {
+			err = d.strictErr
+		}
+	}
+
+	if d.lenient {
+		if decodeData := decodeDataOf(result); decodeData != nil {
+			decodeData.warnings = d.warnings
+		}
+	}
+
 	return result, err
 }
 
+// checkUnknownMembers notes a strict decoding error for the first (sorted)
+// unknown top-level member of |result|.
+func (d *Decoder) checkUnknownMembers(result interface{}) {
+	obj, ok := result.(RDAPObject)
+	if !ok {
+		return
+	}
+
+	decodeData := decodeDataOf(obj)
+	if decodeData == nil {
+		return
+	}
+
+	unknown := decodeData.UnknownFields()
+	if len(unknown) == 0 {
+		return
+	}
+
+	sort.Strings(unknown)
+	d.strictError(pathString([]string{"." + unknown[0]}), "unknown member")
+}
+
+// strictError notes the strict decoding error |text| at |path|, unless an
+// error was already noted.
+func (d *Decoder) strictError(path string, text string) {
+	if d.strictErr == nil {
+		d.strictErr = &StrictDecodeError{Path: path, Text: text}
+	}
+}
+
+// pathString returns the JSON path of the elements |path|.
+func pathString(path []string) string {
+	return "$" + strings.Join(path, "")
+}
+
 // decodeTopLevel decodes the top level object |src|.
 func (d *Decoder) decodeTopLevel(src map[string]interface{}) (interface{}, error) {
+	// Note the rdapConformance values, for activating extensions.
+	d.conformance = map[string]bool{}
+	if conformance, ok := src["rdapConformance"].([]interface{}); ok {
+		for _, c := range conformance {
+			if s, ok := c.(string); ok {
+				d.conformance[s] = true
+			}
+		}
+	}
+
 	// Choose the target struct type.
 	if d.target != nil {
 		// Target already selected, e.g. tests use this.
@@ -141,29 +304,30 @@ func (d *Decoder) decodeTopLevel(src map[string]interface{}) (interface{}, error
 		d.target = &Error{}
 	} else if o, exists := src["objectClassName"]; exists {
 		if objectClassName, ok := o.(string); ok {
-			switch objectClassName {
-			case "autnum":
-				d.target = &Autnum{}
-			case "domain":
-				d.target = &Domain{}
-			case "entity":
-				d.target = &Entity{}
-			case "ip network":
-				d.target = &IPNetwork{}
-			case "nameserver":
-				d.target = &Nameserver{}
-			default:
+			if obj := newObjectClass(objectClassName); obj != nil {
+				d.target = obj
+			} else if !d.lenient {
 				return nil, DecoderError{text: "objectClassName is not recognised"}
+			} else {
+				d.addWarning("$.objectClassName", "objectClassName is not recognised")
 			}
-		} else {
+		} else if !d.lenient {
 			return nil, DecoderError{text: "objectClassName is not a string"}
+		} else {
+			d.addWarning("$.objectClassName", "objectClassName is not a string")
 		}
+	}
+
+	if d.target != nil {
+		// Target selected.
 	} else if _, exists := src["domainSearchResults"]; exists {
 		d.target = &DomainSearchResults{}
 	} else if _, exists := src["entitySearchResults"]; exists {
 		d.target = &EntitySearchResults{}
 	} else if _, exists := src["nameserverSearchResults"]; exists {
 		d.target = &NameserverSearchResults{}
+	} else if _, exists := src["records"]; exists {
+		d.target = &History{}
 	}
 
 	// Default to returning a Help{}.
@@ -180,10 +344,63 @@ func (d *Decoder) decodeTopLevel(src map[string]interface{}) (interface{}, error
 	// Decode the response into the result type.
 	_, err := d.decode("", src, result, nil)
 
+	// History records decode their content on demand, and need the response's
+	// rdapConformance for extensions.
+	if h, ok := result.Interface().(*History); ok {
+		for i := range h.Records {
+			h.Records[i].conformance = h.Conformance
+		}
+	}
+
 	return result.Interface(), err
 
 }
 
+// newObjectClass returns a new object of the objectClassName |name|, e.g.
+// &Domain{} for "domain", including extension object classes. Returns nil if
+// |name| isn't recognised.
+func newObjectClass(name string) RDAPObject {
+	switch name {
+	case "autnum":
+		return &Autnum{}
+	case "domain":
+		return &Domain{}
+	case "entity":
+		return &Entity{}
+	case "ip network":
+		return &IPNetwork{}
+	case "nameserver":
+		return &Nameserver{}
+	default:
+		return extensionObjectClass(name)
+	}
+}
+
+// decodeObjectClass decodes the object |src| (e.g. a search result) by its
+// objectClassName, in a response declaring the rdapConformance values
+// |conformance|. Returns nil if the objectClassName isn't recognised.
+func decodeObjectClass(src map[string]interface{}, conformance []string) RDAPObject {
+	name, _ := src["objectClassName"].(string)
+
+	target := newObjectClass(name)
+	if target == nil {
+		return nil
+	}
+
+	d := &Decoder{
+		conformance: map[string]bool{},
+	}
+
+	for _, c := range conformance {
+		d.conformance[c] = true
+	}
+
+	result := reflect.New(reflect.TypeOf(target).Elem())
+	d.decode("", src, result, nil)
+
+	return result.Interface()
+}
+
 // decode decodes the JSON structure |src| into the value |dst|.
 //
 // The type of |dst| is predetermined, |src| must match it, or be convertable to
@@ -233,7 +450,10 @@ func (d *Decoder) decode(keyName string, src interface{}, dst reflect.Value, dec
 func (d *Decoder) decodeSlice(keyName string, src interface{}, dst reflect.Value, decodeData *DecodeData) (bool, error) {
 	// Cast the input to a slice.
 	srcSlice, ok := src.([]interface{})
-	if !ok {
+	if src == nil {
+		d.addDecodeNote(decodeData, keyName, "null to empty array conversion")
+		return false, nil
+	} else if !ok {
 		d.addDecodeNote(decodeData, keyName, "invalid JSON type, expecting array")
 		return false, nil
 	}
@@ -242,12 +462,14 @@ func (d *Decoder) decodeSlice(keyName string, src interface{}, dst reflect.Value
 	result := reflect.MakeSlice(dst.Type(), 0, len(srcSlice))
 
 	// Foreach value in the input slice...
-	for _, v := range srcSlice {
+	for i, v := range srcSlice {
 		// Construct a result value for it.
 		vdst := reflect.New(dst.Type().Elem())
 
 		// Decode into the result value.
+		d.pushPath(fmt.Sprintf("[%d]", i))
 		success, err := d.decode(keyName, v, reflect.Indirect(vdst), decodeData)
+		d.popPath()
 
 		if err != nil {
 			return false, err
@@ -292,7 +514,9 @@ func (d *Decoder) decodeMap(keyName string, src interface{}, dst reflect.Value,
 		vdst := reflect.New(dst.Type().Elem())
 
 		// Decode into the result value.
+		d.pushPath("." + k)
 		success, err := d.decode(keyName+":"+k, v, reflect.Indirect(vdst), decodeData)
+		d.popPath()
 
 		if err != nil {
 			return false, err
@@ -522,6 +746,12 @@ func (d *Decoder) decodeString(keyName string, src interface{}, dst reflect.Valu
 		d.addDecodeNote(decodeData, keyName, "float64 to string conversion")
 	case string:
 		result = src.(string)
+
+		if keyName == "eventDate" && d.lenient {
+			if _, err := time.Parse(time.RFC3339, result); err != nil {
+				d.addDecodeNote(decodeData, keyName, "invalid RFC 3339 date")
+			}
+		}
 	case nil:
 		result = ""
 		d.addDecodeNote(decodeData, keyName, "null to empty string conversion")
@@ -614,11 +844,20 @@ func (d *Decoder) decodeStruct(keyName string, src interface{}, dst reflect.Valu
 		}
 	}
 
+	// Objects must have an objectClassName in strict mode.
+	if _, ok := fields["objectClassName"]; ok && d.strict {
+		if _, ok := srcMap["objectClassName"]; !ok {
+			d.strictError(pathString(d.path), "missing required member objectClassName")
+		}
+	}
+
 	// Foreach field in |srcMap|...
 	for name, value := range srcMap {
 		// If there's a matching Go field, decode into it...
 		if _, ok := fields[name]; ok {
+			d.pushPath("." + name)
 			_, err := d.decode(name, value, fields[name], myDecodeData)
+			d.popPath()
 
 			if err != nil {
 				return false, err
@@ -626,6 +865,11 @@ func (d *Decoder) decodeStruct(keyName string, src interface{}, dst reflect.Valu
 		}
 	}
 
+	// Run any registered extensions.
+	if myDecodeData != nil {
+		d.decodeExtensions(srcMap, myDecodeData)
+	}
+
 	return true, err
 }
 
@@ -759,12 +1003,48 @@ func (d *Decoder) decodePtr(keyName string, src interface{}, dst reflect.Value,
 	return success, err
 }
 
+// pushPath adds |element| (e.g. ".entities", or "[0]") to the current JSON
+// path, in strict/lenient mode.
+func (d *Decoder) pushPath(element string) {
+	if d.strict || d.lenient {
+		d.path = append(d.path, element)
+	}
+}
+
+// popPath removes the last element of the current JSON path, in strict/lenient
+// mode.
+func (d *Decoder) popPath() {
+	if d.strict || d.lenient {
+		d.path = d.path[:len(d.path)-1]
+	}
+}
+
+// addWarning adds the lenient decoding warning |text| at |path|.
+func (d *Decoder) addWarning(path string, text string) {
+	d.warnings = append(d.warnings, DecodeWarning{Path: path, Text: text})
+}
+
 // addDecodeNote adds a DecodeData note |msg| for the field |key|.
+//
+// In strict mode, the note is also a strict decoding error. In lenient mode,
+// it's also a warning.
 func (d *Decoder) addDecodeNote(decodeData *DecodeData, key string, msg string) {
+	if d.strict {
+		d.strictError(pathString(d.path), msg)
+	}
+
+	if d.lenient {
+		d.addWarning(pathString(d.path), msg)
+	}
+
 	if decodeData == nil {
 		return
 	}
 
+	if d.stats != nil {
+		d.stats.Notes++
+	}
+
 	if _, ok := decodeData.notes[key]; !ok {
 		decodeData.notes[key] = []string{}
 	}
@@ -60,6 +60,44 @@ func TestDecodeDecodeData(t *testing.T) {
 	}
 }
 
+func TestDecodeUnknownMembers(t *testing.T) {
+	d := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.cz",
+		"example_info": {"tier": 2, "tags": ["a", "b"]},
+		"entities": [{"objectClassName": "entity", "example_flag": true}]
+	}`))
+
+	result, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domain := result.(*Domain)
+
+	members := domain.DecodeData.UnknownMembers()
+	if len(members) != 1 || string(members["example_info"]) != `{"tags":["a","b"],"tier":2}` {
+		t.Errorf("Unexpected unknown members %s", members)
+	}
+
+	var info struct {
+		Tier int
+		Tags []string
+	}
+	if err := domain.DecodeData.UnmarshalValue("example_info", &info); err != nil || info.Tier != 2 || len(info.Tags) != 2 {
+		t.Errorf("Unexpected value %+v, error %v", info, err)
+	}
+
+	if err := domain.DecodeData.UnmarshalValue("missing", &info); err == nil {
+		t.Errorf("Unexpected success")
+	}
+
+	entityMembers := domain.Entities[0].DecodeData.UnknownMembers()
+	if len(entityMembers) != 1 || string(entityMembers["example_flag"]) != "true" {
+		t.Errorf("Unexpected entity unknown members %s", entityMembers)
+	}
+}
+
 func TestDecodeVCard(t *testing.T) {
 	type XYZ struct {
 		VCard *VCard
@@ -342,6 +380,106 @@ func TestDecodeMismatchedTypes(t *testing.T) {
 	})
 }
 
+func TestDecodeStrict(t *testing.T) {
+	tests := []struct {
+		JSON string
+		Path string
+	}{
+		{
+			`{"objectClassName": "domain", "ldhName": "example.cz", "entities": [{"objectClassName": "entity", "roles": ["registrar", 1]}]}`,
+			"$.entities[0].roles[1]",
+		},
+		{
+			`{"objectClassName": "domain", "ldhName": "example.cz", "port43": null}`,
+			"$.port43",
+		},
+		{
+			`{"objectClassName": "domain", "ldhName": "example.cz", "nameservers": [{"ldhName": "ns.example.cz"}]}`,
+			"$.nameservers[0]",
+		},
+		{
+			`{"objectClassName": "domain", "ldhName": "example.cz", "zzz": 1, "unknown": true}`,
+			"$.unknown",
+		},
+		{
+			`{"objectClassName": "domain", "ldhName": "example.cz", "events": [{"eventAction": "registration", "eventDate": "2004-08-30T22:55:00Z"}]}`,
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		d := NewDecoder([]byte(test.JSON), WithStrictDecoding())
+		result, err := d.Decode()
+
+		if _, ok := result.(*Domain); !ok {
+			t.Errorf("%s: unexpected result %v", test.JSON, result)
+		}
+
+		strictErr, _ := err.(*StrictDecodeError)
+
+		if test.Path == "" && err != nil {
+			t.Errorf("%s: unexpected error %s", test.JSON, err)
+		} else if test.Path != "" && (strictErr == nil || strictErr.Path != test.Path) {
+			t.Errorf("%s: got error %v, expected path %s", test.JSON, err, test.Path)
+		}
+	}
+
+	// Lenient by default.
+	if _, err := NewDecoder([]byte(tests[0].JSON)).Decode(); err != nil {
+		t.Errorf("Unexpected error %s", err)
+	}
+}
+
+func TestDecodeLenient(t *testing.T) {
+	jsonBlob := `{
+		"objectClassName": "domain",
+		"ldhName": "example.cz",
+		"port43": 43,
+		"status": null,
+		"events": [
+			{"eventAction": "registration", "eventDate": "2004-08-30T22:55:00Z"},
+			{"eventAction": "expiration", "eventDate": "30/08/2019"}
+		]
+	}`
+
+	d := NewDecoder([]byte(jsonBlob), WithLenientDecoding())
+	result, err := d.Decode()
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	domain := result.(*Domain)
+	if domain.Port43 != "43" || len(domain.Events) != 2 || domain.Status != nil {
+		t.Errorf("Unexpected domain %+v", domain)
+	}
+
+	warnings := map[string]string{}
+	for _, w := range domain.DecodeData.Warnings() {
+		warnings[w.Path] = w.Text
+	}
+
+	expected := map[string]string{
+		"$.port43":              "float64 to string conversion",
+		"$.status":              "null to empty array conversion",
+		"$.events[1].eventDate": "invalid RFC 3339 date",
+	}
+
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Got warnings %v, expected %v", warnings, expected)
+	}
+
+	// Unrecognised objectClassName.
+	d = NewDecoder([]byte(`{"objectClassName": "widget"}`), WithLenientDecoding())
+	result, err = d.Decode()
+	if help, ok := result.(*Help); err != nil || !ok || len(help.DecodeData.Warnings()) != 1 {
+		t.Errorf("Unexpected result %v, error %v", result, err)
+	}
+
+	if _, err := NewDecoder([]byte(`{"objectClassName": "widget"}`)).Decode(); err == nil {
+		t.Errorf("Unexpected success")
+	}
+}
+
 func runDecode(t *testing.T, target interface{}, jsonBlob string) (interface{}, bool) {
 	d := NewDecoder([]byte(jsonBlob))
 	d.target = target
@@ -0,0 +1,305 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Difference is a semantic change between two RDAP objects, see Diff().
+type Difference struct {
+	// Changed field, e.g. "status", "nameservers", "event:expiration", or
+	// "contact:registrant:email".
+	Field string
+
+	// Old and new values. For list fields (e.g. "status"), each added or
+	// removed item is a separate Difference, with an empty Old (added) or New
+	// (removed).
+	Old string
+	New string
+}
+
+// String returns the difference as text, e.g. "status: added client hold".
+func (d Difference) String() string {
+	switch {
+	case d.Old == "":
+		return fmt.Sprintf("%s: added %s", d.Field, d.New)
+	case d.New == "":
+		return fmt.Sprintf("%s: removed %s", d.Field, d.Old)
+	default:
+		return fmt.Sprintf("%s: changed %s to %s", d.Field, d.Old, d.New)
+	}
+}
+
+// Diff returns the semantic changes from the RDAP object |from| to |to| (e.g.
+// two *Domain responses for the same domain), for change monitoring.
+//
+// The fields compared are:
+//   - The handle, name, status, and port43 server.
+//   - The latest date of each event action, except "last update of RDAP
+//     database", which changes on every query for some servers.
+//   - The entity handles in each role, and the contact data (name,
+//     organisation, email, telephone, address) of the first entity in each
+//     role.
+//   - Domains: the nameservers and their IP addresses, and DNSSEC data.
+//   - Nameservers: the IP addresses.
+//   - IP networks & autnums: the range, type, and country.
+//
+// Lists are compared as sets, so ordering and case differences are ignored.
+// Changes are sorted by field. Returns nil if there are none.
+func Diff(from RDAPObject, to RDAPObject) []Difference {
+	return NewDiffValues(to).Diff(NewDiffValues(from))
+}
+
+// Diff returns the changes from the values |old| to |v|, see Diff().
+func (v DiffValues) Diff(old DiffValues) []Difference {
+	var names []string
+	for name := range old {
+		names = append(names, name)
+	}
+
+	for name := range v {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	var diffs []Difference
+	for _, name := range names {
+		o := old[name]
+		n := v[name]
+
+		if len(o) == 1 && len(n) == 1 && !diffIsList(name) {
+			if o[0] != n[0] {
+				diffs = append(diffs, Difference{Field: name, Old: o[0], New: n[0]})
+			}
+
+			continue
+		}
+
+		for _, value := range o {
+			if !containsString(n, value) {
+				diffs = append(diffs, Difference{Field: name, Old: value})
+			}
+		}
+
+		for _, value := range n {
+			if !containsString(o, value) {
+				diffs = append(diffs, Difference{Field: name, New: value})
+			}
+		}
+	}
+
+	return diffs
+}
+
+// diffIsList returns true if the Diff() field |name| is a list.
+func diffIsList(name string) bool {
+	switch {
+	case name == "status", name == "nameservers", name == "ipAddresses", name == "dnssec", name == "roles":
+		return true
+	case strings.HasPrefix(name, "entity:"), strings.HasSuffix(name, ":ipAddresses"):
+		return true
+	}
+
+	return false
+}
+
+// DiffValues are the values of an RDAP object compared by Diff(), keyed by
+// field name (e.g. "status", "event:expiration"). List fields have a value per
+// item.
+//
+// DiffValues can be stored (e.g. as JSON), to compare later responses against
+// without keeping the whole RDAP object. See NewDiffValues().
+type DiffValues map[string][]string
+
+func (v DiffValues) set(field string, value string) {
+	if value != "" {
+		v[field] = []string{value}
+	}
+}
+
+func (v DiffValues) add(field string, value string) {
+	if value != "" && !containsString(v[field], value) {
+		v[field] = append(v[field], value)
+	}
+}
+
+// NewDiffValues returns the values compared by Diff() for the RDAP object
+// |obj|.
+func NewDiffValues(obj RDAPObject) DiffValues {
+	v := DiffValues{}
+
+	switch o := obj.(type) {
+	case *Domain:
+		v.set("handle", o.Handle)
+		v.set("name", strings.ToLower(strings.TrimSuffix(o.LDHName, ".")))
+		v.set("port43", o.Port43)
+		v.addStatus(o.Status)
+		v.addEvents(o.Events)
+		v.addEntities(o.Entities)
+
+		for _, ns := range o.Nameservers {
+			name := strings.ToLower(strings.TrimSuffix(ns.LDHName, "."))
+			v.add("nameservers", name)
+
+			addrs, _ := ns.Addrs()
+			for _, addr := range addrs {
+				v.add("nameserver:"+name+":ipAddresses", addr.String())
+			}
+		}
+
+		if o.SecureDNS != nil && o.SecureDNS.DelegationSigned != nil {
+			v.set("delegationSigned", strconv.FormatBool(*o.SecureDNS.DelegationSigned))
+		}
+
+		for _, record := range o.ZoneRecords() {
+			v.add("dnssec", record)
+		}
+	case *Nameserver:
+		v.set("handle", o.Handle)
+		v.set("name", strings.ToLower(strings.TrimSuffix(o.LDHName, ".")))
+		v.set("port43", o.Port43)
+		v.addStatus(o.Status)
+		v.addEvents(o.Events)
+		v.addEntities(o.Entities)
+
+		addrs, _ := o.Addrs()
+		for _, addr := range addrs {
+			v.add("ipAddresses", addr.String())
+		}
+	case *Entity:
+		v.set("handle", o.Handle)
+		v.set("port43", o.Port43)
+		v.addStatus(o.Status)
+		v.addEvents(o.Events)
+		v.addEntities(o.Entities)
+		v.addContact("contact", NewContact(o))
+
+		for _, role := range o.Roles {
+			v.add("roles", role)
+		}
+	case *IPNetwork:
+		v.set("handle", o.Handle)
+		v.set("name", o.Name)
+		v.set("range", o.StartAddress+" - "+o.EndAddress)
+		v.set("type", o.Type)
+		v.set("country", o.Country)
+		v.set("parentHandle", o.ParentHandle)
+		v.set("port43", o.Port43)
+		v.addStatus(o.Status)
+		v.addEvents(o.Events)
+		v.addEntities(o.Entities)
+	case *Autnum:
+		v.set("handle", o.Handle)
+		v.set("name", o.Name)
+		if start, end, err := o.Range(); err == nil {
+			v.set("range", fmt.Sprintf("AS%d - AS%d", start, end))
+		}
+		v.set("type", o.Type)
+		v.set("country", o.Country)
+		v.set("port43", o.Port43)
+		v.addStatus(o.Status)
+		v.addEvents(o.Events)
+		v.addEntities(o.Entities)
+	}
+
+	return v
+}
+
+func (v DiffValues) addStatus(status []string) {
+	for _, s := range ParseStatuses(status) {
+		v.add("status", string(s))
+	}
+}
+
+// addEvents notes the latest date of each event action. Dates are converted
+// to UTC, so they compare correctly whatever time zone offset the server used.
+func (v DiffValues) addEvents(events []Event) {
+	for _, e := range events {
+		if e.Action == EventLastUpdateOfRDAPDatabase {
+			continue
+		}
+
+		field := "event:" + e.Action
+
+		date := e.Date
+		if t, err := e.Time(); err == nil {
+			date = t.UTC().Format(time.RFC3339Nano)
+		}
+
+		if current := v[field]; len(current) == 0 || isLaterDate(date, current[0]) {
+			v.set(field, date)
+		}
+	}
+}
+
+// isLaterDate returns true if the event date |a| is later than |b|. Dates are
+// compared as times if both are valid RFC 3339 dates, otherwise as strings.
+func isLaterDate(a string, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+
+	if errA == nil && errB == nil {
+		return ta.After(tb)
+	}
+
+	return a > b
+}
+
+// addEntities notes the entity handles in each role, and the contact data of
+// the first entity in each role. Nested entities are included.
+func (v DiffValues) addEntities(entities []Entity) {
+	var roles []string
+
+	var walk func(entities []Entity)
+	walk = func(entities []Entity) {
+		for _, e := range entities {
+			handle := e.Handle
+			if v := e.ContactVCard(); handle == "" && v != nil {
+				handle = v.Name()
+			}
+
+			for _, role := range e.Roles {
+				v.add("entity:"+role, handle)
+
+				if !containsString(roles, role) {
+					roles = append(roles, role)
+				}
+			}
+
+			walk(e.Entities)
+		}
+	}
+
+	walk(entities)
+
+	for _, role := range roles {
+		v.addContact("contact:"+role, NewContact(findEntityByRole(role, entities)))
+	}
+}
+
+// addContact notes the contact data |c|, with fields prefixed by |prefix|.
+func (v DiffValues) addContact(prefix string, c *Contact) {
+	if c == nil {
+		return
+	}
+
+	v.set(prefix+":name", c.Name)
+	v.set(prefix+":org", c.Org)
+	v.set(prefix+":email", strings.ToLower(c.Email))
+	v.set(prefix+":tel", c.Tel)
+
+	address := strings.Join(strings.Fields(strings.Join([]string{
+		c.StreetAddress, c.Locality, c.Region, c.PostalCode, c.Country,
+	}, " ")), " ")
+	v.set(prefix+":address", address)
+}
@@ -0,0 +1,100 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestDiff(t *testing.T) {
+	load := func() *Domain {
+		obj, err := NewDecoder(test.LoadFile("rdap/rdap.registry.example/domain-example.example.json")).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return obj.(*Domain)
+	}
+
+	old := load()
+	updated := load()
+
+	// Reordering, case, and the RDAP database update time are ignored.
+	updated.Status = []string{"ACTIVE", "clientTransferProhibited"}
+	updated.Nameservers[0], updated.Nameservers[1] = updated.Nameservers[1], updated.Nameservers[0]
+	updated.Nameservers[0].LDHName = "NS2.example.net."
+	updated.Events[3].Date = "2025-01-01T00:00:00Z"
+
+	if diffs := Diff(old, updated); diffs != nil {
+		t.Errorf("Unexpected differences %v", diffs)
+	}
+
+	updated.Status = append(updated.Status, "client hold")
+	updated.Events[1].Date = "2032-02-03T04:05:06Z"
+	updated.Nameservers = updated.Nameservers[1:]
+	updated.Nameservers[0].IPAddresses.V4 = []string{"192.0.2.54"}
+	updated.Entities[0].Entities[0].VCard = nil
+
+	var got []string
+	for _, d := range Diff(old, updated) {
+		got = append(got, d.String())
+	}
+
+	expected := []string{
+		"contact:abuse:email: removed abuse@registrar.example",
+		"contact:abuse:name: removed Abuse Contact",
+		"contact:abuse:tel: removed tel:+1.5555555555",
+		"entity:abuse: removed Abuse Contact",
+		"event:expiration: changed 2031-02-03T04:05:06Z to 2032-02-03T04:05:06Z",
+		"nameserver:ns1.example.example:ipAddresses: removed 192.0.2.53",
+		"nameserver:ns1.example.example:ipAddresses: added 192.0.2.54",
+		"nameservers: removed ns2.example.net",
+		"status: added client hold",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got differences:\n%q\nexpected:\n%q", got, expected)
+	}
+}
+
+func TestDiffObjectTypes(t *testing.T) {
+	start, end := uint32(64496), uint32(64511)
+
+	diffs := Diff(&Autnum{Handle: "AS64496", StartAutnum: &start}, &Autnum{Handle: "AS64496", StartAutnum: &start, EndAutnum: &end})
+	if len(diffs) != 1 || diffs[0].String() != "range: changed AS64496 - AS64496 to AS64496 - AS64511" {
+		t.Errorf("Unexpected autnum differences %v", diffs)
+	}
+
+	diffs = Diff(&Domain{LDHName: "example.cz"}, &Nameserver{LDHName: "ns1.example.cz"})
+	if len(diffs) != 1 || diffs[0].Field != "name" {
+		t.Errorf("Unexpected differences %v", diffs)
+	}
+}
+
+func TestDiffEventTimeZones(t *testing.T) {
+	from := &Domain{Events: []Event{
+		{Action: EventExpiration, Date: "2030-01-01T10:00:00+10:00"},
+	}}
+
+	// The same time, in UTC.
+	to := &Domain{Events: []Event{
+		{Action: EventExpiration, Date: "2030-01-01T00:00:00Z"},
+	}}
+
+	if diffs := Diff(from, to); diffs != nil {
+		t.Errorf("Unexpected differences %v", diffs)
+	}
+
+	// The latest date is kept, whatever the offsets.
+	to.Events = append(to.Events, Event{Action: EventExpiration, Date: "2029-12-31T20:00:00-05:00"})
+
+	expected := []Difference{{Field: "event:expiration", Old: "2030-01-01T00:00:00Z", New: "2030-01-01T01:00:00Z"}}
+	if diffs := Diff(from, to); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Got %v, expected %v", diffs, expected)
+	}
+}
@@ -0,0 +1,283 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DNSSECAlgorithm is a DNSSEC algorithm number, from the IANA "DNS Security
+// Algorithm Numbers" registry.
+//
+// https://www.iana.org/assignments/dns-sec-alg-numbers/
+type DNSSECAlgorithm uint8
+
+const (
+	AlgorithmRSAMD5           DNSSECAlgorithm = 1
+	AlgorithmDH               DNSSECAlgorithm = 2
+	AlgorithmDSA              DNSSECAlgorithm = 3
+	AlgorithmRSASHA1          DNSSECAlgorithm = 5
+	AlgorithmDSANSEC3SHA1     DNSSECAlgorithm = 6
+	AlgorithmRSASHA1NSEC3SHA1 DNSSECAlgorithm = 7
+	AlgorithmRSASHA256        DNSSECAlgorithm = 8
+	AlgorithmRSASHA512        DNSSECAlgorithm = 10
+	AlgorithmECCGOST          DNSSECAlgorithm = 12
+	AlgorithmECDSAP256SHA256  DNSSECAlgorithm = 13
+	AlgorithmECDSAP384SHA384  DNSSECAlgorithm = 14
+	AlgorithmED25519          DNSSECAlgorithm = 15
+	AlgorithmED448            DNSSECAlgorithm = 16
+)
+
+var dnssecAlgorithmNames = map[DNSSECAlgorithm]string{
+	AlgorithmRSAMD5:           "RSAMD5",
+	AlgorithmDH:               "DH",
+	AlgorithmDSA:              "DSA",
+	AlgorithmRSASHA1:          "RSASHA1",
+	AlgorithmDSANSEC3SHA1:     "DSA-NSEC3-SHA1",
+	AlgorithmRSASHA1NSEC3SHA1: "RSASHA1-NSEC3-SHA1",
+	AlgorithmRSASHA256:        "RSASHA256",
+	AlgorithmRSASHA512:        "RSASHA512",
+	AlgorithmECCGOST:          "ECC-GOST",
+	AlgorithmECDSAP256SHA256:  "ECDSAP256SHA256",
+	AlgorithmECDSAP384SHA384:  "ECDSAP384SHA384",
+	AlgorithmED25519:          "ED25519",
+	AlgorithmED448:            "ED448",
+}
+
+// String returns the algorithm's mnemonic, e.g. "ECDSAP256SHA256", or its
+// number if unknown.
+func (a DNSSECAlgorithm) String() string {
+	if name, ok := dnssecAlgorithmNames[a]; ok {
+		return name
+	}
+
+	return strconv.FormatUint(uint64(a), 10)
+}
+
+// DigestType is a DS record digest type, from the IANA "Delegation Signer (DS)
+// Resource Record (RR) Type Digest Algorithms" registry.
+//
+// https://www.iana.org/assignments/ds-rr-types/
+type DigestType uint8
+
+const (
+	DigestSHA1   DigestType = 1
+	DigestSHA256 DigestType = 2
+	DigestGOST   DigestType = 3
+	DigestSHA384 DigestType = 4
+)
+
+var digestTypeNames = map[DigestType]string{
+	DigestSHA1:   "SHA-1",
+	DigestSHA256: "SHA-256",
+	DigestGOST:   "GOST R 34.11-94",
+	DigestSHA384: "SHA-384",
+}
+
+var digestTypeLengths = map[DigestType]int{
+	DigestSHA1:   20,
+	DigestSHA256: 32,
+	DigestGOST:   32,
+	DigestSHA384: 48,
+}
+
+// String returns the digest type's name, e.g. "SHA-256", or its number if
+// unknown.
+func (d DigestType) String() string {
+	if name, ok := digestTypeNames[d]; ok {
+		return name
+	}
+
+	return strconv.FormatUint(uint64(d), 10)
+}
+
+// Length returns the length of the digest type's digests in bytes, or 0 if
+// the digest type is unknown.
+func (d DigestType) Length() int {
+	return digestTypeLengths[d]
+}
+
+// AlgorithmType returns the DS record's algorithm, or 0 if there's none.
+func (d *DSData) AlgorithmType() DNSSECAlgorithm {
+	if d.Algorithm == nil {
+		return 0
+	}
+
+	return DNSSECAlgorithm(*d.Algorithm)
+}
+
+// DigestTypeValue returns the DS record's digest type, or 0 if there's none.
+func (d *DSData) DigestTypeValue() DigestType {
+	if d.DigestType == nil {
+		return 0
+	}
+
+	return DigestType(*d.DigestType)
+}
+
+// Validate checks the DS record, returning a list of problems found, e.g.
+// "digest length is 20 bytes, expected 32 for SHA-256".
+func (d *DSData) Validate() []string {
+	var problems []string
+
+	if d.KeyTag == nil {
+		problems = append(problems, "missing keyTag")
+	} else if *d.KeyTag > 65535 {
+		problems = append(problems, fmt.Sprintf("keyTag %d out of range", *d.KeyTag))
+	}
+
+	if d.Algorithm == nil {
+		problems = append(problems, "missing algorithm")
+	}
+
+	if d.DigestType == nil {
+		problems = append(problems, "missing digestType")
+	}
+
+	digest, err := hex.DecodeString(normaliseDigest(d.Digest))
+	if err != nil || len(digest) == 0 {
+		problems = append(problems, fmt.Sprintf("digest %q is not hexadecimal", d.Digest))
+	} else if length := d.DigestTypeValue().Length(); length > 0 && len(digest) != length {
+		problems = append(problems, fmt.Sprintf("digest length is %d bytes, expected %d for %s",
+			len(digest), length, d.DigestTypeValue()))
+	}
+
+	return problems
+}
+
+// ZoneFormat returns the DS record in zone file format, for the domain name
+// |owner|, e.g. "example.cz. IN DS 12345 13 2 ABCDEF...".
+func (d *DSData) ZoneFormat(owner string) string {
+	var keyTag uint64
+	if d.KeyTag != nil {
+		keyTag = *d.KeyTag
+	}
+
+	return fmt.Sprintf("%s IN DS %d %d %d %s",
+		fqdn(owner),
+		keyTag,
+		d.AlgorithmType(),
+		d.DigestTypeValue(),
+		strings.ToUpper(normaliseDigest(d.Digest)))
+}
+
+// AlgorithmType returns the key's algorithm, or 0 if there's none.
+func (k *KeyData) AlgorithmType() DNSSECAlgorithm {
+	if k.Algorithm == nil {
+		return 0
+	}
+
+	return DNSSECAlgorithm(*k.Algorithm)
+}
+
+// Validate checks the DNSKEY record, returning a list of problems found.
+func (k *KeyData) Validate() []string {
+	var problems []string
+
+	if k.Flags == nil {
+		problems = append(problems, "missing flags")
+	} else if *k.Flags != 256 && *k.Flags != 257 {
+		problems = append(problems, fmt.Sprintf("flags %d is neither 256 (ZSK) nor 257 (KSK)", *k.Flags))
+	}
+
+	if k.Protocol == nil {
+		problems = append(problems, "missing protocol")
+	} else if *k.Protocol != 3 {
+		problems = append(problems, fmt.Sprintf("protocol %d, expected 3", *k.Protocol))
+	}
+
+	if k.Algorithm == nil {
+		problems = append(problems, "missing algorithm")
+	}
+
+	if key, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(k.PublicKey), "")); err != nil || len(key) == 0 {
+		problems = append(problems, "publicKey is not base64")
+	}
+
+	return problems
+}
+
+// ZoneFormat returns the DNSKEY record in zone file format, for the domain
+// name |owner|, e.g. "example.cz. IN DNSKEY 257 3 13 mdsswUyr3DPW...".
+func (k *KeyData) ZoneFormat(owner string) string {
+	var flags uint16
+	if k.Flags != nil {
+		flags = *k.Flags
+	}
+
+	var protocol uint8
+	if k.Protocol != nil {
+		protocol = *k.Protocol
+	}
+
+	return fmt.Sprintf("%s IN DNSKEY %d %d %d %s",
+		fqdn(owner),
+		flags,
+		protocol,
+		k.AlgorithmType(),
+		strings.Join(strings.Fields(k.PublicKey), ""))
+}
+
+// Validate checks the DS and DNSKEY records, returning a list of problems
+// found, each prefixed with the record, e.g. "dsData[0]: missing keyTag".
+func (s *SecureDNS) Validate() []string {
+	var problems []string
+
+	for i := range s.DS {
+		for _, problem := range s.DS[i].Validate() {
+			problems = append(problems, fmt.Sprintf("dsData[%d]: %s", i, problem))
+		}
+	}
+
+	for i := range s.Keys {
+		for _, problem := range s.Keys[i].Validate() {
+			problems = append(problems, fmt.Sprintf("keyData[%d]: %s", i, problem))
+		}
+	}
+
+	if s.DelegationSigned != nil && *s.DelegationSigned && len(s.DS) == 0 && len(s.Keys) == 0 {
+		problems = append(problems, "delegationSigned is true, but there are no dsData or keyData records")
+	}
+
+	return problems
+}
+
+// ZoneRecords returns the domain's DS and DNSKEY records in zone file format,
+// for cross checking against the DNS. Returns nil if the domain has no
+// secureDNS data.
+func (d *Domain) ZoneRecords() []string {
+	if d.SecureDNS == nil {
+		return nil
+	}
+
+	var records []string
+	for i := range d.SecureDNS.DS {
+		records = append(records, d.SecureDNS.DS[i].ZoneFormat(d.LDHName))
+	}
+
+	for i := range d.SecureDNS.Keys {
+		records = append(records, d.SecureDNS.Keys[i].ZoneFormat(d.LDHName))
+	}
+
+	return records
+}
+
+// normaliseDigest returns the hex digest |digest| without whitespace.
+func normaliseDigest(digest string) string {
+	return strings.Join(strings.Fields(digest), "")
+}
+
+// fqdn returns the domain name |name| with a trailing dot.
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+
+	return name + "."
+}
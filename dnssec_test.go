@@ -0,0 +1,64 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSecureDNS(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.cz",
+		"secureDNS": {
+			"delegationSigned": true,
+			"dsData": [
+				{"keyTag": 12345, "algorithm": 13, "digestType": 2, "digest": "e2d3c916f6deeac73294e8268fb5885044a833fc5459588f4a9184cfc41a5766"},
+				{"keyTag": 54321, "algorithm": 8, "digestType": 2, "digest": "e2d3c916f6deeac73294e8268fb5885044a833fc"}
+			],
+			"keyData": [
+				{"flags": 257, "protocol": 3, "algorithm": 13, "publicKey": "mdsswUyr3DPW132mOi8V9xESWE8jTo0d xCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ=="},
+				{"flags": 1, "protocol": 3, "algorithm": 13, "publicKey": "not base64!"}
+			]
+		}
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := obj.(*Domain)
+
+	ds := d.SecureDNS.DS[0]
+	if ds.AlgorithmType() != AlgorithmECDSAP256SHA256 || ds.AlgorithmType().String() != "ECDSAP256SHA256" || ds.DigestTypeValue().String() != "SHA-256" {
+		t.Errorf("Unexpected DS algorithm/digest type %s/%s", ds.AlgorithmType(), ds.DigestTypeValue())
+	}
+
+	if DNSSECAlgorithm(99).String() != "99" || DigestType(99).Length() != 0 {
+		t.Errorf("Unexpected unknown algorithm/digest type")
+	}
+
+	problems := d.SecureDNS.Validate()
+	expected := []string{
+		"dsData[1]: digest length is 20 bytes, expected 32 for SHA-256",
+		"keyData[1]: flags 1 is neither 256 (ZSK) nor 257 (KSK)",
+		"keyData[1]: publicKey is not base64",
+	}
+
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Got problems %q, expected %q", problems, expected)
+	}
+
+	records := d.ZoneRecords()
+	if len(records) != 4 ||
+		records[0] != "example.cz. IN DS 12345 13 2 E2D3C916F6DEEAC73294E8268FB5885044A833FC5459588F4A9184CFC41A5766" ||
+		records[2] != "example.cz. IN DNSKEY 257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ==" {
+		t.Errorf("Unexpected zone records %q", records)
+	}
+
+	if (&Domain{}).ZoneRecords() != nil {
+		t.Errorf("Unexpected zone records without secureDNS")
+	}
+}
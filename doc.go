@@ -38,5 +38,12 @@
 // RIPE) run RDAP servers. A small number of TLDs (top level domains) support
 // RDAP so far, listed on https://data.iana.org/rdap/dns.json.
 //
+// Outside of the standard library, this package depends only on
+// golang.org/x/net/idna, for converting internationalized domain names. The
+// RDAP types, decoder, and client are all in this package. The command line
+// client (github.com/openrdap/rdap/cli) and monitoring daemon
+// (github.com/openrdap/rdap/daemon) are separate Go modules, so their
+// dependencies aren't required by programs using just this package.
+//
 // The RDAP protocol uses HTTP, with responses in a JSON format. A bootstrapping mechanism (http://data.iana.org/rdap/) is used to determine the server to query.
 package rdap
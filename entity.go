@@ -4,6 +4,8 @@
 
 package rdap
 
+import "net/netip"
+
 // Entity represents information of an organisation or person.
 //
 // Entity is a topmost RDAP response object.
@@ -29,3 +31,47 @@ type Entity struct {
 	Networks     []IPNetwork
 	Autnums      []Autnum
 }
+
+// NetworkPrefixes returns the CIDR prefixes of the entity's IP networks (its
+// networks member, e.g. an RIR organisation's address allocations).
+//
+// Each network's cidr0 prefixes are used if present, see IPNetwork.CIDRs().
+// Otherwise the prefixes are computed from its address range, see
+// IPNetwork.Prefixes(). Networks with an invalid range are skipped.
+func (e *Entity) NetworkPrefixes() []netip.Prefix {
+	var result []netip.Prefix
+	for i := range e.Networks {
+		prefixes := e.Networks[i].CIDRs()
+		if prefixes == nil {
+			prefixes, _ = e.Networks[i].Prefixes()
+		}
+
+		result = append(result, prefixes...)
+	}
+
+	return result
+}
+
+// HoldsAddr returns the entity's IP network containing |addr|, or nil if none
+// does.
+func (e *Entity) HoldsAddr(addr netip.Addr) *IPNetwork {
+	for i := range e.Networks {
+		if e.Networks[i].Contains(addr) {
+			return &e.Networks[i]
+		}
+	}
+
+	return nil
+}
+
+// HoldsASN returns the entity's autnum containing the AS number |asn|, or nil
+// if none does.
+func (e *Entity) HoldsASN(asn uint32) *Autnum {
+	for i := range e.Autnums {
+		if e.Autnums[i].Contains(asn) {
+			return &e.Autnums[i]
+		}
+	}
+
+	return nil
+}
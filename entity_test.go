@@ -0,0 +1,61 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestEntityNetworksAndAutnums(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.arin.net/entity-EXAMPLE-ORG.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, ok := obj.(*Entity)
+	if !ok {
+		t.Fatalf("Unexpected object type %T", obj)
+	}
+
+	if len(e.Networks) != 2 || e.Networks[0].Handle != "NET-192-0-2-0-1" || e.Networks[1].IPVersion != "v6" {
+		t.Fatalf("Unexpected networks %+v", e.Networks)
+	}
+
+	if len(e.Autnums) != 1 || e.Autnums[0].Name != "EXAMPLE-AS" {
+		t.Fatalf("Unexpected autnums %+v", e.Autnums)
+	}
+
+	if e.Networks[0].ObjectClassName != "ip network" || e.Autnums[0].ObjectClassName != "autnum" {
+		t.Errorf("Unexpected objectClassNames")
+	}
+
+	expectedPrefixes := []netip.Prefix{
+		netip.MustParsePrefix("192.0.2.0/23"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+	if prefixes := e.NetworkPrefixes(); !reflect.DeepEqual(prefixes, expectedPrefixes) {
+		t.Errorf("NetworkPrefixes() = %v, expected %v", prefixes, expectedPrefixes)
+	}
+
+	if n := e.HoldsAddr(netip.MustParseAddr("192.0.3.1")); n == nil || n.Handle != "NET-192-0-2-0-1" {
+		t.Errorf("HoldsAddr(192.0.3.1) = %+v", n)
+	}
+
+	if n := e.HoldsAddr(netip.MustParseAddr("198.51.100.1")); n != nil {
+		t.Errorf("HoldsAddr(198.51.100.1) = %+v", n)
+	}
+
+	if a := e.HoldsASN(64498); a == nil || a.Handle != "AS64496" {
+		t.Errorf("HoldsASN(64498) = %+v", a)
+	}
+
+	if a := e.HoldsASN(64500); a != nil {
+		t.Errorf("HoldsASN(64500) = %+v", a)
+	}
+}
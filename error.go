@@ -4,9 +4,16 @@
 
 package rdap
 
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
 // Error represents an error response.
 //
-// Error is a topmost RDAP response object.
+// Error is a topmost RDAP response object. It implements the error interface,
+// and is returned wrapped in a ClientError by the Client.
 type Error struct {
 	DecodeData *DecodeData
 
@@ -19,3 +26,32 @@ type Error struct {
 	Title       string
 	Description []string
 }
+
+// Error returns a description of the error response.
+func (e *Error) Error() string {
+	code := "(none)"
+	if e.ErrorCode != nil {
+		code = fmt.Sprintf("%d", *e.ErrorCode)
+	}
+
+	return fmt.Sprintf("Server returned error code %s, title='%s', description='%s'",
+		code,
+		e.Title,
+		strings.Join(e.Description, " "))
+}
+
+// decodeErrorResponse decodes the HTTP error response body |body| as an RDAP
+// error response. Returns nil if it isn't one (e.g. an HTML error page).
+func decodeErrorResponse(body []byte) *Error {
+	if !bytes.Contains(body, []byte("errorCode")) {
+		return nil
+	}
+
+	obj, err := NewDecoder(body).Decode()
+	if err != nil {
+		return nil
+	}
+
+	e, _ := obj.(*Error)
+	return e
+}
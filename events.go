@@ -0,0 +1,238 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import "time"
+
+// Event actions, from the IANA "RDAP JSON Values" registry (RFC 9083 section
+// 10.2.3).
+//
+// https://www.iana.org/assignments/rdap-json-values/
+const (
+	EventRegistration             = "registration"
+	EventReregistration           = "reregistration"
+	EventLastChanged              = "last changed"
+	EventExpiration               = "expiration"
+	EventDeletion                 = "deletion"
+	EventReinstantiation          = "reinstantiation"
+	EventTransfer                 = "transfer"
+	EventLocked                   = "locked"
+	EventUnlocked                 = "unlocked"
+	EventLastUpdateOfRDAPDatabase = "last update of RDAP database"
+	EventRegistrarExpiration      = "registrar expiration"
+	EventEnumValidationExpiration = "enum validation expiration"
+)
+
+// Time returns the event's date, parsed from RFC 3339 format, in UTC.
+//
+// Returns an error if the date is missing or invalid.
+func (e *Event) Time() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, e.Date)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return t.UTC(), nil
+}
+
+// eventTime returns the time of the first event in |events| with the action
+// |action| and a valid date, or false if there's none.
+func eventTime(events []Event, action string) (time.Time, bool) {
+	for i := range events {
+		if events[i].Action != action {
+			continue
+		}
+
+		if t, err := events[i].Time(); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Registered returns the time of the domain's "registration" event, in UTC.
+//
+// Returns false if there's no such event with a valid date.
+func (d *Domain) Registered() (time.Time, bool) {
+	return eventTime(d.Events, EventRegistration)
+}
+
+// LastChanged returns the time of the domain's "last changed" event, in UTC.
+//
+// Returns false if there's no such event with a valid date.
+func (d *Domain) LastChanged() (time.Time, bool) {
+	return eventTime(d.Events, EventLastChanged)
+}
+
+// Expires returns the time of the domain's "expiration" event, in UTC.
+//
+// Returns false if there's no such event with a valid date.
+func (d *Domain) Expires() (time.Time, bool) {
+	return eventTime(d.Events, EventExpiration)
+}
+
+// ActorEntity returns the entity named by the event's eventActor, found by
+// handle in |entities| (or their nested entities). Returns nil if the event
+// has no eventActor, or no such entity is found.
+func (e *Event) ActorEntity(entities []Entity) *Entity {
+	if e.Actor == "" {
+		return nil
+	}
+
+	for i := range entities {
+		if entities[i].Handle == e.Actor {
+			return &entities[i]
+		}
+	}
+
+	for i := range entities {
+		if actor := e.ActorEntity(entities[i].Entities); actor != nil {
+			return actor
+		}
+	}
+
+	return nil
+}
+
+// ActorEvents returns the events the entity performed on the object containing
+// it (its asEventActor member), as distinct from the entity's own events.
+//
+// Events in asEventActor have no eventActor, the entity being the actor: the
+// returned copies have their Actor set to the entity's handle.
+func (e *Entity) ActorEvents() []Event {
+	var result []Event
+	for _, event := range e.AsEventActor {
+		if event.Actor == "" {
+			event.Actor = e.Handle
+		}
+
+		result = append(result, event)
+	}
+
+	return result
+}
+
+// ObjectEvents returns the events of the RDAP object |obj| itself: its own
+// events, followed by the events its entities performed on it (see
+// Entity.ActorEvents()).
+//
+// Returns nil if |obj| has no events.
+func ObjectEvents(obj RDAPObject) []Event {
+	var result []Event
+	if f := structField(obj, "Events"); f.IsValid() {
+		events, _ := f.Interface().([]Event)
+		result = append(result, events...)
+	}
+
+	if f := structField(obj, "Entities"); f.IsValid() {
+		entities, _ := f.Interface().([]Entity)
+		for i := range entities {
+			result = append(result, entities[i].ActorEvents()...)
+		}
+	}
+
+	return result
+}
+
+// LatestEvent returns the latest event with the action |action|, found in the
+// RDAP object |obj| and the objects embedded in it: entities, nameservers,
+// networks, autnums, and DNSSEC data. For example, the latest "transfer" of a
+// domain or any of its contacts. Events performed by entities (asEventActor)
+// are included.
+//
+// Events without a valid RFC 3339 date are ignored. Returns nil if there's no
+// such event.
+func LatestEvent(obj RDAPObject, action string) *Event {
+	var latest *Event
+	var latestTime time.Time
+
+	for _, events := range allEventsOf(obj) {
+		for i := range events {
+			if events[i].Action != action {
+				continue
+			}
+
+			t, err := events[i].Time()
+			if err != nil {
+				continue
+			}
+
+			if latest == nil || t.After(latestTime) {
+				latest = &events[i]
+				latestTime = t
+			}
+		}
+	}
+
+	return latest
+}
+
+// allEventsOf returns the events of the RDAP object |obj|, and of each object
+// embedded in it.
+func allEventsOf(obj RDAPObject) [][]Event {
+	var result [][]Event
+
+	switch v := obj.(type) {
+	case *Domain:
+		result = append(result, v.Events)
+
+		for i := range v.Nameservers {
+			result = append(result, allEventsOf(&v.Nameservers[i])...)
+		}
+
+		for i := range v.Entities {
+			result = append(result, allEventsOf(&v.Entities[i])...)
+		}
+
+		if v.Network != nil {
+			result = append(result, allEventsOf(v.Network)...)
+		}
+
+		if v.SecureDNS != nil {
+			for _, ds := range v.SecureDNS.DS {
+				result = append(result, ds.Events)
+			}
+
+			for _, key := range v.SecureDNS.Keys {
+				result = append(result, key.Events)
+			}
+		}
+	case *Entity:
+		result = append(result, v.Events, v.AsEventActor)
+
+		for i := range v.Entities {
+			result = append(result, allEventsOf(&v.Entities[i])...)
+		}
+
+		for i := range v.Networks {
+			result = append(result, allEventsOf(&v.Networks[i])...)
+		}
+
+		for i := range v.Autnums {
+			result = append(result, allEventsOf(&v.Autnums[i])...)
+		}
+	case *Nameserver:
+		result = append(result, v.Events)
+
+		for i := range v.Entities {
+			result = append(result, allEventsOf(&v.Entities[i])...)
+		}
+	case *Autnum:
+		result = append(result, v.Events)
+
+		for i := range v.Entities {
+			result = append(result, allEventsOf(&v.Entities[i])...)
+		}
+	case *IPNetwork:
+		result = append(result, v.Events)
+
+		for i := range v.Entities {
+			result = append(result, allEventsOf(&v.Entities[i])...)
+		}
+	}
+
+	return result
+}
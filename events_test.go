@@ -0,0 +1,159 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestEventTime(t *testing.T) {
+	tests := []struct {
+		Date  string
+		Time  time.Time
+		Valid bool
+	}{
+		{"2004-08-30T22:55:00+00:00", time.Date(2004, 8, 30, 22, 55, 0, 0, time.UTC), true},
+		{"2004-08-31T00:55:00.5+02:00", time.Date(2004, 8, 30, 22, 55, 0, 500000000, time.UTC), true},
+		{"2004-08-30", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		e := &Event{Date: test.Date}
+		got, err := e.Time()
+
+		if (err == nil) != test.Valid || !got.Equal(test.Time) || got.Location() != time.UTC {
+			t.Errorf("Date %q: got %s (error %v), expected %s", test.Date, got, err, test.Time)
+		}
+	}
+}
+
+func TestDomainEventTimes(t *testing.T) {
+	d := NewDecoder(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	result, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domain := result.(*Domain)
+
+	if registered, ok := domain.Registered(); !ok || !registered.Equal(time.Date(2004, 8, 30, 22, 55, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected registration time %s", registered)
+	}
+
+	if expires, ok := domain.Expires(); !ok || !expires.Equal(time.Date(2019, 8, 30, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected expiration time %s", expires)
+	}
+
+	if _, ok := domain.LastChanged(); ok {
+		t.Errorf("Unexpected last changed time")
+	}
+
+	// An invalid date is skipped.
+	domain = &Domain{
+		Events: []Event{
+			{Action: EventRegistration, Date: "not a date"},
+			{Action: EventRegistration, Date: "2004-08-30T22:55:00Z"},
+		},
+	}
+
+	if registered, ok := domain.Registered(); !ok || !registered.Equal(time.Date(2004, 8, 30, 22, 55, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected registration time %s", registered)
+	}
+}
+
+func TestLatestEvent(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.cz",
+		"events": [
+			{"eventAction": "transfer", "eventActor": "REG-A", "eventDate": "2010-01-01T00:00:00Z"},
+			{"eventAction": "last changed", "eventDate": "invalid"}
+		],
+		"entities": [
+			{
+				"objectClassName": "entity",
+				"handle": "CONTACT-1",
+				"events": [{"eventAction": "transfer", "eventDate": "2015-01-01T00:00:00Z"}],
+				"entities": [{"objectClassName": "entity", "handle": "REG-A"}]
+			}
+		],
+		"nameservers": [
+			{"objectClassName": "nameserver", "ldhName": "ns1.example.cz", "events": [{"eventAction": "transfer", "eventDate": "2012-01-01T00:00:00Z"}]}
+		]
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domain := obj.(*Domain)
+
+	if e := LatestEvent(domain, EventTransfer); e == nil || e.Date != "2015-01-01T00:00:00Z" {
+		t.Errorf("Unexpected latest transfer %+v", e)
+	}
+
+	if e := LatestEvent(domain, EventLastChanged); e != nil {
+		t.Errorf("Unexpected event with invalid date %+v", e)
+	}
+
+	if actor := domain.Events[0].ActorEntity(domain.Entities); actor == nil || actor.Handle != "REG-A" {
+		t.Errorf("Unexpected actor %+v", actor)
+	}
+
+	if actor := domain.Events[1].ActorEntity(domain.Entities); actor != nil {
+		t.Errorf("Unexpected actor %+v", actor)
+	}
+}
+
+func TestEntityActorEvents(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "ip network",
+		"handle": "NET-1",
+		"events": [{"eventAction": "registration", "eventDate": "2001-01-01T00:00:00Z"}],
+		"entities": [
+			{
+				"objectClassName": "entity",
+				"handle": "XYZ-RIR",
+				"events": [{"eventAction": "registration", "eventDate": "1999-01-01T00:00:00Z"}],
+				"asEventActor": [{"eventAction": "last changed", "eventDate": "2020-01-01T00:00:00Z"}]
+			}
+		]
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	network := obj.(*IPNetwork)
+	entity := &network.Entities[0]
+
+	if len(entity.Events) != 1 || entity.Events[0].Action != EventRegistration {
+		t.Errorf("Unexpected entity events %+v", entity.Events)
+	}
+
+	actorEvents := entity.ActorEvents()
+	if len(actorEvents) != 1 || actorEvents[0].Action != EventLastChanged || actorEvents[0].Actor != "XYZ-RIR" {
+		t.Errorf("Unexpected actor events %+v", actorEvents)
+	}
+
+	if entity.AsEventActor[0].Actor != "" {
+		t.Errorf("ActorEvents() modified AsEventActor")
+	}
+
+	events := ObjectEvents(network)
+	if len(events) != 2 || events[0].Action != EventRegistration || events[1].Action != EventLastChanged {
+		t.Errorf("Unexpected object events %+v", events)
+	}
+
+	if actor := events[1].ActorEntity(network.Entities); actor != entity {
+		t.Errorf("Unexpected actor %+v", actor)
+	}
+
+	if e := LatestEvent(network, EventLastChanged); e == nil || e.Date != "2020-01-01T00:00:00Z" {
+		t.Errorf("Unexpected latest last changed %+v", e)
+	}
+}
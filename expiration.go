@@ -0,0 +1,77 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import "time"
+
+// ExpirationSource identifies which expiration date of a domain was used, see
+// Domain.Expiration().
+type ExpirationSource string
+
+const (
+	// No expiration date was found.
+	ExpirationUnknown ExpirationSource = ""
+
+	// The registry's "expiration" event, see Domain.Expires().
+	ExpirationRegistry ExpirationSource = "registry"
+
+	// The registrar's expiration date, see Domain.RegistrarExpiration().
+	ExpirationRegistrar ExpirationSource = "registrar"
+)
+
+// Expiration returns the domain's expiration date, reconciling the registry's
+// and registrar's expiration dates, and which of them was used.
+//
+// When both are present (e.g. in a registry response merged with the
+// registrar's, where the registrar's "expiration" event becomes a "registrar
+// expiration" event, see Response.MergedDomain()), the earlier date wins. Registries
+// typically auto-renew domains on expiry, so during the auto-renew grace period
+// the registry's date is a year ahead of the registrar's, which reflects the
+// term the registrant paid for. A later registrar date can't outlive the
+// registration at the registry. If the dates are equal, the registry wins.
+//
+// Returns ExpirationUnknown (and the zero time) if the domain has neither.
+func (d *Domain) Expiration() (time.Time, ExpirationSource) {
+	registry, hasRegistry := d.Expires()
+	registrar, hasRegistrar := d.RegistrarExpiration()
+
+	switch {
+	case hasRegistry && hasRegistrar && registrar.Before(registry):
+		return registrar.UTC(), ExpirationRegistrar
+	case hasRegistry:
+		return registry, ExpirationRegistry
+	case hasRegistrar:
+		return registrar.UTC(), ExpirationRegistrar
+	default:
+		return time.Time{}, ExpirationUnknown
+	}
+}
+
+// ExpiresIn returns the time from |now| until the domain expires (negative if
+// it has expired), and which expiration date was used. See Expiration().
+//
+// Returns 0 and ExpirationUnknown if the domain has no expiration date.
+func (d *Domain) ExpiresIn(now time.Time) (time.Duration, ExpirationSource) {
+	expires, source := d.Expiration()
+	if source == ExpirationUnknown {
+		return 0, ExpirationUnknown
+	}
+
+	return expires.Sub(now), source
+}
+
+// IsExpired returns true if the domain expired more than |grace| before |now|,
+// e.g. 45 days for the typical gTLD auto-renew grace period (0 for none). See
+// Expiration().
+//
+// Returns false if the domain has no expiration date.
+func (d *Domain) IsExpired(now time.Time, grace time.Duration) bool {
+	expires, source := d.Expiration()
+	if source == ExpirationUnknown {
+		return false
+	}
+
+	return now.After(expires.Add(grace))
+}
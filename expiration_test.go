@@ -0,0 +1,85 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDomainExpiration(t *testing.T) {
+	decode := func(events string, members string) *Domain {
+		obj, err := NewDecoder([]byte(`{
+			"objectClassName": "domain",
+			"ldhName": "example.com",
+			"events": [` + events + `]` + members + `
+		}`)).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return obj.(*Domain)
+	}
+
+	registry := `{"eventAction": "expiration", "eventDate": "2025-06-01T00:00:00Z"}`
+	registrarEarlier := `{"eventAction": "registrar expiration", "eventDate": "2024-06-01T00:00:00Z"}`
+	registrarLater := `{"eventAction": "registrar expiration", "eventDate": "2026-06-01T00:00:00Z"}`
+
+	tests := []struct {
+		Domain   *Domain
+		Expected string
+		Source   ExpirationSource
+	}{
+		{decode(registry, ""), "2025-06-01T00:00:00Z", ExpirationRegistry},
+		{decode(registry+","+registrarEarlier, ""), "2024-06-01T00:00:00Z", ExpirationRegistrar},
+		{decode(registry+","+registrarLater, ""), "2025-06-01T00:00:00Z", ExpirationRegistry},
+		{decode(`{"eventAction": "registrar expiration", "eventDate": "2024-06-01T00:00:00+10:00"}`, ""), "2024-05-31T14:00:00Z", ExpirationRegistrar},
+		{decode("", ""), "0001-01-01T00:00:00Z", ExpirationUnknown},
+	}
+
+	for i, test := range tests {
+		expires, source := test.Domain.Expiration()
+		if expires.Format(time.RFC3339) != test.Expected || source != test.Source {
+			t.Errorf("#%d: Expiration() = %s, %q, expected %s, %q", i, expires.Format(time.RFC3339), source, test.Expected, test.Source)
+		}
+	}
+
+	now := time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC)
+	d := decode(registry+","+registrarEarlier, "")
+
+	if in, source := d.ExpiresIn(now); in != -10*24*time.Hour || source != ExpirationRegistrar {
+		t.Errorf("ExpiresIn() = %s, %q", in, source)
+	}
+
+	if !d.IsExpired(now, 0) || d.IsExpired(now, 45*24*time.Hour) {
+		t.Errorf("Unexpected IsExpired() results")
+	}
+
+	unknown := decode("", "")
+	if in, source := unknown.ExpiresIn(now); in != 0 || source != ExpirationUnknown || unknown.IsExpired(now, 0) {
+		t.Errorf("Unexpected results for domain without expiration")
+	}
+}
+
+func TestMergedDomainExpiration(t *testing.T) {
+	registry, _ := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.com",
+		"events": [{"eventAction": "expiration", "eventDate": "2025-06-01T00:00:00Z"}]
+	}`)).Decode()
+
+	registrar, _ := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.com",
+		"events": [{"eventAction": "expiration", "eventDate": "2024-06-01T00:00:00Z"}]
+	}`)).Decode()
+
+	resp := &Response{Object: registry, Referral: &Response{Object: registrar}}
+
+	expires, source := resp.MergedDomain().Expiration()
+	if source != ExpirationRegistrar || expires.Format(time.RFC3339) != "2024-06-01T00:00:00Z" {
+		t.Errorf("Unexpected merged expiration %s, %q", expires, source)
+	}
+}
@@ -0,0 +1,365 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Extension describes an RDAP extension, e.g. a registry specific set of
+// response members.
+//
+// Extensions are registered with RegisterExtension(), normally from the init()
+// function of the package implementing the extension. This allows extensions
+// to be shipped as separate Go modules, which plug into the Decoder,
+// ValidateExtensions() and the Printer:
+//
+//	func init() {
+//	  rdap.RegisterExtension(rdap.Extension{
+//	    Name:        "example",
+//	    Conformance: "example_level_0",
+//	    Members:     []string{"example_colour"},
+//	    Decode:      decodeExample,
+//	    Validate:    validateExample,
+//	    Print:       printExample,
+//	  })
+//	}
+//
+// An Extension is active for an RDAP response if its Conformance identifier is
+// listed in the response's rdapConformance, or for any object containing one
+// of its Members, or a member starting with its MemberPrefix (servers don't
+// always list the extensions they use).
+type Extension struct {
+	// Name of the extension, e.g. "fred". Required, and must be unique.
+	Name string
+
+	// rdapConformance identifier, e.g. "fred_version_0". Optional.
+	Conformance string
+
+	// JSON member names (of any RDAP object) used by the extension, e.g.
+	// "fred_keyset".
+	//
+	// These members are not reported by DecodeData.UnknownFields().
+	Members []string
+
+	// Prefix of the JSON member names used by the extension, e.g. "fred_".
+	// Optional.
+	//
+	// RFC 9083 extension members are prefixed by the extension identifier and
+	// an underscore, so a prefix matches all of an extension's members,
+	// including ones not listed in Members. Matching members are not reported
+	// by DecodeData.UnknownFields().
+	MemberPrefix string
+
+	// Constructors for the extension's own object classes, keyed by
+	// objectClassName, e.g. "fred_nsset". Optional.
+	//
+	// Responses with these object classes are decoded into the (pointer to
+	// struct) value returned, instead of failing as unrecognised. The struct
+	// should have a DecodeData field, and a Conformance field tagged
+	// `rdap:"rdapConformance"`.
+	ObjectClasses map[string]func() RDAPObject
+
+	// Decode hook, called for each decoded RDAP object (Domain, Entity, Link,
+	// etc.) while the extension is active.
+	//
+	// |src| is the raw JSON object. The value returned is available via
+	// DecodeData.Extension(). Return nil if |src| contains no extension data.
+	//
+	// Errors are noted as minor errors, see DecodeData.Notes().
+	Decode func(src map[string]interface{}) (interface{}, error)
+
+	// Validator rules, called by ValidateExtensions() for the topmost RDAP
+	// object |obj| (e.g. *Domain).
+	//
+	// Returns a list of problems found, e.g. "fred_keyset: missing handle".
+	Validate func(obj RDAPObject) []string
+
+	// Renderer fragment, called by the Printer for each RDAP object with a
+	// decoded extension value.
+	Print func(value interface{}, p *ExtensionPrinter)
+}
+
+var (
+	extensionsMu sync.RWMutex
+	extensions   = map[string]*Extension{}
+)
+
+// RegisterExtension registers the RDAP extension |ext|.
+//
+// RegisterExtension is intended to be called from init() functions, and panics
+// if |ext| has no name, or an extension of the same name is already
+// registered.
+func RegisterExtension(ext Extension) {
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+
+	if ext.Name == "" {
+		panic("rdap: RegisterExtension called with empty name")
+	}
+
+	if _, exists := extensions[ext.Name]; exists {
+		panic("rdap: RegisterExtension called twice for extension " + ext.Name)
+	}
+
+	extensions[ext.Name] = &ext
+}
+
+// RegisteredExtensions returns the registered RDAP extensions, sorted by
+// name.
+func RegisteredExtensions() []Extension {
+	var result []Extension
+
+	for _, ext := range registeredExtensions() {
+		result = append(result, *ext)
+	}
+
+	return result
+}
+
+func registeredExtensions() []*Extension {
+	extensionsMu.RLock()
+	defer extensionsMu.RUnlock()
+
+	result := make([]*Extension, 0, len(extensions))
+	for _, ext := range extensions {
+		result = append(result, ext)
+	}
+
+	sort.Slice(result, func(i int, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// extensionObjectClass returns a new value for the registered extension
+// object class |objectClassName|, or nil if there's none.
+func extensionObjectClass(objectClassName string) RDAPObject {
+	for _, ext := range registeredExtensions() {
+		if newObject, ok := ext.ObjectClasses[objectClassName]; ok {
+			return newObject()
+		}
+	}
+
+	return nil
+}
+
+// isActive returns true if the extension is active for the RDAP object |src|,
+// in a response declaring the rdapConformance values |conformance|.
+func (e *Extension) isActive(src map[string]interface{}, conformance map[string]bool) bool {
+	if e.Conformance != "" && conformance[e.Conformance] {
+		return true
+	}
+
+	for _, m := range e.Members {
+		if _, ok := src[m]; ok {
+			return true
+		}
+	}
+
+	if e.MemberPrefix != "" {
+		for name := range src {
+			if strings.HasPrefix(name, e.MemberPrefix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// decodeExtensions runs the active extensions' decode hooks on the RDAP object
+// |src|, storing the results in |decodeData|.
+func (d *Decoder) decodeExtensions(src map[string]interface{}, decodeData *DecodeData) {
+	for _, ext := range registeredExtensions() {
+		if !ext.isActive(src, d.conformance) {
+			continue
+		}
+
+		for _, m := range ext.Members {
+			decodeData.isKnown[m] = true
+		}
+
+		if ext.MemberPrefix != "" {
+			for name := range src {
+				if strings.HasPrefix(name, ext.MemberPrefix) {
+					decodeData.isKnown[name] = true
+				}
+			}
+		}
+
+		if ext.Decode == nil {
+			continue
+		}
+
+		value, err := ext.Decode(src)
+		if err != nil {
+			d.addDecodeNote(decodeData, ext.Name, err.Error())
+		} else if value != nil {
+			decodeData.extensions[ext.Name] = value
+		}
+	}
+}
+
+// ValidateExtensions runs the validator rules of each registered extension
+// active in the topmost RDAP object |obj| (e.g. a *Domain).
+//
+// Returns a list of problems found, each prefixed with the extension name.
+func ValidateExtensions(obj RDAPObject) []string {
+	conformance := map[string]bool{}
+	for _, c := range conformanceOf(obj) {
+		conformance[c] = true
+	}
+
+	decodeData := decodeDataOf(obj)
+
+	var problems []string
+	for _, ext := range registeredExtensions() {
+		if ext.Validate == nil {
+			continue
+		}
+
+		active := ext.Conformance != "" && conformance[ext.Conformance]
+		if decodeData != nil && decodeData.Extension(ext.Name) != nil {
+			active = true
+		}
+
+		if !active {
+			continue
+		}
+
+		for _, problem := range ext.Validate(obj) {
+			problems = append(problems, fmt.Sprintf("%s: %s", ext.Name, problem))
+		}
+	}
+
+	return problems
+}
+
+// ExtensionPrinter is used by an Extension's Print function to render the
+// extension's data as part of the Printer output.
+type ExtensionPrinter struct {
+	printer     *Printer
+	indentLevel uint
+}
+
+// Value prints the value |value|, labelled |name|.
+func (e *ExtensionPrinter) Value(name string, value string) {
+	e.printer.printValue(name, value, e.indentLevel)
+}
+
+// Heading prints the heading |heading|. The returned ExtensionPrinter prints
+// values indented beneath the heading.
+func (e *ExtensionPrinter) Heading(heading string) *ExtensionPrinter {
+	e.printer.printHeading(heading, e.indentLevel)
+
+	return &ExtensionPrinter{
+		printer:     e.printer,
+		indentLevel: e.indentLevel + 1,
+	}
+}
+
+// printExtensions prints the decoded extension values in |d|.
+func (p *Printer) printExtensions(d *DecodeData, indentLevel uint) {
+	for _, ext := range registeredExtensions() {
+		value := d.Extension(ext.Name)
+
+		if ext.Print == nil || value == nil {
+			continue
+		}
+
+		ext.Print(value, &ExtensionPrinter{
+			printer:     p,
+			indentLevel: indentLevel,
+		})
+	}
+}
+
+// conformanceOf returns the rdapConformance values of the topmost RDAP object
+// |obj|.
+func conformanceOf(obj RDAPObject) []string {
+	switch v := obj.(type) {
+	case *Domain:
+		return v.Conformance
+	case *Entity:
+		return v.Conformance
+	case *Nameserver:
+		return v.Conformance
+	case *Autnum:
+		return v.Conformance
+	case *IPNetwork:
+		return v.Conformance
+	case *Help:
+		return v.Conformance
+	case *Error:
+		return v.Conformance
+	case *DomainSearchResults:
+		return v.Conformance
+	case *EntitySearchResults:
+		return v.Conformance
+	case *NameserverSearchResults:
+		return v.Conformance
+	default:
+		// Extension object class?
+		if f := structField(obj, "Conformance"); f.IsValid() {
+			if conformance, ok := f.Interface().([]string); ok {
+				return conformance
+			}
+		}
+
+		return nil
+	}
+}
+
+// decodeDataOf returns the DecodeData of the topmost RDAP object |obj|.
+func decodeDataOf(obj RDAPObject) *DecodeData {
+	switch v := obj.(type) {
+	case *Domain:
+		return v.DecodeData
+	case *Entity:
+		return v.DecodeData
+	case *Nameserver:
+		return v.DecodeData
+	case *Autnum:
+		return v.DecodeData
+	case *IPNetwork:
+		return v.DecodeData
+	case *Help:
+		return v.DecodeData
+	case *Error:
+		return v.DecodeData
+	case *DomainSearchResults:
+		return v.DecodeData
+	case *EntitySearchResults:
+		return v.DecodeData
+	case *NameserverSearchResults:
+		return v.DecodeData
+	default:
+		// Extension object class?
+		if f := structField(obj, "DecodeData"); f.IsValid() {
+			if decodeData, ok := f.Interface().(*DecodeData); ok {
+				return decodeData
+			}
+		}
+
+		return nil
+	}
+}
+
+// structField returns the field |name| of the pointer to struct |obj|, or the
+// zero Value if there's none.
+func structField(obj RDAPObject, name string) reflect.Value {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+
+	return v.Elem().FieldByName(name)
+}
@@ -0,0 +1,176 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type testColour struct {
+	Colour string
+}
+
+func init() {
+	RegisterExtension(Extension{
+		Name:        "test_colour",
+		Conformance: "test_colour_level_0",
+		Members:     []string{"test_colour"},
+		Decode: func(src map[string]interface{}) (interface{}, error) {
+			v, ok := src["test_colour"]
+			if !ok {
+				return nil, nil
+			}
+
+			s, ok := v.(string)
+			if !ok {
+				return nil, errors.New("test_colour is not a string")
+			}
+
+			return &testColour{Colour: s}, nil
+		},
+		Validate: func(obj RDAPObject) []string {
+			d := obj.(*Domain)
+			if c, ok := d.DecodeData.Extension("test_colour").(*testColour); !ok || c.Colour == "" {
+				return []string{"missing test_colour"}
+			}
+
+			return nil
+		},
+		Print: func(value interface{}, p *ExtensionPrinter) {
+			p.Heading("Test Colour").Value("Colour", value.(*testColour).Colour)
+		},
+	})
+}
+
+func TestExtensionDecode(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.cz",
+		"test_colour": "green",
+		"entities": [{"objectClassName": "entity", "test_colour": 5}]
+	}`)).Decode()
+
+	if err != nil {
+		t.Fatalf("Decode failed: %s", err)
+	}
+
+	d := obj.(*Domain)
+
+	if c := d.DecodeData.Extension("test_colour"); !reflect.DeepEqual(c, &testColour{Colour: "green"}) {
+		t.Errorf("Bad extension value %v", c)
+	}
+
+	if unknown := d.DecodeData.UnknownFields(); len(unknown) != 0 {
+		t.Errorf("Unexpected unknown fields %v", unknown)
+	}
+
+	if notes := d.Entities[0].DecodeData.Notes("test_colour"); len(notes) != 1 {
+		t.Errorf("Expected decode note, got %v", notes)
+	}
+
+	if problems := ValidateExtensions(d); len(problems) != 0 {
+		t.Errorf("Unexpected problems %v", problems)
+	}
+
+	buf := &bytes.Buffer{}
+	printer := &Printer{Writer: buf}
+	printer.Print(d)
+
+	if !strings.Contains(buf.String(), "  Test Colour:\n    Colour: green\n") {
+		t.Errorf("Extension not printed:\n%s", buf.String())
+	}
+}
+
+func TestExtensionMemberPrefix(t *testing.T) {
+	RegisterExtension(Extension{
+		Name:         "test_shape",
+		MemberPrefix: "test_shape_",
+		Decode: func(src map[string]interface{}) (interface{}, error) {
+			var names []string
+			for name := range src {
+				if strings.HasPrefix(name, "test_shape_") {
+					names = append(names, name)
+				}
+			}
+
+			if names == nil {
+				return nil, nil
+			}
+
+			sort.Strings(names)
+			return names, nil
+		},
+	})
+	defer func() {
+		extensionsMu.Lock()
+		delete(extensions, "test_shape")
+		extensionsMu.Unlock()
+	}()
+
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.cz",
+		"test_shape_sides": 3,
+		"test_shape_colour": "red",
+		"test_shapes": 1
+	}`)).Decode()
+
+	if err != nil {
+		t.Fatalf("Decode failed: %s", err)
+	}
+
+	d := obj.(*Domain)
+
+	if names := d.DecodeData.Extension("test_shape"); !reflect.DeepEqual(names, []string{"test_shape_colour", "test_shape_sides"}) {
+		t.Errorf("Bad extension value %v", names)
+	}
+
+	if unknown := d.DecodeData.UnknownFields(); !reflect.DeepEqual(unknown, []string{"test_shapes"}) {
+		t.Errorf("Unexpected unknown fields %v", unknown)
+	}
+}
+
+func TestExtensionValidate(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"rdapConformance": ["rdap_level_0", "test_colour_level_0"],
+		"ldhName": "example.cz"
+	}`)).Decode()
+
+	if err != nil {
+		t.Fatalf("Decode failed: %s", err)
+	}
+
+	problems := ValidateExtensions(obj)
+	if !reflect.DeepEqual(problems, []string{"test_colour: missing test_colour"}) {
+		t.Errorf("Unexpected problems %v", problems)
+	}
+
+	found := false
+	for _, ext := range RegisteredExtensions() {
+		if ext.Name == "test_colour" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("test_colour missing from RegisteredExtensions()")
+	}
+}
+
+func TestExtensionRegisterDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Duplicate RegisterExtension() did not panic")
+		}
+	}()
+
+	RegisterExtension(Extension{Name: "test_colour"})
+}
@@ -0,0 +1,398 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FlattenColumns lists the columns supported by Flatten() and TableWriter, in
+// their default order.
+//
+// Columns not applicable to an object (e.g. "nameservers" for an IP network)
+// are empty. List values (e.g. "status") are separated by "; ".
+var FlattenColumns = []string{
+	"objectClassName",
+	"handle",
+	"name",
+	"unicodeName",
+	"status",
+	"registration",
+	"lastChanged",
+	"expiration",
+	"registrar",
+	"registrarIANAID",
+	"registrantName",
+	"registrantOrg",
+	"registrantEmail",
+	"registrantCountry",
+	"abuseEmail",
+	"nameservers",
+	"delegationSigned",
+	"ipVersion",
+	"startAddress",
+	"endAddress",
+	"cidrs",
+	"startAutnum",
+	"endAutnum",
+	"type",
+	"country",
+	"parentHandle",
+	"roles",
+	"email",
+	"tel",
+	"port43",
+}
+
+// flattenColumns maps each supported column to a function returning its value
+// for an RDAP object.
+var flattenColumns = map[string]func(obj RDAPObject) string{
+	"objectClassName": func(obj RDAPObject) string { return stringField(obj, "ObjectClassName") },
+	"handle":          func(obj RDAPObject) string { return stringField(obj, "Handle") },
+	"name":            flattenName,
+	"unicodeName":     func(obj RDAPObject) string { return stringField(obj, "UnicodeName") },
+	"status":          func(obj RDAPObject) string { return flattenList(stringsField(obj, "Status")) },
+	"registration":    func(obj RDAPObject) string { return flattenEvent(obj, EventRegistration) },
+	"lastChanged":     func(obj RDAPObject) string { return flattenEvent(obj, EventLastChanged) },
+	"expiration":      func(obj RDAPObject) string { return flattenEvent(obj, EventExpiration) },
+	"registrar": func(obj RDAPObject) string {
+		if c := flattenContact(obj, RoleRegistrar); c != nil {
+			return c.Name
+		}
+
+		return ""
+	},
+	"registrarIANAID": func(obj RDAPObject) string {
+		if e := findEntityByRole(RoleRegistrar, entitiesOf(obj)); e != nil {
+			if id, ok := e.IANARegistrarID(); ok {
+				return strconv.Itoa(id)
+			}
+		}
+
+		return ""
+	},
+	"registrantName": func(obj RDAPObject) string {
+		if c := flattenContact(obj, RoleRegistrant); c != nil {
+			return c.Name
+		}
+
+		return ""
+	},
+	"registrantOrg": func(obj RDAPObject) string {
+		if c := flattenContact(obj, RoleRegistrant); c != nil {
+			return c.Org
+		}
+
+		return ""
+	},
+	"registrantEmail": func(obj RDAPObject) string {
+		if c := flattenContact(obj, RoleRegistrant); c != nil {
+			return c.Email
+		}
+
+		return ""
+	},
+	"registrantCountry": func(obj RDAPObject) string {
+		if c := flattenContact(obj, RoleRegistrant); c != nil {
+			return c.Country
+		}
+
+		return ""
+	},
+	"abuseEmail": func(obj RDAPObject) string {
+		var c *Contact
+		if d, ok := obj.(*Domain); ok {
+			c = d.AbuseContact()
+		} else {
+			c = flattenContact(obj, RoleAbuse)
+		}
+
+		if c != nil {
+			return c.Email
+		}
+
+		return ""
+	},
+	"nameservers": func(obj RDAPObject) string {
+		d, ok := obj.(*Domain)
+		if !ok {
+			return ""
+		}
+
+		var names []string
+		for _, n := range d.Nameservers {
+			names = append(names, n.LDHName)
+		}
+
+		return flattenList(names)
+	},
+	"delegationSigned": func(obj RDAPObject) string {
+		if d, ok := obj.(*Domain); ok && d.SecureDNS != nil && d.SecureDNS.DelegationSigned != nil {
+			return strconv.FormatBool(*d.SecureDNS.DelegationSigned)
+		}
+
+		return ""
+	},
+	"ipVersion":    func(obj RDAPObject) string { return stringField(obj, "IPVersion") },
+	"startAddress": func(obj RDAPObject) string { return stringField(obj, "StartAddress") },
+	"endAddress":   func(obj RDAPObject) string { return stringField(obj, "EndAddress") },
+	"cidrs": func(obj RDAPObject) string {
+		n, ok := obj.(*IPNetwork)
+		if !ok {
+			return ""
+		}
+
+		prefixes := n.CIDRs()
+		if prefixes == nil {
+			prefixes, _ = n.Prefixes()
+		}
+
+		var cidrs []string
+		for _, p := range prefixes {
+			cidrs = append(cidrs, p.String())
+		}
+
+		return flattenList(cidrs)
+	},
+	"startAutnum": func(obj RDAPObject) string {
+		if a, ok := obj.(*Autnum); ok {
+			if start, _, err := a.Range(); err == nil {
+				return strconv.FormatUint(uint64(start), 10)
+			}
+		}
+
+		return ""
+	},
+	"endAutnum": func(obj RDAPObject) string {
+		if a, ok := obj.(*Autnum); ok {
+			if _, end, err := a.Range(); err == nil {
+				return strconv.FormatUint(uint64(end), 10)
+			}
+		}
+
+		return ""
+	},
+	"type":         func(obj RDAPObject) string { return stringField(obj, "Type") },
+	"country":      func(obj RDAPObject) string { return stringField(obj, "Country") },
+	"parentHandle": func(obj RDAPObject) string { return stringField(obj, "ParentHandle") },
+	"roles":        func(obj RDAPObject) string { return flattenList(stringsField(obj, "Roles")) },
+	"email": func(obj RDAPObject) string {
+		if e, ok := obj.(*Entity); ok {
+			return NewContact(e).Email
+		}
+
+		return ""
+	},
+	"tel": func(obj RDAPObject) string {
+		if e, ok := obj.(*Entity); ok {
+			return NewContact(e).Tel
+		}
+
+		return ""
+	},
+	"port43": port43Of,
+}
+
+// Flatten converts the RDAP object |obj| into table rows, one value per column
+// in |columns| (see FlattenColumns). All supported columns are used if
+// |columns| is empty.
+//
+// Domains, entities, nameservers, IP networks, and autnums are one row each.
+// Search results are one row per result. Other objects (e.g. *Help) have no
+// rows.
+//
+// Returns an error if a column isn't supported.
+func Flatten(obj RDAPObject, columns []string) ([][]string, error) {
+	if len(columns) == 0 {
+		columns = FlattenColumns
+	}
+
+	fns := make([]func(obj RDAPObject) string, len(columns))
+	for i, column := range columns {
+		fn, ok := flattenColumns[column]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+
+		fns[i] = fn
+	}
+
+	var objects []RDAPObject
+	switch v := obj.(type) {
+	case *Domain, *Entity, *Nameserver, *IPNetwork, *Autnum:
+		objects = []RDAPObject{v}
+	case *DomainSearchResults:
+		for i := range v.Domains {
+			objects = append(objects, &v.Domains[i])
+		}
+	case *NameserverSearchResults:
+		for i := range v.Nameservers {
+			objects = append(objects, &v.Nameservers[i])
+		}
+	case *EntitySearchResults:
+		for i := range v.Entities {
+			objects = append(objects, &v.Entities[i])
+		}
+	}
+
+	var rows [][]string
+	for _, o := range objects {
+		row := make([]string, len(fns))
+		for i, fn := range fns {
+			row[i] = fn(o)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// TableWriter writes RDAP objects as CSV or TSV rows, e.g. for loading bulk
+// lookup results into a spreadsheet. See Flatten().
+//
+// A header row of column names is written before the first object.
+type TableWriter struct {
+	// Output io.Writer.
+	//
+	// Defaults to os.Stdout.
+	Writer io.Writer
+
+	// Columns to write, see FlattenColumns.
+	//
+	// Defaults to all supported columns.
+	Columns []string
+
+	// Field delimiter, e.g. '\t' for TSV.
+	//
+	// Defaults to ',' (CSV).
+	Comma rune
+
+	// OmitHeader prevents the header row from being written.
+	OmitHeader bool
+
+	csv *csv.Writer
+}
+
+// Write writes the rows for the RDAP object |obj|.
+func (t *TableWriter) Write(obj RDAPObject) error {
+	if len(t.Columns) == 0 {
+		t.Columns = FlattenColumns
+	}
+
+	rows, err := Flatten(obj, t.Columns)
+	if err != nil {
+		return err
+	}
+
+	if t.csv == nil {
+		if t.Writer == nil {
+			t.Writer = os.Stdout
+		}
+
+		t.csv = csv.NewWriter(t.Writer)
+		if t.Comma != 0 {
+			t.csv.Comma = t.Comma
+		}
+
+		if !t.OmitHeader {
+			t.csv.Write(t.Columns)
+		}
+	}
+
+	for _, row := range rows {
+		t.csv.Write(row)
+	}
+
+	t.csv.Flush()
+
+	return t.csv.Error()
+}
+
+// flattenName returns the object's name: the domain or host name of a domain
+// or nameserver, the contact name of an entity, or the name of an IP network
+// or autnum.
+func flattenName(obj RDAPObject) string {
+	switch v := obj.(type) {
+	case *Domain:
+		return v.LDHName
+	case *Nameserver:
+		return v.LDHName
+	case *Entity:
+		return NewContact(v).Name
+	default:
+		return stringField(obj, "Name")
+	}
+}
+
+// flattenEvent returns the date of the RDAP object's own latest event with the
+// action |action|.
+func flattenEvent(obj RDAPObject, action string) string {
+	f := structField(obj, "Events")
+	if !f.IsValid() {
+		return ""
+	}
+
+	events, _ := f.Interface().([]Event)
+
+	var latest *Event
+	var latestTime time.Time
+	for i := range events {
+		if events[i].Action != action {
+			continue
+		}
+
+		t, err := events[i].Time()
+		if latest == nil || (err == nil && t.After(latestTime)) {
+			latest = &events[i]
+			latestTime = t
+		}
+	}
+
+	if latest == nil {
+		return ""
+	}
+
+	return latest.Date
+}
+
+// flattenContact returns the contact with the role |role| in the RDAP object.
+func flattenContact(obj RDAPObject, role string) *Contact {
+	if _, ok := obj.(*Entity); ok {
+		return nil
+	}
+
+	return NewContact(findEntityByRole(role, entitiesOf(obj)))
+}
+
+func flattenList(values []string) string {
+	return strings.Join(values, "; ")
+}
+
+// stringField returns the string field |name| of the RDAP object, or "".
+func stringField(obj RDAPObject, name string) string {
+	if f := structField(obj, name); f.IsValid() && f.Kind() == reflect.String {
+		return f.String()
+	}
+
+	return ""
+}
+
+// stringsField returns the []string field |name| of the RDAP object, or nil.
+func stringsField(obj RDAPObject, name string) []string {
+	if f := structField(obj, name); f.IsValid() {
+		values, _ := f.Interface().([]string)
+		return values
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestFlatten(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.registry.example/domain-example.example.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := Flatten(obj, []string{"name", "status", "expiration", "registrar", "registrarIANAID", "abuseEmail", "nameservers", "cidrs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{
+			"example.example",
+			"client transfer prohibited; active",
+			"2031-02-03T04:05:06Z",
+			"Example Registrar, Inc.",
+			"9999",
+			"abuse@registrar.example",
+			"ns1.example.example; ns2.example.net",
+			"",
+		},
+	}
+
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Unexpected rows %q", rows)
+	}
+
+	if _, err := Flatten(obj, []string{"name", "colour"}); err == nil {
+		t.Errorf("Unexpected success with unknown column")
+	}
+}
+
+func TestFlattenSearchResults(t *testing.T) {
+	results := &DomainSearchResults{
+		Domains: []Domain{
+			{LDHName: "a.example"},
+			{LDHName: "b.example"},
+		},
+	}
+
+	rows, err := Flatten(results, []string{"objectClassName", "name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(rows, [][]string{{"", "a.example"}, {"", "b.example"}}) {
+		t.Errorf("Unexpected rows %q", rows)
+	}
+
+	if rows, _ := Flatten(&Help{}, nil); rows != nil {
+		t.Errorf("Unexpected rows %q for help", rows)
+	}
+}
+
+func TestTableWriter(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.arin.net/ip-192.0.2.1.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	w := &TableWriter{
+		Writer:  buf,
+		Columns: []string{"handle", "startAddress", "endAddress", "cidrs", "registration"},
+		Comma:   '\t',
+	}
+
+	if err := w.Write(obj); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Write(obj); err != nil {
+		t.Fatal(err)
+	}
+
+	row := "NET-192-0-2-0-1\t192.0.2.0\t192.0.3.255\t192.0.2.0/24; 192.0.3.0/24\t2010-09-23T14:24:33-04:00\n"
+	expected := "handle\tstartAddress\tendAddress\tcidrs\tregistration\n" + row + row
+
+	if buf.String() != expected {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+
+	w = &TableWriter{Writer: buf, Columns: []string{"colour"}}
+	buf.Reset()
+	if err := w.Write(obj); err == nil || buf.Len() != 0 {
+		t.Errorf("Unexpected success with unknown column, output %q", buf.String())
+	}
+}
@@ -0,0 +1,167 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FredConformance is the rdapConformance identifier of the FRED extension,
+// used by CZ.NIC's FRED registry software (e.g. for .cz).
+const FredConformance = "fred_version_0"
+
+// FredNSSet is a FRED nameserver set: a named group of nameservers, shared by
+// domains. It appears as the "fred_nsset" member of domains, and is a topmost
+// RDAP response object for nsset queries (e.g.
+// https://rdap.nic.cz/fred_nsset/NSS:EXAMPLE:1).
+type FredNSSet struct {
+	DecodeData *DecodeData
+
+	Common
+	Conformance     []string `rdap:"rdapConformance"`
+	ObjectClassName string
+	Notices         []Notice
+
+	Handle      string
+	Nameservers []Nameserver
+	Entities    []Entity
+	Status      []string
+	Remarks     []Remark
+	Links       []Link
+	Events      []Event
+}
+
+// FredKeySet is a FRED keyset: a named group of DNSSEC keys, shared by
+// domains. It appears as the "fred_keyset" member of domains, and is a topmost
+// RDAP response object for keyset queries (e.g.
+// https://rdap.nic.cz/fred_keyset/KEYSID:EXAMPLE).
+type FredKeySet struct {
+	DecodeData *DecodeData
+
+	Common
+	Conformance     []string `rdap:"rdapConformance"`
+	ObjectClassName string
+	Notices         []Notice
+
+	Handle   string
+	DNSKeys  []FredDNSKey `rdap:"dns_keys"`
+	Entities []Entity
+	Status   []string
+	Remarks  []Remark
+	Links    []Link
+	Events   []Event
+}
+
+// FredDNSKey is a DNSSEC key in a FredKeySet.
+type FredDNSKey struct {
+	DecodeData *DecodeData
+
+	Flags     uint16
+	Protocol  uint8
+	Algorithm uint8  `rdap:"alg"`
+	PublicKey string `rdap:"public_key"`
+}
+
+// FredDomain is the FRED extension data of a Domain. See Domain.Fred().
+type FredDomain struct {
+	// Nameserver set, or nil if none.
+	NSSet *FredNSSet
+
+	// DNSSEC keyset, or nil if none.
+	KeySet *FredKeySet
+}
+
+func init() {
+	RegisterExtension(Extension{
+		Name:         "fred",
+		Conformance:  FredConformance,
+		Members:      []string{"fred_nsset", "fred_keyset"},
+		MemberPrefix: "fred_",
+		ObjectClasses: map[string]func() RDAPObject{
+			"fred_nsset":  func() RDAPObject { return &FredNSSet{} },
+			"fred_keyset": func() RDAPObject { return &FredKeySet{} },
+		},
+		Decode: decodeFred,
+		Print:  printFred,
+	})
+}
+
+// Fred returns the domain's FRED extension data (its nameserver set and
+// keyset), or nil if it has none.
+func (d *Domain) Fred() *FredDomain {
+	if d.DecodeData == nil {
+		return nil
+	}
+
+	fred, _ := d.DecodeData.Extension("fred").(*FredDomain)
+
+	return fred
+}
+
+func decodeFred(src map[string]interface{}) (interface{}, error) {
+	fred := &FredDomain{}
+
+	if v, ok := src["fred_nsset"]; ok {
+		fred.NSSet = &FredNSSet{}
+		if err := decodeObject(v, fred.NSSet); err != nil {
+			return nil, fmt.Errorf("invalid fred_nsset: %s", err)
+		}
+	}
+
+	if v, ok := src["fred_keyset"]; ok {
+		fred.KeySet = &FredKeySet{}
+		if err := decodeObject(v, fred.KeySet); err != nil {
+			return nil, fmt.Errorf("invalid fred_keyset: %s", err)
+		}
+	}
+
+	if fred.NSSet == nil && fred.KeySet == nil {
+		return nil, nil
+	}
+
+	return fred, nil
+}
+
+// decodeObject decodes the raw JSON object |src| into the RDAP struct pointed
+// to by |dst|, as the Decoder does.
+func decodeObject(src interface{}, dst interface{}) error {
+	if _, ok := src.(map[string]interface{}); !ok {
+		return fmt.Errorf("invalid JSON type, expecting object")
+	}
+
+	d := &Decoder{conformance: map[string]bool{}}
+	_, err := d.decode("", src, reflect.ValueOf(dst), nil)
+
+	return err
+}
+
+func printFred(value interface{}, p *ExtensionPrinter) {
+	fred := value.(*FredDomain)
+
+	if fred.NSSet != nil {
+		h := p.Heading("NSSet")
+		h.Value("Handle", fred.NSSet.Handle)
+
+		for _, ns := range fred.NSSet.Nameservers {
+			h.Value("Nameserver", ns.LDHName)
+		}
+	}
+
+	if fred.KeySet != nil {
+		h := p.Heading("KeySet")
+		h.Value("Handle", fred.KeySet.Handle)
+
+		for _, key := range fred.KeySet.DNSKeys {
+			h.Value("DNSKey", strings.Join([]string{
+				fmt.Sprintf("%d", key.Flags),
+				fmt.Sprintf("%d", key.Protocol),
+				fmt.Sprintf("%d", key.Algorithm),
+				key.PublicKey,
+			}, " "))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestFredDomain(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := obj.(*Domain)
+
+	fred := d.Fred()
+	if fred == nil || fred.NSSet == nil || fred.KeySet != nil {
+		t.Fatalf("Unexpected FRED data %+v", fred)
+	}
+
+	if fred.NSSet.Handle != "NSS:PIPNI:1" || len(fred.NSSet.Nameservers) != 3 || fred.NSSet.Nameservers[0].LDHName != "ns2.pipni.cz" {
+		t.Errorf("Unexpected nsset %+v", fred.NSSet)
+	}
+
+	if unknown := d.DecodeData.UnknownFields(); len(unknown) != 0 {
+		t.Errorf("Unexpected unknown fields %v", unknown)
+	}
+
+	buf := &bytes.Buffer{}
+	printer := &Printer{Writer: buf}
+	printer.Print(d)
+
+	if !strings.Contains(buf.String(), "NSSet:\n    Handle: NSS:PIPNI:1\n    Nameserver: ns2.pipni.cz\n") {
+		t.Errorf("NSSet not printed:\n%s", buf.String())
+	}
+}
+
+func TestFredKeySet(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"rdapConformance": ["rdap_level_0", "fred_version_0"],
+		"objectClassName": "fred_keyset",
+		"handle": "KEYSID:EXAMPLE",
+		"dns_keys": [
+			{"flags": 257, "protocol": 3, "alg": 13, "public_key": "AwEAAb=="}
+		],
+		"entities": [{"objectClassName": "entity", "handle": "TECH", "roles": ["technical"]}]
+	}`)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keySet, ok := obj.(*FredKeySet)
+	if !ok {
+		t.Fatalf("Unexpected result %T", obj)
+	}
+
+	if keySet.Handle != "KEYSID:EXAMPLE" || len(keySet.Entities) != 1 {
+		t.Errorf("Unexpected keyset %+v", keySet)
+	}
+
+	if len(keySet.DNSKeys) != 1 || keySet.DNSKeys[0] != (FredDNSKey{DecodeData: keySet.DNSKeys[0].DecodeData, Flags: 257, Protocol: 3, Algorithm: 13, PublicKey: "AwEAAb=="}) {
+		t.Errorf("Unexpected DNS keys %+v", keySet.DNSKeys)
+	}
+
+	if decodeDataOf(keySet) != keySet.DecodeData || len(conformanceOf(keySet)) != 2 {
+		t.Errorf("Extension object class not supported by decodeDataOf()/conformanceOf()")
+	}
+}
@@ -0,0 +1,186 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// GeofeedConformance is the rdapConformance identifier of the RDAP geofeed
+// extension (RFC 9632 section 4), which links IP networks to their geofeed
+// files.
+const GeofeedConformance = "geofeed1"
+
+const (
+	// LinkRelGeo is the link relation type of geofeed links.
+	LinkRelGeo = "geo"
+
+	// MediaTypeGeofeed is the media type of geofeed files (RFC 9632).
+	MediaTypeGeofeed = "application/geofeed+csv"
+)
+
+// GeofeedEntry is a row of a geofeed file (RFC 8805 section 2.1).
+type GeofeedEntry struct {
+	Prefix netip.Prefix
+
+	// ISO 3166-1 alpha-2 country code, e.g. "AU".
+	Country string
+
+	// ISO 3166-2 region code, e.g. "AU-QLD".
+	Region string
+
+	City string
+
+	// Deprecated by RFC 8805, but present in some geofeeds.
+	PostalCode string
+}
+
+// GeofeedURL returns the URL of the IP network's geofeed file, from its "geo"
+// link (RFC 9632 section 4), or "" if there's none.
+func (n *IPNetwork) GeofeedURL() string {
+	for i := range n.Links {
+		l := &n.Links[i]
+
+		if l.HasRel(LinkRelGeo) && l.Href != "" && (l.Type == "" || l.HasType(MediaTypeGeofeed)) {
+			return l.Href
+		}
+	}
+
+	return ""
+}
+
+// ParseGeofeed parses the geofeed file |r| (RFC 8805), e.g.:
+//
+//	# Comment.
+//	192.0.2.0/24,AU,AU-QLD,Brisbane,
+//	2001:db8::/32,NZ,,,
+//
+// Comments (including RFC 9632 signatures) and blank lines are skipped.
+// Returns an error for rows without a valid IP prefix, and the rows parsed
+// before it.
+func ParseGeofeed(r io.Reader) ([]GeofeedEntry, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var entries []GeofeedEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return entries, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(record[0]))
+		if err != nil {
+			return entries, fmt.Errorf("geofeed line %d: invalid IP prefix %q", line, record[0])
+		}
+
+		entry := GeofeedEntry{
+			Prefix: prefix.Masked(),
+		}
+
+		fields := []*string{&entry.Country, &entry.Region, &entry.City, &entry.PostalCode}
+		for i, field := range fields {
+			if i+1 < len(record) {
+				*field = strings.TrimSpace(record[i+1])
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// MaxGeofeedSize is the maximum size of geofeed file FetchGeofeed() reads, in
+// bytes.
+const MaxGeofeedSize = 64 << 20
+
+// FetchGeofeed downloads and parses the geofeed file of the IP network |n|.
+// See IPNetwork.GeofeedURL() and ParseGeofeed().
+//
+// The request is made like RDAP queries are: with the Client's HTTP client,
+// Transport, Proxies, ClientCertificates, TransportOptions, Middleware,
+// RedirectPolicy, RateLimiter and Politeness policy. Geofeeds are never
+// fetched in Offline mode.
+//
+// Returns an InputError ClientError if the IP network has no geofeed link, a
+// CacheMissError in Offline mode, and an error if the file is larger than
+// MaxGeofeedSize.
+func (c *Client) FetchGeofeed(ctx context.Context, n *IPNetwork) ([]GeofeedEntry, error) {
+	c.init()
+
+	geofeedURL := n.GeofeedURL()
+	if geofeedURL == "" {
+		return nil, &ClientError{
+			Type: InputError,
+			Text: "IP network has no geofeed link",
+		}
+	}
+
+	u, err := url.Parse(geofeedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Offline {
+		return nil, &CacheMissError{Key: geofeedURL}
+	}
+
+	rdapReq := NewRawRequest(u).WithContext(ctx)
+
+	var politeness *Politeness
+	if c.Politeness != nil {
+		policy := c.Politeness.For(u.Hostname())
+		politeness = &policy
+	}
+
+	if err := c.waitForRateLimit(ctx, rdapReq, politeness); err != nil {
+		return nil, err
+	}
+
+	c.Verbose(fmt.Sprintf("client: Fetching geofeed %s", geofeedURL))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", geofeedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.UserAgent != "" {
+		req.Header.Add("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.httpClientFor(rdapReq, &HTTPResponse{URL: geofeedURL}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geofeed fetch failed: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxGeofeedSize+1))
+	if err != nil {
+		return nil, err
+	} else if len(body) > MaxGeofeedSize {
+		return nil, fmt.Errorf("geofeed %s larger than %d bytes", geofeedURL, MaxGeofeedSize)
+	}
+
+	return ParseGeofeed(bytes.NewReader(body))
+}
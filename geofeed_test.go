@@ -0,0 +1,132 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testGeofeed = `# Example geofeed.
+192.0.2.0/24,AU,AU-QLD,Brisbane,
+2001:db8::/32,NZ
+
+# RPKI Signature: 192.0.2.0/24
+# MIIGlwYJKoZIhvcNAQcCoIIGiDCCBoQCAQMxDTALBglghkgBZQMEAgEwggMBBgsq
+# End Signature: 192.0.2.0/24
+`
+
+func TestParseGeofeed(t *testing.T) {
+	entries, err := ParseGeofeed(strings.NewReader(testGeofeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []GeofeedEntry{
+		{Prefix: netip.MustParsePrefix("192.0.2.0/24"), Country: "AU", Region: "AU-QLD", City: "Brisbane"},
+		{Prefix: netip.MustParsePrefix("2001:db8::/32"), Country: "NZ"},
+	}
+
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Unexpected entries %+v", entries)
+	}
+
+	entries, err = ParseGeofeed(strings.NewReader("192.0.2.0/24,AU\nexample,AU\n"))
+	if err == nil || err.Error() != `geofeed line 2: invalid IP prefix "example"` || len(entries) != 1 {
+		t.Errorf("Unexpected result %v, error %v", entries, err)
+	}
+}
+
+func TestClientFetchGeofeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", MediaTypeGeofeed)
+		w.Write([]byte(testGeofeed))
+	}))
+	defer server.Close()
+
+	obj, err := NewDecoder([]byte(fmt.Sprintf(`{
+		"objectClassName": "ip network",
+		"rdapConformance": ["rdap_level_0", "geofeed1"],
+		"startAddress": "192.0.2.0",
+		"endAddress": "192.0.2.255",
+		"links": [
+			{"rel": "self", "href": "https://rdap.example/ip/192.0.2.0", "type": "application/rdap+json"},
+			{"rel": "geo", "href": "%s/geofeed.csv", "type": "application/geofeed+csv"}
+		]
+	}`, server.URL))).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := obj.(*IPNetwork)
+	if !HasExtension(n, GeofeedConformance) || n.GeofeedURL() != server.URL+"/geofeed.csv" {
+		t.Errorf("Unexpected geofeed URL %q", n.GeofeedURL())
+	}
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	entries, err := client.FetchGeofeed(context.Background(), n)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(entries) != 2 || entries[0].City != "Brisbane" {
+		t.Errorf("Unexpected entries %+v", entries)
+	}
+
+	if _, err := client.FetchGeofeed(context.Background(), &IPNetwork{}); err == nil {
+		t.Errorf("Unexpected success without a geofeed link")
+	}
+}
+
+func TestClientFetchGeofeedClientOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/large.csv" {
+			w.Write([]byte(strings.Repeat("# Comment.\n", MaxGeofeedSize/10)))
+			return
+		}
+
+		w.Write([]byte(testGeofeed))
+	}))
+	defer server.Close()
+
+	n := &IPNetwork{
+		Links: []Link{{Rel: "geo", Href: server.URL + "/geofeed.csv"}},
+	}
+
+	var requests int
+	client := &Client{
+		Verbose: verboseFunc(),
+		Middleware: []Middleware{func(next Handler) Handler {
+			return func(req *http.Request) (*http.Response, error) {
+				requests++
+				return next(req)
+			}
+		}},
+	}
+
+	if _, err := client.FetchGeofeed(context.Background(), n); err != nil || requests != 1 {
+		t.Errorf("Unexpected error %v, %d requests through the Middleware", err, requests)
+	}
+
+	n.Links[0].Href = server.URL + "/large.csv"
+	if _, err := client.FetchGeofeed(context.Background(), n); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("Unexpected error %v for a geofeed over MaxGeofeedSize", err)
+	}
+
+	client.Offline = true
+	if _, err := client.FetchGeofeed(context.Background(), n); !errors.Is(err, ErrCacheMiss) || requests != 2 {
+		t.Errorf("Unexpected error %v in Offline mode", err)
+	}
+}
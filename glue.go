@@ -0,0 +1,79 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Addrs returns the IPv4 then IPv6 addresses of the set.
+//
+// Invalid addresses (including IPv6 addresses in the v4 list, and vice versa)
+// are skipped, and returned as an error.
+func (s *IPAddressSet) Addrs() ([]netip.Addr, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	var addrs []netip.Addr
+	var errs []error
+
+	for _, list := range []struct {
+		name   string
+		values []string
+		is4    bool
+	}{
+		{"v4", s.V4, true},
+		{"v6", s.V6, false},
+	} {
+		for i, value := range list.values {
+			addr, err := netip.ParseAddr(value)
+			if err != nil || addr.Zone() != "" {
+				errs = append(errs, fmt.Errorf("ipAddresses.%s[%d]: invalid IP address %q", list.name, i, value))
+			} else if addr.Is4() != list.is4 {
+				errs = append(errs, fmt.Errorf("ipAddresses.%s[%d]: %s is not an IP%s address", list.name, i, value, list.name))
+			} else {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+
+	return addrs, errors.Join(errs...)
+}
+
+// Addrs returns the nameserver's IP addresses. See IPAddressSet.Addrs().
+func (n *Nameserver) Addrs() ([]netip.Addr, error) {
+	return n.IPAddresses.Addrs()
+}
+
+// GlueAddrs returns the IP addresses of the domain's nameservers, keyed by
+// lowercase nameserver name (without a trailing dot). Nameservers without IP
+// addresses are omitted.
+//
+// Invalid addresses are skipped, and returned as an error.
+func (d *Domain) GlueAddrs() (map[string][]netip.Addr, error) {
+	result := map[string][]netip.Addr{}
+	var errs []error
+
+	for _, ns := range d.Nameservers {
+		name := strings.ToLower(strings.TrimSuffix(ns.LDHName, "."))
+
+		addrs, err := ns.Addrs()
+		if err != nil {
+			for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+				errs = append(errs, fmt.Errorf("nameserver %s: %w", name, e))
+			}
+		}
+
+		if len(addrs) > 0 {
+			result[name] = append(result[name], addrs...)
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
@@ -0,0 +1,47 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDomainGlueAddrs(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.cz",
+		"nameservers": [
+			{"objectClassName": "nameserver", "ldhName": "NS1.example.cz.", "ipAddresses": {"v4": ["192.0.2.53"], "v6": ["2001:db8::53"]}},
+			{"objectClassName": "nameserver", "ldhName": "ns2.example.cz", "ipAddresses": {"v4": ["2001:db8::54", "192.0.2.54", "192.0.2.300"]}},
+			{"objectClassName": "nameserver", "ldhName": "ns.example.net"}
+		]
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := obj.(*Domain)
+
+	addrs, err := d.Nameservers[0].Addrs()
+	if err != nil || fmt.Sprint(addrs) != "[192.0.2.53 2001:db8::53]" {
+		t.Errorf("Unexpected addresses %v, %v", addrs, err)
+	}
+
+	if addrs, err := d.Nameservers[2].Addrs(); addrs != nil || err != nil {
+		t.Errorf("Unexpected addresses %v, %v", addrs, err)
+	}
+
+	glue, err := d.GlueAddrs()
+	if fmt.Sprint(glue) != "map[ns1.example.cz:[192.0.2.53 2001:db8::53] ns2.example.cz:[192.0.2.54]]" {
+		t.Errorf("Unexpected glue %v", glue)
+	}
+
+	expected := "nameserver ns2.example.cz: ipAddresses.v4[0]: 2001:db8::54 is not an IPv4 address\n" +
+		"nameserver ns2.example.cz: ipAddresses.v4[2]: invalid IP address \"192.0.2.300\""
+	if err == nil || err.Error() != expected {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -1,16 +1,13 @@
 module github.com/openrdap/rdap
 
-go 1.19
+go 1.21
 
 require (
-	github.com/alecthomas/kingpin/v2 v2.3.2
 	github.com/davecgh/go-spew v1.1.1
 	github.com/jarcoal/httpmock v1.3.0
-	github.com/mitchellh/go-homedir v1.1.0
-	golang.org/x/crypto v0.17.0
 )
 
 require (
-	github.com/alecthomas/units v0.0.0-20211218093645-b94a6e3cc137 // indirect
-	github.com/xhit/go-str2duration/v2 v2.1.0 // indirect
+	golang.org/x/net v0.30.0
+	golang.org/x/text v0.19.0 // indirect
 )
@@ -0,0 +1,138 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import "strings"
+
+// rdapConformance identifiers of the ICANN gTLD RDAP profile.
+const (
+	GTLDResponseProfileConformance = "icann_rdap_response_profile_1"
+	GTLDTechnicalGuideConformance  = "icann_rdap_technical_implementation_guide_1"
+)
+
+// ValidateGTLDProfile checks the domain response |obj| against the ICANN gTLD
+// RDAP Response Profile (February 2024), returning the violations found (nil
+// if none). Each violation's Section is the profile's section number:
+//   - "conformance": the profile's rdapConformance identifiers.
+//   - 2.1, 2.2: the domain name and handle.
+//   - 2.3.1.1-2.3.1.3: registration, expiration, and "last update of RDAP
+//     database" events.
+//   - 2.4.1, 2.4.2: a registrar entity, with a name and IANA Registrar ID.
+//   - 2.4.5: the registrar's abuse contact, with an email address and
+//     telephone number.
+//   - 2.6.1: status values must be RDAP status values (mapped from EPP
+//     statuses, RFC 8056).
+//   - 2.6.3: a notice linking to the status codes explanation
+//     (https://icann.org/epp).
+//   - 2.9.1, 2.9.2: nameservers must have names, and nameservers within the
+//     domain (EPP hosts with glue) must have IP addresses.
+//   - 2.10: a notice linking to the RDDS Inaccuracy Complaint Form
+//     (https://icann.org/wicf).
+//
+// Other objects aren't covered, and fail with a violation.
+func ValidateGTLDProfile(obj RDAPObject) []ProfileViolation {
+	var v profileViolations
+
+	d, ok := obj.(*Domain)
+	if !ok {
+		v.add("object", "%T responses are not covered by this validator", obj)
+		return v
+	}
+
+	conformance := map[string]bool{}
+	for _, c := range d.Conformance {
+		conformance[c] = true
+	}
+
+	for _, c := range []string{RDAPLevel0Conformance, GTLDResponseProfileConformance, GTLDTechnicalGuideConformance} {
+		if !conformance[c] {
+			v.add("conformance", "rdapConformance missing %s", c)
+		}
+	}
+
+	if d.LDHName == "" {
+		v.add("2.1", "missing ldhName")
+	}
+
+	if d.Handle == "" {
+		v.add("2.2", "missing handle")
+	}
+
+	for _, e := range []struct {
+		section string
+		action  string
+	}{
+		{"2.3.1.1", EventRegistration},
+		{"2.3.1.2", EventExpiration},
+		{"2.3.1.3", EventLastUpdateOfRDAPDatabase},
+	} {
+		if _, ok := eventTime(d.Events, e.action); !ok {
+			v.add(e.section, "missing or invalid %s event", e.action)
+		}
+	}
+
+	registrar := findEntityByRole(RoleRegistrar, d.Entities)
+	if registrar == nil {
+		v.add("2.4.1", "missing registrar entity")
+	} else {
+		if card := registrar.ContactVCard(); card == nil || card.Name() == "" {
+			v.add("2.4.1", "registrar entity has no name (fn)")
+		}
+
+		if _, ok := registrar.IANARegistrarID(); !ok {
+			v.add("2.4.2", "registrar entity has no (numeric) IANA Registrar ID public ID")
+		}
+
+		abuse := findEntityByRole(RoleAbuse, registrar.Entities)
+		if abuse == nil {
+			v.add("2.4.5", "registrar entity has no abuse contact entity")
+		} else if card := abuse.ContactVCard(); card == nil || card.Email() == "" || card.Tel() == "" {
+			v.add("2.4.5", "abuse contact has no email address or telephone number")
+		}
+	}
+
+	for _, s := range d.Status {
+		if !DefaultJSONValues().Has(JSONValueStatus, s) {
+			v.add("2.6.1", "status %q is not an RDAP status value", s)
+		}
+	}
+
+	if !hasNoticeLink(d.Notices, "https://icann.org/epp") {
+		v.add("2.6.3", "missing status codes notice linking to https://icann.org/epp")
+	}
+
+	for i, ns := range d.Nameservers {
+		if ns.LDHName == "" {
+			v.add("2.9.1", "nameserver %d has no ldhName", i)
+			continue
+		}
+
+		if d.LDHName != "" && strings.HasSuffix(strings.ToLower(ns.LDHName), "."+strings.ToLower(d.LDHName)) {
+			if ns.IPAddresses == nil || len(ns.IPAddresses.V4)+len(ns.IPAddresses.V6) == 0 {
+				v.add("2.9.2", "nameserver %s within the domain has no IP addresses", ns.LDHName)
+			}
+		}
+	}
+
+	if !hasNoticeLink(d.Notices, "https://icann.org/wicf") {
+		v.add("2.10", "missing RDDS Inaccuracy Complaint Form notice linking to https://icann.org/wicf")
+	}
+
+	return v
+}
+
+// hasNoticeLink returns true if any of |notices| links to |href| (ignoring
+// any trailing slash).
+func hasNoticeLink(notices []Notice, href string) bool {
+	for _, n := range notices {
+		for _, l := range n.Links {
+			if strings.TrimSuffix(l.Href, "/") == href {
+				return true
+			}
+		}
+	}
+
+	return false
+}
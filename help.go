@@ -4,6 +4,8 @@
 
 package rdap
 
+import "strings"
+
 // Help represents a help response.
 //
 // Help is a topmost RDAP response object.
@@ -14,3 +16,49 @@ type Help struct {
 	Conformance []string `rdap:"rdapConformance"`
 	Notices     []Notice
 }
+
+// TermsOfService returns the terms of service notices in the help response,
+// or nil if there are none. BaseURL and the timestamps are not set.
+func (h *Help) TermsOfService() *TermsOfService {
+	var notices []Notice
+	for _, n := range h.Notices {
+		if isTermsOfServiceNotice(n) {
+			notices = append(notices, n)
+		}
+	}
+
+	if len(notices) == 0 {
+		return nil
+	}
+
+	return &TermsOfService{
+		Notices: notices,
+	}
+}
+
+// RateLimitNotices returns the notices in the help response which describe
+// the server's rate limits (query limits), if any.
+func (h *Help) RateLimitNotices() []Notice {
+	var notices []Notice
+	for _, n := range h.Notices {
+		if isRateLimitNotice(n) {
+			notices = append(notices, n)
+		}
+	}
+
+	return notices
+}
+
+// isRateLimitNotice returns true if the Notice |n| looks like a description
+// of rate limits.
+func isRateLimitNotice(n Notice) bool {
+	text := strings.ToLower(n.Title + "\n" + strings.Join(n.Description, "\n"))
+
+	for _, keyword := range []string{"rate limit", "query limit", "queries per", "requests per", "too many"} {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,135 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"time"
+)
+
+// HistoryConformance is the rdapConformance identifier of the RDAP history
+// extension, which serves the prior versions of objects (e.g. by APNIC).
+const HistoryConformance = "history_version_0"
+
+// History represents a history extension response: the versions of an
+// object, see NewHistoryRequest().
+//
+// History is a topmost RDAP response object.
+type History struct {
+	DecodeData *DecodeData
+
+	Common
+	Conformance []string `rdap:"rdapConformance"`
+	Notices     []Notice
+
+	Records []HistoryRecord
+}
+
+// HistoryRecord is a version of an object, and the period it applied for.
+type HistoryRecord struct {
+	DecodeData *DecodeData
+
+	// RFC 3339 timestamps. ApplicableUntil is empty for the current version.
+	ApplicableFrom  string
+	ApplicableUntil string
+
+	// rdapConformance values of the response, set by the Decoder.
+	conformance []string
+}
+
+// From returns the time the version applied from.
+func (r *HistoryRecord) From() (time.Time, error) {
+	return time.Parse(time.RFC3339, r.ApplicableFrom)
+}
+
+// Until returns the time the version applied until, or the zero time (and no
+// error) for the current version.
+func (r *HistoryRecord) Until() (time.Time, error) {
+	if r.ApplicableUntil == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, r.ApplicableUntil)
+}
+
+// IsCurrent returns true if the record is the current version of the object.
+func (r *HistoryRecord) IsCurrent() bool {
+	return r.ApplicableUntil == ""
+}
+
+// Object returns the version of the object (e.g. a *Domain), decoded from the
+// record's content according to its objectClassName.
+//
+// Returns nil if there's no content, or its objectClassName isn't recognised.
+func (r *HistoryRecord) Object() RDAPObject {
+	if r.DecodeData == nil {
+		return nil
+	}
+
+	src, ok := r.DecodeData.Value("content").(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	return decodeObjectClass(src, r.conformance)
+}
+
+// At returns the record of the version which applied at the time |t|, or nil
+// if there's none.
+func (h *History) At(t time.Time) *HistoryRecord {
+	for i := range h.Records {
+		r := &h.Records[i]
+
+		from, err := r.From()
+		if err != nil || t.Before(from) {
+			continue
+		}
+
+		until, err := r.Until()
+		if err != nil || (!until.IsZero() && !t.Before(until)) {
+			continue
+		}
+
+		return r
+	}
+
+	return nil
+}
+
+// Objects returns the versions of the object, in the order of Records. Records
+// without decodable content are skipped. See HistoryRecord.Object().
+func (h *History) Objects() RDAPObjects {
+	var result RDAPObjects
+	for i := range h.Records {
+		if obj := h.Records[i].Object(); obj != nil {
+			result = append(result, obj)
+		}
+	}
+
+	return result
+}
+
+// QueryHistory makes an RDAP history extension request for the prior versions
+// of the object |query| of type |requestType| (e.g. DomainRequest, "example.cz").
+// The server is bootstrapped.
+//
+// The timeout is 30s, unless |ctx| has a deadline.
+func (c *Client) QueryHistory(ctx context.Context, requestType RequestType, query string) (*History, error) {
+	resp, err := c.doQuickRequest(ctx, NewHistoryRequest(requestType, query))
+	if err != nil {
+		return nil, err
+	}
+
+	if history, ok := resp.Object.(*History); ok {
+		return history, nil
+	} else if respError, ok := resp.Object.(*Error); ok {
+		return nil, clientErrorFromRDAPError(respError)
+	}
+
+	return nil, &ClientError{
+		Type: WrongResponseType,
+		Text: "The server returned a non-History RDAP response",
+	}
+}
@@ -0,0 +1,112 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const historyResponse = `{
+	"rdapConformance": ["history_version_0", "rdap_level_0"],
+	"records": [
+		{
+			"applicableFrom": "2020-01-01T00:00:00Z",
+			"applicableUntil": "2022-06-01T00:00:00Z",
+			"content": {
+				"objectClassName": "ip network",
+				"handle": "NET-192-0-2-0-1",
+				"startAddress": "192.0.2.0",
+				"endAddress": "192.0.2.255",
+				"name": "OLD-NET"
+			}
+		},
+		{
+			"applicableFrom": "2022-06-01T00:00:00Z",
+			"content": {
+				"objectClassName": "ip network",
+				"handle": "NET-192-0-2-0-1",
+				"startAddress": "192.0.2.0",
+				"endAddress": "192.0.2.255",
+				"name": "NEW-NET"
+			}
+		}
+	]
+}`
+
+func TestDecodeHistory(t *testing.T) {
+	obj, err := NewDecoder([]byte(historyResponse)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, ok := obj.(*History)
+	if !ok || len(h.Records) != 2 {
+		t.Fatalf("Unexpected result %#v", obj)
+	}
+
+	if h.Records[0].IsCurrent() || !h.Records[1].IsCurrent() {
+		t.Errorf("Unexpected IsCurrent() results")
+	}
+
+	networks := h.Objects().IPNetworks()
+	if len(networks) != 2 || networks[0].Name != "OLD-NET" || networks[1].Name != "NEW-NET" {
+		t.Errorf("Unexpected versions %v", networks)
+	}
+
+	tests := []struct {
+		Time     string
+		Expected string
+	}{
+		{"2019-12-31T23:59:59Z", ""},
+		{"2021-03-04T05:06:07Z", "OLD-NET"},
+		{"2022-06-01T00:00:00Z", "NEW-NET"},
+		{"2030-01-01T00:00:00Z", "NEW-NET"},
+	}
+
+	for _, test := range tests {
+		at, _ := time.Parse(time.RFC3339, test.Time)
+
+		name := ""
+		if r := h.At(at); r != nil {
+			name = r.Object().(*IPNetwork).Name
+		}
+
+		if name != test.Expected {
+			t.Errorf("At(%s): got %q, expected %q", test.Time, name, test.Expected)
+		}
+	}
+}
+
+func TestClientQueryHistory(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.Write([]byte(historyResponse))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	resp, err := client.Do(NewHistoryRequest(IPRequest, "192.0.2.0").WithServer(serverURL))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if path != "/history/ip/192.0.2.0" {
+		t.Errorf("Unexpected request path %s", path)
+	}
+
+	if h, ok := resp.Object.(*History); !ok || len(h.Records) != 2 {
+		t.Errorf("Unexpected response %#v", resp.Object)
+	}
+}
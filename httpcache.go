@@ -0,0 +1,426 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openrdap/rdap/bootstrap/cache"
+)
+
+// HTTPCache caches RDAP responses in memory, per URL.
+//
+// Responses are cached as per the server's Cache-Control/Expires headers.
+// Expired responses with an ETag or Last-Modified header are revalidated
+// using If-None-Match/If-Modified-Since, so unchanged responses aren't
+// downloaded again.
+//
+// This is intended for monitoring workloads, which repeatedly query the same
+// objects:
+//
+//	client := &rdap.Client{
+//	  HTTPCache: rdap.NewHTTPCache(),
+//	}
+//
+// Responses are stored in memory by default. Set Backend to store them
+// elsewhere, e.g. in a cache shared by the workers of a distributed crawler.
+//
+// Responses are shared by all requests for the same URL, so requests with
+// credentials (e.g. an OpenID Connect access token), a TLS client certificate,
+// or their own HTTP client (Request.HTTP) aren't cached, and nor are responses
+// with Cache-Control: private.
+//
+// An HTTPCache is safe for concurrent use.
+type HTTPCache struct {
+	// Optional cache backend. When set, responses are stored in the backend
+	// (keyed by URL) instead of in memory, and MaxEntries is ignored.
+	//
+	// Responses with validators (ETag/Last-Modified) are kept in the backend
+	// for HTTPCacheRevalidationWindow after they become stale, so they can be
+	// revalidated.
+	Backend cache.Cache
+
+	// Maximum number of responses to cache. The least recently used responses
+	// are evicted first.
+	//
+	// The default is 1000. Set to a negative value for no limit.
+	MaxEntries int
+
+	// Freshness lifetime of responses without Cache-Control max-age or
+	// Expires headers.
+	//
+	// The default (0) is to revalidate such responses on every request.
+	DefaultTTL time.Duration
+
+	// Returns the current time. The default is time.Now.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+// HTTPCacheRevalidationWindow is how long stale responses are kept in an
+// HTTPCache's Backend for revalidation.
+const HTTPCacheRevalidationWindow = time.Hour * 24
+
+type httpCacheEntry struct {
+	url          string
+	status       int
+	header       http.Header
+	body         []byte
+	expires      time.Time
+	etag         string
+	lastModified string
+}
+
+// httpCacheRecord is the serialised form of an httpCacheEntry, as stored in an
+// HTTPCache's Backend.
+type httpCacheRecord struct {
+	Status  int         `json:"status"`
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+	Expires time.Time   `json:"expires"`
+}
+
+// NewHTTPCache creates a new, empty HTTPCache.
+func NewHTTPCache() *HTTPCache {
+	return &HTTPCache{
+		MaxEntries: 1000,
+	}
+}
+
+// Len returns the number of cached responses. Responses stored in a Backend
+// aren't counted.
+func (h *HTTPCache) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.entries)
+}
+
+// Clear removes all cached responses. Responses stored in a Backend aren't
+// removed.
+func (h *HTTPCache) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = nil
+	h.lru = nil
+}
+
+func (h *HTTPCache) now() time.Time {
+	if h.Now != nil {
+		return h.Now()
+	}
+
+	return time.Now()
+}
+
+// get returns the cached response for |url|, and whether it's fresh (usable
+// without revalidation). Returns nil if there's no cached response.
+func (h *HTTPCache) get(url string) (*httpCacheEntry, bool) {
+	if h.Backend != nil {
+		entry := h.load(url)
+		if entry == nil {
+			return nil, false
+		}
+
+		return entry, h.now().Before(entry.expires)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	elem, ok := h.entries[url]
+	if !ok {
+		return nil, false
+	}
+
+	h.lru.MoveToFront(elem)
+	entry := elem.Value.(*httpCacheEntry)
+
+	return entry, h.now().Before(entry.expires)
+}
+
+// store caches the HTTP response |resp| with body |body| for |url|, if it's
+// cacheable.
+//
+// Responses to requests with an Authorization header aren't cacheable, see
+// Client.usesHTTPCache().
+func (h *HTTPCache) store(url string, resp *http.Response, body []byte) {
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	if resp.Request != nil && resp.Request.Header.Get("Authorization") != "" {
+		return
+	}
+
+	directives := parseCacheControl(resp.Header.Get("Cache-Control"))
+	_, noStore := directives["no-store"]
+	_, private := directives["private"]
+	if noStore || private {
+		h.remove(url)
+		return
+	}
+
+	entry := &httpCacheEntry{
+		url:          url,
+		status:       resp.StatusCode,
+		header:       resp.Header.Clone(),
+		body:         body,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+	entry.expires = h.expiry(resp.Header, directives)
+
+	// Nothing to gain from caching a response which is immediately stale, and
+	// can't be revalidated.
+	if !h.now().Before(entry.expires) && entry.etag == "" && entry.lastModified == "" {
+		h.remove(url)
+		return
+	}
+
+	if h.Backend != nil {
+		h.save(entry)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.entries == nil {
+		h.entries = map[string]*list.Element{}
+		h.lru = list.New()
+	}
+
+	if elem, ok := h.entries[url]; ok {
+		elem.Value = entry
+		h.lru.MoveToFront(elem)
+	} else {
+		h.entries[url] = h.lru.PushFront(entry)
+	}
+
+	maxEntries := h.MaxEntries
+	if maxEntries == 0 {
+		maxEntries = 1000
+	}
+
+	for maxEntries > 0 && h.lru.Len() > maxEntries {
+		oldest := h.lru.Back()
+		h.lru.Remove(oldest)
+		delete(h.entries, oldest.Value.(*httpCacheEntry).url)
+	}
+}
+
+// revalidated updates the cached response for |url| after a 304 Not Modified
+// response |resp|. Returns the updated entry.
+func (h *HTTPCache) revalidated(entry *httpCacheEntry, resp *http.Response) *httpCacheEntry {
+	// Headers in the 304 response replace the stored ones (RFC 9111 section
+	// 4.3.4).
+	header := entry.header.Clone()
+	for k, v := range resp.Header {
+		header[k] = v
+	}
+
+	updated := &httpCacheEntry{
+		url:          entry.url,
+		status:       entry.status,
+		header:       header,
+		body:         entry.body,
+		etag:         header.Get("ETag"),
+		lastModified: header.Get("Last-Modified"),
+	}
+	updated.expires = h.expiry(header, parseCacheControl(header.Get("Cache-Control")))
+
+	if h.Backend != nil {
+		h.save(updated)
+		return updated
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if elem, ok := h.entries[entry.url]; ok {
+		elem.Value = updated
+	}
+
+	return updated
+}
+
+func (h *HTTPCache) remove(url string) {
+	if h.Backend != nil {
+		h.Backend.Delete(url)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if elem, ok := h.entries[url]; ok {
+		h.lru.Remove(elem)
+		delete(h.entries, url)
+	}
+}
+
+// load returns the entry for |url| from the Backend, or nil if absent (or
+// unreadable).
+func (h *HTTPCache) load(url string) *httpCacheEntry {
+	data, err := h.Backend.Get(url)
+	if err != nil {
+		return nil
+	}
+
+	var record httpCacheRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil
+	}
+
+	return &httpCacheEntry{
+		url:          url,
+		status:       record.Status,
+		header:       record.Header,
+		body:         record.Body,
+		expires:      record.Expires,
+		etag:         record.Header.Get("ETag"),
+		lastModified: record.Header.Get("Last-Modified"),
+	}
+}
+
+// save stores |entry| in the Backend. Errors are ignored, the response just
+// isn't cached.
+func (h *HTTPCache) save(entry *httpCacheEntry) {
+	data, err := json.Marshal(&httpCacheRecord{
+		Status:  entry.status,
+		Header:  entry.header,
+		Body:    entry.body,
+		Expires: entry.expires,
+	})
+	if err != nil {
+		return
+	}
+
+	ttl := entry.expires.Sub(h.now())
+	if entry.etag != "" || entry.lastModified != "" {
+		ttl += HTTPCacheRevalidationWindow
+	}
+
+	if ttl <= 0 {
+		// A ttl <= 0 means no expiry.
+		ttl = time.Nanosecond
+	}
+
+	h.Backend.Set(entry.url, data, ttl)
+}
+
+// expiry returns the time a response with headers |header| becomes stale.
+func (h *HTTPCache) expiry(header http.Header, directives map[string]string) time.Time {
+	now := h.now()
+
+	if _, ok := directives["no-cache"]; ok {
+		return now
+	}
+
+	var age time.Duration
+	if a, err := strconv.Atoi(header.Get("Age")); err == nil && a > 0 {
+		age = time.Duration(a) * time.Second
+	}
+
+	if maxAge, ok := directives["max-age"]; ok {
+		if seconds, err := strconv.Atoi(maxAge); err == nil {
+			return now.Add(time.Duration(seconds)*time.Second - age)
+		}
+
+		return now
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			// Invalid dates (e.g. "0") mean already expired.
+			return now
+		}
+
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			// Compensate for clock skew between client and server.
+			return now.Add(t.Sub(date))
+		}
+
+		return t
+	}
+
+	return now.Add(h.DefaultTTL)
+}
+
+// response returns a copy of the cached response as an http.Response.
+func (e *httpCacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(e.status) + " " + http.StatusText(e.status),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// parseCacheControl parses the Cache-Control header |value| into a map of
+// directive names to (optional) values.
+func parseCacheControl(value string) map[string]string {
+	directives := map[string]string{}
+
+	for _, d := range strings.Split(value, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+
+		name, arg := d, ""
+		if i := strings.IndexByte(d, '='); i != -1 {
+			name, arg = d[0:i], strings.Trim(strings.TrimSpace(d[i+1:]), `"`)
+		}
+
+		directives[strings.ToLower(strings.TrimSpace(name))] = arg
+	}
+
+	return directives
+}
+
+// usesHTTPCache returns true if the Client's HTTPCache can be used for the
+// request |rdapReq|.
+//
+// The cache is keyed by URL only, so it's not used for requests with
+// credentials, or made with their own identity (see hasOwnIdentity()), which
+// would otherwise be served to other requests (and other users of the cache's
+// Backend).
+func (c *Client) usesHTTPCache(rdapReq *Request) bool {
+	return c.HTTPCache != nil && rdapReq.openIDToken == nil &&
+		c.Credentials.For(rdapReq.URL().String()) == nil && !c.hasOwnIdentity(rdapReq)
+}
+
+// hasOwnIdentity returns true if the request |rdapReq| is made with an
+// identity other requests for the same URL may not share: its own HTTP client
+// (Request.HTTP), or a TLS client certificate (see Client.ClientCertificates).
+func (c *Client) hasOwnIdentity(rdapReq *Request) bool {
+	if rdapReq.HTTP != nil {
+		return true
+	}
+
+	_, ok := c.ClientCertificates.For(rdapReq.URL().Hostname())
+
+	return ok
+}
@@ -0,0 +1,349 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openrdap/rdap/bootstrap/cache"
+	"github.com/openrdap/rdap/test"
+)
+
+// cachingTransport is a http.RoundTripper which serves a domain response
+// with |header|, and answers conditional requests matching |etag| with 304
+// Not Modified.
+type cachingTransport struct {
+	header   http.Header
+	etag     string
+	requests int
+	notMod   int
+}
+
+func (c *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+
+	header := c.header.Clone()
+	if c.etag != "" {
+		header.Set("ETag", c.etag)
+	}
+
+	if c.etag != "" && req.Header.Get("If-None-Match") == c.etag {
+		c.notMod++
+
+		return &http.Response{
+			StatusCode: http.StatusNotModified,
+			Header:     header,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader(string(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json")))),
+		Request:    req,
+	}, nil
+}
+
+func runCachedQuery(t *testing.T, client *Client) *Response {
+	server, _ := url.Parse("https://rdap.nic.cz")
+
+	resp, err := client.Do(NewDomainRequest("example.cz").WithServer(server))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, ok := resp.Object.(*Domain); !ok {
+		t.Fatalf("Unexpected response %v", resp.Object)
+	}
+
+	return resp
+}
+
+func TestHTTPCacheMaxAge(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	httpCache := NewHTTPCache()
+	httpCache.Now = func() time.Time { return now }
+
+	transport := &cachingTransport{
+		header: http.Header{"Cache-Control": []string{"max-age=60"}},
+	}
+	client := &Client{
+		HTTP:      &http.Client{Transport: transport},
+		HTTPCache: httpCache,
+		Verbose:   verboseFunc(),
+	}
+
+	if resp := runCachedQuery(t, client); resp.HTTP[0].Cached {
+		t.Errorf("First response unexpectedly cached")
+	}
+
+	now = now.Add(time.Second * 30)
+	if resp := runCachedQuery(t, client); !resp.HTTP[0].Cached || transport.requests != 1 {
+		t.Errorf("Expected fresh cached response, made %d requests", transport.requests)
+	}
+
+	now = now.Add(time.Second * 31)
+	if resp := runCachedQuery(t, client); resp.HTTP[0].Cached || transport.requests != 2 {
+		t.Errorf("Expected expired response to be fetched, made %d requests", transport.requests)
+	}
+}
+
+func TestHTTPCacheRevalidate(t *testing.T) {
+	transport := &cachingTransport{
+		header: http.Header{"Cache-Control": []string{"no-cache"}},
+		etag:   `"v1"`,
+	}
+	client := &Client{
+		HTTP:      &http.Client{Transport: transport},
+		HTTPCache: NewHTTPCache(),
+		Verbose:   verboseFunc(),
+	}
+
+	runCachedQuery(t, client)
+
+	resp := runCachedQuery(t, client)
+	if !resp.HTTP[0].Cached || resp.HTTP[0].Response.StatusCode != 200 {
+		t.Errorf("Expected revalidated cached response")
+	} else if transport.requests != 2 || transport.notMod != 1 {
+		t.Errorf("Unexpected requests=%d notModified=%d", transport.requests, transport.notMod)
+	}
+
+	// Changed response.
+	transport.etag = `"v2"`
+	resp = runCachedQuery(t, client)
+	if resp.HTTP[0].Cached || transport.notMod != 1 {
+		t.Errorf("Expected changed response to be fetched")
+	}
+}
+
+func TestHTTPCacheNoStore(t *testing.T) {
+	transport := &cachingTransport{
+		header: http.Header{"Cache-Control": []string{"no-store, max-age=60"}},
+		etag:   `"v1"`,
+	}
+	client := &Client{
+		HTTP:      &http.Client{Transport: transport},
+		HTTPCache: NewHTTPCache(),
+		Verbose:   verboseFunc(),
+	}
+
+	runCachedQuery(t, client)
+	runCachedQuery(t, client)
+
+	if transport.requests != 2 || transport.notMod != 0 || client.HTTPCache.Len() != 0 {
+		t.Errorf("Unexpected caching of no-store response")
+	}
+}
+
+func TestHTTPCacheBackend(t *testing.T) {
+	backend := cache.NewMemoryStore()
+
+	transport := &cachingTransport{
+		header: http.Header{"Cache-Control": []string{"max-age=0"}},
+		etag:   `"v1"`,
+	}
+
+	// Two clients sharing the backend.
+	client1 := &Client{
+		HTTP:      &http.Client{Transport: transport},
+		HTTPCache: &HTTPCache{Backend: backend},
+		Verbose:   verboseFunc(),
+	}
+	client2 := &Client{
+		HTTP:      &http.Client{Transport: transport},
+		HTTPCache: &HTTPCache{Backend: backend},
+		Verbose:   verboseFunc(),
+	}
+
+	runCachedQuery(t, client1)
+
+	if _, err := backend.Get("https://rdap.nic.cz/domain/example.cz"); err != nil {
+		t.Fatalf("Expected response in backend: %s", err)
+	}
+
+	resp := runCachedQuery(t, client2)
+	if !resp.HTTP[0].Cached || transport.notMod != 1 {
+		t.Errorf("Expected response revalidated from the shared backend")
+	}
+}
+
+func TestHTTPCacheExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	httpCache := &HTTPCache{
+		DefaultTTL: time.Minute,
+		Now:        func() time.Time { return now },
+	}
+
+	tests := []struct {
+		Header  http.Header
+		Expires time.Time
+	}{
+		{
+			http.Header{"Cache-Control": []string{`public, max-age="120"`}, "Age": []string{"20"}},
+			now.Add(time.Second * 100),
+		},
+		{
+			http.Header{
+				"Date":    []string{"Mon, 01 Jan 2024 10:00:00 GMT"},
+				"Expires": []string{"Mon, 01 Jan 2024 11:00:00 GMT"},
+			},
+			now.Add(time.Hour),
+		},
+		{
+			http.Header{"Expires": []string{"0"}},
+			now,
+		},
+		{
+			http.Header{},
+			now.Add(time.Minute),
+		},
+	}
+
+	for _, test := range tests {
+		expires := httpCache.expiry(test.Header, parseCacheControl(test.Header.Get("Cache-Control")))
+
+		if !expires.Equal(test.Expires) {
+			t.Errorf("Headers %v: got expiry %s, expected %s", test.Header, expires, test.Expires)
+		}
+	}
+}
+
+func TestHTTPCacheMaxEntries(t *testing.T) {
+	httpCache := &HTTPCache{MaxEntries: 2}
+	resp := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Cache-Control": []string{"max-age=60"}},
+	}
+
+	httpCache.store("a", resp, nil)
+	httpCache.store("b", resp, nil)
+	httpCache.get("a")
+	httpCache.store("c", resp, nil)
+
+	if _, fresh := httpCache.get("b"); fresh || httpCache.Len() != 2 {
+		t.Errorf("Expected least recently used entry to be evicted")
+	}
+
+	if _, fresh := httpCache.get("a"); !fresh {
+		t.Errorf("Expected entry to be cached")
+	}
+}
+
+func TestHTTPCacheCredentials(t *testing.T) {
+	transport := &cachingTransport{
+		header: http.Header{"Cache-Control": []string{"max-age=60"}},
+		etag:   `"v1"`,
+	}
+	client := &Client{
+		HTTP:      &http.Client{Transport: transport},
+		HTTPCache: NewHTTPCache(),
+		Verbose:   verboseFunc(),
+	}
+
+	// Responses fetched with a token aren't cached, or served from the cache.
+	for i, token := range []string{"token-a", "token-b"} {
+		client.OpenID = OpenIDTokens{"rdap.nic.cz": &StaticOpenIDToken{AccessToken: token}}
+
+		if resp := runCachedQuery(t, client); resp.HTTP[0].Cached || transport.requests != i+1 {
+			t.Errorf("Unexpected cached response for %s", token)
+		} else if transport.notMod != 0 || client.HTTPCache.Len() != 0 {
+			t.Errorf("Unexpected caching of response for %s", token)
+		}
+	}
+
+	client.OpenID = nil
+	if resp := runCachedQuery(t, client); resp.HTTP[0].Cached || transport.requests != 3 {
+		t.Errorf("Unauthenticated query served a response fetched with a token")
+	}
+
+	client.OpenID = OpenIDTokens{"rdap.nic.cz": &StaticOpenIDToken{AccessToken: "token-a"}}
+	if resp := runCachedQuery(t, client); resp.HTTP[0].Cached || transport.requests != 4 {
+		t.Errorf("Authenticated query served an unauthenticated response")
+	}
+}
+
+func TestHTTPCachePrivate(t *testing.T) {
+	transport := &cachingTransport{
+		header: http.Header{"Cache-Control": []string{"private, max-age=60"}},
+	}
+	client := &Client{
+		HTTP:      &http.Client{Transport: transport},
+		HTTPCache: NewHTTPCache(),
+		Verbose:   verboseFunc(),
+	}
+
+	runCachedQuery(t, client)
+	runCachedQuery(t, client)
+
+	if transport.requests != 2 || client.HTTPCache.Len() != 0 {
+		t.Errorf("Unexpected caching of private response")
+	}
+}
+
+func TestHTTPCacheClientCredentials(t *testing.T) {
+	transport := &cachingTransport{
+		header: http.Header{"Cache-Control": []string{"max-age=60"}},
+	}
+	client := &Client{
+		HTTP:      &http.Client{Transport: transport},
+		HTTPCache: NewHTTPCache(),
+		Credentials: Credentials{
+			"https://rdap.nic.cz": {Username: "user", Password: "password"},
+		},
+		Verbose: verboseFunc(),
+	}
+
+	runCachedQuery(t, client)
+	runCachedQuery(t, client)
+
+	if transport.requests != 2 || client.HTTPCache.Len() != 0 {
+		t.Errorf("Unexpected caching of response to request with credentials")
+	}
+}
+
+func TestHTTPCacheClientIdentity(t *testing.T) {
+	transport := &cachingTransport{
+		header: http.Header{"Cache-Control": []string{"max-age=60"}},
+	}
+	client := &Client{
+		HTTP:               &http.Client{Transport: transport},
+		HTTPCache:          NewHTTPCache(),
+		ClientCertificates: ClientCertificates{"nic.cz": tls.Certificate{}},
+		Verbose:            verboseFunc(),
+	}
+
+	runCachedQuery(t, client)
+	runCachedQuery(t, client)
+
+	if transport.requests != 2 || client.HTTPCache.Len() != 0 {
+		t.Errorf("Unexpected caching of response to request with a client certificate")
+	}
+
+	// Requests with their own HTTP client.
+	client.ClientCertificates = nil
+	server, _ := url.Parse("https://rdap.nic.cz")
+	req := NewDomainRequest("example.cz").WithServer(server)
+	req.HTTP = &http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Do(req); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	if transport.requests != 4 || client.HTTPCache.Len() != 0 {
+		t.Errorf("Unexpected caching of response to request with its own HTTP client")
+	}
+}
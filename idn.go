@@ -0,0 +1,143 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// ToASCII converts the internationalized domain name |domain| to its ASCII
+// (A-label) form, e.g. "ñandú.example" to "xn--and-6ma2c.example".
+//
+// The name is mapped and validated as per UTS #46 and IDNA2008. ASCII names
+// are lowercased.
+func ToASCII(domain string) (string, error) {
+	return idna.Lookup.ToASCII(domain)
+}
+
+// ToUnicode converts the domain name |domain| to its Unicode (U-label) form,
+// e.g. "xn--and-6ma2c.example" to "ñandú.example".
+//
+// ASCII names without A-labels are returned lowercased.
+func ToUnicode(domain string) (string, error) {
+	return idna.Lookup.ToUnicode(domain)
+}
+
+// isASCII returns true if |text| contains only ASCII characters.
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= 0x80 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasIDNQuery returns true if the Request type takes a domain name query,
+// which may be an internationalized domain name.
+func hasIDNQuery(req *Request) bool {
+	switch req.Type {
+	case DomainRequest, NameserverRequest:
+		return true
+	}
+
+	return false
+}
+
+// idnQueries returns the forms of the Request's domain name to use for
+// bootstrapping (always the A-label form), and for the RDAP query itself
+// (the A-label form, or the U-label form if |unicode| is set).
+//
+// Queries which are already in the form required are returned unchanged.
+func idnQueries(req *Request, unicode bool) (bootstrapQuery string, query string, err error) {
+	bootstrapQuery = req.Query
+	query = req.Query
+
+	if !hasIDNQuery(req) {
+		return
+	}
+
+	if !isASCII(req.Query) {
+		bootstrapQuery, err = ToASCII(req.Query)
+		if err != nil {
+			return "", "", &ClientError{
+				Type: InputError,
+				Text: fmt.Sprintf("Invalid internationalized domain name '%s': %s", req.Query, err),
+			}
+		}
+
+		query = bootstrapQuery
+	}
+
+	if unicode && strings.Contains(strings.ToLower(bootstrapQuery), "xn--") {
+		query, err = ToUnicode(bootstrapQuery)
+		if err != nil {
+			return "", "", &ClientError{
+				Type: InputError,
+				Text: fmt.Sprintf("Invalid internationalized domain name '%s': %s", req.Query, err),
+			}
+		}
+	}
+
+	return
+}
+
+// ALabel returns the domain name in ASCII (A-label) form.
+//
+// This is the LDHName if present, otherwise the UnicodeName converted to
+// A-labels. Returns "" if neither name is present, or the conversion fails.
+func (d *Domain) ALabel() string {
+	return aLabel(d.LDHName, d.UnicodeName)
+}
+
+// ULabel returns the domain name in Unicode (U-label) form.
+//
+// This is the UnicodeName if present, otherwise the LDHName converted to
+// U-labels. Returns "" if neither name is present, or the conversion fails.
+func (d *Domain) ULabel() string {
+	return uLabel(d.LDHName, d.UnicodeName)
+}
+
+// ALabel returns the nameserver's host name in ASCII (A-label) form. See
+// Domain.ALabel().
+func (n *Nameserver) ALabel() string {
+	return aLabel(n.LDHName, n.UnicodeName)
+}
+
+// ULabel returns the nameserver's host name in Unicode (U-label) form. See
+// Domain.ULabel().
+func (n *Nameserver) ULabel() string {
+	return uLabel(n.LDHName, n.UnicodeName)
+}
+
+func aLabel(ldhName string, unicodeName string) string {
+	if ldhName != "" {
+		return ldhName
+	}
+
+	name, err := ToASCII(unicodeName)
+	if err != nil {
+		return ""
+	}
+
+	return name
+}
+
+func uLabel(ldhName string, unicodeName string) string {
+	if unicodeName != "" {
+		return unicodeName
+	}
+
+	name, err := ToUnicode(ldhName)
+	if err != nil {
+		return ""
+	}
+
+	return name
+}
@@ -0,0 +1,86 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestClientIDNQuery(t *testing.T) {
+	var path string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	tests := []struct {
+		Query          string
+		UnicodeQueries bool
+		Path           string
+	}{
+		{"example.cz", false, "/domain/example.cz"},
+		{"Háček.CZ", false, "/domain/xn--hek-ela4t.cz"},
+		{"háček.cz", true, "/domain/h%C3%A1%C4%8Dek.cz"},
+		{"xn--hek-ela4t.cz", true, "/domain/h%C3%A1%C4%8Dek.cz"},
+		{"xn--hek-ela4t.cz", false, "/domain/xn--hek-ela4t.cz"},
+	}
+
+	for _, test := range tests {
+		client := &Client{
+			UnicodeQueries: test.UnicodeQueries,
+			Verbose:        verboseFunc(),
+		}
+
+		_, err := client.Do(NewDomainRequest(test.Query).WithServer(serverURL))
+		if err != nil {
+			t.Errorf("Query %q: unexpected error %s", test.Query, err)
+		} else if path != test.Path {
+			t.Errorf("Query %q: got path %s, expected %s", test.Query, path, test.Path)
+		}
+	}
+
+	client := &Client{}
+	if _, err := client.Do(NewDomainRequest("bad name.cz").WithServer(serverURL)); !isClientError(InputError, err) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestDomainLabels(t *testing.T) {
+	tests := []struct {
+		LDHName     string
+		UnicodeName string
+		ALabel      string
+		ULabel      string
+	}{
+		{"xn--hek-ela4t.cz", "", "xn--hek-ela4t.cz", "háček.cz"},
+		{"", "háček.cz", "xn--hek-ela4t.cz", "háček.cz"},
+		{"example.cz", "", "example.cz", "example.cz"},
+		{"", "", "", ""},
+	}
+
+	for _, test := range tests {
+		d := &Domain{LDHName: test.LDHName, UnicodeName: test.UnicodeName}
+		n := &Nameserver{LDHName: test.LDHName, UnicodeName: test.UnicodeName}
+
+		if d.ALabel() != test.ALabel || n.ALabel() != test.ALabel {
+			t.Errorf("%+v: got A-label %q, expected %q", test, d.ALabel(), test.ALabel)
+		}
+
+		if d.ULabel() != test.ULabel || n.ULabel() != test.ULabel {
+			t.Errorf("%+v: got U-label %q, expected %q", test, d.ULabel(), test.ULabel)
+		}
+	}
+}
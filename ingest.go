@@ -0,0 +1,399 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openrdap/rdap/bootstrap"
+)
+
+// DefaultIngestRate is the default query rate (requests per second) per RDAP
+// server used by NewIngestPlan().
+const DefaultIngestRate = 1.0
+
+// ReadDomainList reads a list of domain names from |r|, calling |fn| for each
+// unique domain name.
+//
+// Several formats are supported:
+//   - Plain lists, one domain name per line.
+//   - CSV style lists (e.g. CT log extracts), domain name in the first column.
+//   - DNS zone files (RFC 1035 master files). The owner names of NS records
+//     (i.e. the delegated domains) are read, other records are ignored.
+//
+// Domain names are lowercased, and trailing dots and leading wildcard labels
+// ("*.") are removed. Comments ("#" and ";") and blank lines are skipped.
+//
+// Reading stops at the first error returned by |fn|.
+func ReadDomainList(r io.Reader, fn func(domain string) error) error {
+	seen := map[string]bool{}
+	origin := ""
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if i := strings.IndexAny(line, "#;"); i != -1 {
+			line = line[0:i]
+		}
+
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ','
+		})
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		var domain string
+
+		switch {
+		case strings.EqualFold(fields[0], "$ORIGIN") && len(fields) > 1:
+			origin = strings.ToLower(strings.TrimSuffix(fields[1], "."))
+			continue
+		case strings.HasPrefix(fields[0], "$"):
+			// Other zone file directives, e.g. $TTL.
+			continue
+		case len(fields) == 1 || !isZoneFileRecord(fields):
+			domain = fields[0]
+		case isNSRecord(fields) && !isBlankOwner(line):
+			domain = fields[0]
+
+			// Relative owner names.
+			if domain == "@" {
+				domain = origin
+			} else if !strings.HasSuffix(domain, ".") && origin != "" {
+				domain += "." + origin
+			}
+		default:
+			continue
+		}
+
+		domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+		domain = strings.TrimPrefix(domain, "*.")
+
+		if domain == "" || seen[domain] || !strings.Contains(domain, ".") {
+			continue
+		}
+		seen[domain] = true
+
+		if err := fn(domain); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// isZoneFileRecord returns true if |fields| look like a zone file resource
+// record, e.g. "example.cz. 3600 IN NS a.ns.example.cz.".
+func isZoneFileRecord(fields []string) bool {
+	for _, f := range fields[1:] {
+		switch strings.ToUpper(f) {
+		case "IN", "CH", "HS", "NS", "SOA", "A", "AAAA", "DS", "DNSKEY",
+			"RRSIG", "NSEC", "NSEC3", "NSEC3PARAM", "CNAME", "MX", "TXT":
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNSRecord returns true if the zone file record |fields| is an NS record.
+func isNSRecord(fields []string) bool {
+	for i, f := range fields[1:] {
+		if strings.EqualFold(f, "NS") {
+			// The type follows the optional TTL and class, so appears by the 4th
+			// field.
+			return i < 3
+		}
+	}
+
+	return false
+}
+
+// isBlankOwner returns true if the zone file record |line| omits its owner
+// name (i.e. starts with whitespace, continuing the previous owner).
+func isBlankOwner(line string) bool {
+	return len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+}
+
+// IngestOptions specifies options for NewIngestPlan().
+type IngestOptions struct {
+	// Default query rate (requests per second) per RDAP server.
+	//
+	// The default is DefaultIngestRate.
+	Rate float64
+
+	// Query rates for specific RDAP servers, keyed by hostname
+	// (e.g. "rdap.nic.cz"), overriding Rate.
+	Rates map[string]float64
+
+	// Optional politeness presets. When set, each server's Politeness QPS is
+	// used (unless overridden by Rates). See DefaultPolitenessPresets().
+	Politeness PolitenessPresets
+}
+
+// An IngestPlan is an execution plan for querying a large list of domains.
+//
+// Domains are grouped by RDAP server, so that each server's rate limit can be
+// respected, while querying all servers in parallel. The plan's overall
+// duration is that of its slowest group.
+type IngestPlan struct {
+	// Groups of domains, one per RDAP server, largest first.
+	Groups []*IngestGroup
+
+	// Domains with no RDAP server (not listed in the bootstrap registry).
+	Unroutable []string
+}
+
+// An IngestGroup is a set of domains handled by the same RDAP server.
+type IngestGroup struct {
+	// RDAP server base URL(s), as per bootstrapping.
+	Servers []*url.URL
+
+	// TLDs in this group, e.g. ["cz"]. Registries often run a single RDAP
+	// server for many TLDs.
+	TLDs []string
+
+	// Domains to query.
+	Domains []string
+
+	// Query rate (requests per second).
+	Rate float64
+}
+
+// Host returns the hostname of the group's RDAP server.
+func (g *IngestGroup) Host() string {
+	if len(g.Servers) == 0 {
+		return ""
+	}
+
+	return g.Servers[0].Hostname()
+}
+
+// Duration returns the time needed to query all of the group's domains at the
+// group's rate.
+func (g *IngestGroup) Duration() time.Duration {
+	if g.Rate <= 0 {
+		return 0
+	}
+
+	return time.Duration(float64(len(g.Domains)) / g.Rate * float64(time.Second))
+}
+
+// Duration returns the estimated time needed to execute the plan.
+func (p *IngestPlan) Duration() time.Duration {
+	var max time.Duration
+
+	for _, g := range p.Groups {
+		if d := g.Duration(); d > max {
+			max = d
+		}
+	}
+
+	return max
+}
+
+// String returns a human readable summary of the plan.
+func (p *IngestPlan) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Ingest plan: %d server(s), estimated duration %s\n",
+		len(p.Groups), p.Duration().Round(time.Second))
+
+	for _, g := range p.Groups {
+		fmt.Fprintf(&b, "  %s: %d domain(s), TLDs %s, %.2f req/s, %s\n",
+			g.Host(), len(g.Domains), strings.Join(g.TLDs, ","), g.Rate,
+			g.Duration().Round(time.Second))
+	}
+
+	if len(p.Unroutable) > 0 {
+		fmt.Fprintf(&b, "  (no RDAP server): %d domain(s)\n", len(p.Unroutable))
+	}
+
+	return b.String()
+}
+
+// NewIngestPlan groups |domains| by RDAP server, using the bootstrap client
+// |bs|.
+//
+// Bootstrapping is performed once per TLD. |ctx| controls cancellation of any
+// bootstrap file download.
+func NewIngestPlan(ctx context.Context, bs *bootstrap.Client, domains []string, opts IngestOptions) (*IngestPlan, error) {
+	if opts.Rate <= 0 {
+		opts.Rate = DefaultIngestRate
+	}
+
+	plan := &IngestPlan{}
+
+	groups := map[string]*IngestGroup{}
+	tldGroups := map[string]*IngestGroup{}
+
+	for _, domain := range domains {
+		tld := domain
+		if i := strings.LastIndexByte(domain, '.'); i != -1 {
+			tld = domain[i+1:]
+		}
+
+		group, ok := tldGroups[tld]
+		if !ok {
+			question := &bootstrap.Question{
+				RegistryType: bootstrap.DNS,
+				Query:        domain,
+			}
+
+			answer, err := bs.Lookup(question.WithContext(ctx))
+			if err != nil {
+				return nil, err
+			}
+
+			if len(answer.URLs) > 0 {
+				key := answer.URLs[0].String()
+
+				group = groups[key]
+				if group == nil {
+					group = &IngestGroup{
+						Servers: answer.URLs,
+					}
+
+					group.Rate = opts.Rate
+					if rate, ok := opts.Rates[group.Host()]; ok && rate > 0 {
+						group.Rate = rate
+					} else if qps := opts.Politeness.For(answer.URLs[0].Hostname()).QPS; qps > 0 {
+						group.Rate = qps
+					}
+
+					groups[key] = group
+					plan.Groups = append(plan.Groups, group)
+				}
+
+				group.TLDs = append(group.TLDs, tld)
+			}
+
+			tldGroups[tld] = group
+		}
+
+		if group == nil {
+			plan.Unroutable = append(plan.Unroutable, domain)
+		} else {
+			group.Domains = append(group.Domains, domain)
+		}
+	}
+
+	sort.SliceStable(plan.Groups, func(i int, j int) bool {
+		return len(plan.Groups[i].Domains) > len(plan.Groups[j].Domains)
+	})
+
+	return plan, nil
+}
+
+// An IngestRequest is a single scheduled query of an IngestPlan.
+type IngestRequest struct {
+	// Domain query, with the RDAP server set.
+	Request *Request
+
+	// Scheduled start time, relative to the start of the plan.
+	Offset time.Duration
+}
+
+// Requests returns the plan's queries, in execution order.
+//
+// Each group's queries are spaced at the group's rate, and the groups are
+// interleaved, so executing the requests in order (starting each no earlier
+// than its Offset) queries all servers in parallel without exceeding any
+// server's rate.
+func (p *IngestPlan) Requests() []IngestRequest {
+	var requests []IngestRequest
+
+	for _, g := range p.Groups {
+		interval := time.Duration(float64(time.Second) / g.Rate)
+
+		for i, domain := range g.Domains {
+			requests = append(requests, IngestRequest{
+				Request: NewDomainRequest(domain).WithServer(g.Servers[0]),
+				Offset:  time.Duration(i) * interval,
+			})
+		}
+	}
+
+	sort.SliceStable(requests, func(i int, j int) bool {
+		return requests[i].Offset < requests[j].Offset
+	})
+
+	return requests
+}
+
+// Run executes the plan using |client|, calling |fn| with the result of each
+// query.
+//
+// Up to |workers| queries run concurrently (minimum 1). Each query starts no
+// earlier than its scheduled Offset. Run returns early if |ctx| is done.
+func (p *IngestPlan) Run(ctx context.Context, client *Client, workers int, fn func(req *Request, resp *Response, err error)) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	// Initialise the client before it's used concurrently.
+	client.init()
+
+	start := time.Now()
+	sem := make(chan struct{}, workers)
+	done := make(chan struct{})
+	results := make(chan func(), workers)
+
+	// Deliver results on a single goroutine, so |fn| needn't be thread safe.
+	go func() {
+		for r := range results {
+			r()
+		}
+		close(done)
+	}()
+
+	var err error
+
+	for _, r := range p.Requests() {
+		if wait := time.Until(start.Add(r.Offset)); wait > 0 {
+			timer := time.NewTimer(wait)
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		sem <- struct{}{}
+
+		go func(req *Request) {
+			resp, err := client.Do(req.WithContext(ctx))
+			results <- func() { fn(req, resp, err) }
+			<-sem
+		}(r.Request)
+	}
+
+	// Wait for in-flight queries.
+	for i := 0; i < workers; i++ {
+		sem <- struct{}{}
+	}
+
+	close(results)
+	<-done
+
+	return err
+}
@@ -0,0 +1,106 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openrdap/rdap/bootstrap"
+	"github.com/openrdap/rdap/test"
+)
+
+func readDomainList(t *testing.T, data []byte) []string {
+	var domains []string
+
+	err := ReadDomainList(bytes.NewReader(data), func(domain string) error {
+		domains = append(domains, domain)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("ReadDomainList failed: %s", err)
+	}
+
+	return domains
+}
+
+func TestReadDomainListZoneFile(t *testing.T) {
+	domains := readDomainList(t, test.LoadFile("ingest/zone.txt"))
+	expected := []string{"example.cz", "thin.cz"}
+
+	if !reflect.DeepEqual(domains, expected) {
+		t.Errorf("Got %v, expected %v", domains, expected)
+	}
+}
+
+func TestReadDomainListPlain(t *testing.T) {
+	domains := readDomainList(t, []byte(`
+# CT log extract
+*.example.com,2024-01-01T00:00:00Z
+www.Example.NET.
+example.com
+cz
+`))
+	expected := []string{"example.com", "www.example.net"}
+
+	if !reflect.DeepEqual(domains, expected) {
+		t.Errorf("Got %v, expected %v", domains, expected)
+	}
+}
+
+func TestIngestPlan(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	domains := []string{"example.cz", "thin.cz", "non-existent.cz", "example.br", "example.invalid"}
+
+	plan, err := NewIngestPlan(context.Background(), &bootstrap.Client{}, domains, IngestOptions{
+		Rates: map[string]float64{"rdap.nic.cz": 100},
+	})
+
+	if err != nil {
+		t.Fatalf("NewIngestPlan failed: %s", err)
+	}
+
+	if len(plan.Groups) != 2 {
+		t.Fatalf("Unexpected groups %s", plan)
+	}
+
+	cz := plan.Groups[0]
+	if cz.Host() != "rdap.nic.cz" || cz.Rate != 100 || len(cz.Domains) != 3 || !reflect.DeepEqual(cz.TLDs, []string{"cz"}) {
+		t.Errorf("Unexpected .cz group %s", plan)
+	}
+
+	if plan.Groups[1].Rate != DefaultIngestRate || !reflect.DeepEqual(plan.Unroutable, []string{"example.invalid"}) {
+		t.Errorf("Unexpected plan %s", plan)
+	}
+
+	if plan.Duration() != time.Second || !strings.Contains(plan.String(), "rdap.nic.cz: 3 domain(s)") {
+		t.Errorf("Unexpected plan %s", plan)
+	}
+
+	requests := plan.Requests()
+	if len(requests) != 4 || requests[1].Offset != 0 || requests[2].Offset != 10*time.Millisecond {
+		t.Errorf("Unexpected requests %v", requests)
+	}
+
+	// Run only the .cz group.
+	plan.Groups = plan.Groups[0:1]
+
+	results := map[string]error{}
+	err = plan.Run(context.Background(), &Client{}, 2, func(req *Request, resp *Response, err error) {
+		results[req.Query] = err
+	})
+
+	if err != nil || len(results) != 3 || results["example.cz"] != nil || results["non-existent.cz"] == nil {
+		t.Errorf("Unexpected results %v, err %v", results, err)
+	}
+}
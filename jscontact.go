@@ -0,0 +1,669 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// JSCard represents a JSContact Card, as defined in
+// https://datatracker.ietf.org/doc/rfc9553/.
+//
+// JSContact is a JSON alternative to jCard. RDAP servers implementing the
+// JSContact extension return contacts in this format, instead of (or alongside)
+// a vCard.
+//
+// A JSCard can be converted to/from a VCard:
+//
+//	card := vcard.JSCard()
+//	vcard2 := card.VCard()
+//
+// The conversion follows https://datatracker.ietf.org/doc/rfc9555/. vCard
+// properties with no JSContact equivalent are carried in VCardProps, so
+// converting a VCard to a JSCard and back is lossless for these.
+type JSCard struct {
+	Type     string `json:"@type"`
+	Version  string `json:"version"`
+	UID      string `json:"uid,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Language string `json:"language,omitempty"`
+
+	Name          *JSName                    `json:"name,omitempty"`
+	Organizations map[string]*JSOrganization `json:"organizations,omitempty"`
+	Titles        map[string]*JSTitle        `json:"titles,omitempty"`
+	Emails        map[string]*JSEmailAddress `json:"emails,omitempty"`
+	Phones        map[string]*JSPhone        `json:"phones,omitempty"`
+	Addresses     map[string]*JSAddress      `json:"addresses,omitempty"`
+	Links         map[string]*JSLink         `json:"links,omitempty"`
+
+	// vCard properties with no JSContact equivalent, in jCard format.
+	//
+	// e.g. ["bday", {}, "date-and-or-time", "--02-03"].
+	VCardProps [][]interface{} `json:"vCardProps,omitempty"`
+}
+
+// JSName is the name of the entity represented by a JSCard.
+type JSName struct {
+	Components []*JSNameComponent `json:"components,omitempty"`
+	Full       string             `json:"full,omitempty"`
+}
+
+// JSNameComponent is a single component of a JSName.
+//
+// Kind is one of "title", "given", "given2", "surname", "surname2",
+// "credential", "generation", "separator".
+type JSNameComponent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// JSOrganization is an organization the entity belongs to.
+type JSOrganization struct {
+	Name  string       `json:"name,omitempty"`
+	Units []*JSOrgUnit `json:"units,omitempty"`
+}
+
+// JSOrgUnit is an organizational unit of a JSOrganization.
+type JSOrgUnit struct {
+	Name string `json:"name"`
+}
+
+// JSTitle is a job title or role of the entity.
+//
+// Kind is either "title" or "role".
+type JSTitle struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// JSEmailAddress is an email address.
+type JSEmailAddress struct {
+	Address  string          `json:"address"`
+	Contexts map[string]bool `json:"contexts,omitempty"`
+	Pref     int             `json:"pref,omitempty"`
+}
+
+// JSPhone is a telephone number.
+//
+// Features is a set of "mobile", "voice", "text", "video", "main-number",
+// "textphone", "fax", "pager".
+type JSPhone struct {
+	Number   string          `json:"number"`
+	Features map[string]bool `json:"features,omitempty"`
+	Contexts map[string]bool `json:"contexts,omitempty"`
+	Pref     int             `json:"pref,omitempty"`
+}
+
+// JSAddress is a postal address.
+type JSAddress struct {
+	Components  []*JSAddressComponent `json:"components,omitempty"`
+	Full        string                `json:"full,omitempty"`
+	CountryCode string                `json:"countryCode,omitempty"`
+	Contexts    map[string]bool       `json:"contexts,omitempty"`
+	Pref        int                   `json:"pref,omitempty"`
+}
+
+// JSAddressComponent is a single component of a JSAddress.
+//
+// Kind is e.g. "postOfficeBox", "apartment", "name" (street name), "locality",
+// "region", "postcode", "country".
+type JSAddressComponent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// JSLink is a link to a resource associated with the entity.
+type JSLink struct {
+	URI      string          `json:"uri"`
+	Contexts map[string]bool `json:"contexts,omitempty"`
+	Pref     int             `json:"pref,omitempty"`
+}
+
+// vCard N property components, in order.
+var jsNameKinds = []string{"surname", "given", "given2", "title", "credential"}
+
+// vCard ADR property components, in order.
+var jsAddressKinds = []string{"postOfficeBox", "apartment", "name", "locality", "region", "postcode", "country"}
+
+// NewJSCard creates a JSCard from the JSContact document |jsonBlob|.
+func NewJSCard(jsonBlob []byte) (*JSCard, error) {
+	card := &JSCard{}
+
+	err := json.Unmarshal(jsonBlob, card)
+	if err != nil {
+		return nil, err
+	}
+
+	if card.Type != "Card" {
+		return nil, fmt.Errorf("JSContact error: @type is '%s', expected 'Card'", card.Type)
+	}
+
+	return card, nil
+}
+
+// JSCard converts the VCard to a JSContact Card.
+func (v *VCard) JSCard() *JSCard {
+	card := &JSCard{
+		Type:    "Card",
+		Version: "1.0",
+	}
+
+	for _, p := range v.Properties {
+		switch p.Name {
+		case "version":
+			// JSContact has its own versioning.
+		case "uid":
+			card.UID = p.firstValue()
+		case "kind":
+			card.Kind = p.firstValue()
+		case "fn":
+			card.ensureName().Full = p.firstValue()
+		case "n":
+			name := card.ensureName()
+			for i, values := range p.components(len(jsNameKinds)) {
+				for _, value := range values {
+					if value != "" {
+						name.Components = append(name.Components, &JSNameComponent{
+							Kind:  jsNameKinds[i],
+							Value: value,
+						})
+					}
+				}
+			}
+		case "org":
+			org := &JSOrganization{}
+			for i, values := range p.components(0) {
+				if len(values) == 0 || values[0] == "" {
+					continue
+				}
+
+				if i == 0 {
+					org.Name = values[0]
+				} else {
+					org.Units = append(org.Units, &JSOrgUnit{Name: values[0]})
+				}
+			}
+
+			if card.Organizations == nil {
+				card.Organizations = map[string]*JSOrganization{}
+			}
+			card.Organizations[jsID("org", len(card.Organizations))] = org
+		case "title", "role":
+			if card.Titles == nil {
+				card.Titles = map[string]*JSTitle{}
+			}
+			card.Titles[jsID("title", len(card.Titles))] = &JSTitle{
+				Name: p.firstValue(),
+				Kind: p.Name,
+			}
+		case "email":
+			if card.Emails == nil {
+				card.Emails = map[string]*JSEmailAddress{}
+			}
+			card.Emails[jsID("email", len(card.Emails))] = &JSEmailAddress{
+				Address:  p.firstValue(),
+				Contexts: p.jsContexts(),
+				Pref:     p.pref(),
+			}
+		case "tel":
+			if card.Phones == nil {
+				card.Phones = map[string]*JSPhone{}
+			}
+			card.Phones[jsID("phone", len(card.Phones))] = &JSPhone{
+				Number:   p.firstValue(),
+				Features: p.jsFeatures(),
+				Contexts: p.jsContexts(),
+				Pref:     p.pref(),
+			}
+		case "adr":
+			address := &JSAddress{
+				Contexts: p.jsContexts(),
+				Pref:     p.pref(),
+			}
+
+			if label, ok := p.Parameters["label"]; ok && len(label) > 0 {
+				address.Full = label[0]
+			}
+
+			if cc, ok := p.Parameters["cc"]; ok && len(cc) > 0 {
+				address.CountryCode = cc[0]
+			}
+
+			for i, values := range p.components(len(jsAddressKinds)) {
+				for _, value := range values {
+					if value != "" {
+						address.Components = append(address.Components, &JSAddressComponent{
+							Kind:  jsAddressKinds[i],
+							Value: value,
+						})
+					}
+				}
+			}
+
+			if card.Addresses == nil {
+				card.Addresses = map[string]*JSAddress{}
+			}
+			card.Addresses[jsID("addr", len(card.Addresses))] = address
+		case "url":
+			if card.Links == nil {
+				card.Links = map[string]*JSLink{}
+			}
+			card.Links[jsID("link", len(card.Links))] = &JSLink{
+				URI:      p.firstValue(),
+				Contexts: p.jsContexts(),
+				Pref:     p.pref(),
+			}
+		default:
+			card.VCardProps = append(card.VCardProps, p.jCard(v.encodings[p]))
+		}
+	}
+
+	return card
+}
+
+// VCard converts the JSContact Card to a VCard.
+//
+// The properties are created in a fixed order: version, uid, kind, fn, n, org,
+// title/role, email, tel, adr, url, then any VCardProps.
+func (c *JSCard) VCard() *VCard {
+	v := &VCard{
+		encodings: make(map[*VCardProperty]*jCardEncoding),
+	}
+
+	add := func(name string, parameters map[string][]string, value interface{}) {
+		if parameters == nil {
+			parameters = map[string][]string{}
+		}
+
+		propertyType := "text"
+		switch name {
+		case "tel", "url", "uid":
+			propertyType = "uri"
+		}
+
+		v.Properties = append(v.Properties, &VCardProperty{
+			Name:       name,
+			Parameters: parameters,
+			Type:       propertyType,
+			Value:      value,
+		})
+	}
+
+	add("version", nil, "4.0")
+
+	if c.UID != "" {
+		add("uid", nil, c.UID)
+	}
+
+	if c.Kind != "" {
+		add("kind", nil, c.Kind)
+	}
+
+	if c.Name != nil {
+		if c.Name.Full != "" {
+			add("fn", nil, c.Name.Full)
+		}
+
+		if len(c.Name.Components) > 0 {
+			components := make([][]string, len(jsNameKinds))
+			for _, nc := range c.Name.Components {
+				if i := indexOf(jsNameKinds, nc.Kind); i != -1 {
+					components[i] = append(components[i], nc.Value)
+				}
+			}
+
+			add("n", nil, jCardComponents(components))
+		}
+	}
+
+	for _, id := range sortedKeys(c.Organizations) {
+		org := c.Organizations[id]
+
+		value := []interface{}{org.Name}
+		for _, unit := range org.Units {
+			value = append(value, unit.Name)
+		}
+
+		if len(value) == 1 {
+			add("org", nil, org.Name)
+		} else {
+			add("org", nil, value)
+		}
+	}
+
+	for _, id := range sortedKeys(c.Titles) {
+		title := c.Titles[id]
+
+		name := "title"
+		if title.Kind == "role" {
+			name = "role"
+		}
+
+		add(name, nil, title.Name)
+	}
+
+	for _, id := range sortedKeys(c.Emails) {
+		email := c.Emails[id]
+		add("email", vCardParameters(email.Contexts, nil, email.Pref), email.Address)
+	}
+
+	for _, id := range sortedKeys(c.Phones) {
+		phone := c.Phones[id]
+		add("tel", vCardParameters(phone.Contexts, phone.Features, phone.Pref), phone.Number)
+	}
+
+	for _, id := range sortedKeys(c.Addresses) {
+		address := c.Addresses[id]
+
+		parameters := vCardParameters(address.Contexts, nil, address.Pref)
+		if address.Full != "" {
+			parameters["label"] = []string{address.Full}
+		}
+		if address.CountryCode != "" {
+			parameters["cc"] = []string{address.CountryCode}
+		}
+
+		components := make([][]string, len(jsAddressKinds))
+		for _, ac := range address.Components {
+			if i := indexOf(jsAddressKinds, ac.Kind); i != -1 {
+				components[i] = append(components[i], ac.Value)
+			}
+		}
+
+		add("adr", parameters, jCardComponents(components))
+	}
+
+	for _, id := range sortedKeys(c.Links) {
+		link := c.Links[id]
+		add("url", vCardParameters(link.Contexts, nil, link.Pref), link.URI)
+	}
+
+	for _, jcard := range c.VCardProps {
+		property, encoding, err := decodeVCardProperty(jcard)
+		if err == nil {
+			v.Properties = append(v.Properties, property)
+			v.encodings[property] = encoding
+		}
+	}
+
+	return v
+}
+
+func (c *JSCard) ensureName() *JSName {
+	if c.Name == nil {
+		c.Name = &JSName{}
+	}
+
+	return c.Name
+}
+
+// firstValue returns the first of the property's flattened values.
+func (p *VCardProperty) firstValue() string {
+	values := p.Values()
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// components splits a structured property value (e.g. "n", "adr") into its
+// components, each of which may have multiple values.
+//
+// If |size| is non-zero, the result is padded/truncated to |size| components.
+func (p *VCardProperty) components(size int) [][]string {
+	result := p.StructuredValues()
+
+	if size > 0 {
+		for len(result) < size {
+			result = append(result, nil)
+		}
+		result = result[0:size]
+	}
+
+	return result
+}
+
+// pref returns the property's PREF parameter, or 0 if absent/invalid.
+func (p *VCardProperty) pref() int {
+	if prefs, ok := p.Parameters["pref"]; ok && len(prefs) > 0 {
+		if pref, err := strconv.Atoi(prefs[0]); err == nil {
+			return pref
+		}
+	}
+
+	return 0
+}
+
+// jsContexts returns the JSContact contexts for the property's TYPE parameter.
+func (p *VCardProperty) jsContexts() map[string]bool {
+	var contexts map[string]bool
+
+	for _, t := range p.Parameters["type"] {
+		var context string
+
+		switch t {
+		case "work":
+			context = "work"
+		case "home":
+			context = "private"
+		default:
+			continue
+		}
+
+		if contexts == nil {
+			contexts = map[string]bool{}
+		}
+		contexts[context] = true
+	}
+
+	return contexts
+}
+
+// jsFeatures returns the JSContact phone features for the property's TYPE
+// parameter.
+func (p *VCardProperty) jsFeatures() map[string]bool {
+	var features map[string]bool
+
+	for _, t := range p.Parameters["type"] {
+		var feature string
+
+		switch t {
+		case "cell":
+			feature = "mobile"
+		case "voice", "text", "video", "textphone", "fax", "pager":
+			feature = t
+		default:
+			continue
+		}
+
+		if features == nil {
+			features = map[string]bool{}
+		}
+		features[feature] = true
+	}
+
+	return features
+}
+
+// vCardParameters returns vCard parameters for JSContact |contexts|,
+// |features| and |pref|.
+func vCardParameters(contexts map[string]bool, features map[string]bool, pref int) map[string][]string {
+	parameters := map[string][]string{}
+
+	for _, context := range sortedKeys(contexts) {
+		if !contexts[context] {
+			continue
+		}
+
+		switch context {
+		case "work":
+			parameters["type"] = append(parameters["type"], "work")
+		case "private":
+			parameters["type"] = append(parameters["type"], "home")
+		}
+	}
+
+	for _, feature := range sortedKeys(features) {
+		if !features[feature] {
+			continue
+		}
+
+		switch feature {
+		case "mobile":
+			parameters["type"] = append(parameters["type"], "cell")
+		case "voice", "text", "video", "textphone", "fax", "pager":
+			parameters["type"] = append(parameters["type"], feature)
+		}
+	}
+
+	if pref > 0 {
+		parameters["pref"] = []string{strconv.Itoa(pref)}
+	}
+
+	return parameters
+}
+
+// jCardComponents converts |components| to a jCard structured value.
+func jCardComponents(components [][]string) []interface{} {
+	result := make([]interface{}, 0, len(components))
+
+	for _, values := range components {
+		switch len(values) {
+		case 0:
+			result = append(result, "")
+		case 1:
+			result = append(result, values[0])
+		default:
+			a := make([]interface{}, 0, len(values))
+			for _, value := range values {
+				a = append(a, value)
+			}
+			result = append(result, a)
+		}
+	}
+
+	return result
+}
+
+// jsID returns a JSContact object ID, e.g. "email1".
+func jsID(prefix string, count int) string {
+	return prefix + strconv.Itoa(count+1)
+}
+
+// sortedKeys returns the keys of |m|, sorted by length then lexically (so
+// "email2" sorts before "email10").
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
+
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// JSContactConformance is the rdapConformance identifier of the RDAP
+// JSContact extension, which returns entity contacts as JSContact Cards.
+const JSContactConformance = "jscontact"
+
+// jsContactMembers are the entity members holding a JSContact Card: the
+// extension's jscontact_card, and the jscard of earlier drafts.
+var jsContactMembers = []string{"jscontact_card", "jscard"}
+
+func init() {
+	RegisterExtension(Extension{
+		Name:        JSContactConformance,
+		Conformance: JSContactConformance,
+		Members:     jsContactMembers,
+		Decode:      decodeJSContact,
+		Print:       printJSContact,
+	})
+}
+
+func decodeJSContact(src map[string]interface{}) (interface{}, error) {
+	for _, member := range jsContactMembers {
+		v, ok := src[member]
+		if !ok {
+			continue
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		card, err := NewJSCard(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", member, err)
+		}
+
+		return card, nil
+	}
+
+	return nil, nil
+}
+
+func printJSContact(value interface{}, p *ExtensionPrinter) {
+	v := value.(*JSCard).VCard()
+
+	h := p.Heading("JSContact")
+	h.Value("Name", v.Name())
+	h.Value("Organization", v.Org())
+	h.Value("Email", v.Email())
+	h.Value("Phone", v.Tel())
+	h.Value("Address", v.StreetAddress())
+	h.Value("City", v.Locality())
+	h.Value("Country", v.Country())
+}
+
+// JSCard returns the entity's JSContact Card (JSContact extension), or nil if
+// it has none.
+func (e *Entity) JSCard() *JSCard {
+	if e.DecodeData == nil {
+		return nil
+	}
+
+	card, _ := e.DecodeData.Extension(JSContactConformance).(*JSCard)
+
+	return card
+}
+
+// ContactVCard returns the entity's contact information as a VCard, whichever
+// representation the server used: the vcardArray, or else the JSContact Card
+// converted to a VCard (see JSCard.VCard()). Returns nil if there's neither.
+func (e *Entity) ContactVCard() *VCard {
+	if e.VCard != nil {
+		return e.VCard
+	}
+
+	if card := e.JSCard(); card != nil {
+		return card.VCard()
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestVCardToJSCard(t *testing.T) {
+	j, err := NewVCard(test.LoadFile("jcard/example.json"))
+	if err != nil {
+		t.Fatalf("jCard parse failed %s\n", err)
+	}
+
+	card := j.JSCard()
+
+	if card.Type != "Card" || card.Version != "1.0" {
+		t.Errorf("Bad @type/version %s/%s", card.Type, card.Version)
+	}
+
+	if card.Name == nil || card.Name.Full != "Simon Perreault" {
+		t.Fatalf("Bad name %v", card.Name)
+	}
+
+	expectedComponents := []*JSNameComponent{
+		{Kind: "surname", Value: "Perreault"},
+		{Kind: "given", Value: "Simon"},
+		{Kind: "credential", Value: "ing. jr"},
+		{Kind: "credential", Value: "M.Sc."},
+	}
+
+	if !reflect.DeepEqual(card.Name.Components, expectedComponents) {
+		t.Errorf("Bad name components %v", card.Name.Components)
+	}
+
+	expectedPhone := &JSPhone{
+		Number:   "tel:+1-418-656-9254;ext=102",
+		Features: map[string]bool{"voice": true},
+		Contexts: map[string]bool{"work": true},
+		Pref:     1,
+	}
+
+	if !reflect.DeepEqual(card.Phones["phone1"], expectedPhone) {
+		t.Errorf("Bad phone %v", card.Phones["phone1"])
+	}
+
+	if !card.Phones["phone2"].Features["mobile"] {
+		t.Errorf("Expected cell phone to have mobile feature")
+	}
+
+	if card.Emails["email1"].Address != "simon.perreault@viagenie.ca" {
+		t.Errorf("Bad email %v", card.Emails["email1"])
+	}
+
+	if card.Organizations["org1"].Name != "Viagenie" {
+		t.Errorf("Bad organization %v", card.Organizations["org1"])
+	}
+
+	if card.Links["link1"].URI != "http://nomis80.org" || !card.Links["link1"].Contexts["private"] {
+		t.Errorf("Bad link %v", card.Links["link1"])
+	}
+
+	// bday, anniversary, gender, lang x2, geo, key, tz.
+	if len(card.VCardProps) != 8 {
+		t.Errorf("Got %d vCardProps, expected 8", len(card.VCardProps))
+	}
+}
+
+func TestJSCardRoundTrip(t *testing.T) {
+	j, err := NewVCard(test.LoadFile("jcard/example.json"))
+	if err != nil {
+		t.Fatalf("jCard parse failed %s\n", err)
+	}
+
+	jsonBlob, err := json.Marshal(j.JSCard())
+	if err != nil {
+		t.Fatalf("JSCard marshal failed %s\n", err)
+	}
+
+	card, err := NewJSCard(jsonBlob)
+	if err != nil {
+		t.Fatalf("JSCard parse failed %s\n", err)
+	}
+
+	j2 := card.VCard()
+
+	if len(j2.Properties) != len(j.Properties) {
+		t.Errorf("Got %d properties, expected %d", len(j2.Properties), len(j.Properties))
+	}
+
+	got := []string{j2.Name(), j2.StreetAddress(), j2.Locality(), j2.Country(), j2.Tel(), j2.Email(), j2.Org()}
+	expected := []string{j.Name(), j.StreetAddress(), j.Locality(), j.Country(), j.Tel(), j.Email(), j.Org()}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v expected %v\n", got, expected)
+	}
+
+	if !reflect.DeepEqual(j2.GetFirst("n").Values(), j.GetFirst("n").Values()) {
+		t.Errorf("Bad n %v", j2.GetFirst("n").Values())
+	}
+
+	if !reflect.DeepEqual(j2.GetFirst("bday"), j.GetFirst("bday")) {
+		t.Errorf("Bad bday %v", j2.GetFirst("bday"))
+	}
+}
+
+func TestVCardToJSCardEmptyOrg(t *testing.T) {
+	j, err := NewVCard([]byte(`["vcard", [
+		["version", {}, "text", "4.0"],
+		["org", {}, "text", ["ACME", []]],
+		["org", {}, "text", [[], "Sales"]]
+	]]`))
+	if err != nil {
+		t.Fatalf("jCard parse failed %s\n", err)
+	}
+
+	card := j.JSCard()
+
+	expected := map[string]*JSOrganization{
+		"org1": {Name: "ACME"},
+		"org2": {Units: []*JSOrgUnit{{Name: "Sales"}}},
+	}
+
+	if !reflect.DeepEqual(card.Organizations, expected) {
+		t.Errorf("Bad organizations %v", card.Organizations)
+	}
+}
+
+func TestNewJSCardBadType(t *testing.T) {
+	_, err := NewJSCard([]byte(`{"@type": "Group", "version": "1.0"}`))
+
+	if err == nil {
+		t.Errorf("Unexpected success")
+	}
+}
+
+func TestEntityJSContact(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"rdapConformance": ["rdap_level_0", "jscontact"],
+		"ldhName": "example.cz",
+		"entities": [
+			{
+				"objectClassName": "entity",
+				"handle": "REG-1",
+				"roles": ["registrant"],
+				"jscontact_card": {
+					"@type": "Card",
+					"version": "1.0",
+					"name": {"full": "Joe User"},
+					"organizations": {"org": {"name": "Example Inc."}},
+					"emails": {"email": {"address": "joe.user@example.cz"}},
+					"addresses": {"addr": {"countryCode": "CZ", "components": [{"kind": "locality", "value": "Prague"}]}}
+				}
+			},
+			{
+				"objectClassName": "entity",
+				"handle": "TECH-1",
+				"roles": ["technical"],
+				"vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "Tech Person"]]],
+				"jscard": {"@type": "Card", "version": "1.0", "name": {"full": "Other Name"}}
+			},
+			{
+				"objectClassName": "entity",
+				"handle": "BAD-1",
+				"roles": ["billing"],
+				"jscard": {"@type": "NotACard"}
+			}
+		]
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := obj.(*Domain)
+
+	c := NewContact(&d.Entities[0])
+	if c.Name != "Joe User" || c.Org != "Example Inc." || c.Email != "joe.user@example.cz" || c.Locality != "Prague" {
+		t.Errorf("Unexpected JSContact contact %+v", c)
+	}
+
+	if d.Entities[1].JSCard() == nil || NewContact(&d.Entities[1]).Name != "Tech Person" {
+		t.Errorf("vCard not preferred over JSContact")
+	}
+
+	if d.Entities[2].JSCard() != nil || d.Entities[2].ContactVCard() != nil ||
+		len(d.Entities[2].DecodeData.Notes("jscontact")) != 1 {
+		t.Errorf("Unexpected invalid JSContact result, notes %v", d.Entities[2].DecodeData.Notes("jscontact"))
+	}
+
+	for i := range d.Entities {
+		if unknown := d.Entities[i].DecodeData.UnknownFields(); len(unknown) != 0 {
+			t.Errorf("Unexpected unknown fields %v", unknown)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	_ "embed"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Regenerate the embedded registry from iana.org with "go generate".
+//
+//go:generate go run jsonvalues_gen.go
+
+//go:embed jsonvalues/rdap-json-values.xml
+var jsonValuesSnapshot []byte
+
+// JSONValuesURL is the URL of the IANA "RDAP JSON Values" registry, in XML
+// format.
+const JSONValuesURL = "https://www.iana.org/assignments/rdap-json-values/rdap-json-values.xml"
+
+// Types of value in the IANA "RDAP JSON Values" registry (RFC 9083 section
+// 10.2).
+const (
+	JSONValueNoticeType      = "notice or remark type"
+	JSONValueStatus          = "status"
+	JSONValueRole            = "role"
+	JSONValueEventAction     = "event action"
+	JSONValueVariantRelation = "domain variant relation"
+)
+
+// JSONValues is a copy of the IANA "RDAP JSON Values" registry, for validating
+// the status values, roles, event actions, notice and remark types, and
+// variant relations in RDAP responses. See ValidateJSONValues().
+//
+// https://www.iana.org/assignments/rdap-json-values/
+type JSONValues struct {
+	// Values, keyed by type (e.g. JSONValueStatus), then lowercased value.
+	values map[string]map[string]bool
+}
+
+var (
+	defaultJSONValuesOnce sync.Once
+	defaultJSONValues     *JSONValues
+)
+
+// DefaultJSONValues returns the compiled-in copy of the registry. Being
+// compiled in, it can be out of date, see FetchJSONValues().
+func DefaultJSONValues() *JSONValues {
+	defaultJSONValuesOnce.Do(func() {
+		var err error
+		defaultJSONValues, err = ParseJSONValues(jsonValuesSnapshot)
+		if err != nil {
+			panic("rdap: invalid embedded RDAP JSON Values registry: " + err.Error())
+		}
+	})
+
+	return defaultJSONValues
+}
+
+// ParseJSONValues parses the registry |data|, in IANA's XML format.
+func ParseJSONValues(data []byte) (*JSONValues, error) {
+	var registry struct {
+		Records []struct {
+			Value string `xml:"value"`
+			Type  string `xml:"type"`
+		} `xml:"registry>record"`
+	}
+
+	if err := xml.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("invalid RDAP JSON Values registry: %s", err)
+	} else if len(registry.Records) == 0 {
+		return nil, fmt.Errorf("invalid RDAP JSON Values registry: no records")
+	}
+
+	v := &JSONValues{
+		values: map[string]map[string]bool{},
+	}
+
+	for _, r := range registry.Records {
+		t := strings.ToLower(strings.TrimSpace(r.Type))
+		if v.values[t] == nil {
+			v.values[t] = map[string]bool{}
+		}
+
+		v.values[t][strings.ToLower(strings.TrimSpace(r.Value))] = true
+	}
+
+	return v, nil
+}
+
+// FetchJSONValues downloads the current registry from IANA (JSONValuesURL),
+// using the HTTP client |client| (nil for http.DefaultClient).
+func FetchJSONValues(ctx context.Context, client *http.Client) (*JSONValues, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", JSONValuesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RDAP JSON Values registry download failed: %s", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJSONValues(data)
+}
+
+// Has returns true if |value| is registered with the type |valueType| (e.g.
+// JSONValueRole). The comparison is case insensitive.
+func (v *JSONValues) Has(valueType string, value string) bool {
+	return v.values[valueType][strings.ToLower(value)]
+}
+
+// Values returns the number of values registered with the type |valueType|.
+func (v *JSONValues) Values(valueType string) int {
+	return len(v.values[valueType])
+}
+
+// ValidateJSONValues checks the status values, roles, event actions, notice
+// and remark types, and variant relations of the RDAP object |obj| (and the
+// objects embedded in it) against the registry |values| (nil for
+// DefaultJSONValues()).
+//
+// Returns a list of the unregistered values found, each prefixed by its JSON
+// path, e.g. `$.entities[0].roles[1]: unregistered role "owner"`. Returns nil
+// if there are none.
+func ValidateJSONValues(obj RDAPObject, values *JSONValues) []string {
+	if values == nil {
+		values = DefaultJSONValues()
+	}
+
+	c := &jsonValuesChecker{values: values}
+	c.checkObject("$", obj)
+
+	return c.problems
+}
+
+type jsonValuesChecker struct {
+	values   *JSONValues
+	problems []string
+}
+
+func (c *jsonValuesChecker) check(path string, valueType string, value string) {
+	if !c.values.Has(valueType, value) {
+		c.problems = append(c.problems, fmt.Sprintf("%s: unregistered %s %q", path, valueType, value))
+	}
+}
+
+func (c *jsonValuesChecker) checkStatus(path string, status []string) {
+	for i, s := range status {
+		c.check(fmt.Sprintf("%s.status[%d]", path, i), JSONValueStatus, s)
+	}
+}
+
+func (c *jsonValuesChecker) checkEvents(path string, member string, events []Event) {
+	for i, e := range events {
+		c.check(fmt.Sprintf("%s.%s[%d].eventAction", path, member, i), JSONValueEventAction, e.Action)
+	}
+}
+
+func (c *jsonValuesChecker) checkNotices(path string, notices []Notice) {
+	for i, n := range notices {
+		if n.Type != "" {
+			c.check(fmt.Sprintf("%s.notices[%d].type", path, i), JSONValueNoticeType, n.Type)
+		}
+	}
+}
+
+func (c *jsonValuesChecker) checkRemarks(path string, remarks []Remark) {
+	for i, r := range remarks {
+		if r.Type != "" {
+			c.check(fmt.Sprintf("%s.remarks[%d].type", path, i), JSONValueNoticeType, r.Type)
+		}
+	}
+}
+
+func (c *jsonValuesChecker) checkEntities(path string, entities []Entity) {
+	for i := range entities {
+		c.checkObject(fmt.Sprintf("%s.entities[%d]", path, i), &entities[i])
+	}
+}
+
+func (c *jsonValuesChecker) checkObject(path string, obj RDAPObject) {
+	switch v := obj.(type) {
+	case *Domain:
+		c.checkNotices(path, v.Notices)
+		c.checkRemarks(path, v.Remarks)
+		c.checkStatus(path, v.Status)
+		c.checkEvents(path, "events", v.Events)
+		c.checkEntities(path, v.Entities)
+
+		for i, variant := range v.Variants {
+			for j, relation := range variant.Relation {
+				c.check(fmt.Sprintf("%s.variants[%d].relation[%d]", path, i, j), JSONValueVariantRelation, relation)
+			}
+		}
+
+		for i := range v.Nameservers {
+			c.checkObject(fmt.Sprintf("%s.nameservers[%d]", path, i), &v.Nameservers[i])
+		}
+
+		if v.Network != nil {
+			c.checkObject(path+".network", v.Network)
+		}
+	case *Entity:
+		c.checkNotices(path, v.Notices)
+		c.checkRemarks(path, v.Remarks)
+		c.checkStatus(path, v.Status)
+		c.checkEvents(path, "events", v.Events)
+		c.checkEvents(path, "asEventActor", v.AsEventActor)
+		c.checkEntities(path, v.Entities)
+
+		for i, role := range v.Roles {
+			c.check(fmt.Sprintf("%s.roles[%d]", path, i), JSONValueRole, role)
+		}
+
+		for i := range v.Networks {
+			c.checkObject(fmt.Sprintf("%s.networks[%d]", path, i), &v.Networks[i])
+		}
+
+		for i := range v.Autnums {
+			c.checkObject(fmt.Sprintf("%s.autnums[%d]", path, i), &v.Autnums[i])
+		}
+	case *Nameserver:
+		c.checkNotices(path, v.Notices)
+		c.checkRemarks(path, v.Remarks)
+		c.checkStatus(path, v.Status)
+		c.checkEvents(path, "events", v.Events)
+		c.checkEntities(path, v.Entities)
+	case *IPNetwork:
+		c.checkNotices(path, v.Notices)
+		c.checkRemarks(path, v.Remarks)
+		c.checkStatus(path, v.Status)
+		c.checkEvents(path, "events", v.Events)
+		c.checkEntities(path, v.Entities)
+	case *Autnum:
+		c.checkNotices(path, v.Notices)
+		c.checkRemarks(path, v.Remarks)
+		c.checkStatus(path, v.Status)
+		c.checkEvents(path, "events", v.Events)
+		c.checkEntities(path, v.Entities)
+	case *DomainSearchResults:
+		c.checkNotices(path, v.Notices)
+		for i := range v.Domains {
+			c.checkObject(fmt.Sprintf("%s.domainSearchResults[%d]", path, i), &v.Domains[i])
+		}
+	case *NameserverSearchResults:
+		c.checkNotices(path, v.Notices)
+		for i := range v.Nameservers {
+			c.checkObject(fmt.Sprintf("%s.nameserverSearchResults[%d]", path, i), &v.Nameservers[i])
+		}
+	case *EntitySearchResults:
+		c.checkNotices(path, v.Notices)
+		for i := range v.Entities {
+			c.checkObject(fmt.Sprintf("%s.entitySearchResults[%d]", path, i), &v.Entities[i])
+		}
+	case *Help:
+		c.checkNotices(path, v.Notices)
+	case *Error:
+		c.checkNotices(path, v.Notices)
+	}
+}
@@ -0,0 +1,63 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+//go:build ignore
+
+// jsonvalues_gen downloads the IANA RDAP JSON Values registry into
+// jsonvalues/, for embedding as the default registry.
+//
+// Run using "go generate" in the rdap directory.
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const registryURL = "https://www.iana.org/assignments/rdap-json-values/rdap-json-values.xml"
+
+func main() {
+	resp, err := http.Get(registryURL)
+	if err != nil {
+		fail(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		fail(fmt.Errorf("server returned %s", resp.Status))
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		fail(err)
+	}
+
+	// Sanity check, don't embed an error page.
+	var registry struct {
+		Records []struct {
+			Value string `xml:"value"`
+		} `xml:"registry>record"`
+	}
+
+	if err := xml.Unmarshal(data, &registry); err != nil {
+		fail(err)
+	} else if len(registry.Records) == 0 {
+		fail(fmt.Errorf("no records"))
+	}
+
+	if err := ioutil.WriteFile(filepath.Join("jsonvalues", "rdap-json-values.xml"), data, 0644); err != nil {
+		fail(err)
+	}
+
+	fmt.Printf("jsonvalues_gen: Downloaded %d records\n", len(registry.Records))
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "jsonvalues_gen: %s\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,106 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestDefaultJSONValues(t *testing.T) {
+	v := DefaultJSONValues()
+
+	counts := map[string]int{
+		JSONValueNoticeType:      7,
+		JSONValueStatus:          34,
+		JSONValueRole:            11,
+		JSONValueEventAction:     12,
+		JSONValueVariantRelation: 5,
+	}
+
+	for valueType, count := range counts {
+		if n := v.Values(valueType); n != count {
+			t.Errorf("%s: %d values, expected %d", valueType, n, count)
+		}
+	}
+
+	if !v.Has(JSONValueStatus, "Client Hold") || v.Has(JSONValueStatus, "clientHold") || v.Has(JSONValueRole, "active") {
+		t.Errorf("Unexpected Has() result")
+	}
+
+	if _, err := ParseJSONValues([]byte("<html>Not Found</html>")); err == nil {
+		t.Errorf("Unexpected success parsing a non-registry")
+	}
+}
+
+func TestValidateJSONValues(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.registry.example/domain-example.example.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if problems := ValidateJSONValues(obj, nil); problems != nil {
+		t.Errorf("Unexpected problems %v", problems)
+	}
+
+	obj, err = NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.cz",
+		"status": ["active", "clientHold"],
+		"events": [{"eventAction": "registration", "eventDate": "2001-02-03T04:05:06Z"}, {"eventAction": "renewed"}],
+		"remarks": [{"type": "private remark type"}],
+		"variants": [{"relation": ["registered", "parked"]}],
+		"entities": [{"objectClassName": "entity", "roles": ["registrant", "owner"]}]
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`$.remarks[0].type: unregistered notice or remark type "private remark type"`,
+		`$.status[1]: unregistered status "clientHold"`,
+		`$.events[1].eventAction: unregistered event action "renewed"`,
+		`$.entities[0].roles[1]: unregistered role "owner"`,
+		`$.variants[0].relation[1]: unregistered domain variant relation "parked"`,
+	}
+
+	if problems := ValidateJSONValues(obj, nil); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Unexpected problems %q", problems)
+	}
+}
+
+type staticTransport []byte
+
+func (s staticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(bytes.NewReader(s)),
+		Request:    req,
+	}, nil
+}
+
+func TestFetchJSONValues(t *testing.T) {
+	registry := []byte(`<?xml version='1.0' encoding='UTF-8'?>
+<registry xmlns="http://www.iana.org/assignments" id="rdap-json-values">
+  <registry id="rdap-json-values-1">
+    <record><value>colourful</value><type>status</type></record>
+  </registry>
+</registry>`)
+
+	v, err := FetchJSONValues(context.Background(), &http.Client{Transport: staticTransport(registry)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !v.Has(JSONValueStatus, "colourful") || v.Has(JSONValueStatus, "active") {
+		t.Errorf("Unexpected values")
+	}
+}
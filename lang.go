@@ -0,0 +1,105 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import "strings"
+
+// Language returns the language of the notice: its own lang member, or else
+// |defaultLang|, the language of the object containing it.
+func (n Notice) Language(defaultLang string) string {
+	if n.Lang != "" {
+		return n.Lang
+	}
+
+	return defaultLang
+}
+
+// Language returns the language of the remark: its own lang member, or else
+// |defaultLang|, the language of the object containing it.
+func (r Remark) Language(defaultLang string) string {
+	if r.Lang != "" {
+		return r.Lang
+	}
+
+	return defaultLang
+}
+
+// SelectNotices returns the |notices| in the language |lang| (e.g. "en"), for
+// servers which return each notice in several languages. |defaultLang| is the
+// language of notices without a lang member, normally the lang of the object
+// containing them.
+//
+// Language tags match if either is a prefix of the other, e.g. "en" matches
+// "en-GB". Notices with no language are always kept. If no notice is in the
+// language |lang|, or |lang| is empty, all of the |notices| are returned.
+func SelectNotices(notices []Notice, lang string, defaultLang string) []Notice {
+	return selectLanguage(notices, func(n Notice) string { return n.Language(defaultLang) }, lang)
+}
+
+// SelectRemarks returns the |remarks| in the language |lang|. See
+// SelectNotices().
+func SelectRemarks(remarks []Remark, lang string, defaultLang string) []Remark {
+	return selectLanguage(remarks, func(r Remark) string { return r.Language(defaultLang) }, lang)
+}
+
+// Lang returns the language of the response's topmost object (its lang
+// member), or "" if it has none.
+func (r *Response) Lang() string {
+	return stringField(r.Object, "Lang")
+}
+
+// NoticesInLanguage returns the response's notices in the language |lang|
+// (e.g. "en"), or all of its notices if none are in the language. See
+// SelectNotices().
+func (r *Response) NoticesInLanguage(lang string) []Notice {
+	return SelectNotices(noticesOf(r.Object), lang, r.Lang())
+}
+
+// RemarksInLanguage returns the remarks of the response's topmost object in the
+// language |lang| (e.g. "en"), or all of its remarks if none are in the
+// language. See SelectNotices().
+func (r *Response) RemarksInLanguage(lang string) []Remark {
+	f := structField(r.Object, "Remarks")
+	if !f.IsValid() {
+		return nil
+	}
+
+	remarks, _ := f.Interface().([]Remark)
+
+	return SelectRemarks(remarks, lang, r.Lang())
+}
+
+// selectLanguage returns the |items| in the language |lang|, and those with no
+// language, or all |items| if none are in the language.
+func selectLanguage[T any](items []T, languageOf func(T) string, lang string) []T {
+	if lang == "" {
+		return items
+	}
+
+	var result []T
+	var found bool
+	for _, item := range items {
+		itemLang := languageOf(item)
+
+		if itemLang == "" {
+			result = append(result, item)
+		} else if languageMatches(lang, itemLang) {
+			result = append(result, item)
+			found = true
+		}
+	}
+
+	if !found {
+		return items
+	}
+
+	return result
+}
+
+// languageMatches returns true if the language tags |a| and |b| are equal, or
+// either is a prefix of the other.
+func languageMatches(a string, b string) bool {
+	return strings.EqualFold(a, b) || isLanguagePrefix(a, b) || isLanguagePrefix(b, a)
+}
@@ -0,0 +1,79 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResponseLanguage(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"rdapConformance": ["rdap_level_0"],
+		"objectClassName": "domain",
+		"ldhName": "example.ca",
+		"lang": "fr-CA",
+		"notices": [
+			{"title": "Conditions d'utilisation", "description": ["Français"]},
+			{"title": "Terms of Use", "lang": "en", "description": ["English"]}
+		],
+		"remarks": [
+			{"title": "Remarque", "lang": "fr", "description": ["Français"]},
+			{"title": "Remark", "lang": "en-CA", "description": ["English"]}
+		]
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Response{Object: obj}
+
+	if r.Lang() != "fr-CA" {
+		t.Errorf("Lang() = %q", r.Lang())
+	}
+
+	titles := func(notices []Notice) []string {
+		var result []string
+		for _, n := range notices {
+			result = append(result, n.Title)
+		}
+		return result
+	}
+
+	noticeTests := []struct {
+		Lang     string
+		Expected []string
+	}{
+		{"en", []string{"Terms of Use"}},
+		{"EN-GB", []string{"Terms of Use"}},
+		{"fr", []string{"Conditions d'utilisation"}},
+		{"de", []string{"Conditions d'utilisation", "Terms of Use"}},
+		{"", []string{"Conditions d'utilisation", "Terms of Use"}},
+	}
+
+	for _, test := range noticeTests {
+		if got := titles(r.NoticesInLanguage(test.Lang)); !reflect.DeepEqual(got, test.Expected) {
+			t.Errorf("NoticesInLanguage(%q) = %v, expected %v", test.Lang, got, test.Expected)
+		}
+	}
+
+	remarks := r.RemarksInLanguage("en")
+	if len(remarks) != 1 || remarks[0].Title != "Remark" || remarks[0].Language("fr-CA") != "en-CA" {
+		t.Errorf("RemarksInLanguage(en) = %v", remarks)
+	}
+}
+
+func TestSelectNoticesUntagged(t *testing.T) {
+	notices := []Notice{
+		{Title: "Untagged"},
+		{Title: "English", Common: Common{Lang: "en"}},
+		{Title: "Deutsch", Common: Common{Lang: "de"}},
+	}
+
+	got := SelectNotices(notices, "de", "")
+	if len(got) != 2 || got[0].Title != "Untagged" || got[1].Title != "Deutsch" {
+		t.Errorf("SelectNotices(de) = %v", got)
+	}
+}
@@ -0,0 +1,161 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"mime"
+	"strings"
+)
+
+// Link relation types commonly used in RDAP responses, from the IANA "Link
+// Relations" registry.
+//
+// https://www.iana.org/assignments/link-relations/
+const (
+	LinkRelSelf           = "self"
+	LinkRelRelated        = "related"
+	LinkRelAlternate      = "alternate"
+	LinkRelAbout          = "about"
+	LinkRelHelp           = "help"
+	LinkRelGlossary       = "glossary"
+	LinkRelTermsOfService = "terms-of-service"
+	LinkRelCopyright      = "copyright"
+	LinkRelUp             = "up"
+	LinkRelDown           = "down"
+)
+
+// HasRel returns true if the link's relation type is |rel|, e.g. "self". The
+// comparison is case insensitive.
+func (l *Link) HasRel(rel string) bool {
+	return strings.EqualFold(l.Rel, rel)
+}
+
+// HasType returns true if the link's media type is |mediaType|, e.g.
+// "application/rdap+json". Media type parameters and case are ignored.
+func (l *Link) HasType(mediaType string) bool {
+	t, _, err := mime.ParseMediaType(l.Type)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(t, mediaType)
+}
+
+// selfLink returns the first "self" link in |links|, or nil if there's none.
+func selfLink(links []Link) *Link {
+	for i := range links {
+		if links[i].HasRel(LinkRelSelf) {
+			return &links[i]
+		}
+	}
+
+	return nil
+}
+
+// relatedLinks returns the "related" links in |links|.
+func relatedLinks(links []Link) []Link {
+	var result []Link
+
+	for _, l := range links {
+		if l.HasRel(LinkRelRelated) {
+			result = append(result, l)
+		}
+	}
+
+	return result
+}
+
+// alternateLink returns the first "alternate" link in |links| of the media
+// type |mediaType| (any media type if ""), or nil if there's none.
+func alternateLink(links []Link, mediaType string) *Link {
+	for i := range links {
+		if links[i].HasRel(LinkRelAlternate) && (mediaType == "" || links[i].HasType(mediaType)) {
+			return &links[i]
+		}
+	}
+
+	return nil
+}
+
+// Self returns the domain's "self" link, or nil if there's none.
+func (d *Domain) Self() *Link {
+	return selfLink(d.Links)
+}
+
+// Related returns the domain's "related" links, e.g. to the registrar's RDAP
+// server.
+func (d *Domain) Related() []Link {
+	return relatedLinks(d.Links)
+}
+
+// Alternate returns the domain's first "alternate" link of media type
+// |mediaType| (e.g. "text/html", or "" for any), or nil if there's none.
+func (d *Domain) Alternate(mediaType string) *Link {
+	return alternateLink(d.Links, mediaType)
+}
+
+// Self returns the entity's "self" link, or nil if there's none.
+func (e *Entity) Self() *Link {
+	return selfLink(e.Links)
+}
+
+// Related returns the entity's "related" links.
+func (e *Entity) Related() []Link {
+	return relatedLinks(e.Links)
+}
+
+// Alternate returns the entity's first "alternate" link of media type
+// |mediaType| (or "" for any), or nil if there's none.
+func (e *Entity) Alternate(mediaType string) *Link {
+	return alternateLink(e.Links, mediaType)
+}
+
+// Self returns the nameserver's "self" link, or nil if there's none.
+func (n *Nameserver) Self() *Link {
+	return selfLink(n.Links)
+}
+
+// Related returns the nameserver's "related" links.
+func (n *Nameserver) Related() []Link {
+	return relatedLinks(n.Links)
+}
+
+// Alternate returns the nameserver's first "alternate" link of media type
+// |mediaType| (or "" for any), or nil if there's none.
+func (n *Nameserver) Alternate(mediaType string) *Link {
+	return alternateLink(n.Links, mediaType)
+}
+
+// Self returns the autnum's "self" link, or nil if there's none.
+func (a *Autnum) Self() *Link {
+	return selfLink(a.Links)
+}
+
+// Related returns the autnum's "related" links.
+func (a *Autnum) Related() []Link {
+	return relatedLinks(a.Links)
+}
+
+// Alternate returns the autnum's first "alternate" link of media type
+// |mediaType| (or "" for any), or nil if there's none.
+func (a *Autnum) Alternate(mediaType string) *Link {
+	return alternateLink(a.Links, mediaType)
+}
+
+// Self returns the IP network's "self" link, or nil if there's none.
+func (n *IPNetwork) Self() *Link {
+	return selfLink(n.Links)
+}
+
+// Related returns the IP network's "related" links.
+func (n *IPNetwork) Related() []Link {
+	return relatedLinks(n.Links)
+}
+
+// Alternate returns the IP network's first "alternate" link of media type
+// |mediaType| (or "" for any), or nil if there's none.
+func (n *IPNetwork) Alternate(mediaType string) *Link {
+	return alternateLink(n.Links, mediaType)
+}
@@ -0,0 +1,49 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import "testing"
+
+func TestLinkHelpers(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.cz",
+		"links": [
+			{"rel": "Self", "href": "https://rdap.nic.cz/domain/example.cz", "type": "application/rdap+json"},
+			{"rel": "related", "href": "https://rdap.registrar.example/domain/example.cz", "type": "application/rdap+json; charset=utf-8"},
+			{"rel": "related", "href": "https://www.registrar.example/", "type": "text/html"},
+			{"rel": "alternate", "href": "https://www.nic.cz/whois/domain/example.cz", "type": "text/html", "hreflang": ["cs", "en"]}
+		]
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := obj.(*Domain)
+
+	if self := d.Self(); self == nil || self.Href != "https://rdap.nic.cz/domain/example.cz" {
+		t.Errorf("Unexpected self link %+v", self)
+	}
+
+	if related := d.Related(); len(related) != 2 || !related[0].HasType("application/rdap+json") || related[1].HasType("application/rdap+json") {
+		t.Errorf("Unexpected related links %+v", related)
+	}
+
+	if alt := d.Alternate("TEXT/HTML"); alt == nil || len(alt.HrefLang) != 2 {
+		t.Errorf("Unexpected alternate link %+v", alt)
+	}
+
+	if alt := d.Alternate("application/pdf"); alt != nil {
+		t.Errorf("Unexpected alternate link %+v", alt)
+	}
+
+	if link := relatedRDAPLink(d.Links); link == nil || link.Href != "https://rdap.registrar.example/domain/example.cz" {
+		t.Errorf("Unexpected related RDAP link %+v", link)
+	}
+
+	if (&Entity{}).Self() != nil || (&IPNetwork{}).Related() != nil || (&Autnum{}).Alternate("") != nil {
+		t.Errorf("Unexpected links for empty objects")
+	}
+}
@@ -0,0 +1,61 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Structured log messages, see Client.Logger.
+//
+// Each message includes attributes describing the event, e.g. "url",
+// "status", "duration", and "error".
+const (
+	// Bootstrap lookup finished (Debug). Attributes: registry, query,
+	// servers, duration, error.
+	LogBootstrap = "rdap bootstrap"
+
+	// RDAP server selected for a query (Debug). Attributes: query_type,
+	// query, url.
+	LogServer = "rdap server"
+
+	// HTTP request finished (Debug). Attributes: url, attempt, status,
+	// cached, duration, error.
+	LogHTTPAttempt = "rdap http attempt"
+
+	// HTTP request to be retried (Info). Attributes: url, attempt, delay.
+	LogRetry = "rdap retry"
+
+	// HTTP redirect followed (Debug). Attributes: from, to.
+	LogRedirect = "rdap redirect"
+
+	// Response decoded with warnings (Warn). Attributes: url, notes.
+	LogDecodeWarnings = "rdap decode warnings"
+
+	// Query finished (Info, or Warn on failure). Attributes: query_type,
+	// query, url, duration, error.
+	LogQuery = "rdap query"
+)
+
+// log logs the message |msg| with the attributes |args| to the Client's
+// Logger, if set.
+func (c *Client) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if c.Logger == nil {
+		return
+	}
+
+	c.Logger.Log(ctx, level, msg, args...)
+}
+
+// errorAttr returns the "error" attribute for |err|, which is empty if |err|
+// is nil.
+func errorAttr(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+
+	return slog.String("error", err.Error())
+}
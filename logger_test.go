@@ -0,0 +1,57 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestClientLogger(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	var buf bytes.Buffer
+	client := &Client{
+		Logger:  slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Verbose: verboseFunc(),
+	}
+
+	if _, err := client.QueryDomain("example.cz"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var messages []string
+	var query map[string]interface{}
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Bad log line %q: %s", line, err)
+		}
+
+		msg := record["msg"].(string)
+		messages = append(messages, msg)
+
+		if msg == LogQuery {
+			query = record
+		}
+	}
+
+	expected := []string{LogBootstrap, LogServer, LogHTTPAttempt, LogQuery}
+	if strings.Join(messages, ",") != strings.Join(expected, ",") {
+		t.Errorf("Logged %v, expected %v", messages, expected)
+	}
+
+	if query == nil || query["query_type"] != "domain" || query["url"] != "https://rdap.nic.cz/domain/example.cz" || query["error"] != nil {
+		t.Errorf("Unexpected query log %v", query)
+	}
+}
@@ -0,0 +1,249 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// LookupResult is the result of a Lookup().
+type LookupResult struct {
+	// The query, as passed to Lookup().
+	Query string
+
+	// The RDAP request made. Use Request.Type to find the query type chosen.
+	Request *Request
+
+	// The RDAP response, and decoded object (e.g. *Domain, *IPNetwork).
+	Response *Response
+	Object   RDAPObject
+
+	// Response from the registrar's RDAP server, for domains in thin
+	// registries which refer to it (via a "related" link). nil if no referral
+	// was followed, or if the referral failed.
+	Referral *Response
+
+	// Error encountered while following the referral, if any. A failed referral
+	// does not fail the Lookup().
+	ReferralError error
+
+	// Abuse contact entity, if one was found.
+	//
+	// The referral response is searched first (registrars typically publish
+	// the abuse contact), then the main response.
+	Abuse *Entity
+
+	// Abuse contact email address & telephone number, if known.
+	AbuseEmail string
+	AbusePhone string
+}
+
+// LookupOption sets a Lookup() option.
+type LookupOption func(*lookupOptions)
+
+type lookupOptions struct {
+	client          *Client
+	server          *url.URL
+	followReferrals bool
+}
+
+// WithLookupClient sets the Client used by Lookup().
+//
+// The default is a new Client{}.
+func WithLookupClient(client *Client) LookupOption {
+	return func(o *lookupOptions) {
+		o.client = client
+	}
+}
+
+// WithLookupServer sets the RDAP server used by Lookup(), disabling
+// bootstrapping.
+func WithLookupServer(server *url.URL) LookupOption {
+	return func(o *lookupOptions) {
+		o.server = server
+	}
+}
+
+// WithoutReferrals disables following registrar referrals in Lookup().
+func WithoutReferrals() LookupOption {
+	return func(o *lookupOptions) {
+		o.followReferrals = false
+	}
+}
+
+// Lookup runs an RDAP query for |query|, which can be a domain name, IP
+// address/network, AS number, entity handle, or RDAP URL. The query type is
+// chosen as per NewAutoRequest().
+//
+// This is intended to cover the common case in one call:
+//
+//	result, err := rdap.Lookup(ctx, "example.com")
+//
+//	if err == nil {
+//	  fmt.Printf("Abuse contact: %s\n", result.AbuseEmail)
+//	}
+//
+// Lookup bootstraps the query, queries the RDAP server, follows any registrar
+// referral (see LookupResult.Referral), and finds the abuse contact.
+//
+// |ctx| controls cancellation/timeouts for the whole lookup. |opts| is an
+// optional list of LookupOptions.
+func Lookup(ctx context.Context, query string, opts ...LookupOption) (*LookupResult, error) {
+	o := &lookupOptions{
+		followReferrals: true,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.client == nil {
+		o.client = &Client{}
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, &ClientError{
+			Type: InputError,
+			Text: "empty query",
+		}
+	}
+
+	req := NewAutoRequest(query)
+	if o.server != nil && req.Type != RawRequest {
+		req = req.WithServer(o.server)
+	}
+
+	result := &LookupResult{
+		Query:   query,
+		Request: req,
+	}
+
+	resp, err := o.client.Do(req.WithContext(ctx))
+	result.Response = resp
+
+	if err != nil {
+		return result, err
+	}
+
+	result.Object = resp.Object
+
+	if respError, ok := resp.Object.(*Error); ok {
+		return result, clientErrorFromRDAPError(respError)
+	}
+
+	if o.followReferrals {
+		if resp.Referral != nil || resp.ReferralError != nil {
+			// Already followed by the Client, see Client.FollowReferrals.
+			result.Referral, result.ReferralError = resp.Referral, resp.ReferralError
+		} else if link := relatedRDAPLink(linksOf(resp.Object)); link != nil {
+			result.Referral, result.ReferralError = o.client.followLink(ctx, link)
+		}
+	}
+
+	if result.Referral != nil {
+		result.Abuse = findEntityByRole("abuse", entitiesOf(result.Referral.Object))
+	}
+
+	if result.Abuse == nil {
+		result.Abuse = findEntityByRole("abuse", entitiesOf(resp.Object))
+	}
+
+	if result.Abuse != nil {
+		if v := result.Abuse.ContactVCard(); v != nil {
+			result.AbuseEmail = v.Email()
+			result.AbusePhone = v.Tel()
+		}
+	}
+
+	return result, nil
+}
+
+// followLink runs an RDAP query for the URL in |link|.
+func (c *Client) followLink(ctx context.Context, link *Link) (*Response, error) {
+	return c.queryURL(ctx, link.Href)
+}
+
+// relatedRDAPLink returns the first "related" link to another RDAP resource,
+// or nil if there are none.
+func relatedRDAPLink(links []Link) *Link {
+	var self string
+	if l := selfLink(links); l != nil {
+		self = l.Href
+	}
+
+	for _, l := range relatedLinks(links) {
+		if l.Href != "" && l.Href != self && l.HasType("application/rdap+json") {
+			link := l
+			return &link
+		}
+	}
+
+	return nil
+}
+
+// findEntityByRole returns the first entity with role |role| in |entities|.
+// All of |entities| are checked before their nested entities, which are then
+// searched the same way, one entity at a time. So a top level entity is
+// preferred to a nested one.
+//
+// Returns nil if no entity is found.
+func findEntityByRole(role string, entities []Entity) *Entity {
+	for i := range entities {
+		for _, r := range entities[i].Roles {
+			if r == role {
+				return &entities[i]
+			}
+		}
+	}
+
+	for i := range entities {
+		if e := findEntityByRole(role, entities[i].Entities); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// linksOf returns the top level links of the RDAP object |obj|.
+func linksOf(obj RDAPObject) []Link {
+	switch v := obj.(type) {
+	case *Domain:
+		return v.Links
+	case *Entity:
+		return v.Links
+	case *Nameserver:
+		return v.Links
+	case *Autnum:
+		return v.Links
+	case *IPNetwork:
+		return v.Links
+	default:
+		return nil
+	}
+}
+
+// entitiesOf returns the top level entities of the RDAP object |obj|.
+//
+// For an Entity, the Entity itself is returned.
+func entitiesOf(obj RDAPObject) []Entity {
+	switch v := obj.(type) {
+	case *Domain:
+		return v.Entities
+	case *Entity:
+		return []Entity{*v}
+	case *Nameserver:
+		return v.Entities
+	case *Autnum:
+		return v.Entities
+	case *IPNetwork:
+		return v.Entities
+	default:
+		return nil
+	}
+}
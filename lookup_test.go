@@ -0,0 +1,70 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestLookup(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	result, err := Lookup(context.Background(), "thin.cz", WithLookupClient(client))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if result.Request.Type != DomainRequest {
+		t.Errorf("Unexpected request type %s", result.Request.Type)
+	}
+
+	if d, ok := result.Object.(*Domain); !ok || d.LDHName != "thin.cz" {
+		t.Errorf("Unexpected object %v", result.Object)
+	}
+
+	if result.Referral == nil || result.ReferralError != nil {
+		t.Fatalf("Referral not followed: %v", result.ReferralError)
+	}
+
+	if result.AbuseEmail != "abuse@registrar.example" || result.AbusePhone != "tel:+1.5555551234" {
+		t.Errorf("Unexpected abuse contact %s/%s", result.AbuseEmail, result.AbusePhone)
+	}
+}
+
+func TestLookupWithoutReferrals(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	result, err := Lookup(context.Background(), "thin.cz", WithoutReferrals())
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if result.Referral != nil || result.Abuse != nil {
+		t.Errorf("Unexpected referral")
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	_, err := Lookup(context.Background(), "non-existent.cz")
+
+	if !isClientError(ObjectDoesNotExist, err) {
+		t.Errorf("Unexpected err %s", err)
+	}
+}
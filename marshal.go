@@ -0,0 +1,93 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// MarshalRDAP returns the RDAP JSON encoding of the RDAP object |obj| (e.g. a
+// *Domain), as an RDAP server would return it. This allows proxies, caches,
+// and test fixtures to serve responses built from (or modified in) the typed
+// structs.
+//
+// Fields use their RDAP names, and empty fields are omitted. Decoded members
+// without a struct field (e.g. extension members such as "cidr0_cidrs") are
+// output as decoded, from each object's DecodeData.
+//
+// Unlike MarshalSchema(), the output isn't versioned: it follows the types, as
+// the RDAP specification does.
+func MarshalRDAP(obj RDAPObject) ([]byte, error) {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported object type %T", obj)
+	}
+
+	value, err := schemaValue(v, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		value = map[string]interface{}{}
+	}
+
+	return json.Marshal(value)
+}
+
+// MarshalJSON encodes the Domain as RDAP JSON. See MarshalRDAP().
+func (d *Domain) MarshalJSON() ([]byte, error) {
+	return MarshalRDAP(d)
+}
+
+// MarshalJSON encodes the Entity as RDAP JSON. See MarshalRDAP().
+func (e *Entity) MarshalJSON() ([]byte, error) {
+	return MarshalRDAP(e)
+}
+
+// MarshalJSON encodes the Nameserver as RDAP JSON. See MarshalRDAP().
+func (n *Nameserver) MarshalJSON() ([]byte, error) {
+	return MarshalRDAP(n)
+}
+
+// MarshalJSON encodes the IPNetwork as RDAP JSON. See MarshalRDAP().
+func (n *IPNetwork) MarshalJSON() ([]byte, error) {
+	return MarshalRDAP(n)
+}
+
+// MarshalJSON encodes the Autnum as RDAP JSON. See MarshalRDAP().
+func (a *Autnum) MarshalJSON() ([]byte, error) {
+	return MarshalRDAP(a)
+}
+
+// MarshalJSON encodes the Help as RDAP JSON. See MarshalRDAP().
+func (h *Help) MarshalJSON() ([]byte, error) {
+	return MarshalRDAP(h)
+}
+
+// MarshalJSON encodes the Error as RDAP JSON. See MarshalRDAP().
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return MarshalRDAP(e)
+}
+
+// MarshalJSON encodes the DomainSearchResults as RDAP JSON. See
+// MarshalRDAP().
+func (r *DomainSearchResults) MarshalJSON() ([]byte, error) {
+	return MarshalRDAP(r)
+}
+
+// MarshalJSON encodes the NameserverSearchResults as RDAP JSON. See
+// MarshalRDAP().
+func (r *NameserverSearchResults) MarshalJSON() ([]byte, error) {
+	return MarshalRDAP(r)
+}
+
+// MarshalJSON encodes the EntitySearchResults as RDAP JSON. See
+// MarshalRDAP().
+func (r *EntitySearchResults) MarshalJSON() ([]byte, error) {
+	return MarshalRDAP(r)
+}
@@ -0,0 +1,73 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestMarshalRDAPRoundTrip(t *testing.T) {
+	for _, filename := range []string{
+		"rdap/rdap.nic.cz/domain-example.cz.json",
+		"rdap/rdap.nic.cz/nameserver-ns2.pipni.cz.json",
+		"rdap/rdap.nic.cz/error-404.json",
+		"rdap/rdap.arin.net/entity-ABC123-ARIN.json",
+		"rdap/rdap.arin.net/ip-192.0.2.1.json",
+		"rdap/rdap.registry.example/domain-example.example.json",
+		"rdap/rdap.registry.example/domain-variants.json",
+	} {
+		obj, err := NewDecoder(test.LoadFile(filename)).Decode()
+		if err != nil {
+			t.Fatalf("%s: %s", filename, err)
+		}
+
+		data, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatalf("%s: %s", filename, err)
+		}
+
+		var expected, got interface{}
+		json.Unmarshal(test.LoadFile(filename), &expected)
+		json.Unmarshal(data, &got)
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: round trip mismatch, got %s", filename, data)
+		}
+	}
+}
+
+func TestMarshalRDAPConstructed(t *testing.T) {
+	start := uint32(0)
+
+	results := &DomainSearchResults{
+		Conformance: []string{"rdap_level_0"},
+		Domains: []Domain{
+			{ObjectClassName: "domain", LDHName: "example.cz", Status: []string{"active"}},
+		},
+	}
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"domainSearchResults":[{"ldhName":"example.cz","objectClassName":"domain","status":["active"]}],"rdapConformance":["rdap_level_0"]}`
+	if string(data) != expected {
+		t.Errorf("Got %s, expected %s", data, expected)
+	}
+
+	data, err = MarshalRDAP(&Autnum{ObjectClassName: "autnum", StartAutnum: &start})
+	if err != nil || string(data) != `{"objectClassName":"autnum","startAutnum":0}` {
+		t.Errorf("Unexpected autnum %s, %v", data, err)
+	}
+
+	if _, err := MarshalRDAP(nil); err == nil {
+		t.Errorf("Unexpected success for nil object")
+	}
+}
@@ -0,0 +1,99 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// RDAP media types.
+const (
+	// RDAP media type (RFC 7480 section 4.2).
+	MediaTypeRDAP = "application/rdap+json"
+
+	// Generic JSON media type, which RDAP clients should also accept.
+	MediaTypeJSON = "application/json"
+)
+
+// MediaTypePolicy specifies which response media types (Content-Type header
+// values) the Client accepts from RDAP servers.
+//
+// Responses of other media types are treated as failures, and the next RDAP
+// server (if any) is tried. The media type of each response is available via
+// HTTPResponse.MediaType().
+type MediaTypePolicy uint8
+
+const (
+	// Accept application/rdap+json and application/json, and responses
+	// without a Content-Type header. This is the default.
+	MediaTypeDefault MediaTypePolicy = iota
+
+	// Accept application/rdap+json only, as per RFC 7480.
+	MediaTypeStrict
+
+	// Accept any media type (e.g. text/json or text/plain from
+	// non-conformant servers), as long as the response decodes.
+	MediaTypeLenient
+)
+
+// accept returns the HTTP Accept header value for the policy.
+func (p MediaTypePolicy) accept() string {
+	switch p {
+	case MediaTypeStrict:
+		return MediaTypeRDAP
+	case MediaTypeLenient:
+		return MediaTypeRDAP + ", " + MediaTypeJSON + ", text/json;q=0.5, */*;q=0.1"
+	default:
+		return MediaTypeRDAP + ", " + MediaTypeJSON
+	}
+}
+
+// allows returns true if the policy accepts the media type |mediaType| (""
+// for none).
+func (p MediaTypePolicy) allows(mediaType string) bool {
+	switch p {
+	case MediaTypeStrict:
+		return mediaType == MediaTypeRDAP
+	case MediaTypeLenient:
+		return true
+	default:
+		return mediaType == "" || mediaType == MediaTypeRDAP || mediaType == MediaTypeJSON
+	}
+}
+
+// MediaType returns the response's media type (the Content-Type header,
+// lowercased, without parameters), e.g. "application/rdap+json". Returns ""
+// if there's no Content-Type header.
+func (h *HTTPResponse) MediaType() string {
+	contentType := h.Header().Get("Content-Type")
+	if contentType == "" {
+		return ""
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// Malformed parameters.
+		mediaType = strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	}
+
+	return strings.ToLower(mediaType)
+}
+
+// checkMediaType returns an error if the Client's MediaTypes policy doesn't
+// accept |httpResponse|'s media type.
+func (c *Client) checkMediaType(httpResponse *HTTPResponse) error {
+	mediaType := httpResponse.MediaType()
+
+	if c.MediaTypes.allows(mediaType) {
+		return nil
+	}
+
+	return &ClientError{
+		Type: UnsupportedMediaType,
+		Text: fmt.Sprintf("RDAP server returned unsupported media type '%s'", mediaType),
+	}
+}
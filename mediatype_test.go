@@ -0,0 +1,69 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestClientMediaTypes(t *testing.T) {
+	var accept string
+	var contentType string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	tests := []struct {
+		Policy      MediaTypePolicy
+		ContentType string
+		MediaType   string
+		OK          bool
+	}{
+		{MediaTypeDefault, "application/rdap+json", "application/rdap+json", true},
+		{MediaTypeDefault, "Application/JSON; charset=utf-8", "application/json", true},
+		{MediaTypeDefault, "text/json", "text/json", false},
+		{MediaTypeStrict, "application/rdap+json", "application/rdap+json", true},
+		{MediaTypeStrict, "application/json", "application/json", false},
+		{MediaTypeLenient, "text/json", "text/json", true},
+		{MediaTypeLenient, "text/plain; charset=utf-8", "text/plain", true},
+	}
+
+	for _, test := range tests {
+		contentType = test.ContentType
+
+		client := &Client{
+			MediaTypes: test.Policy,
+			Verbose:    verboseFunc(),
+		}
+
+		resp, err := client.Do(NewDomainRequest("example.cz").WithServer(serverURL))
+
+		if (err == nil) != test.OK {
+			t.Errorf("Policy %d, Content-Type %q: unexpected error %v", test.Policy, test.ContentType, err)
+		} else if !test.OK && !isClientError(UnsupportedMediaType, resp.FinalHTTP().Error) {
+			t.Errorf("Policy %d, Content-Type %q: unexpected HTTP error %v", test.Policy, test.ContentType, resp.FinalHTTP().Error)
+		}
+
+		if got := resp.FinalHTTP().MediaType(); got != test.MediaType {
+			t.Errorf("Content-Type %q: got media type %q, expected %q", test.ContentType, got, test.MediaType)
+		}
+
+		if test.Policy == MediaTypeStrict && accept != MediaTypeRDAP {
+			t.Errorf("Unexpected Accept header %q", accept)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"errors"
+	"time"
+)
+
+// Query outcomes, see Metrics.
+const (
+	OutcomeSuccess     = "success"
+	OutcomeNotFound    = "not_found"
+	OutcomeRateLimited = "rate_limited"
+	OutcomeError       = "error"
+)
+
+// HTTP cache lookup results, see Metrics.
+const (
+	// Fresh cached response used.
+	CacheHit = "hit"
+
+	// Stale cached response revalidated by the server (HTTP 304).
+	CacheRevalidated = "revalidated"
+
+	// No usable cached response.
+	CacheMiss = "miss"
+)
+
+// Metrics receives measurements of the Client's queries, for monitoring RDAP
+// lookups at scale. See Client.Metrics.
+//
+// The github.com/openrdap/rdap/prometheus module provides a Prometheus
+// implementation.
+//
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// QueryDone is called when each query (Client.Do) finishes.
+	//
+	// |outcome| is OutcomeSuccess, OutcomeNotFound, OutcomeRateLimited, or
+	// OutcomeError.
+	QueryDone(queryType RequestType, outcome string, duration time.Duration)
+
+	// HTTPRequestDone is called after each HTTP request to an RDAP server,
+	// including retries.
+	//
+	// |server| is the RDAP server's hostname, e.g. "rdap.nic.cz". |status| is
+	// the HTTP status code, or 0 if the request failed.
+	HTTPRequestDone(server string, status int, duration time.Duration)
+
+	// CacheLookup is called for each HTTP cache lookup, when Client.HTTPCache
+	// is set. |result| is CacheHit, CacheRevalidated, or CacheMiss.
+	CacheLookup(result string)
+}
+
+// queryOutcome returns the Metrics outcome of a query which returned |err|.
+func queryOutcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case isClientError(ObjectDoesNotExist, err):
+		return OutcomeNotFound
+	case errors.Is(err, ErrRateLimited):
+		return OutcomeRateLimited
+	default:
+		return OutcomeError
+	}
+}
+
+// recordHTTPMetrics records the HTTP request |httpResponse|, for |rdapReq|, in
+// the Client's Metrics.
+func (c *Client) recordHTTPMetrics(rdapReq *Request, httpResponse *HTTPResponse) {
+	if c.Metrics == nil {
+		return
+	}
+
+	var status int
+	if httpResponse.Response != nil {
+		status = httpResponse.Response.StatusCode
+	}
+
+	c.Metrics.HTTPRequestDone(rdapReq.URL().Hostname(), status, httpResponse.Duration)
+
+	if c.HTTPCache != nil {
+		if httpResponse.Cached {
+			c.Metrics.CacheLookup(CacheRevalidated)
+		} else {
+			c.Metrics.CacheLookup(CacheMiss)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recordingMetrics) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *recordingMetrics) QueryDone(queryType RequestType, outcome string, duration time.Duration) {
+	r.record(fmt.Sprintf("query %s %s", queryType, outcome))
+}
+
+func (r *recordingMetrics) HTTPRequestDone(server string, status int, duration time.Duration) {
+	r.record(fmt.Sprintf("http %s %d", server, status))
+}
+
+func (r *recordingMetrics) CacheLookup(result string) {
+	r.record("cache " + result)
+}
+
+func TestClientMetrics(t *testing.T) {
+	metrics := &recordingMetrics{}
+
+	transport := &cachingTransport{
+		header: http.Header{"Cache-Control": []string{"no-cache"}},
+		etag:   `"v1"`,
+	}
+	client := &Client{
+		HTTP:      &http.Client{Transport: transport},
+		HTTPCache: NewHTTPCache(),
+		Metrics:   metrics,
+		Verbose:   verboseFunc(),
+	}
+
+	runCachedQuery(t, client)
+	runCachedQuery(t, client)
+
+	expected := []string{
+		"http rdap.nic.cz 200",
+		"cache miss",
+		"query domain success",
+		"http rdap.nic.cz 200",
+		"cache revalidated",
+		"query domain success",
+	}
+
+	if got := strings.Join(metrics.events, ","); got != strings.Join(expected, ",") {
+		t.Errorf("Got metrics %v, expected %v", metrics.events, expected)
+	}
+}
+
+func TestQueryOutcome(t *testing.T) {
+	tests := []struct {
+		Err     error
+		Outcome string
+	}{
+		{nil, OutcomeSuccess},
+		{&ClientError{Type: ObjectDoesNotExist}, OutcomeNotFound},
+		{&RateLimitedError{}, OutcomeRateLimited},
+		{&ClientError{Type: NoWorkingServers}, OutcomeError},
+	}
+
+	for _, test := range tests {
+		if got := queryOutcome(test.Err); got != test.Outcome {
+			t.Errorf("queryOutcome(%v) = %s, expected %s", test.Err, got, test.Outcome)
+		}
+	}
+}
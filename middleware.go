@@ -0,0 +1,75 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"net/http"
+)
+
+// Handler makes an HTTP request to an RDAP server.
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler, to inspect or modify the HTTP requests to RDAP
+// servers, and their responses. See Client.Middleware.
+//
+// For example, to record the response status codes:
+//
+//	func statusLogger(next rdap.Handler) rdap.Handler {
+//	  return func(req *http.Request) (*http.Response, error) {
+//	    resp, err := next(req)
+//	    if err == nil {
+//	      log.Printf("%s: %d", req.URL, resp.StatusCode)
+//	    }
+//
+//	    return resp, err
+//	  }
+//	}
+//
+// Middleware runs for each HTTP request, including retries and redirects.
+// RequestFromContext() returns the RDAP request an HTTP request is for.
+//
+// Middleware modifying the request should modify a copy (see
+// http.Request.Clone()). Middleware reading the response body must replace it
+// for the rest of the chain, e.g. with io.NopCloser(bytes.NewReader(body)).
+type Middleware func(next Handler) Handler
+
+// middlewareTransport is a http.RoundTripper running a Handler.
+type middlewareTransport Handler
+
+func (m middlewareTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return m(req)
+}
+
+// applyMiddleware returns |rt| (nil meaning http.DefaultTransport), wrapped
+// by the Client's Middleware.
+func (c *Client) applyMiddleware(rt http.RoundTripper) http.RoundTripper {
+	if len(c.Middleware) == 0 {
+		return rt
+	}
+
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	handler := Handler(rt.RoundTrip)
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		handler = c.Middleware[i](handler)
+	}
+
+	return middlewareTransport(handler)
+}
+
+type requestContextKey struct{}
+
+// RequestFromContext returns the RDAP request from the context of an HTTP
+// request made by the Client, or nil if there is none.
+//
+// This is intended for Middleware, e.g. to record metrics by request type.
+func RequestFromContext(ctx context.Context) *Request {
+	rdapReq, _ := ctx.Value(requestContextKey{}).(*Request)
+
+	return rdapReq
+}
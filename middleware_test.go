@@ -0,0 +1,77 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestClientMiddleware(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	var calls []string
+	var archived []byte
+	var requestType RequestType
+
+	tracer := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				return next(req)
+			}
+		}
+	}
+
+	archiver := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			requestType = RequestFromContext(req.Context()).Type
+
+			req = req.Clone(req.Context())
+			req.Header.Set("X-Test", "1")
+
+			resp, err := next(req)
+			if err != nil {
+				return resp, err
+			}
+
+			archived, _ = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = ioutil.NopCloser(bytes.NewReader(archived))
+
+			return resp, nil
+		}
+	}
+
+	client := &Client{
+		Middleware: []Middleware{tracer("outer"), archiver, tracer("inner")},
+		Verbose:    verboseFunc(),
+	}
+
+	domain, err := client.QueryDomain("example.cz")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if domain.LDHName != "example.cz" {
+		t.Errorf("Unexpected LDHName %s", domain.LDHName)
+	}
+
+	if len(calls) != 2 || calls[0] != "outer" || calls[1] != "inner" {
+		t.Errorf("Unexpected middleware calls %v", calls)
+	}
+
+	if !bytes.Equal(archived, test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json")) {
+		t.Errorf("Unexpected archived response %q", archived)
+	}
+
+	if requestType != DomainRequest {
+		t.Errorf("Unexpected request type %s", requestType)
+	}
+}
@@ -0,0 +1,108 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// Range returns the IP network's startAddress and endAddress.
+//
+// Returns an error if either address is missing or invalid, they're of
+// different IP versions (or don't match ipVersion), or the start address is
+// after the end address. IPv4-mapped IPv6 addresses are returned as IPv4.
+func (n *IPNetwork) Range() (netip.Addr, netip.Addr, error) {
+	start, err := netip.ParseAddr(n.StartAddress)
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("invalid startAddress %q", n.StartAddress)
+	}
+
+	end, err := netip.ParseAddr(n.EndAddress)
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("invalid endAddress %q", n.EndAddress)
+	}
+
+	start = start.Unmap().WithZone("")
+	end = end.Unmap().WithZone("")
+
+	if start.Is4() != end.Is4() {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("startAddress %s and endAddress %s are different IP versions", start, end)
+	}
+
+	if (n.IPVersion == "v4" && !start.Is4()) || (n.IPVersion == "v6" && start.Is4()) {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("addresses don't match ipVersion %q", n.IPVersion)
+	}
+
+	if start.Compare(end) > 0 {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("startAddress %s is after endAddress %s", start, end)
+	}
+
+	return start, end, nil
+}
+
+// Contains returns true if |addr| is within the IP network's address range.
+//
+// Returns false if the range is invalid, see Range().
+func (n *IPNetwork) Contains(addr netip.Addr) bool {
+	start, end, err := n.Range()
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap().WithZone("")
+
+	return addr.Is4() == start.Is4() && start.Compare(addr) <= 0 && addr.Compare(end) <= 0
+}
+
+// Prefixes returns the minimal list of CIDR prefixes covering the IP
+// network's address range, e.g. [192.0.2.0/23 192.0.4.0/23] for
+// 192.0.2.0-192.0.5.255.
+//
+// See also CIDRs(), for the prefixes listed by the server. Returns an error if
+// the range is invalid, see Range().
+func (n *IPNetwork) Prefixes() ([]netip.Prefix, error) {
+	start, end, err := n.Range()
+	if err != nil {
+		return nil, err
+	}
+
+	return rangePrefixes(start, end), nil
+}
+
+// rangePrefixes returns the minimal list of prefixes covering the addresses
+// |start| to |end| (inclusive, of the same IP version, start <= end).
+func rangePrefixes(start netip.Addr, end netip.Addr) []netip.Prefix {
+	var prefixes []netip.Prefix
+
+	for cur := start; cur.IsValid() && cur.Compare(end) <= 0; {
+		// Largest prefix starting at |cur| and ending before |end|.
+		var prefix netip.Prefix
+		for bits := 0; bits <= cur.BitLen(); bits++ {
+			p := netip.PrefixFrom(cur, bits)
+			if p.Masked().Addr() == cur && lastAddr(p).Compare(end) <= 0 {
+				prefix = p
+				break
+			}
+		}
+
+		prefixes = append(prefixes, prefix)
+		cur = lastAddr(prefix).Next()
+	}
+
+	return prefixes
+}
+
+// lastAddr returns the last address in the prefix |p|.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+
+	last, _ := netip.AddrFromSlice(b)
+
+	return last
+}
@@ -0,0 +1,71 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+)
+
+func TestIPNetworkPrefixes(t *testing.T) {
+	tests := []struct {
+		Start    string
+		End      string
+		Version  string
+		Prefixes string
+	}{
+		{"192.0.2.0", "192.0.2.255", "v4", "[192.0.2.0/24]"},
+		{"192.0.2.0", "192.0.5.255", "", "[192.0.2.0/23 192.0.4.0/23]"},
+		{"192.0.2.1", "192.0.2.6", "v4", "[192.0.2.1/32 192.0.2.2/31 192.0.2.4/31 192.0.2.6/32]"},
+		{"192.0.2.5", "192.0.2.5", "v4", "[192.0.2.5/32]"},
+		{"0.0.0.0", "255.255.255.255", "v4", "[0.0.0.0/0]"},
+		{"2001:db8::", "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff", "v6", "[2001:db8::/32]"},
+		{"2001:db8::1", "2001:db8::4", "v6", "[2001:db8::1/128 2001:db8::2/127 2001:db8::4/128]"},
+		{"::ffff:192.0.2.0", "::ffff:192.0.2.127", "v4", "[192.0.2.0/25]"},
+	}
+
+	for _, test := range tests {
+		n := &IPNetwork{StartAddress: test.Start, EndAddress: test.End, IPVersion: test.Version}
+
+		prefixes, err := n.Prefixes()
+		if err != nil {
+			t.Errorf("%s-%s: unexpected error %s", test.Start, test.End, err)
+		} else if got := fmt.Sprint(prefixes); got != test.Prefixes {
+			t.Errorf("%s-%s: got %s, expected %s", test.Start, test.End, got, test.Prefixes)
+		}
+	}
+
+	for _, n := range []*IPNetwork{
+		{StartAddress: "192.0.2.0", EndAddress: ""},
+		{StartAddress: "192.0.2.255", EndAddress: "192.0.2.0"},
+		{StartAddress: "192.0.2.0", EndAddress: "2001:db8::"},
+		{StartAddress: "192.0.2.0", EndAddress: "192.0.2.255", IPVersion: "v6"},
+	} {
+		if _, err := n.Prefixes(); err == nil {
+			t.Errorf("%s-%s: unexpected success", n.StartAddress, n.EndAddress)
+		}
+	}
+}
+
+func TestIPNetworkContains(t *testing.T) {
+	n := &IPNetwork{StartAddress: "192.0.2.0", EndAddress: "192.0.2.255", IPVersion: "v4"}
+
+	for addr, expected := range map[string]bool{
+		"192.0.2.0":          true,
+		"192.0.2.255":        true,
+		"::ffff:192.0.2.128": true,
+		"192.0.3.0":          false,
+		"::c000:201":         false,
+	} {
+		if got := n.Contains(netip.MustParseAddr(addr)); got != expected {
+			t.Errorf("Contains(%s) = %v, expected %v", addr, got, expected)
+		}
+	}
+
+	if (&IPNetwork{}).Contains(netip.MustParseAddr("192.0.2.1")) {
+		t.Errorf("Unexpected Contains() for empty network")
+	}
+}
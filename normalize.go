@@ -0,0 +1,268 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Normalize converts the RDAP object |obj| (e.g. a *Domain) to a canonical
+// form, in place, so two fetches of the same object encode (see MarshalRDAP())
+// identically, for caching and deduplication:
+//   - Domain and nameserver names are lowercased, without a trailing dot.
+//   - Statuses are lowercased RDAP status values (EPP status codes are
+//     converted, see ParseStatuses()), and sorted.
+//   - Nameservers are sorted by name, and their IP addresses are in canonical
+//     form and sorted.
+//   - Entities are sorted by handle, then roles. Roles, public IDs, links, and
+//     rdapConformance are sorted.
+//   - Event dates are converted to UTC, and events are sorted by action and
+//     date.
+//   - Volatile data is removed: notices (which describe the response, e.g.
+//     terms of service and truncation due to load, rather than the object),
+//     and "last update of RDAP database" events.
+//
+// Nested objects are normalized too. Remarks are unchanged.
+func Normalize(obj RDAPObject) {
+	switch v := obj.(type) {
+	case *Domain:
+		normalizeDomain(v)
+	case *Entity:
+		normalizeEntity(v)
+	case *Nameserver:
+		normalizeNameserver(v)
+	case *Autnum:
+		normalizeAutnum(v)
+	case *IPNetwork:
+		normalizeIPNetwork(v)
+	case *DomainSearchResults:
+		v.Notices = nil
+		sort.Strings(v.Conformance)
+		for i := range v.Domains {
+			normalizeDomain(&v.Domains[i])
+		}
+	case *NameserverSearchResults:
+		v.Notices = nil
+		sort.Strings(v.Conformance)
+		for i := range v.Nameservers {
+			normalizeNameserver(&v.Nameservers[i])
+		}
+	case *EntitySearchResults:
+		v.Notices = nil
+		sort.Strings(v.Conformance)
+		for i := range v.Entities {
+			normalizeEntity(&v.Entities[i])
+		}
+	}
+}
+
+func normalizeDomain(d *Domain) {
+	sort.Strings(d.Conformance)
+	d.Notices = nil
+	d.LDHName = normalizeName(d.LDHName)
+	d.Status = normalizeStatus(d.Status)
+	d.Events = normalizeEvents(d.Events)
+	normalizeLinks(d.Links)
+	normalizePublicIDs(d.PublicIDs)
+
+	for i := range d.Nameservers {
+		normalizeNameserver(&d.Nameservers[i])
+	}
+
+	sort.SliceStable(d.Nameservers, func(i int, j int) bool {
+		return d.Nameservers[i].LDHName < d.Nameservers[j].LDHName
+	})
+
+	normalizeEntities(d.Entities)
+
+	if d.Network != nil {
+		normalizeIPNetwork(d.Network)
+	}
+
+	if d.SecureDNS != nil {
+		for i := range d.SecureDNS.DS {
+			d.SecureDNS.DS[i].Events = normalizeEvents(d.SecureDNS.DS[i].Events)
+		}
+
+		for i := range d.SecureDNS.Keys {
+			d.SecureDNS.Keys[i].Events = normalizeEvents(d.SecureDNS.Keys[i].Events)
+		}
+	}
+}
+
+func normalizeNameserver(n *Nameserver) {
+	sort.Strings(n.Conformance)
+	n.Notices = nil
+	n.LDHName = normalizeName(n.LDHName)
+	n.Status = normalizeStatus(n.Status)
+	n.Events = normalizeEvents(n.Events)
+	normalizeLinks(n.Links)
+	normalizeEntities(n.Entities)
+
+	if n.IPAddresses != nil {
+		n.IPAddresses.V4 = normalizeAddresses(n.IPAddresses.V4)
+		n.IPAddresses.V6 = normalizeAddresses(n.IPAddresses.V6)
+	}
+}
+
+func normalizeEntity(e *Entity) {
+	sort.Strings(e.Conformance)
+	e.Notices = nil
+	e.Status = normalizeStatus(e.Status)
+	e.Events = normalizeEvents(e.Events)
+	e.AsEventActor = normalizeEvents(e.AsEventActor)
+	sort.Strings(e.Roles)
+	normalizeLinks(e.Links)
+	normalizePublicIDs(e.PublicIDs)
+	normalizeEntities(e.Entities)
+
+	for i := range e.Networks {
+		normalizeIPNetwork(&e.Networks[i])
+	}
+
+	for i := range e.Autnums {
+		normalizeAutnum(&e.Autnums[i])
+	}
+}
+
+func normalizeAutnum(a *Autnum) {
+	sort.Strings(a.Conformance)
+	a.Notices = nil
+	a.Status = normalizeStatus(a.Status)
+	a.Events = normalizeEvents(a.Events)
+	normalizeLinks(a.Links)
+	normalizeEntities(a.Entities)
+}
+
+func normalizeIPNetwork(n *IPNetwork) {
+	sort.Strings(n.Conformance)
+	n.Notices = nil
+	n.Status = normalizeStatus(n.Status)
+	n.Events = normalizeEvents(n.Events)
+	normalizeLinks(n.Links)
+	normalizeEntities(n.Entities)
+
+	if start, end, err := n.Range(); err == nil {
+		n.StartAddress = start.String()
+		n.EndAddress = end.String()
+	}
+}
+
+// normalizeEntities normalizes and sorts |entities|.
+func normalizeEntities(entities []Entity) {
+	for i := range entities {
+		normalizeEntity(&entities[i])
+	}
+
+	sort.SliceStable(entities, func(i int, j int) bool {
+		if entities[i].Handle != entities[j].Handle {
+			return entities[i].Handle < entities[j].Handle
+		}
+
+		return strings.Join(entities[i].Roles, ",") < strings.Join(entities[j].Roles, ",")
+	})
+}
+
+// normalizeName returns the domain name |name| lowercased, without a trailing
+// dot.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// normalizeStatus returns the sorted RDAP status values of |status|.
+func normalizeStatus(status []string) []string {
+	if status == nil {
+		return nil
+	}
+
+	result := []string{}
+	for _, s := range ParseStatuses(status) {
+		if !containsString(result, string(s)) {
+			result = append(result, string(s))
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// normalizeEvents returns |events| with UTC dates, sorted by action and date,
+// without "last update of RDAP database" events.
+func normalizeEvents(events []Event) []Event {
+	if events == nil {
+		return nil
+	}
+
+	result := []Event{}
+	for _, e := range events {
+		if e.Action == EventLastUpdateOfRDAPDatabase {
+			continue
+		}
+
+		if t, err := e.Time(); err == nil {
+			e.Date = t.Format(time.RFC3339Nano)
+		}
+
+		normalizeLinks(e.Links)
+		result = append(result, e)
+	}
+
+	sort.SliceStable(result, func(i int, j int) bool {
+		if result[i].Action != result[j].Action {
+			return result[i].Action < result[j].Action
+		}
+
+		return result[i].Date < result[j].Date
+	})
+
+	return result
+}
+
+// normalizeLinks sorts |links| by relation type and href.
+func normalizeLinks(links []Link) {
+	sort.SliceStable(links, func(i int, j int) bool {
+		if links[i].Rel != links[j].Rel {
+			return links[i].Rel < links[j].Rel
+		}
+
+		return links[i].Href < links[j].Href
+	})
+}
+
+// normalizePublicIDs sorts |ids| by type and identifier.
+func normalizePublicIDs(ids []PublicID) {
+	sort.SliceStable(ids, func(i int, j int) bool {
+		if ids[i].Type != ids[j].Type {
+			return ids[i].Type < ids[j].Type
+		}
+
+		return ids[i].Identifier < ids[j].Identifier
+	})
+}
+
+// normalizeAddresses returns the IP addresses |addresses| in canonical form,
+// sorted. Invalid addresses are kept as is.
+func normalizeAddresses(addresses []string) []string {
+	if addresses == nil {
+		return nil
+	}
+
+	result := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		if addr, err := netip.ParseAddr(a); err == nil {
+			a = addr.String()
+		}
+
+		result = append(result, a)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
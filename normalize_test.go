@@ -0,0 +1,69 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestNormalize(t *testing.T) {
+	load := func() *Domain {
+		obj, err := NewDecoder(test.LoadFile("rdap/rdap.registry.example/domain-example.example.json")).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return obj.(*Domain)
+	}
+
+	a := load()
+	b := load()
+
+	// A second fetch, with cosmetic and volatile differences.
+	b.LDHName = "Example.EXAMPLE."
+	b.Status = []string{"ok", "clientTransferProhibited"}
+	b.Nameservers[0], b.Nameservers[1] = b.Nameservers[1], b.Nameservers[0]
+	b.Nameservers[0].LDHName = "NS2.example.net."
+	b.Events[0], b.Events[2] = b.Events[2], b.Events[0]
+	b.Events[1].Date = "2031-02-03T05:05:06+01:00"
+	b.Events[3].Date = "2025-01-01T00:00:00Z"
+	b.Notices = b.Notices[1:]
+	b.Entities = append(b.Entities, Entity{ObjectClassName: "entity", Handle: "1000", Roles: []string{"technical"}})
+	a.Entities = append([]Entity{{ObjectClassName: "entity", Handle: "1000", Roles: []string{"technical"}}}, a.Entities...)
+
+	Normalize(a)
+	Normalize(b)
+
+	aJSON, err := MarshalRDAP(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bJSON, err := MarshalRDAP(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(aJSON) != string(bJSON) {
+		t.Errorf("Normalized responses differ:\n%s\n%s", aJSON, bJSON)
+	}
+
+	if a.LDHName != "example.example" || a.Notices != nil || len(a.Events) != 3 || a.Events[1].Action != EventLastChanged {
+		t.Errorf("Unexpected normalized domain %+v", a)
+	}
+
+	if len(a.Status) != 2 || a.Status[0] != "active" || a.Status[1] != "client transfer prohibited" {
+		t.Errorf("Unexpected normalized status %v", a.Status)
+	}
+
+	n := &Nameserver{LDHName: "NS1.Example.CZ", IPAddresses: &IPAddressSet{V6: []string{"2001:DB8:0::53"}}}
+	Normalize(n)
+
+	if n.LDHName != "ns1.example.cz" || n.IPAddresses.V6[0] != "2001:db8::53" {
+		t.Errorf("Unexpected normalized nameserver %+v", n)
+	}
+}
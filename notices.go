@@ -0,0 +1,200 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import "strings"
+
+// Notice and remark types, from the IANA "RDAP JSON Values" registry (RFC 9083
+// section 10.2.1, RFC 9537 section 6.2).
+//
+// https://www.iana.org/assignments/rdap-json-values/
+const (
+	ResultSetTruncatedAuthorization = "result set truncated due to authorization"
+	ResultSetTruncatedLoad          = "result set truncated due to excessive load"
+	ResultSetTruncatedUnexplainable = "result set truncated due to unexplainable reasons"
+	ObjectTruncatedAuthorization    = "object truncated due to authorization"
+	ObjectTruncatedLoad             = "object truncated due to excessive load"
+	ObjectTruncatedUnexplainable    = "object truncated due to unexplainable reasons"
+	ObjectRedactedAuthorization     = "object redacted due to authorization"
+)
+
+// IsRegisteredNoticeType returns true if |t| is a notice and remark type in
+// the IANA registry (see DefaultJSONValues()). The comparison is case
+// insensitive.
+func IsRegisteredNoticeType(t string) bool {
+	return DefaultJSONValues().Has(JSONValueNoticeType, t)
+}
+
+// isTruncationType returns true if |t| is a registered "result set truncated"
+// or "object truncated" type.
+func isTruncationType(t string) bool {
+	return IsRegisteredNoticeType(t) && strings.Contains(strings.ToLower(t), "truncated")
+}
+
+// isAuthorizationType returns true if |t| is a registered "... due to
+// authorization" type, e.g. "object redacted due to authorization".
+func isAuthorizationType(t string) bool {
+	return IsRegisteredNoticeType(t) && strings.HasSuffix(strings.ToLower(t), "due to authorization")
+}
+
+// IsTruncation returns true if the notice's type says the response was
+// truncated, e.g. "result set truncated due to excessive load".
+func (n Notice) IsTruncation() bool {
+	return isTruncationType(n.Type)
+}
+
+// IsTermsOfService returns true if the notice looks like a terms of service
+// notice: it has a "terms-of-service" link, or a title such as "Terms of Use".
+func (n Notice) IsTermsOfService() bool {
+	return isTermsOfServiceNotice(n)
+}
+
+// IsAuthorizationLimited returns true if the notice's type says data was
+// withheld because the client isn't authorized to see it, e.g. "result set
+// truncated due to authorization".
+func (n Notice) IsAuthorizationLimited() bool {
+	return isAuthorizationType(n.Type)
+}
+
+// IsTruncation returns true if the remark's type says the object was
+// truncated, e.g. "object truncated due to authorization".
+func (r Remark) IsTruncation() bool {
+	return isTruncationType(r.Type)
+}
+
+// TermsOfServiceNotices returns the response's terms of service notices.
+func (r *Response) TermsOfServiceNotices() []Notice {
+	var result []Notice
+	for _, n := range noticesOf(r.Object) {
+		if n.IsTermsOfService() {
+			result = append(result, n)
+		}
+	}
+
+	return result
+}
+
+// TruncationNotices returns the response's notices saying the response was
+// truncated.
+func (r *Response) TruncationNotices() []Notice {
+	var result []Notice
+	for _, n := range noticesOf(r.Object) {
+		if n.IsTruncation() {
+			result = append(result, n)
+		}
+	}
+
+	return result
+}
+
+// IsAuthorizationLimited returns true if the remark's type says data was
+// withheld because the client isn't authorized to see it, e.g. "object
+// truncated due to authorization".
+func (r Remark) IsAuthorizationLimited() bool {
+	return isAuthorizationType(r.Type)
+}
+
+// Truncated returns true if the response is incomplete: a notice says the
+// result set or object was truncated, or a remark on the object (or an object
+// embedded in it, e.g. an entity) says it was truncated.
+func (r *Response) Truncated() bool {
+	return r.hasNoticeOrRemark(Notice.IsTruncation, Remark.IsTruncation)
+}
+
+// AuthorizationLimited returns true if data was truncated or redacted because
+// the client isn't authorized to see it, i.e. a notice or remark (on the
+// object or an object embedded in it) has a "... due to authorization" type.
+//
+// Authenticating (see Client.Credentials) may return more data.
+func (r *Response) AuthorizationLimited() bool {
+	return r.hasNoticeOrRemark(Notice.IsAuthorizationLimited, Remark.IsAuthorizationLimited)
+}
+
+func (r *Response) hasNoticeOrRemark(noticeFn func(Notice) bool, remarkFn func(Remark) bool) bool {
+	for _, n := range noticesOf(r.Object) {
+		if noticeFn(n) {
+			return true
+		}
+	}
+
+	for _, remarks := range allRemarksOf(r.Object) {
+		for _, remark := range remarks {
+			if remarkFn(remark) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// allRemarksOf returns the remarks of the RDAP object |obj|, and of each object
+// embedded in it (including search results).
+func allRemarksOf(obj RDAPObject) [][]Remark {
+	var result [][]Remark
+
+	switch v := obj.(type) {
+	case *Domain:
+		result = append(result, v.Remarks)
+
+		for i := range v.Nameservers {
+			result = append(result, allRemarksOf(&v.Nameservers[i])...)
+		}
+
+		for i := range v.Entities {
+			result = append(result, allRemarksOf(&v.Entities[i])...)
+		}
+
+		if v.Network != nil {
+			result = append(result, allRemarksOf(v.Network)...)
+		}
+	case *Entity:
+		result = append(result, v.Remarks)
+
+		for i := range v.Entities {
+			result = append(result, allRemarksOf(&v.Entities[i])...)
+		}
+
+		for i := range v.Networks {
+			result = append(result, allRemarksOf(&v.Networks[i])...)
+		}
+
+		for i := range v.Autnums {
+			result = append(result, allRemarksOf(&v.Autnums[i])...)
+		}
+	case *Nameserver:
+		result = append(result, v.Remarks)
+
+		for i := range v.Entities {
+			result = append(result, allRemarksOf(&v.Entities[i])...)
+		}
+	case *Autnum:
+		result = append(result, v.Remarks)
+
+		for i := range v.Entities {
+			result = append(result, allRemarksOf(&v.Entities[i])...)
+		}
+	case *IPNetwork:
+		result = append(result, v.Remarks)
+
+		for i := range v.Entities {
+			result = append(result, allRemarksOf(&v.Entities[i])...)
+		}
+	case *DomainSearchResults:
+		for i := range v.Domains {
+			result = append(result, allRemarksOf(&v.Domains[i])...)
+		}
+	case *NameserverSearchResults:
+		for i := range v.Nameservers {
+			result = append(result, allRemarksOf(&v.Nameservers[i])...)
+		}
+	case *EntitySearchResults:
+		for i := range v.Entities {
+			result = append(result, allRemarksOf(&v.Entities[i])...)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,96 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import "testing"
+
+func TestNoticeClassification(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.cz",
+		"notices": [
+			{"title": "Terms of Use", "description": ["Usage is subject to the terms of use."]},
+			{"title": "Search policy", "type": "Result Set Truncated Due To Excessive Load", "description": ["Too many results."]},
+			{"title": "Other", "type": "some private type"}
+		],
+		"remarks": [
+			{"type": "object truncated due to authorization", "description": ["Some data is not shown."]},
+			{"type": "object redacted due to authorization"}
+		]
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := &Response{Object: obj}
+
+	if tos := resp.TermsOfServiceNotices(); len(tos) != 1 || tos[0].Title != "Terms of Use" {
+		t.Errorf("Unexpected terms of service notices %+v", tos)
+	}
+
+	if truncated := resp.TruncationNotices(); len(truncated) != 1 || truncated[0].Title != "Search policy" {
+		t.Errorf("Unexpected truncation notices %+v", truncated)
+	}
+
+	remarks := obj.(*Domain).Remarks
+	if !remarks[0].IsTruncation() || remarks[1].IsTruncation() {
+		t.Errorf("Unexpected remark classification %+v", remarks)
+	}
+
+	if !IsRegisteredNoticeType(remarks[1].Type) || IsRegisteredNoticeType("some private type") {
+		t.Errorf("Unexpected IsRegisteredNoticeType result")
+	}
+
+	if (&Response{}).TruncationNotices() != nil {
+		t.Errorf("Unexpected notices without an object")
+	}
+}
+
+func TestResponseTruncated(t *testing.T) {
+	tests := []struct {
+		JSON                 string
+		Truncated            bool
+		AuthorizationLimited bool
+	}{
+		{
+			`{"objectClassName": "domain", "ldhName": "example.cz"}`,
+			false, false,
+		},
+		{
+			`{"objectClassName": "domain", "ldhName": "example.cz",
+			  "notices": [{"type": "result set truncated due to excessive load"}]}`,
+			true, false,
+		},
+		{
+			`{"objectClassName": "domain", "ldhName": "example.cz",
+			  "entities": [{"objectClassName": "entity", "handle": "X",
+			    "remarks": [{"type": "object truncated due to authorization"}]}]}`,
+			true, true,
+		},
+		{
+			`{"objectClassName": "domain", "ldhName": "example.cz",
+			  "remarks": [{"type": "Object Redacted Due To Authorization"}]}`,
+			false, true,
+		},
+		{
+			`{"domainSearchResults": [{"objectClassName": "domain", "ldhName": "example.cz",
+			  "remarks": [{"type": "object truncated due to unexplainable reasons"}]}]}`,
+			true, false,
+		},
+	}
+
+	for _, test := range tests {
+		obj, err := NewDecoder([]byte(test.JSON)).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := &Response{Object: obj}
+
+		if resp.Truncated() != test.Truncated || resp.AuthorizationLimited() != test.AuthorizationLimited {
+			t.Errorf("%s: got Truncated()=%v AuthorizationLimited()=%v", test.JSON, resp.Truncated(), resp.AuthorizationLimited())
+		}
+	}
+}
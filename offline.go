@@ -0,0 +1,70 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCacheMiss matches (using errors.Is()) the CacheMissError returned by
+// Client.Do() in offline mode, see Client.Offline.
+var ErrCacheMiss = errors.New("not in cache")
+
+// CacheMissError is returned by Client.Do() in offline mode (see
+// Client.Offline), when the query can't be answered from the cache.
+//
+// Check for it using errors.Is(err, rdap.ErrCacheMiss).
+type CacheMissError struct {
+	// What wasn't cached: the RDAP query URL, or the bootstrap Service
+	// Registry file name (e.g. "dns.json").
+	Key string
+}
+
+func (e *CacheMissError) Error() string {
+	return fmt.Sprintf("offline: %s: %s", e.Key, ErrCacheMiss)
+}
+
+// Is returns true for ErrCacheMiss.
+func (e *CacheMissError) Is(target error) bool {
+	return target == ErrCacheMiss
+}
+
+// getOffline returns the cached response for |rdapReq|, even if it's stale.
+// If there's none, the HTTPResponse's Error is a CacheMissError.
+func (c *Client) getOffline(rdapReq *Request) *HTTPResponse {
+	url := rdapReq.URL().String()
+
+	var cached *httpCacheEntry
+	if c.usesHTTPCache(rdapReq) {
+		cached, _ = c.HTTPCache.get(url)
+	}
+
+	httpResponse := &HTTPResponse{
+		URL: url,
+	}
+
+	if cached != nil {
+		c.Verbose(fmt.Sprintf("client: offline, using cached response for %s", url))
+
+		httpResponse.Response = cached.response(nil)
+		httpResponse.Body = cached.body
+		httpResponse.Cached = true
+	} else {
+		httpResponse.Error = &CacheMissError{Key: url}
+	}
+
+	c.logHTTPAttempt(rdapReq.Context(), httpResponse)
+
+	if c.Metrics != nil {
+		if cached != nil {
+			c.Metrics.CacheLookup(CacheHit)
+		} else {
+			c.Metrics.CacheLookup(CacheMiss)
+		}
+	}
+
+	return httpResponse
+}
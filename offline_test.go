@@ -0,0 +1,62 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/openrdap/rdap/bootstrap"
+)
+
+func TestClientOffline(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	httpCache := NewHTTPCache()
+	httpCache.Now = func() time.Time { return now }
+
+	transport := &cachingTransport{
+		header: http.Header{"Cache-Control": []string{"max-age=60"}},
+	}
+	client := &Client{
+		HTTP:      &http.Client{Transport: transport},
+		HTTPCache: httpCache,
+		Verbose:   verboseFunc(),
+	}
+
+	runCachedQuery(t, client)
+
+	// Stale responses are used offline.
+	client.Offline = true
+	now = now.Add(time.Hour)
+
+	if resp := runCachedQuery(t, client); !resp.HTTP[0].Cached || transport.requests != 1 {
+		t.Errorf("Expected cached response, made %d requests", transport.requests)
+	}
+
+	// Uncached query.
+	server, _ := url.Parse("https://rdap.nic.cz")
+	_, err := client.Do(NewDomainRequest("other.cz").WithServer(server))
+
+	var cacheMiss *CacheMissError
+	if !errors.Is(err, ErrCacheMiss) || !errors.As(err, &cacheMiss) || cacheMiss.Key != "https://rdap.nic.cz/domain/other.cz" {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if transport.requests != 1 {
+		t.Errorf("Unexpected requests %d", transport.requests)
+	}
+
+	// Uncached bootstrap Service Registry file.
+	client.Bootstrap = &bootstrap.Client{}
+
+	_, err = client.Do(NewDomainRequest("example.cz"))
+	if !errors.As(err, &cacheMiss) || cacheMiss.Key != "dns.json" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -0,0 +1,675 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenIDConformance is the rdapConformance identifier of the RDAP OpenID
+// Connect federated authentication extension (RFC 9560).
+const OpenIDConformance = "farv1"
+
+// OpenIDConfiguration describes an RDAP server's OpenID Connect support, as
+// advertised in its help response (RFC 9560 section 4.1).
+//
+//	help := resp.Object.(*rdap.Help)
+//
+//	if config := help.OpenIDConfiguration(); config != nil {
+//	  fmt.Println(config.Providers)
+//	}
+type OpenIDConfiguration struct {
+	SessionClientSupported        bool             `json:"sessionClientSupported"`
+	QueryClientSupported          bool             `json:"queryClientSupported"`
+	ProviderDiscoverySupported    bool             `json:"providerDiscoverySupported"`
+	IssuerIdentifierSupported     bool             `json:"issuerIdentifierSupported"`
+	ImplicitTokenRefreshSupported bool             `json:"implicitTokenRefreshSupported"`
+	ExplicitTokenRefreshSupported bool             `json:"explicitTokenRefreshSupported"`
+	Providers                     []OpenIDProvider `json:"openidcProviders"`
+}
+
+// OpenIDProvider is an OpenID Provider (identity provider) accepted by an RDAP
+// server.
+type OpenIDProvider struct {
+	Issuer  string `json:"iss"`
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+}
+
+// OpenIDSession describes an RDAP server's login session (RFC 9560 section
+// 4.2), as returned by the session status and refresh endpoints.
+type OpenIDSession struct {
+	UserID     string                 `json:"userID"`
+	Issuer     string                 `json:"iss"`
+	UserClaims map[string]interface{} `json:"userClaims"`
+
+	Info struct {
+		// Remaining token lifetime, in seconds.
+		TokenExpiration int64 `json:"tokenExpiration"`
+
+		// Whether the server refreshes the token itself.
+		TokenRefresh bool `json:"tokenRefresh"`
+	} `json:"sessionInfo"`
+}
+
+// openIDData is the decoded farv1 extension data of a response.
+type openIDData struct {
+	Configuration *OpenIDConfiguration `json:"farv1_openidcConfiguration"`
+	Session       *OpenIDSession       `json:"farv1_session"`
+}
+
+func init() {
+	RegisterExtension(Extension{
+		Name:        OpenIDConformance,
+		Conformance: OpenIDConformance,
+		Members:     []string{"farv1_openidcConfiguration", "farv1_session"},
+		Decode:      decodeOpenID,
+		Print:       printOpenID,
+	})
+}
+
+func decodeOpenID(src map[string]interface{}) (interface{}, error) {
+	_, hasConfig := src["farv1_openidcConfiguration"]
+	_, hasSession := src["farv1_session"]
+	if !hasConfig && !hasSession {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"farv1_openidcConfiguration": src["farv1_openidcConfiguration"],
+		"farv1_session":              src["farv1_session"],
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &openIDData{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("invalid OpenID data: %s", err)
+	}
+
+	return result, nil
+}
+
+func printOpenID(value interface{}, p *ExtensionPrinter) {
+	data := value.(*openIDData)
+
+	if config := data.Configuration; config != nil {
+		h := p.Heading("OpenID Connect")
+		h.Value("Session clients", fmt.Sprintf("%t", config.SessionClientSupported))
+		h.Value("Query clients", fmt.Sprintf("%t", config.QueryClientSupported))
+
+		for _, provider := range config.Providers {
+			h.Value("Provider", strings.TrimSpace(provider.Issuer+" "+provider.Name))
+		}
+	}
+
+	if session := data.Session; session != nil {
+		h := p.Heading("OpenID Session")
+		h.Value("User ID", session.UserID)
+		h.Value("Issuer", session.Issuer)
+		h.Value("Token expiration", fmt.Sprintf("%ds", session.Info.TokenExpiration))
+	}
+}
+
+// OpenIDConfiguration returns the RDAP server's OpenID Connect configuration,
+// or nil if the help response doesn't include one.
+func (h *Help) OpenIDConfiguration() *OpenIDConfiguration {
+	if data := h.openIDData(); data != nil {
+		return data.Configuration
+	}
+
+	return nil
+}
+
+// OpenIDSession returns the login session information in a session status or
+// refresh response, or nil if there is none.
+func (h *Help) OpenIDSession() *OpenIDSession {
+	if data := h.openIDData(); data != nil {
+		return data.Session
+	}
+
+	return nil
+}
+
+func (h *Help) openIDData() *openIDData {
+	if h.DecodeData == nil {
+		return nil
+	}
+
+	data, _ := h.DecodeData.Extension(OpenIDConformance).(*openIDData)
+	return data
+}
+
+// OpenIDToken is an OAuth 2.0 token set, obtained from an OpenID Provider.
+type OpenIDToken struct {
+	AccessToken  string `json:"access_token"`
+	IDToken      string `json:"id_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+
+	// Access token expiry time. Zero if unknown.
+	Expiry time.Time `json:"expiry"`
+}
+
+// Expired returns true if the access token has expired, or expires within
+// the next 30 seconds.
+func (t *OpenIDToken) Expired() bool {
+	if t.Expiry.IsZero() {
+		return false
+	}
+
+	return time.Now().Add(30 * time.Second).After(t.Expiry)
+}
+
+// OpenIDTokenSource provides access tokens for RDAP queries.
+//
+// Implementations must be safe for concurrent use. See OpenIDDeviceFlow.
+type OpenIDTokenSource interface {
+	// Token returns a valid token, refreshing or obtaining a new one if
+	// necessary.
+	Token(ctx context.Context) (*OpenIDToken, error)
+}
+
+// StaticOpenIDToken is an OpenIDTokenSource which always returns the same
+// token, e.g. one obtained out of band.
+type StaticOpenIDToken OpenIDToken
+
+// Token returns the token.
+func (s *StaticOpenIDToken) Token(ctx context.Context) (*OpenIDToken, error) {
+	token := OpenIDToken(*s)
+	return &token, nil
+}
+
+// OpenIDTokens maps RDAP server hostnames to OpenIDTokenSources. Queries to a
+// matching server include an access token (as an "Authorization: Bearer"
+// header), as RFC 9560 query clients do.
+//
+// A hostname matches itself and its subdomains, e.g. "nic.cz" matches
+// "rdap.nic.cz". The most specific match is used. The empty string key is the
+// default for unlisted servers. Tokens are only ever sent to matching
+// servers, so avoid using a default with bootstrapped queries.
+//
+//	flow := &rdap.OpenIDDeviceFlow{
+//	  Issuer:   "https://login.registry.example",
+//	  ClientID: "my-rdap-client",
+//	  Prompt: func(auth *rdap.DeviceAuthorization) {
+//	    fmt.Printf("Visit %s and enter code %s\n", auth.VerificationURI, auth.UserCode)
+//	  },
+//	}
+//
+//	client := &rdap.Client{
+//	  OpenID: rdap.OpenIDTokens{
+//	    "rdap.registry.example": flow,
+//	  },
+//	}
+type OpenIDTokens map[string]OpenIDTokenSource
+
+// For returns the OpenIDTokenSource for the RDAP server hostname |host|
+// (without port number), or nil if there's no match and no default.
+func (o OpenIDTokens) For(host string) OpenIDTokenSource {
+	return o[matchHost(host, func(h string) bool {
+		_, ok := o[h]
+		return ok
+	})]
+}
+
+// withOpenIDToken returns a copy of |rdapReq| with an access token for its
+// RDAP server, or |rdapReq| unchanged if the Client has no token source for
+// the server.
+func (c *Client) withOpenIDToken(rdapReq *Request) (*Request, error) {
+	if c.OpenID == nil || rdapReq.Server == nil {
+		return rdapReq, nil
+	}
+
+	source := c.OpenID.For(rdapReq.Server.Hostname())
+	if source == nil {
+		return rdapReq, nil
+	}
+
+	token, err := source.Token(rdapReq.Context())
+	if err != nil {
+		return nil, &ClientError{
+			Type: AuthenticationFailed,
+			Text: fmt.Sprintf("OpenID token for %s unavailable: %s", rdapReq.Server.Hostname(), err),
+		}
+	}
+
+	c.Verbose(fmt.Sprintf("client: using OpenID access token for %s", rdapReq.Server.Hostname()))
+
+	r2 := new(Request)
+	*r2 = *rdapReq
+	r2.openIDToken = token
+
+	return r2, nil
+}
+
+// OpenIDProviderMetadata is an OpenID Provider's configuration, obtained via
+// OpenID Connect Discovery. Only the members used by this package are
+// included.
+type OpenIDProviderMetadata struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	RevocationEndpoint          string `json:"revocation_endpoint"`
+}
+
+// DiscoverOpenIDProvider fetches the OpenID Provider metadata for the issuer
+// URL |issuer|, e.g. "https://login.registry.example".
+//
+// |httpClient| may be nil for http.DefaultClient.
+func DiscoverOpenIDProvider(ctx context.Context, httpClient *http.Client, issuer string) (*OpenIDProviderMetadata, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, "GET", discoveryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenID discovery for %s failed: HTTP %d", issuer, resp.StatusCode)
+	}
+
+	metadata := &OpenIDProviderMetadata{}
+	if err := json.Unmarshal(body, metadata); err != nil {
+		return nil, fmt.Errorf("OpenID discovery for %s failed: %s", issuer, err)
+	}
+
+	return metadata, nil
+}
+
+// DeviceAuthorization is an OAuth 2.0 device authorization response (RFC 8628
+// section 3.2). The user completes the authorization by visiting
+// VerificationURI and entering UserCode.
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+
+	// Lifetime of the codes, and the minimum polling interval, in seconds.
+	ExpiresIn int `json:"expires_in"`
+	Interval  int `json:"interval"`
+}
+
+// OpenIDDeviceFlow is an OpenIDTokenSource which obtains tokens using the
+// OAuth 2.0 Device Authorization Grant (RFC 8628), for command line clients
+// without a browser.
+//
+// The token is cached, and refreshed using its refresh token when it expires.
+// A new device authorization is only needed if there's no refresh token, or
+// the refresh fails.
+type OpenIDDeviceFlow struct {
+	// OpenID Provider issuer URL. The provider's endpoints are discovered
+	// using OpenID Connect Discovery, unless Provider is set.
+	Issuer string
+
+	// Optional OpenID Provider endpoints, skipping discovery.
+	Provider *OpenIDProviderMetadata
+
+	// OAuth 2.0 client credentials. ClientSecret is optional.
+	ClientID     string
+	ClientSecret string
+
+	// Scopes requested. The default is "openid".
+	Scopes []string
+
+	// Optional HTTP client. The default is http.DefaultClient.
+	HTTP *http.Client
+
+	// Callback function to show the user the device authorization
+	// instructions. Required.
+	Prompt func(auth *DeviceAuthorization)
+
+	// Optional callback function called with each new token, e.g. to save
+	// the refresh token for later use (see SetToken()).
+	OnToken func(token *OpenIDToken)
+
+	mu    sync.Mutex
+	token *OpenIDToken
+}
+
+// SetToken sets the current token, e.g. a previously saved token.
+func (d *OpenIDDeviceFlow) SetToken(token *OpenIDToken) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.token = token
+}
+
+// Token returns the current token, refreshing it, or running the device
+// authorization flow, as necessary.
+func (d *OpenIDDeviceFlow) Token(ctx context.Context) (*OpenIDToken, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.token != nil && !d.token.Expired() {
+		return d.token, nil
+	}
+
+	provider, err := d.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var token *OpenIDToken
+	if d.token != nil && d.token.RefreshToken != "" {
+		token, err = d.refresh(ctx, provider)
+	}
+
+	if token == nil {
+		token, err = d.authorize(ctx, provider)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	d.token = token
+
+	if d.OnToken != nil {
+		d.OnToken(token)
+	}
+
+	return token, nil
+}
+
+// Refresh forces a token refresh, e.g. after the RDAP server rejected the
+// current token.
+func (d *OpenIDDeviceFlow) Refresh(ctx context.Context) (*OpenIDToken, error) {
+	d.mu.Lock()
+	if d.token != nil {
+		expired := *d.token
+		expired.Expiry = time.Unix(1, 0)
+		d.token = &expired
+	}
+	d.mu.Unlock()
+
+	return d.Token(ctx)
+}
+
+func (d *OpenIDDeviceFlow) provider(ctx context.Context) (*OpenIDProviderMetadata, error) {
+	if d.Provider == nil {
+		provider, err := DiscoverOpenIDProvider(ctx, d.HTTP, d.Issuer)
+		if err != nil {
+			return nil, err
+		}
+
+		d.Provider = provider
+	}
+
+	return d.Provider, nil
+}
+
+// refresh returns a new token using the current token's refresh token, or a
+// nil token if the refresh was refused.
+func (d *OpenIDDeviceFlow) refresh(ctx context.Context, provider *OpenIDProviderMetadata) (*OpenIDToken, error) {
+	token, _, err := d.post(ctx, provider.TokenEndpoint, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {d.token.RefreshToken},
+	})
+
+	if err != nil || token == nil {
+		return nil, err
+	}
+
+	// Refresh responses needn't include a new refresh token.
+	if token.RefreshToken == "" {
+		token.RefreshToken = d.token.RefreshToken
+	}
+
+	return token, nil
+}
+
+// authorize runs the device authorization flow.
+func (d *OpenIDDeviceFlow) authorize(ctx context.Context, provider *OpenIDProviderMetadata) (*OpenIDToken, error) {
+	if provider.DeviceAuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("OpenID Provider %s doesn't support device authorization", provider.Issuer)
+	} else if d.Prompt == nil {
+		return nil, fmt.Errorf("OpenIDDeviceFlow.Prompt not set")
+	}
+
+	scopes := d.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid"}
+	}
+
+	auth := &DeviceAuthorization{}
+	body, err := d.postForm(ctx, provider.DeviceAuthorizationEndpoint, url.Values{
+		"scope": {strings.Join(scopes, " ")},
+	})
+	if err == nil {
+		err = json.Unmarshal(body, auth)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("device authorization failed: %s", err)
+	}
+
+	d.Prompt(auth)
+
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	var expired <-chan time.Time
+	if auth.ExpiresIn > 0 {
+		timer := time.NewTimer(time.Duration(auth.ExpiresIn) * time.Second)
+		defer timer.Stop()
+
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-expired:
+			return nil, fmt.Errorf("device authorization expired")
+		case <-time.After(interval):
+		}
+
+		token, oauthErr, err := d.post(ctx, provider.TokenEndpoint, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {auth.DeviceCode},
+		})
+
+		switch {
+		case err != nil:
+			return nil, err
+		case token != nil:
+			return token, nil
+		case oauthErr == "authorization_pending":
+		case oauthErr == "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, fmt.Errorf("device authorization failed: %s", oauthErr)
+		}
+	}
+}
+
+// post makes an OAuth 2.0 token request. Returns the token, or the OAuth 2.0
+// error code (e.g. "authorization_pending") if the request was refused.
+func (d *OpenIDDeviceFlow) post(ctx context.Context, endpoint string, values url.Values) (*OpenIDToken, string, error) {
+	body, err := d.postForm(ctx, endpoint, values)
+
+	if oauthErr, ok := err.(*oauthError); ok {
+		return nil, oauthErr.Code, nil
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	var resp struct {
+		OpenIDToken
+		ExpiresIn int64 `json:"expires_in"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, "", fmt.Errorf("bad token response: %s", err)
+	} else if resp.AccessToken == "" {
+		return nil, "", fmt.Errorf("bad token response: no access_token")
+	}
+
+	token := resp.OpenIDToken
+	if resp.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+
+	return &token, "", nil
+}
+
+// oauthError is an OAuth 2.0 error response (RFC 6749 section 5.2).
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (o *oauthError) Error() string {
+	if o.Description != "" {
+		return fmt.Sprintf("%s (%s)", o.Code, o.Description)
+	}
+
+	return o.Code
+}
+
+// postForm POSTs the form |values| (plus the client credentials) to
+// |endpoint|, returning the response body. OAuth 2.0 error responses are
+// returned as an *oauthError.
+func (d *OpenIDDeviceFlow) postForm(ctx context.Context, endpoint string, values url.Values) ([]byte, error) {
+	values.Set("client_id", d.ClientID)
+	if d.ClientSecret != "" {
+		values.Set("client_secret", d.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := d.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		oauthErr := &oauthError{}
+		if json.Unmarshal(body, oauthErr) == nil && oauthErr.Code != "" {
+			return nil, oauthErr
+		}
+
+		return nil, fmt.Errorf("%s returned HTTP %d", endpoint, resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+// NewOpenIDLoginRequest creates a login request for an RDAP server's session
+// oriented OpenID Connect support (RFC 9560 section 5.2.2).
+//
+// |id| is the end user's identifier, and |issuer| the OpenID Provider to use.
+// Either may be empty. The login starts an interactive OpenID Connect
+// authentication, so the request's URL is normally opened in a web browser,
+// which then holds the session.
+func NewOpenIDLoginRequest(server *url.URL, id string, issuer string) *Request {
+	loginURL := openIDURL(server, "login")
+
+	values := url.Values{}
+	if id != "" {
+		values.Set("farv1_id", id)
+	}
+	if issuer != "" {
+		values.Set("farv1_iss", issuer)
+	}
+	loginURL.RawQuery = values.Encode()
+
+	return NewRawRequest(loginURL)
+}
+
+// openIDURL returns the URL of the OpenID endpoint |path| (e.g. "login") of
+// the RDAP server |server|.
+func openIDURL(server *url.URL, path string) *url.URL {
+	result := *server
+	result.Path = strings.TrimSuffix(result.Path, "/") + "/" + path
+	result.RawQuery = ""
+	result.Fragment = ""
+
+	return &result
+}
+
+// OpenIDSessionStatus queries the login session status of the RDAP server
+// |server|.
+//
+// Session oriented servers identify the session with a cookie, so the
+// Client's HTTP client needs a cookie jar holding the session cookie.
+func (c *Client) OpenIDSessionStatus(ctx context.Context, server *url.URL) (*OpenIDSession, error) {
+	return c.openIDSession(ctx, server, "session/status")
+}
+
+// OpenIDSessionRefresh refreshes the login session of the RDAP server
+// |server|. See OpenIDSessionStatus().
+func (c *Client) OpenIDSessionRefresh(ctx context.Context, server *url.URL) (*OpenIDSession, error) {
+	return c.openIDSession(ctx, server, "session/refresh")
+}
+
+// OpenIDSessionLogout ends the login session of the RDAP server |server|. See
+// OpenIDSessionStatus().
+func (c *Client) OpenIDSessionLogout(ctx context.Context, server *url.URL) error {
+	_, err := c.openIDSession(ctx, server, "session/logout")
+	return err
+}
+
+func (c *Client) openIDSession(ctx context.Context, server *url.URL, path string) (*OpenIDSession, error) {
+	resp, err := c.doQuickRequest(ctx, NewRawRequest(openIDURL(server, path)))
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := resp.Object.(type) {
+	case *Help:
+		return v.OpenIDSession(), nil
+	case *Error:
+		return nil, clientErrorFromRDAPError(v)
+	}
+
+	return nil, &ClientError{
+		Type: WrongResponseType,
+		Text: "The server returned a non-session RDAP response",
+	}
+}
@@ -0,0 +1,208 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestOpenIDConfiguration(t *testing.T) {
+	helpJSON := `{
+		"rdapConformance": ["rdap_level_0", "farv1"],
+		"farv1_openidcConfiguration": {
+			"sessionClientSupported": true,
+			"queryClientSupported": true,
+			"providerDiscoverySupported": true,
+			"openidcProviders": [
+				{"iss": "https://idp.example", "name": "Example IDP", "default": true}
+			]
+		}
+	}`
+
+	obj, err := NewDecoder([]byte(helpJSON)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	help := obj.(*Help)
+	config := help.OpenIDConfiguration()
+
+	if config == nil || !config.QueryClientSupported || len(config.Providers) != 1 ||
+		config.Providers[0].Issuer != "https://idp.example" || !config.Providers[0].Default {
+		t.Errorf("Unexpected configuration %+v", config)
+	}
+
+	if help.OpenIDSession() != nil {
+		t.Errorf("Unexpected session")
+	}
+
+	if unknown := help.DecodeData.UnknownFields(); len(unknown) != 0 {
+		t.Errorf("Unexpected unknown fields %v", unknown)
+	}
+}
+
+// openIDServer is an OpenID Provider supporting the device authorization
+// flow, and an RDAP server requiring its access tokens.
+type openIDServer struct {
+	*httptest.Server
+
+	prompts   int
+	refreshes int
+}
+
+func newOpenIDServer() *openIDServer {
+	s := &openIDServer{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&OpenIDProviderMetadata{
+			Issuer:                      s.URL,
+			TokenEndpoint:               s.URL + "/token",
+			DeviceAuthorizationEndpoint: s.URL + "/device",
+		})
+	})
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&DeviceAuthorization{
+			DeviceCode:      "device-code",
+			UserCode:        "USER-CODE",
+			VerificationURI: s.URL + "/activate",
+			Interval:        1,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		switch {
+		case r.Form.Get("client_id") != "rdap-client":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client"}`))
+		case r.Form.Get("device_code") == "device-code":
+			w.Write([]byte(`{"access_token":"access-1","refresh_token":"refresh-1","token_type":"Bearer","expires_in":3600}`))
+		case r.Form.Get("refresh_token") == "refresh-1":
+			s.refreshes++
+			w.Write([]byte(`{"access_token":"access-2","token_type":"Bearer","expires_in":3600}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+		}
+	})
+	mux.HandleFunc("/rdap/domain/example.cz", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer access-1", "Bearer access-2":
+			w.Header().Set("Content-Type", MediaTypeRDAP)
+			w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+func TestClientOpenIDDeviceFlow(t *testing.T) {
+	server := newOpenIDServer()
+	defer server.Close()
+
+	flow := &OpenIDDeviceFlow{
+		Issuer:   server.URL,
+		ClientID: "rdap-client",
+		Prompt: func(auth *DeviceAuthorization) {
+			server.prompts++
+
+			if auth.UserCode != "USER-CODE" {
+				t.Errorf("Unexpected user code %q", auth.UserCode)
+			}
+		},
+	}
+
+	rdapURL, _ := url.Parse(server.URL + "/rdap")
+	client := &Client{
+		OpenID:  OpenIDTokens{"127.0.0.1": flow},
+		Verbose: verboseFunc(),
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Do(NewDomainRequest("example.cz").WithServer(rdapURL)); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	if server.prompts != 1 {
+		t.Errorf("Device authorization ran %d times, expected once", server.prompts)
+	}
+
+	// Expired token, refreshed without another device authorization.
+	flow.SetToken(&OpenIDToken{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		Expiry:       time.Now(),
+	})
+
+	token, err := flow.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	} else if token.AccessToken != "access-2" || token.RefreshToken != "refresh-1" {
+		t.Errorf("Unexpected refreshed token %+v", token)
+	} else if server.refreshes != 1 || server.prompts != 1 {
+		t.Errorf("Unexpected refreshes=%d prompts=%d", server.refreshes, server.prompts)
+	}
+
+	// No token source for the server.
+	client.OpenID = OpenIDTokens{"rdap.example": flow}
+	if _, err := client.Do(NewDomainRequest("example.cz").WithServer(rdapURL)); !isClientError(NoWorkingServers, err) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// Token unavailable.
+	client.OpenID = OpenIDTokens{"": &OpenIDDeviceFlow{Issuer: server.URL, ClientID: "unknown"}}
+	if _, err := client.Do(NewDomainRequest("example.cz").WithServer(rdapURL)); !isClientError(AuthenticationFailed, err) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestOpenIDSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rdap/session/status" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write([]byte(`{
+			"rdapConformance": ["rdap_level_0", "farv1"],
+			"farv1_session": {
+				"userID": "user.idp.example",
+				"iss": "https://idp.example",
+				"sessionInfo": {"tokenExpiration": 3599, "tokenRefresh": true}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	rdapURL, _ := url.Parse(server.URL + "/rdap/")
+	client := &Client{}
+
+	session, err := client.OpenIDSessionStatus(context.Background(), rdapURL)
+	if err != nil {
+		t.Fatal(err)
+	} else if session == nil || session.UserID != "user.idp.example" || session.Info.TokenExpiration != 3599 {
+		t.Errorf("Unexpected session %+v", session)
+	}
+
+	login := NewOpenIDLoginRequest(rdapURL, "", "https://idp.example")
+	if got := login.URL().String(); got != server.URL+"/rdap/login?farv1_iss=https%3A%2F%2Fidp.example" {
+		t.Errorf("Unexpected login URL %s", got)
+	}
+}
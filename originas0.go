@@ -0,0 +1,67 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"math"
+)
+
+// OriginAS0Conformance is the rdapConformance identifier of ARIN's originas0
+// extension, listing the origin autonomous systems of IP networks.
+const OriginAS0Conformance = "arin_originas0"
+
+func init() {
+	RegisterExtension(Extension{
+		Name:        OriginAS0Conformance,
+		Conformance: OriginAS0Conformance,
+		Members:     []string{"arin_originas0_originautnums"},
+		Decode:      decodeOriginAS0,
+		Print:       printOriginAS0,
+	})
+}
+
+// OriginAutnums returns the origin AS numbers of the IP network, from its
+// arin_originas0_originautnums member (originas0 extension). Returns nil if
+// it has none.
+func (n *IPNetwork) OriginAutnums() []uint32 {
+	if n.DecodeData == nil {
+		return nil
+	}
+
+	asns, _ := n.DecodeData.Extension(OriginAS0Conformance).([]uint32)
+
+	return asns
+}
+
+func decodeOriginAS0(src map[string]interface{}) (interface{}, error) {
+	v, ok := src["arin_originas0_originautnums"]
+	if !ok {
+		return nil, nil
+	}
+
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("arin_originas0_originautnums is not an array")
+	}
+
+	asns := []uint32{}
+	for i, item := range list {
+		asn, ok := item.(float64)
+		if !ok || asn < 0 || asn > math.MaxUint32 || asn != math.Trunc(asn) {
+			return nil, fmt.Errorf("arin_originas0_originautnums[%d] is not an AS number", i)
+		}
+
+		asns = append(asns, uint32(asn))
+	}
+
+	return asns, nil
+}
+
+func printOriginAS0(value interface{}, p *ExtensionPrinter) {
+	for _, asn := range value.([]uint32) {
+		p.Value("Origin AS", fmt.Sprintf("AS%d", asn))
+	}
+}
@@ -0,0 +1,35 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestOriginAS0(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.arin.net/ip-192.0.2.1.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if asns := obj.(*IPNetwork).OriginAutnums(); !reflect.DeepEqual(asns, []uint32{64496, 64511}) {
+		t.Errorf("Unexpected origin autnums %v", asns)
+	}
+
+	for _, invalid := range []string{`"AS64496"`, `-1`, `4294967296`, `1.5`} {
+		obj, err := NewDecoder([]byte(`{"objectClassName": "ip network", "arin_originas0_originautnums": [` + invalid + `]}`)).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		n := obj.(*IPNetwork)
+		if asns := n.OriginAutnums(); asns != nil || len(n.DecodeData.Notes(OriginAS0Conformance)) != 1 {
+			t.Errorf("%s: unexpected origin autnums %v", invalid, asns)
+		}
+	}
+}
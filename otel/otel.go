@@ -0,0 +1,96 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+// Package otel provides OpenTelemetry tracing for the RDAP client.
+//
+//	client := &rdap.Client{
+//	  Tracer: otel.NewTracer(nil),
+//	}
+//
+// Each query is traced as an "rdap.query" span, with child spans for the
+// bootstrap lookup, each HTTP request, and response decoding. See
+// rdap.Tracer.
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openrdap/rdap"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the OpenTelemetry instrumentation scope name.
+const InstrumentationName = "github.com/openrdap/rdap"
+
+// Tracer implements rdap.Tracer using OpenTelemetry.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns an rdap.Tracer creating spans using the
+// TracerProvider |provider|. If |provider| is nil, the global TracerProvider
+// is used.
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &Tracer{
+		tracer: provider.Tracer(InstrumentationName),
+	}
+}
+
+// Start starts an OpenTelemetry span.
+func (t *Tracer) Start(ctx context.Context, name string, attrs map[string]interface{}) (context.Context, rdap.Span) {
+	kind := trace.SpanKindInternal
+	if name == rdap.SpanHTTP {
+		kind = trace.SpanKindClient
+	}
+
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for k, v := range attrs {
+		kvs = append(kvs, keyValue(k, v))
+	}
+
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(kvs...))
+
+	return ctx, &spanAdapter{span: span}
+}
+
+type spanAdapter struct {
+	span trace.Span
+}
+
+func (s *spanAdapter) SetAttribute(key string, value interface{}) {
+	s.span.SetAttributes(keyValue(key, value))
+}
+
+func (s *spanAdapter) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+
+	s.span.End()
+}
+
+// keyValue converts an rdap.Span attribute to an OpenTelemetry attribute.
+func keyValue(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,68 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package otel
+
+import (
+	"testing"
+
+	"github.com/openrdap/rdap"
+	"github.com/openrdap/rdap/test"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client := &rdap.Client{
+		Tracer: NewTracer(provider),
+	}
+
+	if _, err := client.QueryDomain("example.cz"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, err := client.QueryDomain("non-existent.cz"); err == nil {
+		t.Fatalf("Unexpected success")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 7 {
+		t.Fatalf("Got %d spans, expected 7", len(spans))
+	}
+
+	// Spans end innermost first.
+	query := spans[3]
+	if query.Name() != rdap.SpanQuery || query.Status().Code == codes.Error {
+		t.Fatalf("Unexpected query span %s %v", query.Name(), query.Status())
+	}
+
+	for _, span := range spans[0:3] {
+		if span.Parent().SpanID() != query.SpanContext().SpanID() {
+			t.Errorf("Span %s isn't a child of the query span", span.Name())
+		}
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range query.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+
+	if attrs[rdap.AttrTLD].AsString() != "cz" || attrs[rdap.AttrQueryType].AsString() != "domain" {
+		t.Errorf("Unexpected query span attributes %v", query.Attributes())
+	}
+
+	// Failed query.
+	if failed := spans[6]; failed.Name() != rdap.SpanQuery || failed.Status().Code != codes.Error {
+		t.Errorf("Unexpected failed query span %s %v", failed.Name(), failed.Status())
+	}
+}
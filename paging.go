@@ -0,0 +1,421 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// rdapConformance identifiers of the sorting and paging (RFC 8977), and
+// partial response (RFC 8982) extensions.
+const (
+	SortingConformance    = "sorting"
+	PagingConformance     = "paging"
+	SubsettingConformance = "subsetting"
+)
+
+// PagingMetadata describes the page of search results returned (RFC 8977
+// section 2.2).
+type PagingMetadata struct {
+	// Total number of search results, if requested (see Request.WithCount()),
+	// and known. 0 otherwise.
+	TotalCount int `json:"totalCount"`
+
+	PageSize   int    `json:"pageSize"`
+	PageNumber int    `json:"pageNumber"`
+	Links      []Link `json:"links"`
+}
+
+// Next returns the URL of the next page of search results, or "" if this is
+// the last page.
+func (p *PagingMetadata) Next() string {
+	for _, l := range p.Links {
+		if l.Rel == "next" && l.Href != "" {
+			return l.Href
+		}
+	}
+
+	return ""
+}
+
+// SortingMetadata describes the sort order of the search results returned,
+// and the sort orders available (RFC 8977 section 2.1).
+type SortingMetadata struct {
+	// Current sort, e.g. "registrationDate:d". "" if the results are unsorted.
+	CurrentSort    string         `json:"currentSort"`
+	AvailableSorts []SortProperty `json:"availableSorts"`
+}
+
+// SortProperty is a property which search results can be sorted by.
+type SortProperty struct {
+	// e.g. "registrationDate".
+	Property string `json:"property"`
+
+	// JSONPath of the property in the search results.
+	JSONPath string `json:"jsonPath"`
+
+	Default bool   `json:"default"`
+	Links   []Link `json:"links"`
+}
+
+// SubsettingMetadata describes the field set (partial response) returned,
+// and the field sets available (RFC 8982 section 4).
+type SubsettingMetadata struct {
+	// Current field set, e.g. "brief".
+	CurrentFieldSet    string     `json:"currentFieldSet"`
+	AvailableFieldSets []FieldSet `json:"availableFieldSets"`
+}
+
+// FieldSet is a named set of response fields, e.g. "id", "brief", or "full".
+type FieldSet struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+	Links       []Link `json:"links"`
+}
+
+// FieldSet returns the available field set named |name|, or nil if there's
+// none.
+func (s *SubsettingMetadata) FieldSet(name string) *FieldSet {
+	for i := range s.AvailableFieldSets {
+		if s.AvailableFieldSets[i].Name == name {
+			return &s.AvailableFieldSets[i]
+		}
+	}
+
+	return nil
+}
+
+// Default returns the server's default field set, or nil if none is marked
+// as the default.
+func (s *SubsettingMetadata) Default() *FieldSet {
+	for i := range s.AvailableFieldSets {
+		if s.AvailableFieldSets[i].Default {
+			return &s.AvailableFieldSets[i]
+		}
+	}
+
+	return nil
+}
+
+// Choose returns the first of the field sets |preferred| which the server
+// offers, e.g. Choose("brief", "id") for the smallest useful search results.
+// Returns "" if none are offered.
+func (s *SubsettingMetadata) Choose(preferred ...string) string {
+	for _, name := range preferred {
+		if s.FieldSet(name) != nil {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func init() {
+	RegisterExtension(Extension{
+		Name:        PagingConformance,
+		Conformance: PagingConformance,
+		Members:     []string{"paging_metadata"},
+		Decode: func(src map[string]interface{}) (interface{}, error) {
+			return decodeMetadata(src, "paging_metadata", &PagingMetadata{})
+		},
+		Print: printPaging,
+	})
+
+	RegisterExtension(Extension{
+		Name:        SortingConformance,
+		Conformance: SortingConformance,
+		Members:     []string{"sorting_metadata"},
+		Decode: func(src map[string]interface{}) (interface{}, error) {
+			return decodeMetadata(src, "sorting_metadata", &SortingMetadata{})
+		},
+		Print: printSorting,
+	})
+
+	RegisterExtension(Extension{
+		Name:        SubsettingConformance,
+		Conformance: SubsettingConformance,
+		Members:     []string{"subsetting_metadata"},
+		Decode: func(src map[string]interface{}) (interface{}, error) {
+			return decodeMetadata(src, "subsetting_metadata", &SubsettingMetadata{})
+		},
+		Print: printSubsetting,
+	})
+}
+
+// decodeMetadata decodes the JSON member |member| of |src| into |result|.
+// Returns nil if there's no such member.
+func decodeMetadata(src map[string]interface{}, member string, result interface{}) (interface{}, error) {
+	value, ok := src[member]
+	if !ok {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", member, err)
+	}
+
+	return result, nil
+}
+
+func printPaging(value interface{}, p *ExtensionPrinter) {
+	paging := value.(*PagingMetadata)
+
+	h := p.Heading("Paging")
+	h.Value("Page number", fmt.Sprintf("%d", paging.PageNumber))
+	h.Value("Page size", fmt.Sprintf("%d", paging.PageSize))
+	if paging.TotalCount > 0 {
+		h.Value("Total count", fmt.Sprintf("%d", paging.TotalCount))
+	}
+	h.Value("Next page", paging.Next())
+}
+
+func printSorting(value interface{}, p *ExtensionPrinter) {
+	sorting := value.(*SortingMetadata)
+
+	h := p.Heading("Sorting")
+	h.Value("Current sort", sorting.CurrentSort)
+	for _, s := range sorting.AvailableSorts {
+		h.Value("Available sort", s.Property)
+	}
+}
+
+func printSubsetting(value interface{}, p *ExtensionPrinter) {
+	subsetting := value.(*SubsettingMetadata)
+
+	h := p.Heading("Field Sets")
+	h.Value("Current field set", subsetting.CurrentFieldSet)
+	for _, f := range subsetting.AvailableFieldSets {
+		h.Value("Available field set", f.Name)
+	}
+}
+
+// Paging returns the response's paging metadata, or nil if there is none.
+func (r *Response) Paging() *PagingMetadata {
+	paging, _ := r.extension(PagingConformance).(*PagingMetadata)
+	return paging
+}
+
+// Sorting returns the response's sorting metadata, or nil if there is none.
+func (r *Response) Sorting() *SortingMetadata {
+	sorting, _ := r.extension(SortingConformance).(*SortingMetadata)
+	return sorting
+}
+
+// Subsetting returns the response's field set metadata, or nil if there is
+// none.
+func (r *Response) Subsetting() *SubsettingMetadata {
+	subsetting, _ := r.extension(SubsettingConformance).(*SubsettingMetadata)
+	return subsetting
+}
+
+// extension returns the decoded value of extension |name| in the topmost RDAP
+// object, or nil if there is none.
+func (r *Response) extension(name string) interface{} {
+	d := decodeDataOf(r.Object)
+	if d == nil {
+		return nil
+	}
+
+	return d.Extension(name)
+}
+
+// WithSort returns a copy of the Request, with the search results sorted by
+// |keys| (RFC 8977), e.g. "registrationDate:d" or "name:a". See
+// Response.Sorting() for the sort properties a server supports.
+func (r *Request) WithSort(keys ...string) *Request {
+	return r.withParam("sort", strings.Join(keys, ","))
+}
+
+// WithCount returns a copy of the Request, which asks the server for the total
+// number of search results (RFC 8977). See PagingMetadata.TotalCount.
+func (r *Request) WithCount() *Request {
+	return r.withParam("count", "true")
+}
+
+// WithCursor returns a copy of the Request, for the page of search results at
+// |cursor| (RFC 8977). Cursors are opaque, and are normally taken from
+// PagingMetadata links. See also Client.Pages().
+func (r *Request) WithCursor(cursor string) *Request {
+	return r.withParam("cursor", cursor)
+}
+
+// WithFieldSet returns a copy of the Request, asking for the field set
+// |fieldSet| (RFC 8982), e.g. "brief". See Response.Subsetting() for the field
+// sets a server supports.
+func (r *Request) WithFieldSet(fieldSet string) *Request {
+	return r.withParam("fieldSet", fieldSet)
+}
+
+// isSearchRequestType returns true if |t| is a search (or reverse search)
+// request type.
+func isSearchRequestType(t RequestType) bool {
+	switch t {
+	case DomainSearchRequest, DomainSearchByNameserverRequest, DomainSearchByNameserverIPRequest,
+		NameserverSearchRequest, NameserverSearchByNameserverIPRequest,
+		EntitySearchRequest, EntitySearchByHandleRequest, AutnumSearchRequest,
+		DomainReverseSearchRequest, NameserverReverseSearchRequest, EntityReverseSearchRequest:
+		return true
+	}
+
+	return false
+}
+
+// withParam returns a copy of the Request, with the URL query parameter |key|
+// set to |value|.
+func (r *Request) withParam(key string, value string) *Request {
+	r2 := new(Request)
+	*r2 = *r
+
+	r2.Params = url.Values{}
+	for k, v := range r.Params {
+		r2.Params[k] = v
+	}
+	r2.Params.Set(key, value)
+
+	return r2
+}
+
+// PageIterator walks the pages of search results of a search Request. See
+// Client.Pages().
+type PageIterator struct {
+	client *Client
+	next   *Request
+	seen   map[string]bool
+
+	resp *Response
+	err  error
+}
+
+// Pages returns a PageIterator for the pages of search results of the search
+// Request |req|. Each page after the first is fetched by following the
+// previous page's "next" link (RFC 8977):
+//
+//	pages := client.Pages(req)
+//
+//	for pages.Next() {
+//	  results := pages.Response().Object.(*rdap.DomainSearchResults)
+//	  ...
+//	}
+//
+//	if err := pages.Err(); err != nil {
+//	  ...
+//	}
+//
+// The pages are fetched with Do(), using |req|'s context.
+func (c *Client) Pages(req *Request) *PageIterator {
+	return &PageIterator{
+		client: c,
+		next:   req,
+		seen:   map[string]bool{},
+	}
+}
+
+// Next fetches the next page of search results. Returns false when there are
+// no more pages, or on error. See Response() and Err().
+func (p *PageIterator) Next() bool {
+	if p.next == nil || p.err != nil {
+		return false
+	}
+
+	req := p.next
+	p.next = nil
+	p.resp = nil
+
+	if err := req.Context().Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	if u := req.URL(); u != nil {
+		p.seen[u.String()] = true
+	}
+
+	resp, err := p.client.Do(req)
+	if err == nil {
+		if respError, ok := resp.Object.(*Error); ok {
+			err = clientErrorFromRDAPError(respError)
+		}
+	}
+
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	p.resp = resp
+
+	if paging := resp.Paging(); paging != nil && paging.Next() != "" {
+		nextURL, err := url.Parse(paging.Next())
+		if err == nil && req.Server != nil {
+			nextURL = req.Server.ResolveReference(nextURL)
+		}
+
+		if err != nil || !isHTTPURL(nextURL) {
+			p.err = &ClientError{
+				Type: WrongResponseType,
+				Text: fmt.Sprintf("Invalid next page URL '%s'", paging.Next()),
+			}
+		} else if !p.seen[nextURL.String()] {
+			next := NewRawRequest(nextURL).WithContext(req.Context())
+			next.HTTP = req.HTTP
+			next.Timeout = req.Timeout
+
+			p.next = next
+		}
+	}
+
+	return true
+}
+
+// Response returns the current page of search results. The decoded object
+// is e.g. a *DomainSearchResults.
+func (p *PageIterator) Response() *Response {
+	return p.resp
+}
+
+// Err returns the error which stopped the iteration, or nil.
+func (p *PageIterator) Err() error {
+	return p.err
+}
+
+// AllPages returns an iterator over the pages of search results of the search
+// Request |req|, as per Pages(). It's compatible with range-over-func (Go
+// 1.23, as an iter.Seq2[*Response, error]):
+//
+//	for resp, err := range client.AllPages(req) {
+//	  if err != nil {
+//	    ...
+//	  }
+//
+//	  results := resp.Object.(*rdap.DomainSearchResults)
+//	  ...
+//	}
+//
+// An error (e.g. from a failed page, or |req|'s context being cancelled) is
+// yielded with a nil Response, and ends the iteration.
+func (c *Client) AllPages(req *Request) func(yield func(*Response, error) bool) {
+	return func(yield func(*Response, error) bool) {
+		pages := c.Pages(req)
+
+		for pages.Next() {
+			if !yield(pages.Response(), nil) {
+				return
+			}
+		}
+
+		if err := pages.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
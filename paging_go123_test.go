@@ -0,0 +1,83 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+//go:build go1.23
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestClientAllPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		if cursor == "p3" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		next := "p2"
+		if cursor == "p2" {
+			next = "p3"
+		}
+
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		fmt.Fprintf(w, `{
+			"rdapConformance": ["rdap_level_0", "paging"],
+			"domainSearchResults": [{"objectClassName": "domain", "ldhName": "example-%s.cz"}],
+			"paging_metadata": {"pageSize": 1, "links": [{"rel": "next", "href": "/domains?name=exampl*.cz&cursor=%s"}]}
+		}`, cursor, next)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	req := NewRequest(DomainSearchRequest, "exampl*.cz").WithServer(serverURL)
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	var names []string
+	var lastErr error
+	for resp, err := range client.AllPages(req) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+
+		names = append(names, resp.Object.(*DomainSearchResults).Domains[0].LDHName)
+	}
+
+	if fmt.Sprint(names) != "[example-.cz example-p2.cz]" || lastErr == nil {
+		t.Errorf("Unexpected results %v, error %v", names, lastErr)
+	}
+
+	// Stopping early.
+	count := 0
+	for range client.AllPages(req) {
+		count++
+		break
+	}
+
+	if count != 1 {
+		t.Errorf("Unexpected page count %d", count)
+	}
+
+	// Cancelled context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for resp, err := range client.AllPages(req.WithContext(ctx)) {
+		if resp != nil || !errors.Is(err, context.Canceled) {
+			t.Errorf("Unexpected response %v, error %v", resp, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestClientPages(t *testing.T) {
+	var queries []url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+
+		next := ""
+		page := 1
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			next = `, "links": [{"rel": "next", "href": "/domains?name=exampl*.cz&sort=name:a&cursor=p2", "type": "application/rdap+json"}]`
+		case "p2":
+			page = 2
+		}
+
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		fmt.Fprintf(w, `{
+			"rdapConformance": ["rdap_level_0", "paging", "sorting", "subsetting"],
+			"domainSearchResults": [
+				{"objectClassName": "domain", "ldhName": "example%d.cz"}
+			],
+			"paging_metadata": {"totalCount": 2, "pageSize": 1, "pageNumber": %d%s},
+			"sorting_metadata": {
+				"currentSort": "name:a",
+				"availableSorts": [{"property": "name", "jsonPath": "$.domainSearchResults[*].ldhName", "default": true}]
+			},
+			"subsetting_metadata": {
+				"currentFieldSet": "brief",
+				"availableFieldSets": [{"name": "brief", "default": false}, {"name": "full", "default": true}]
+			}
+		}`, page, page, next)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	req := NewRequest(DomainSearchRequest, "exampl*.cz").WithServer(serverURL).
+		WithSort("name:a").WithCount().WithFieldSet("brief")
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	var names []string
+	pages := client.Pages(req)
+
+	for pages.Next() {
+		resp := pages.Response()
+
+		for _, d := range resp.Object.(*DomainSearchResults).Domains {
+			names = append(names, d.LDHName)
+		}
+
+		if paging := resp.Paging(); paging == nil || paging.TotalCount != 2 {
+			t.Errorf("Unexpected paging metadata %+v", paging)
+		}
+
+		if sorting := resp.Sorting(); sorting == nil || sorting.CurrentSort != "name:a" || len(sorting.AvailableSorts) != 1 {
+			t.Errorf("Unexpected sorting metadata %+v", sorting)
+		}
+
+		if subsetting := resp.Subsetting(); subsetting == nil || subsetting.CurrentFieldSet != "brief" || len(subsetting.AvailableFieldSets) != 2 {
+			t.Errorf("Unexpected subsetting metadata %+v", subsetting)
+		}
+
+		if unknown := decodeDataOf(resp.Object).UnknownFields(); len(unknown) != 0 {
+			t.Errorf("Unexpected unknown fields %v", unknown)
+		}
+	}
+
+	if err := pages.Err(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if fmt.Sprint(names) != "[example1.cz example2.cz]" {
+		t.Errorf("Unexpected results %v", names)
+	}
+
+	if len(queries) != 2 || queries[0].Get("sort") != "name:a" || queries[0].Get("count") != "true" ||
+		queries[0].Get("fieldSet") != "brief" || queries[1].Get("cursor") != "p2" {
+		t.Errorf("Unexpected queries %v", queries)
+	}
+
+	if req.WithCursor("abc").Params.Get("cursor") != "abc" || req.Params.Get("cursor") != "" {
+		t.Errorf("WithCursor modified the original Request")
+	}
+}
+
+func TestClientFieldSet(t *testing.T) {
+	var queries []url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		fmt.Fprint(w, `{
+			"rdapConformance": ["rdap_level_0", "subsetting"],
+			"domainSearchResults": [{"objectClassName": "domain", "ldhName": "example.cz"}],
+			"subsetting_metadata": {
+				"currentFieldSet": "id",
+				"availableFieldSets": [{"name": "id"}, {"name": "brief"}, {"name": "full", "default": true}]
+			}
+		}`)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	client := &Client{
+		Verbose:  verboseFunc(),
+		FieldSet: "id",
+	}
+
+	reqs := []*Request{
+		NewRequest(DomainSearchRequest, "exampl*.cz").WithServer(serverURL),
+		NewRequest(DomainSearchRequest, "exampl*.cz").WithServer(serverURL).WithFieldSet("brief"),
+		NewRequest(DomainRequest, "example.cz").WithServer(serverURL),
+	}
+
+	var resp *Response
+	for _, req := range reqs {
+		var err error
+		if resp, err = client.Do(req); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	if len(queries) != 3 || queries[0].Get("fieldSet") != "id" || queries[1].Get("fieldSet") != "brief" ||
+		queries[2].Has("fieldSet") {
+		t.Errorf("Unexpected queries %v", queries)
+	}
+
+	subsetting := resp.Subsetting()
+	if subsetting == nil {
+		t.Fatalf("No subsetting metadata")
+	}
+
+	if d := subsetting.Default(); d == nil || d.Name != "full" {
+		t.Errorf("Unexpected default field set %+v", d)
+	}
+
+	if subsetting.Choose("summary", "brief", "id") != "brief" || subsetting.Choose("summary") != "" ||
+		subsetting.FieldSet("nope") != nil {
+		t.Errorf("Unexpected field set choice")
+	}
+}
@@ -0,0 +1,126 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"time"
+)
+
+// Politeness limits the load a Client places on an RDAP server.
+//
+// Politeness rate limits apply per RDAP server hostname, in addition to any
+// Client.RateLimiter.
+//
+// Many registries rate limit, or block, clients making too many queries. Use
+// DefaultPolitenessPresets() for curated per-registry defaults:
+//
+//	client := &rdap.Client{
+//	  Politeness: rdap.DefaultPolitenessPresets(),
+//	}
+type Politeness struct {
+	// Maximum queries per second to each server hostname, with no bursts.
+	// Each HTTP attempt, including retries, counts as a query. 0 means
+	// unlimited.
+	QPS float64
+
+	// Maximum concurrent queries. 0 means unlimited.
+	Concurrency int
+
+	// Retry policy for the server, used unless Client.RetryPolicy is set.
+	Retry *RetryPolicy
+}
+
+// PolitenessPresets maps RDAP server hostnames to Politeness policies.
+//
+// A hostname matches itself and its subdomains, e.g. "arin.net" matches
+// "rdap.arin.net". The most specific match is used. The empty string key is
+// the default for unlisted servers.
+//
+// Presets can be overridden by modifying the map:
+//
+//	presets := rdap.DefaultPolitenessPresets()
+//	presets["rdap.nic.cz"] = rdap.Politeness{QPS: 5, Concurrency: 2}
+type PolitenessPresets map[string]Politeness
+
+// DefaultPolitenessPresets returns conservative per-registry Politeness
+// policies.
+//
+// These are derived from the registries' published RDAP/WHOIS access policies
+// and observed rate limiting. They're intentionally on the cautious side:
+// registries rarely publish exact limits, and change them without notice.
+func DefaultPolitenessPresets() PolitenessPresets {
+	backoff := func(initial time.Duration) *RetryPolicy {
+		return &RetryPolicy{
+			MaxAttempts:          3,
+			InitialBackoff:       initial,
+			MaxBackoff:           time.Minute,
+			Multiplier:           2,
+			Jitter:               0.2,
+			RetryableStatusCodes: []int{429, 502, 503, 504},
+		}
+	}
+
+	return PolitenessPresets{
+		// Default for all other servers.
+		"": {QPS: 1, Concurrency: 2, Retry: backoff(time.Second)},
+
+		// Regional Internet Registries.
+		"arin.net":    {QPS: 2, Concurrency: 2, Retry: backoff(time.Second * 2)},
+		"ripe.net":    {QPS: 2, Concurrency: 2, Retry: backoff(time.Second * 2)},
+		"apnic.net":   {QPS: 2, Concurrency: 2, Retry: backoff(time.Second * 2)},
+		"afrinic.net": {QPS: 1, Concurrency: 1, Retry: backoff(time.Second * 5)},
+		"lacnic.net":  {QPS: 0.2, Concurrency: 1, Retry: backoff(time.Second * 10)},
+
+		// Domain registries.
+		"verisign.com":               {QPS: 2, Concurrency: 2, Retry: backoff(time.Second * 5)},
+		"identitydigital.services":   {QPS: 1, Concurrency: 1, Retry: backoff(time.Second * 5)},
+		"publicinterestregistry.org": {QPS: 1, Concurrency: 1, Retry: backoff(time.Second * 5)},
+		"registry.google":            {QPS: 2, Concurrency: 2, Retry: backoff(time.Second * 2)},
+		"nominet.uk":                 {QPS: 1, Concurrency: 1, Retry: backoff(time.Second * 5)},
+		"nic.cz":                     {QPS: 1, Concurrency: 1, Retry: backoff(time.Second * 5)},
+		"registro.br":                {QPS: 0.5, Concurrency: 1, Retry: backoff(time.Second * 10)},
+	}
+}
+
+// For returns the Politeness policy for the RDAP server hostname |host|
+// (without port number).
+//
+// Returns the zero Politeness (no limits) if there's no match and no default.
+func (p PolitenessPresets) For(host string) Politeness {
+	return p[matchHost(host, func(h string) bool {
+		_, ok := p[h]
+		return ok
+	})]
+}
+
+// acquireSlot waits for a concurrency slot for the RDAP server |host|, as per
+// |policy|.
+//
+// Returns a function to release the slot, or an error if |ctx| is done first.
+func (c *Client) acquireSlot(ctx context.Context, host string, policy Politeness) (func(), error) {
+	if policy.Concurrency <= 0 {
+		return func() {}, nil
+	}
+
+	c.mu.Lock()
+	if c.slots == nil {
+		c.slots = map[string]chan struct{}{}
+	}
+
+	slots, ok := c.slots[host]
+	if !ok || cap(slots) != policy.Concurrency {
+		slots = make(chan struct{}, policy.Concurrency)
+		c.slots[host] = slots
+	}
+	c.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
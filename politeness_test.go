@@ -0,0 +1,96 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPolitenessPresetsFor(t *testing.T) {
+	p := DefaultPolitenessPresets()
+	p["rdap.nic.cz"] = Politeness{QPS: 5}
+
+	tests := []struct {
+		Host     string
+		Expected float64
+	}{
+		{"rdap.arin.net", 2},
+		{"RDAP.LACNIC.NET.", 0.2},
+		{"rdap.nic.cz", 5},
+		{"other.nic.cz", 1},
+		{"rdap.example", 1},
+	}
+
+	for _, test := range tests {
+		if got := p.For(test.Host).QPS; got != test.Expected {
+			t.Errorf("For(%s).QPS = %f, expected %f", test.Host, got, test.Expected)
+		}
+	}
+
+	if (PolitenessPresets{}).For("rdap.arin.net").Concurrency != 0 {
+		t.Errorf("Empty presets not unlimited")
+	}
+}
+
+// concurrencyTransport is a http.RoundTripper which records the maximum
+// number of concurrent requests.
+type concurrencyTransport struct {
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+func (c *concurrencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.current++
+	if c.current > c.max {
+		c.max = c.current
+	}
+	c.mu.Unlock()
+
+	time.Sleep(time.Millisecond * 10)
+
+	c.mu.Lock()
+	c.current--
+	c.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: 404,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestPolitenessConcurrency(t *testing.T) {
+	transport := &concurrencyTransport{}
+
+	client := &Client{
+		HTTP:       &http.Client{Transport: transport},
+		Politeness: PolitenessPresets{"": {Concurrency: 2}},
+	}
+
+	server, _ := url.Parse("https://rdap.example")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.Do(NewDomainRequest("example.cz").WithServer(server))
+		}()
+	}
+	wg.Wait()
+
+	if transport.max != 2 {
+		t.Errorf("Max concurrency %d, expected 2", transport.max)
+	}
+}
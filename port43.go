@@ -0,0 +1,104 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"fmt"
+)
+
+// Port43 returns the port 43 (WHOIS) server of the response's RDAP object,
+// e.g. "whois.nic.cz", or "" if there's none.
+func (r *Response) Port43() string {
+	return port43Of(r.Object)
+}
+
+// port43Of returns the port43 member of the topmost RDAP object |obj|.
+func port43Of(obj RDAPObject) string {
+	switch v := obj.(type) {
+	case *Domain:
+		return v.Port43
+	case *Entity:
+		return v.Port43
+	case *Nameserver:
+		return v.Port43
+	case *Autnum:
+		return v.Port43
+	case *IPNetwork:
+		return v.Port43
+	default:
+		return ""
+	}
+}
+
+// whoisQueryOf returns the WHOIS query text for the topmost RDAP object |obj|,
+// or "" if unknown.
+func whoisQueryOf(obj RDAPObject) string {
+	switch v := obj.(type) {
+	case *Domain:
+		return v.LDHName
+	case *Entity:
+		return v.Handle
+	case *Nameserver:
+		return v.LDHName
+	case *Autnum:
+		if v.StartAutnum != nil {
+			return fmt.Sprintf("AS%d", *v.StartAutnum)
+		}
+
+		return v.Handle
+	case *IPNetwork:
+		return v.StartAddress
+	default:
+		return ""
+	}
+}
+
+// QueryServer sends |query| to the WHOIS server |server| (e.g.
+// "whois.nic.cz", or "whois.nic.cz:4343").
+func (w *WhoisClient) QueryServer(ctx context.Context, server string, query string) (*WhoisResponse, error) {
+	text, err := w.query(ctx, server, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WhoisResponse{
+		Server: server,
+		Query:  query,
+		Text:   text,
+	}, nil
+}
+
+// QueryPort43 fetches the legacy WHOIS record corresponding to the RDAP
+// response |resp|, from the response's port43 server. This is intended for
+// comparing RDAP and WHOIS data.
+//
+// The WHOIS query is the domain/nameserver name, entity handle, network start
+// address, or AS number. Client.WhoisFallback is used for the query if set
+// (for its timeout and dial function), otherwise a default WhoisClient.
+//
+// Returns an InputError if the response has no port43 server.
+func (c *Client) QueryPort43(ctx context.Context, resp *Response) (*WhoisResponse, error) {
+	c.init()
+
+	server := resp.Port43()
+	query := whoisQueryOf(resp.Object)
+
+	if server == "" || query == "" {
+		return nil, &ClientError{
+			Type: InputError,
+			Text: "Response has no port43 server",
+		}
+	}
+
+	w := c.WhoisFallback
+	if w == nil {
+		w = &WhoisClient{}
+	}
+
+	c.Verbose(fmt.Sprintf("client: Querying port43 server %s for '%s'", server, query))
+
+	return w.QueryServer(ctx, server, query)
+}
@@ -0,0 +1,44 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestClientQueryPort43(t *testing.T) {
+	server := whoisServer(t, func(query string) string {
+		return "Domain Name: " + strings.ToUpper(query) + "\r\n"
+	})
+	defer server.Close()
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	resp := &Response{
+		Object: &Domain{LDHName: "example.cz", Port43: server.Addr().String()},
+	}
+
+	if resp.Port43() != server.Addr().String() {
+		t.Errorf("Unexpected port43 %q", resp.Port43())
+	}
+
+	whois, err := client.QueryPort43(context.Background(), resp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if whois.Query != "example.cz" || whois.Text != "Domain Name: EXAMPLE.CZ\r\n" {
+		t.Errorf("Unexpected WHOIS response %+v", whois)
+	}
+
+	_, err = client.QueryPort43(context.Background(), &Response{Object: &Domain{LDHName: "example.cz"}})
+	if !isClientError(InputError, err) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
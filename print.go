@@ -807,6 +807,8 @@ func (p *Printer) printUnknowns(d *DecodeData, indentLevel uint) {
 		return
 	}
 
+	p.printExtensions(d, indentLevel)
+
 	for k, v := range d.values {
 		isKnown, _ := d.isKnown[k]
 		isOverrided, _ := d.overrideKnownValue[k]
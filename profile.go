@@ -0,0 +1,142 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import "fmt"
+
+// ProfileViolation is a requirement of an RDAP profile which a response
+// doesn't meet. See ValidateNROProfile().
+type ProfileViolation struct {
+	// Section of the profile, e.g. "events".
+	Section string
+
+	// Description of the violation, e.g. "missing registration event".
+	Text string
+}
+
+// String returns the violation as "section: text".
+func (v ProfileViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Section, v.Text)
+}
+
+// profileViolations collects ProfileViolations.
+type profileViolations []ProfileViolation
+
+func (p *profileViolations) add(section string, format string, args ...interface{}) {
+	*p = append(*p, ProfileViolation{
+		Section: section,
+		Text:    fmt.Sprintf(format, args...),
+	})
+}
+
+// NROProfileConformance is the rdapConformance identifier of the NRO RDAP
+// profile, followed by the Regional Internet Registries.
+const NROProfileConformance = "nro_rdap_profile_0"
+
+// ValidateNROProfile checks the IP network or autnum response |obj| against
+// the NRO RDAP profile, returning the violations found (nil if none):
+//   - "conformance": rdapConformance must include rdap_level_0 and
+//     nro_rdap_profile_0 (and cidr0 for IP networks).
+//   - "notices": a terms of service notice is required.
+//   - "links": a self link is required.
+//   - "events": registration and last changed events are required.
+//   - "entities": a registrant entity is required, and entities must have a
+//     handle.
+//   - "object": the object's required members (handle, addresses/AS
+//     numbers, IP version, CIDRs).
+//
+// Other objects aren't covered by the profile, and fail with a violation.
+func ValidateNROProfile(obj RDAPObject) []ProfileViolation {
+	var v profileViolations
+
+	var events []Event
+	var isNetwork bool
+
+	switch o := obj.(type) {
+	case *IPNetwork:
+		isNetwork = true
+		events = o.Events
+
+		if o.Handle == "" {
+			v.add("object", "missing handle")
+		}
+		if o.StartAddress == "" || o.EndAddress == "" {
+			v.add("object", "missing startAddress/endAddress")
+		}
+		if o.IPVersion != "v4" && o.IPVersion != "v6" {
+			v.add("object", "invalid ipVersion %q", o.IPVersion)
+		}
+		if len(o.CIDRs()) == 0 {
+			v.add("object", "missing cidr0_cidrs")
+		}
+	case *Autnum:
+		events = o.Events
+
+		if o.Handle == "" {
+			v.add("object", "missing handle")
+		}
+		if o.StartAutnum == nil || o.EndAutnum == nil {
+			v.add("object", "missing startAutnum/endAutnum")
+		}
+	default:
+		v.add("object", "%T responses are not covered by the NRO RDAP profile", obj)
+		return v
+	}
+
+	conformance := map[string]bool{}
+	for _, c := range conformanceOf(obj) {
+		conformance[c] = true
+	}
+
+	required := []string{RDAPLevel0Conformance, NROProfileConformance}
+	if isNetwork {
+		required = append(required, CIDR0Conformance)
+	}
+
+	for _, c := range required {
+		if !conformance[c] {
+			v.add("conformance", "rdapConformance missing %s", c)
+		}
+	}
+
+	hasTermsOfService := false
+	for _, n := range noticesOf(obj) {
+		if isTermsOfServiceNotice(n) {
+			hasTermsOfService = true
+		}
+	}
+	if !hasTermsOfService {
+		v.add("notices", "missing terms of service notice")
+	}
+
+	hasSelf := false
+	for _, l := range linksOf(obj) {
+		if l.Rel == "self" && l.Href != "" {
+			hasSelf = true
+		}
+	}
+	if !hasSelf {
+		v.add("links", "missing self link")
+	}
+
+	for _, action := range []string{EventRegistration, EventLastChanged} {
+		if _, ok := eventTime(events, action); !ok {
+			v.add("events", "missing or invalid %s event", action)
+		}
+	}
+
+	entities := entitiesOf(obj)
+	if findEntityByRole(RoleRegistrant, entities) == nil {
+		v.add("entities", "missing registrant entity")
+	}
+
+	for i, e := range entities {
+		if e.Handle == "" {
+			v.add("entities", "entity %d (%v) has no handle", i, e.Roles)
+		}
+	}
+
+	return v
+}
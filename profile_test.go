@@ -0,0 +1,98 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestValidateNROProfile(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.arin.net/ip-192.0.2.1.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := obj.(*IPNetwork)
+
+	expected := []ProfileViolation{
+		{"notices", "missing terms of service notice"},
+		{"events", "missing or invalid last changed event"},
+		{"entities", "missing registrant entity"},
+	}
+
+	if violations := ValidateNROProfile(n); !reflect.DeepEqual(violations, expected) {
+		t.Errorf("Got violations %v, expected %v", violations, expected)
+	}
+
+	n.Notices = append(n.Notices, Notice{Title: "Terms of Service"})
+	n.Events = append(n.Events, Event{Action: "last changed", Date: "2021-12-14T10:09:34-05:00"})
+	n.Entities = append(n.Entities, Entity{Handle: "IANA", Roles: []string{"registrant"}})
+
+	if violations := ValidateNROProfile(n); violations != nil {
+		t.Errorf("Unexpected violations %v", violations)
+	}
+
+	// Autnum.
+	violations := ValidateNROProfile(&Autnum{Conformance: []string{"rdap_level_0"}})
+	if len(violations) != 8 || violations[0] != (ProfileViolation{"object", "missing handle"}) {
+		t.Errorf("Unexpected violations %v", violations)
+	}
+
+	// Not covered by the profile.
+	if violations := ValidateNROProfile(&Domain{}); len(violations) != 1 {
+		t.Errorf("Unexpected violations %v", violations)
+	}
+}
+
+func TestValidateGTLDProfile(t *testing.T) {
+	load := func() *Domain {
+		obj, err := NewDecoder(test.LoadFile("rdap/rdap.registry.example/domain-example.example.json")).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return obj.(*Domain)
+	}
+
+	if violations := ValidateGTLDProfile(load()); violations != nil {
+		t.Errorf("Unexpected violations %v", violations)
+	}
+
+	d := load()
+	d.Conformance = []string{"rdap_level_0"}
+	d.Status = append(d.Status, "clientHold")
+	d.Events = d.Events[:2]
+	d.Notices = d.Notices[:1]
+	d.Nameservers[0].IPAddresses = nil
+	d.Entities[0].PublicIDs = nil
+	d.Entities[0].Entities[0].VCard = nil
+
+	var sections []string
+	for _, v := range ValidateGTLDProfile(d) {
+		sections = append(sections, v.Section)
+	}
+
+	expected := []string{"conformance", "conformance", "2.3.1.3", "2.4.2", "2.4.5", "2.6.1", "2.6.3", "2.9.2", "2.10"}
+	if !reflect.DeepEqual(sections, expected) {
+		t.Errorf("Got violations in sections %v, expected %v", sections, expected)
+	}
+
+	d = load()
+	for _, e := range []*Entity{&d.Entities[0], &d.Entities[0].Entities[0]} {
+		e.DecodeData = e.DecodeData.withExtension(JSContactConformance, e.VCard.JSCard())
+		e.VCard = nil
+	}
+
+	if violations := ValidateGTLDProfile(d); violations != nil {
+		t.Errorf("Unexpected violations with JSContact contacts %v", violations)
+	}
+
+	if violations := ValidateGTLDProfile(&Nameserver{}); len(violations) != 1 {
+		t.Errorf("Unexpected violations %v", violations)
+	}
+}
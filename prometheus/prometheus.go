@@ -0,0 +1,100 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+// Package prometheus provides Prometheus metrics for the RDAP client.
+//
+//	client := &rdap.Client{
+//	  Metrics: prometheus.NewMetrics(prom.DefaultRegisterer),
+//	}
+//
+// The metrics are:
+//
+//	rdap_queries_total{type,outcome}                 Queries, by type and outcome.
+//	rdap_query_duration_seconds{type}                Query latency.
+//	rdap_http_requests_total{server,code}            HTTP requests, by RDAP server.
+//	rdap_http_request_duration_seconds{server}       HTTP request latency, by RDAP server.
+//	rdap_http_cache_lookups_total{result}            HTTP cache lookups (hit, revalidated, miss).
+package prometheus
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/openrdap/rdap"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics implements rdap.Metrics using Prometheus collectors.
+type Metrics struct {
+	queries         *prom.CounterVec
+	queryDuration   *prom.HistogramVec
+	httpRequests    *prom.CounterVec
+	httpDuration    *prom.HistogramVec
+	httpCacheLookup *prom.CounterVec
+}
+
+// NewMetrics creates the RDAP client metrics, and registers them with
+// |registerer| (if not nil).
+//
+// Panics if the metrics are already registered.
+func NewMetrics(registerer prom.Registerer) *Metrics {
+	m := &Metrics{
+		queries: prom.NewCounterVec(prom.CounterOpts{
+			Name: "rdap_queries_total",
+			Help: "RDAP queries, by query type and outcome.",
+		}, []string{"type", "outcome"}),
+		queryDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Name:    "rdap_query_duration_seconds",
+			Help:    "RDAP query latency, including bootstrapping and retries.",
+			Buckets: prom.DefBuckets,
+		}, []string{"type"}),
+		httpRequests: prom.NewCounterVec(prom.CounterOpts{
+			Name: "rdap_http_requests_total",
+			Help: "HTTP requests to RDAP servers, by server and status code (0 for failed requests).",
+		}, []string{"server", "code"}),
+		httpDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Name:    "rdap_http_request_duration_seconds",
+			Help:    "HTTP request latency, by RDAP server.",
+			Buckets: prom.DefBuckets,
+		}, []string{"server"}),
+		httpCacheLookup: prom.NewCounterVec(prom.CounterOpts{
+			Name: "rdap_http_cache_lookups_total",
+			Help: "HTTP cache lookups, by result (hit, revalidated, miss).",
+		}, []string{"result"}),
+	}
+
+	if registerer != nil {
+		registerer.MustRegister(m.Collectors()...)
+	}
+
+	return m
+}
+
+// Collectors returns the Prometheus collectors, e.g. for custom registration.
+func (m *Metrics) Collectors() []prom.Collector {
+	return []prom.Collector{
+		m.queries,
+		m.queryDuration,
+		m.httpRequests,
+		m.httpDuration,
+		m.httpCacheLookup,
+	}
+}
+
+// QueryDone implements rdap.Metrics.
+func (m *Metrics) QueryDone(queryType rdap.RequestType, outcome string, duration time.Duration) {
+	m.queries.WithLabelValues(queryType.String(), outcome).Inc()
+	m.queryDuration.WithLabelValues(queryType.String()).Observe(duration.Seconds())
+}
+
+// HTTPRequestDone implements rdap.Metrics.
+func (m *Metrics) HTTPRequestDone(server string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(server, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(server).Observe(duration.Seconds())
+}
+
+// CacheLookup implements rdap.Metrics.
+func (m *Metrics) CacheLookup(result string) {
+	m.httpCacheLookup.WithLabelValues(result).Inc()
+}
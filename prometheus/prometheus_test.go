@@ -0,0 +1,53 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openrdap/rdap"
+	"github.com/openrdap/rdap/test"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	registry := prom.NewRegistry()
+	client := &rdap.Client{
+		Metrics: NewMetrics(registry),
+	}
+
+	if _, err := client.QueryDomain("example.cz"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, err := client.QueryDomain("non-existent.cz"); err == nil {
+		t.Fatalf("Unexpected success")
+	}
+
+	expected := `
+# HELP rdap_queries_total RDAP queries, by query type and outcome.
+# TYPE rdap_queries_total counter
+rdap_queries_total{outcome="not_found",type="domain"} 1
+rdap_queries_total{outcome="success",type="domain"} 1
+# HELP rdap_http_requests_total HTTP requests to RDAP servers, by server and status code (0 for failed requests).
+# TYPE rdap_http_requests_total counter
+rdap_http_requests_total{code="200",server="rdap.nic.cz"} 1
+rdap_http_requests_total{code="404",server="rdap.nic.cz"} 1
+`
+
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "rdap_queries_total", "rdap_http_requests_total"); err != nil {
+		t.Error(err)
+	}
+
+	if count := testutil.CollectAndCount(registry, "rdap_http_request_duration_seconds"); count != 1 {
+		t.Errorf("Unexpected rdap_http_request_duration_seconds count %d", count)
+	}
+}
@@ -0,0 +1,66 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PublicIDIANARegistrarID is the publicIds type of a gTLD registrar's IANA
+// Registrar ID.
+const PublicIDIANARegistrarID = "IANA Registrar ID"
+
+// findPublicID returns the identifier of the first public ID in |ids| with
+// the type |idType| (case insensitive), or false if there's none.
+func findPublicID(ids []PublicID, idType string) (string, bool) {
+	for _, id := range ids {
+		if strings.EqualFold(id.Type, idType) && id.Identifier != "" {
+			return id.Identifier, true
+		}
+	}
+
+	return "", false
+}
+
+// PublicID returns the entity's public ID of type |idType| (case
+// insensitive), e.g. "IANA Registrar ID". Returns false if there's none.
+func (e *Entity) PublicID(idType string) (string, bool) {
+	return findPublicID(e.PublicIDs, idType)
+}
+
+// IANARegistrarID returns the entity's IANA Registrar ID, e.g. 9999, from its
+// publicIds. Returns false if there's none, or it's not a number.
+func (e *Entity) IANARegistrarID() (int, bool) {
+	id, ok := e.PublicID(PublicIDIANARegistrarID)
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.ParseUint(strings.TrimSpace(id), 10, 31)
+	if err != nil {
+		return 0, false
+	}
+
+	return int(n), true
+}
+
+// PublicID returns the domain's public ID of type |idType| (case
+// insensitive). Returns false if there's none.
+func (d *Domain) PublicID(idType string) (string, bool) {
+	return findPublicID(d.PublicIDs, idType)
+}
+
+// IANARegistrarID returns the IANA Registrar ID of the domain's registrar
+// entity. Returns false if there's no registrar entity, or it has no (numeric)
+// IANA Registrar ID.
+func (d *Domain) IANARegistrarID() (int, bool) {
+	registrar := findEntityByRole(RoleRegistrar, d.Entities)
+	if registrar == nil {
+		return 0, false
+	}
+
+	return registrar.IANARegistrarID()
+}
@@ -0,0 +1,51 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestIANARegistrarID(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.registry.example/domain-example.example.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := obj.(*Domain)
+
+	if id, ok := d.IANARegistrarID(); !ok || id != 9999 {
+		t.Errorf("Unexpected IANA Registrar ID %d", id)
+	}
+
+	if id, ok := d.Entities[0].PublicID("iana registrar id"); !ok || id != "9999" {
+		t.Errorf("Unexpected public ID %q", id)
+	}
+
+	tests := []struct {
+		IDs   []PublicID
+		ID    int
+		Valid bool
+	}{
+		{[]PublicID{{Type: "IANA Registrar ID", Identifier: " 292 "}}, 292, true},
+		{[]PublicID{{Type: "Other", Identifier: "1"}, {Type: "IANA Registrar ID", Identifier: "1"}}, 1, true},
+		{[]PublicID{{Type: "IANA Registrar ID", Identifier: "not available"}}, 0, false},
+		{[]PublicID{{Type: "IANA Registrar ID"}}, 0, false},
+		{nil, 0, false},
+	}
+
+	for _, test := range tests {
+		e := &Entity{PublicIDs: test.IDs}
+		if id, ok := e.IANARegistrarID(); id != test.ID || ok != test.Valid {
+			t.Errorf("%+v: got %d, %v", test.IDs, id, ok)
+		}
+	}
+
+	if _, ok := (&Domain{}).IANARegistrarID(); ok {
+		t.Errorf("Unexpected IANA Registrar ID without a registrar")
+	}
+}
@@ -0,0 +1,104 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultRaceStagger is the default delay between starting each raced query.
+// See Client.RaceServers.
+const DefaultRaceStagger = time.Millisecond * 250
+
+// race runs the RDAP requests |reqs| (the same query, on different RDAP
+// servers) in parallel, staggered by the Client's RaceStagger.
+//
+// The first finished query (a decoded response, a 404, or an error such as a
+// timeout) is the result, and the remaining queries are cancelled. Failed
+// queries (e.g. network errors, 5xx responses) start the next query early.
+func (c *Client) race(resp *Response, reqs []*Request) (*Response, error) {
+	ctx, cancel := context.WithCancel(reqs[0].Context())
+	defer cancel()
+
+	stagger := c.RaceStagger
+	if stagger == 0 {
+		stagger = DefaultRaceStagger
+	}
+
+	c.Verbose(fmt.Sprintf("client: Racing %d RDAP servers (stagger %s)", len(reqs), stagger))
+
+	var rateLimited *RateLimitedError
+
+	results := make(chan *serverResult, len(reqs))
+	next := 0
+	running := 0
+
+	start := func() {
+		r := reqs[next].WithContext(ctx)
+		next++
+		running++
+
+		go func() {
+			results <- c.queryServer(r)
+		}()
+	}
+
+	start()
+	for stagger < 0 && next < len(reqs) {
+		start()
+	}
+
+	timer := time.NewTimer(stagger)
+	defer timer.Stop()
+
+	for running > 0 {
+		var staggerC <-chan time.Time
+		if next < len(reqs) {
+			staggerC = timer.C
+		}
+
+		select {
+		case <-staggerC:
+			start()
+			timer.Reset(stagger)
+		case result := <-results:
+			running--
+
+			if result.httpResponse != nil {
+				resp.HTTP = append(resp.HTTP, result.httpResponse)
+			}
+
+			if result.done {
+				// Cancel the other queries, and wait for them to finish.
+				cancel()
+				for ; running > 0; running-- {
+					<-results
+				}
+
+				resp.Object = result.object
+				return resp, result.err
+			} else if result.rateLimited != nil {
+				rateLimited = result.rateLimited
+			}
+
+			// Failed, so start the next query now.
+			if next < len(reqs) {
+				start()
+
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(stagger)
+			}
+		}
+	}
+
+	return resp, noWorkingServers(reqs, rateLimited)
+}
@@ -0,0 +1,95 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openrdap/rdap/test"
+)
+
+// raceTransport is a http.RoundTripper where https:// requests hang until
+// cancelled, and http:// requests return an entity response after |delay|.
+type raceTransport struct {
+	delay time.Duration
+
+	mu        sync.Mutex
+	cancelled int
+}
+
+func (r *raceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "https" {
+		<-req.Context().Done()
+
+		r.mu.Lock()
+		r.cancelled++
+		r.mu.Unlock()
+
+		return nil, req.Context().Err()
+	}
+
+	time.Sleep(r.delay)
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(string(test.LoadFile("rdap/rdap.arin.net/entity-ABC123-ARIN.json")))),
+		Request:    req,
+	}, nil
+}
+
+func TestClientRaceServers(t *testing.T) {
+	test.Start(test.Bootstrap)
+	defer test.Finish()
+
+	// ARIN has https:// and http:// RDAP server URLs.
+	transport := &raceTransport{}
+	client := &Client{
+		HTTP:        &http.Client{Transport: transport},
+		RaceServers: true,
+		RaceStagger: time.Millisecond * 10,
+		Verbose:     verboseFunc(),
+	}
+
+	resp, err := client.Do(NewEntityRequest("ABC123-ARIN"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if _, ok := resp.Object.(*Entity); !ok {
+		t.Fatalf("Unexpected response %v", resp.Object)
+	}
+
+	if len(resp.HTTP) != 1 || !strings.HasPrefix(resp.HTTP[0].URL, "http://") {
+		t.Errorf("Unexpected HTTP responses %v", resp.HTTP)
+	}
+
+	if transport.cancelled != 1 {
+		t.Errorf("Losing query not cancelled")
+	}
+}
+
+func TestClientRaceServersAllFail(t *testing.T) {
+	test.Start(test.Bootstrap)
+	defer test.Finish()
+
+	client := &Client{
+		HTTP:        &http.Client{Transport: &sequenceTransport{statuses: []int{503, 503}}},
+		RaceServers: true,
+		RaceStagger: time.Hour,
+		Verbose:     verboseFunc(),
+	}
+
+	// Failures start the next query early, rather than after RaceStagger.
+	resp, err := client.Do(NewEntityRequest("ABC123-ARIN"))
+	if !isClientError(NoWorkingServers, err) {
+		t.Fatalf("Unexpected error: %v", err)
+	} else if len(resp.HTTP) != 2 {
+		t.Errorf("Unexpected number of HTTP responses %d", len(resp.HTTP))
+	}
+}
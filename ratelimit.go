@@ -0,0 +1,199 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit specifies a token bucket rate limit.
+type RateLimit struct {
+	// Sustained queries per second. 0 means unlimited.
+	QPS float64
+
+	// Maximum number of queries which can be made in a burst, after a quiet
+	// period. Values < 1 are treated as 1.
+	Burst int
+}
+
+// RateLimiter limits the rate of queries to each RDAP server, using a token
+// bucket per RDAP base URL.
+//
+// A RateLimiter is safe for concurrent use, and is typically shared by all
+// goroutines using a Client:
+//
+//	limiter := rdap.NewRateLimiter(rdap.RateLimit{QPS: 2, Burst: 5})
+//	limiter.SetLimit("https://rdap.verisign.com/com/v1", rdap.RateLimit{QPS: 1})
+//
+//	client := &rdap.Client{
+//	  RateLimiter: limiter,
+//	}
+type RateLimiter struct {
+	mu       sync.Mutex
+	fallback RateLimit
+	limits   map[string]RateLimit
+	buckets  map[string]*tokenBucket
+}
+
+// NewRateLimiter creates a RateLimiter, with the rate limit |fallback| for
+// each RDAP base URL without a specific limit (see SetLimit()).
+func NewRateLimiter(fallback RateLimit) *RateLimiter {
+	return &RateLimiter{
+		fallback: fallback,
+		limits:   map[string]RateLimit{},
+		buckets:  map[string]*tokenBucket{},
+	}
+}
+
+// SetLimit sets the rate limit for the RDAP base URL |baseURL|, e.g.
+// "https://rdap.nic.cz".
+func (r *RateLimiter) SetLimit(baseURL string, limit RateLimit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	baseURL = rateLimitKey(baseURL)
+
+	r.limits[baseURL] = limit
+	delete(r.buckets, baseURL)
+}
+
+// Wait blocks until a query to the RDAP base URL |baseURL| is allowed, or
+// |ctx| is done.
+//
+// Returns the context's error if |ctx| is done first.
+func (r *RateLimiter) Wait(ctx context.Context, baseURL string) error {
+	baseURL = rateLimitKey(baseURL)
+
+	r.mu.Lock()
+	limit, ok := r.limits[baseURL]
+	if !ok {
+		limit = r.fallback
+	}
+	r.mu.Unlock()
+
+	return r.wait(ctx, baseURL, limit)
+}
+
+// wait blocks until a query for the bucket |key| is allowed by |limit|, or
+// |ctx| is done.
+func (r *RateLimiter) wait(ctx context.Context, key string, limit RateLimit) error {
+	if limit.QPS <= 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	if r.buckets == nil {
+		r.buckets = map[string]*tokenBucket{}
+	}
+
+	b, ok := r.buckets[key]
+	if !ok || b.limit != limit {
+		b = newTokenBucket(limit)
+		r.buckets[key] = b
+	}
+
+	delay := b.reserve(time.Now())
+	r.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Return the unused token.
+		r.mu.Lock()
+		b.tokens++
+		r.mu.Unlock()
+
+		return ctx.Err()
+	}
+}
+
+// rateLimitKey normalises the RDAP base URL |baseURL|.
+func rateLimitKey(baseURL string) string {
+	return strings.TrimRight(strings.ToLower(baseURL), "/")
+}
+
+// tokenBucket implements the token bucket algorithm. The caller is
+// responsible for locking.
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	b := &tokenBucket{
+		limit: limit,
+	}
+	b.tokens = b.burst()
+
+	return b
+}
+
+func (b *tokenBucket) burst() float64 {
+	if b.limit.Burst < 1 {
+		return 1
+	}
+
+	return float64(b.limit.Burst)
+}
+
+// reserve takes a token from the bucket, returning how long the caller must
+// wait before using it.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.limit.QPS
+
+		if b.tokens > b.burst() {
+			b.tokens = b.burst()
+		}
+	}
+	b.last = now
+
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	// The token is taken in advance (the bucket goes negative), so
+	// concurrent callers queue up behind each other.
+	return time.Duration(-b.tokens / b.limit.QPS * float64(time.Second))
+}
+
+// waitForRateLimit waits until the Client's RateLimiter, and the Politeness
+// policy |policy| (if any), allow a query to |rdapReq|'s server.
+func (c *Client) waitForRateLimit(ctx context.Context, rdapReq *Request, policy *Politeness) error {
+	u := rdapReq.URL()
+
+	if c.RateLimiter != nil {
+		if err := c.RateLimiter.Wait(ctx, rdapReq.baseURL()); err != nil {
+			return err
+		}
+	}
+
+	if policy != nil && policy.QPS > 0 {
+		c.mu.Lock()
+		if c.politenessLimiter == nil {
+			c.politenessLimiter = NewRateLimiter(RateLimit{})
+		}
+		limiter := c.politenessLimiter
+		c.mu.Unlock()
+
+		return limiter.wait(ctx, u.Hostname(), RateLimit{QPS: policy.QPS})
+	}
+
+	return nil
+}
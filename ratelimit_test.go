@@ -0,0 +1,71 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(RateLimit{QPS: 2, Burst: 2})
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	expected := []time.Duration{0, 0, time.Millisecond * 500, time.Second}
+	for i, e := range expected {
+		if got := b.reserve(now); got != e {
+			t.Errorf("reserve #%d = %s, expected %s", i, got, e)
+		}
+	}
+
+	// After 10 seconds, the bucket is full again (but no more than Burst).
+	now = now.Add(time.Second * 10)
+	expected = []time.Duration{0, 0, time.Millisecond * 500}
+	for i, e := range expected {
+		if got := b.reserve(now); got != e {
+			t.Errorf("reserve #%d after refill = %s, expected %s", i, got, e)
+		}
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	r := NewRateLimiter(RateLimit{})
+	r.SetLimit("https://rdap.example/", RateLimit{QPS: 50, Burst: 1})
+
+	ctx := context.Background()
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if err := r.Wait(ctx, "https://RDAP.example"); err != nil {
+			t.Fatalf("Wait failed: %s", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Millisecond*35 {
+		t.Errorf("Rate limit not applied, 3 queries in %s", elapsed)
+	}
+
+	// No limit on other servers.
+	start = time.Now()
+	for i := 0; i < 100; i++ {
+		r.Wait(ctx, "https://rdap.other.example")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Millisecond*20 {
+		t.Errorf("Unexpected rate limit, 100 queries in %s", elapsed)
+	}
+
+	// Cancelled wait.
+	r.SetLimit("https://slow.example", RateLimit{QPS: 0.001})
+	r.Wait(ctx, "https://slow.example")
+
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+	defer cancel()
+
+	if err := r.Wait(ctx, "https://slow.example"); err != context.DeadlineExceeded {
+		t.Errorf("Unexpected err %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactedText replaces redacted values.
+const RedactedText = "REDACTED"
+
+// Redactor anonymizes contact information in RDAP responses.
+//
+// This is intended for producing shareable reports, e.g. for pasting into a
+// public ticket:
+//
+//	r := &rdap.Redactor{Emails: true, Phones: true}
+//	redactedJSON, err := r.RedactJSON(resp.HTTP[0].Body)
+//
+// Contact information is redacted from jCards (the vcardArray members) and
+// JSContact cards (the jscontact_card/jscard members). Email addresses are
+// additionally redacted from all string values (e.g. remarks).
+type Redactor struct {
+	// Redact email addresses.
+	Emails bool
+
+	// Redact telephone/fax numbers.
+	Phones bool
+
+	// Redact postal addresses.
+	Addresses bool
+}
+
+var emailRegexp = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// NewRedactor creates a Redactor from a comma separated list of fields to
+// redact, e.g. "emails,phones,addresses".
+func NewRedactor(fields string) (*Redactor, error) {
+	r := &Redactor{}
+
+	for _, f := range strings.Split(fields, ",") {
+		switch strings.ToLower(strings.TrimSpace(f)) {
+		case "":
+		case "email", "emails":
+			r.Emails = true
+		case "phone", "phones":
+			r.Phones = true
+		case "address", "addresses":
+			r.Addresses = true
+		case "all":
+			r.Emails = true
+			r.Phones = true
+			r.Addresses = true
+		default:
+			return nil, fmt.Errorf("unknown redact field '%s' (expected emails, phones, addresses, or all)", f)
+		}
+	}
+
+	return r, nil
+}
+
+// RedactJSON returns a redacted copy of the RDAP response |jsonBlob|.
+//
+// The returned JSON document is re-encoded, so member order and whitespace are
+// not preserved.
+func (r *Redactor) RedactJSON(jsonBlob []byte) ([]byte, error) {
+	var doc interface{}
+
+	err := json.Unmarshal(jsonBlob, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(r.redactValue("", doc))
+}
+
+func (r *Redactor) redactValue(key string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if containsString(jsContactMembers, key) {
+			r.redactJSCard(v)
+		}
+
+		for k, v2 := range v {
+			v[k] = r.redactValue(k, v2)
+		}
+	case []interface{}:
+		if key == "vcardArray" && len(v) == 2 {
+			if properties, ok := v[1].([]interface{}); ok {
+				for _, p := range properties {
+					r.redactJCardProperty(p)
+				}
+			}
+		}
+
+		for i, v2 := range v {
+			v[i] = r.redactValue("", v2)
+		}
+	case string:
+		if r.Emails {
+			return emailRegexp.ReplaceAllString(v, RedactedText)
+		}
+	}
+
+	return v
+}
+
+// redactJCardProperty redacts the jCard property |p| in place.
+func (r *Redactor) redactJCardProperty(p interface{}) {
+	a, ok := p.([]interface{})
+	if !ok || len(a) < 4 {
+		return
+	}
+
+	name, _ := a[0].(string)
+
+	var redact bool
+	switch strings.ToLower(name) {
+	case "email":
+		redact = r.Emails
+	case "tel":
+		redact = r.Phones
+	case "adr":
+		redact = r.Addresses
+
+		if params, ok := a[1].(map[string]interface{}); ok && redact {
+			if _, ok := params["label"]; ok {
+				params["label"] = RedactedText
+			}
+		}
+	}
+
+	if !redact {
+		return
+	}
+
+	for i := 3; i < len(a); i++ {
+		a[i] = redactedJCardValue(a[i])
+	}
+}
+
+// redactJSCard redacts the JSContact card |card| in place.
+func (r *Redactor) redactJSCard(card map[string]interface{}) {
+	members := map[string]bool{
+		"emails":    r.Emails,
+		"phones":    r.Phones,
+		"addresses": r.Addresses,
+	}
+
+	for member, redact := range members {
+		entries, ok := card[member].(map[string]interface{})
+		if !redact || !ok {
+			continue
+		}
+
+		for id, e := range entries {
+			entries[id] = redactedJSContactValue("", e)
+		}
+	}
+}
+
+// redactedJSContactValue returns the JSContact member |key|'s value |v| with
+// all non-empty strings replaced, other than the @type and kind members.
+func redactedJSContactValue(key string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		if v == "" || key == "@type" || key == "kind" {
+			return v
+		}
+
+		return RedactedText
+	case map[string]interface{}:
+		for k, v2 := range v {
+			v[k] = redactedJSContactValue(k, v2)
+		}
+
+		return v
+	case []interface{}:
+		for i, v2 := range v {
+			v[i] = redactedJSContactValue("", v2)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
+
+// redactedJCardValue returns |v| with all non-empty strings replaced.
+func redactedJCardValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return v
+		}
+
+		return RedactedText
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, v2 := range v {
+			result[i] = redactedJCardValue(v2)
+		}
+
+		return result
+	default:
+		return v
+	}
+}
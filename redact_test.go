@@ -0,0 +1,109 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestRedactJSON(t *testing.T) {
+	r, err := NewRedactor("emails,phones")
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %s", err)
+	}
+
+	redacted, err := r.RedactJSON(test.LoadFile("rdap/rdap.registrar.example/domain-thin.cz.json"))
+	if err != nil {
+		t.Fatalf("RedactJSON failed: %s", err)
+	}
+
+	s := string(redacted)
+	for _, secret := range []string{"abuse@registrar.example", "jane@thin.cz", "+1.5555551234"} {
+		if strings.Contains(s, secret) {
+			t.Errorf("%s not redacted", secret)
+		}
+	}
+
+	obj, err := NewDecoder(redacted).Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %s", err)
+	}
+
+	abuse := findEntityByRole("abuse", obj.(*Domain).Entities)
+	if abuse.VCard.Email() != RedactedText || abuse.VCard.Tel() != RedactedText {
+		t.Errorf("Unexpected abuse contact %s", abuse.VCard)
+	}
+
+	if abuse.VCard.Name() != "Abuse Desk" {
+		t.Errorf("Unexpectedly redacted name %s", abuse.VCard.Name())
+	}
+}
+
+func TestRedactAddresses(t *testing.T) {
+	r := &Redactor{Addresses: true}
+
+	redacted, err := r.RedactJSON([]byte(`{"vcardArray": ["vcard", [
+		["version", {}, "text", "4.0"],
+		["adr", {"label": "1 Main St"}, "text", ["", "", "1 Main St", "Town", "", "", "Canada"]]
+	]]}`))
+
+	if err != nil {
+		t.Fatalf("RedactJSON failed: %s", err)
+	}
+
+	expected := `{"vcardArray":["vcard",[["version",{},"text","4.0"],` +
+		`["adr",{"label":"REDACTED"},"text",["","","REDACTED","REDACTED","","","REDACTED"]]]]}`
+
+	if string(redacted) != expected {
+		t.Errorf("Got %s expected %s", redacted, expected)
+	}
+}
+
+func TestRedactJSContact(t *testing.T) {
+	r := &Redactor{Emails: true, Phones: true, Addresses: true}
+
+	redacted, err := r.RedactJSON([]byte(`{
+		"objectClassName": "entity",
+		"rdapConformance": ["rdap_level_0", "jscontact"],
+		"handle": "REG-1",
+		"jscontact_card": {
+			"@type": "Card",
+			"version": "1.0",
+			"name": {"full": "Joe User"},
+			"emails": {"email": {"address": "joe.user@example.cz"}},
+			"phones": {"voice": {"features": {"voice": true}, "number": "+420.123456789"}},
+			"addresses": {"addr": {"countryCode": "CZ", "components": [{"kind": "locality", "value": "Prague"}]}}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("RedactJSON failed: %s", err)
+	}
+
+	s := string(redacted)
+	for _, secret := range []string{"joe.user@example.cz", "+420.123456789", "Prague", `"CZ"`} {
+		if strings.Contains(s, secret) {
+			t.Errorf("%s not redacted", secret)
+		}
+	}
+
+	obj, err := NewDecoder(redacted).Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %s", err)
+	}
+
+	c := NewContact(obj.(*Entity))
+	if c.Name != "Joe User" || c.Email != RedactedText || c.Tel != RedactedText || c.Locality != RedactedText {
+		t.Errorf("Unexpected redacted contact %+v", c)
+	}
+}
+
+func TestNewRedactorBadField(t *testing.T) {
+	if _, err := NewRedactor("emails,shoe-sizes"); err == nil {
+		t.Errorf("Unexpected success")
+	}
+}
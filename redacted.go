@@ -0,0 +1,131 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RedactedConformance is the rdapConformance identifier of the redacted
+// extension (RFC 9537), which describes data removed from a response (e.g.
+// registrant contact details, for privacy).
+const RedactedConformance = "redacted"
+
+// Redaction methods (RFC 9537 section 3).
+const (
+	RedactionRemoval          = "removal"
+	RedactionEmptyValue       = "emptyValue"
+	RedactionPartialValue     = "partialValue"
+	RedactionReplacementValue = "replacementValue"
+)
+
+// Redaction describes a redacted field of an RDAP response (RFC 9537 section
+// 4.2).
+type Redaction struct {
+	// Redacted field, e.g. {Type: "Registrant Email"}.
+	Name RedactionText `json:"name"`
+
+	// Why the field was redacted, if given.
+	Reason *RedactionText `json:"reason"`
+
+	// JSONPath expressions locating the field: before redaction (for
+	// removed fields), after redaction, and of the replacement value.
+	PrePath         string `json:"prePath"`
+	PostPath        string `json:"postPath"`
+	ReplacementPath string `json:"replacementPath"`
+
+	// JSONPath language of the paths. "" means "jsonpath".
+	PathLang string `json:"pathLang"`
+
+	// Redaction method, e.g. RedactionRemoval (the default).
+	Method string `json:"method"`
+}
+
+// RedactionText is a registered type (e.g. "Registrant Email"), or a free text
+// description, of a redacted field or a redaction reason.
+type RedactionText struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// String returns the type, or the description if there's no type.
+func (t RedactionText) String() string {
+	if t.Type != "" {
+		return t.Type
+	}
+
+	return t.Description
+}
+
+func init() {
+	RegisterExtension(Extension{
+		Name:        RedactedConformance,
+		Conformance: RedactedConformance,
+		Members:     []string{"redacted"},
+		Decode:      decodeRedacted,
+		Print:       printRedacted,
+	})
+}
+
+// Redactions returns the domain's redacted fields (RFC 9537), or nil if none
+// are listed.
+func (d *Domain) Redactions() []Redaction {
+	if d.DecodeData == nil {
+		return nil
+	}
+
+	redactions, _ := d.DecodeData.Extension(RedactedConformance).([]Redaction)
+
+	return redactions
+}
+
+// IsRedacted returns true if the field |name| (e.g. "Registrant Email") is
+// listed as redacted. |name| is matched case insensitively against each
+// Redaction's name type and description.
+//
+// This distinguishes redacted fields from fields which are absent.
+func (d *Domain) IsRedacted(name string) bool {
+	for _, r := range d.Redactions() {
+		if strings.EqualFold(r.Name.Type, name) || strings.EqualFold(r.Name.Description, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func decodeRedacted(src map[string]interface{}) (interface{}, error) {
+	var redactions []Redaction
+
+	result, err := decodeMetadata(src, "redacted", &redactions)
+	if result == nil || err != nil {
+		return nil, err
+	}
+
+	for i := range redactions {
+		if redactions[i].Name.String() == "" {
+			return nil, fmt.Errorf("redacted[%d] has no name", i)
+		}
+
+		if redactions[i].Method == "" {
+			redactions[i].Method = RedactionRemoval
+		}
+	}
+
+	return redactions, nil
+}
+
+func printRedacted(value interface{}, p *ExtensionPrinter) {
+	for _, r := range value.([]Redaction) {
+		h := p.Heading("Redacted")
+		h.Value("Name", r.Name.String())
+		h.Value("Method", r.Method)
+
+		if r.Reason != nil {
+			h.Value("Reason", r.Reason.String())
+		}
+	}
+}
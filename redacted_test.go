@@ -0,0 +1,74 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"testing"
+)
+
+func TestRedacted(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"rdapConformance": ["rdap_level_0", "redacted"],
+		"objectClassName": "domain",
+		"ldhName": "example.com",
+		"redacted": [
+			{
+				"name": {"type": "Registrant Email"},
+				"prePath": "$.entities[?(@.roles[0]=='registrant')].vcardArray[1][?(@[0]=='email')]",
+				"method": "removal",
+				"reason": {"description": "Server policy"}
+			},
+			{
+				"name": {"description": "Administrative Contact"},
+				"prePath": "$.entities[?(@.roles[0]=='administrative')]"
+			},
+			{
+				"name": {"type": "Registrant Name"},
+				"postPath": "$.entities[?(@.roles[0]=='registrant')].vcardArray[1][?(@[0]=='fn')][3]",
+				"pathLang": "jsonpath",
+				"method": "emptyValue"
+			}
+		]
+	}`)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := obj.(*Domain)
+
+	redactions := d.Redactions()
+	if len(redactions) != 3 {
+		t.Fatalf("Unexpected redactions %+v", redactions)
+	}
+
+	if r := redactions[0]; r.Reason == nil || r.Reason.String() != "Server policy" || r.PrePath == "" {
+		t.Errorf("Unexpected redaction %+v", r)
+	}
+
+	if redactions[1].Method != RedactionRemoval || redactions[2].Method != RedactionEmptyValue {
+		t.Errorf("Unexpected methods %s, %s", redactions[1].Method, redactions[2].Method)
+	}
+
+	for _, name := range []string{"registrant email", "Administrative Contact", "Registrant Name"} {
+		if !d.IsRedacted(name) {
+			t.Errorf("%s not redacted", name)
+		}
+	}
+
+	if d.IsRedacted("Registrant Phone") {
+		t.Errorf("Registrant Phone unexpectedly redacted")
+	}
+
+	// Invalid redacted member.
+	obj, err = NewDecoder([]byte(`{"objectClassName": "domain", "redacted": [{"method": "removal"}]}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := obj.(*Domain); d.Redactions() != nil || len(d.DecodeData.Notes(RedactedConformance)) != 1 {
+		t.Errorf("Unexpected redactions %+v", d.Redactions())
+	}
+}
@@ -0,0 +1,145 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// RedirectPolicy specifies how HTTP redirects (3xx responses) from RDAP
+// servers are followed.
+//
+// RDAP servers use redirects for referrals, e.g. a redirector service (such as
+// rdap.org) redirects queries to the authoritative RDAP server.
+//
+// Redirect loops are always detected. The redirects followed are recorded in
+// HTTPResponse.Redirects.
+//
+// A refused redirect fails the HTTP request to that RDAP server, with a
+// ClientError (RedirectLoop, TooManyRedirects, or RedirectNotAllowed) as its
+// HTTPResponse.Error. Client.Do() then tries the next server, if any, and
+// otherwise returns a NoWorkingServers ClientError, along with the Response
+// and its HTTP exchanges.
+type RedirectPolicy struct {
+	// Maximum number of redirects followed per request. Exceeding it fails the
+	// HTTP request with a TooManyRedirects ClientError. 0 follows no redirects.
+	MaxRedirects int
+
+	// Only follow redirects to https:// URLs. This prevents HTTPS downgrades.
+	RequireHTTPS bool
+
+	// Only follow redirects to the same hostname as the original request.
+	SameHost bool
+}
+
+// DefaultRedirectPolicy returns the RedirectPolicy used if Client.RedirectPolicy
+// is unset: up to 10 redirects are followed, to any URL.
+func DefaultRedirectPolicy() *RedirectPolicy {
+	return &RedirectPolicy{
+		MaxRedirects: 10,
+	}
+}
+
+// check implements http.Client's CheckRedirect for the policy. |req| is the
+// upcoming request, |via| the requests made so far, oldest first.
+func (p *RedirectPolicy) check(req *http.Request, via []*http.Request) error {
+	target := req.URL.String()
+
+	for _, r := range via {
+		if r.URL.String() == target {
+			return &ClientError{
+				Type: RedirectLoop,
+				Text: fmt.Sprintf("Redirect loop detected at %s", target),
+			}
+		}
+	}
+
+	if len(via) > p.MaxRedirects {
+		return &ClientError{
+			Type: TooManyRedirects,
+			Text: fmt.Sprintf("Too many redirects (maximum %d)", p.MaxRedirects),
+		}
+	}
+
+	if p.RequireHTTPS && req.URL.Scheme != "https" {
+		return &ClientError{
+			Type: RedirectNotAllowed,
+			Text: fmt.Sprintf("Redirect to non-HTTPS URL %s not allowed", target),
+		}
+	}
+
+	if p.SameHost && req.URL.Hostname() != via[0].URL.Hostname() {
+		return &ClientError{
+			Type: RedirectNotAllowed,
+			Text: fmt.Sprintf("Redirect to another host (%s) not allowed", target),
+		}
+	}
+
+	return nil
+}
+
+// httpClientFor returns the http.Client to make the request |rdapReq| with:
+// the Request's HTTP client, or the Client's HTTP client and Transport, wrapped
+// by the Client's Middleware.
+//
+// Redirects are checked against the Client's RedirectPolicy, and recorded in
+// |httpResponse|.
+func (c *Client) httpClientFor(rdapReq *Request, httpResponse *HTTPResponse) *http.Client {
+	policy := c.RedirectPolicy
+	if policy == nil {
+		policy = DefaultRedirectPolicy()
+	}
+
+	var client http.Client
+	if rdapReq.HTTP != nil {
+		client = *rdapReq.HTTP
+	} else {
+		client = *c.HTTP
+
+		if c.Transport != nil {
+			client.Transport = c.Transport
+		}
+
+		client.Transport = c.configureTransport(client.Transport)
+	}
+
+	client.Transport = c.applyMiddleware(client.Transport)
+
+	checkRedirect := client.CheckRedirect
+
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := policy.check(req, via); err != nil {
+			c.Verbose(fmt.Sprintf("client: redirect refused: %s", err))
+			return err
+		}
+
+		// Enough time left?
+		if c.DeadlineBudget.exhausted(req.Context(), 0) {
+			c.Verbose(fmt.Sprintf("client: redirect refused: %s", ErrDeadlineBudgetExhausted))
+			return ErrDeadlineBudgetExhausted
+		}
+
+		// Custom http.Client policy too?
+		if checkRedirect != nil {
+			if err := checkRedirect(req, via); err != nil {
+				return err
+			}
+		}
+
+		c.redirectCredentials(req, via[0])
+
+		c.Verbose(fmt.Sprintf("client: redirected to %s", req.URL))
+		c.log(req.Context(), slog.LevelDebug, LogRedirect,
+			slog.String("from", via[len(via)-1].URL.String()),
+			slog.String("to", req.URL.String()))
+		httpResponse.Redirects = append(httpResponse.Redirects, req.URL.String())
+
+		return nil
+	}
+
+	return &client
+}
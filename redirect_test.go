@@ -0,0 +1,121 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+// redirectTransport is a http.RoundTripper which redirects each URL in
+// |redirects| to its value. Other URLs return a domain response.
+type redirectTransport struct {
+	redirects map[string]string
+}
+
+func (r *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if location, ok := r.redirects[req.URL.String()]; ok {
+		return &http.Response{
+			StatusCode: http.StatusFound,
+			Header:     http.Header{"Location": []string{location}},
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(string(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json")))),
+		Request:    req,
+	}, nil
+}
+
+func runRedirectQuery(policy *RedirectPolicy, redirects map[string]string) (*Response, error) {
+	client := &Client{
+		HTTP:           &http.Client{Transport: &redirectTransport{redirects: redirects}},
+		RedirectPolicy: policy,
+		Verbose:        verboseFunc(),
+	}
+
+	server, _ := url.Parse("https://rdap.example")
+
+	return client.Do(NewDomainRequest("example.cz").WithServer(server))
+}
+
+func redirectErrorType(resp *Response) ClientErrorType {
+	var ce *ClientError
+	if resp == nil || len(resp.HTTP) == 0 || !errors.As(resp.HTTP[0].Error, &ce) {
+		return 0
+	}
+
+	return ce.Type
+}
+
+func TestRedirectChain(t *testing.T) {
+	resp, err := runRedirectQuery(nil, map[string]string{
+		"https://rdap.example/domain/example.cz":          "https://rdap.registry.example/domain/example.cz",
+		"https://rdap.registry.example/domain/example.cz": "https://rdap.nic.cz/domain/example.cz",
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := []string{
+		"https://rdap.registry.example/domain/example.cz",
+		"https://rdap.nic.cz/domain/example.cz",
+	}
+
+	if strings.Join(resp.HTTP[0].Redirects, " ") != strings.Join(expected, " ") {
+		t.Errorf("Unexpected redirects %v", resp.HTTP[0].Redirects)
+	}
+}
+
+func TestRedirectLoop(t *testing.T) {
+	resp, err := runRedirectQuery(nil, map[string]string{
+		"https://rdap.example/domain/example.cz":          "https://rdap.registry.example/domain/example.cz",
+		"https://rdap.registry.example/domain/example.cz": "https://rdap.example/domain/example.cz",
+	})
+
+	if !isClientError(NoWorkingServers, err) {
+		t.Fatalf("Unexpected error: %v", err)
+	} else if redirectErrorType(resp) != RedirectLoop {
+		t.Errorf("Unexpected HTTP error %v", resp.HTTP[0].Error)
+	}
+}
+
+func TestRedirectPolicy(t *testing.T) {
+	redirects := map[string]string{
+		"https://rdap.example/domain/example.cz": "http://rdap.nic.cz/domain/example.cz",
+	}
+
+	tests := []struct {
+		Policy  *RedirectPolicy
+		Allowed bool
+		Type    ClientErrorType
+	}{
+		{&RedirectPolicy{MaxRedirects: 1}, true, 0},
+		{&RedirectPolicy{MaxRedirects: 0}, false, TooManyRedirects},
+		{&RedirectPolicy{MaxRedirects: 1, RequireHTTPS: true}, false, RedirectNotAllowed},
+		{&RedirectPolicy{MaxRedirects: 1, SameHost: true}, false, RedirectNotAllowed},
+	}
+
+	for i, test := range tests {
+		resp, err := runRedirectQuery(test.Policy, redirects)
+
+		if test.Allowed && err != nil {
+			t.Errorf("Test #%d: unexpected error: %s", i, err)
+		} else if !test.Allowed && redirectErrorType(resp) != test.Type {
+			t.Errorf("Test #%d: unexpected error: %v", i, err)
+		}
+	}
+}
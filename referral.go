@@ -0,0 +1,233 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// followReferral follows the registrar referral in the domain response |resp|
+// to |req|, if Client.FollowReferrals is set. The result is saved in
+// resp.Referral/resp.ReferralError.
+func (c *Client) followReferral(req *Request, resp *Response) {
+	if !c.FollowReferrals || req.Type != DomainRequest {
+		return
+	}
+
+	if _, ok := resp.Object.(*Domain); !ok {
+		return
+	}
+
+	link := relatedRDAPLink(linksOf(resp.Object))
+	if link == nil {
+		return
+	}
+
+	c.Verbose(fmt.Sprintf("client: Following registrar referral to %s", link.Href))
+
+	resp.Referral, resp.ReferralError = c.followLink(req.Context(), link)
+
+	if resp.ReferralError != nil {
+		c.Verbose(fmt.Sprintf("client: Registrar referral failed: %s", resp.ReferralError))
+	}
+}
+
+// MergedDomain returns the Domain response merged with the registrar's
+// Domain response (see Client.FollowReferrals), or nil if the response isn't
+// a Domain.
+//
+// The registry's data is preferred, with the registrar's data filling the
+// gaps:
+//   - Entities are matched by handle. The registry's entities are kept, with
+//     any empty vCard, public IDs, nested entities, remarks, and links taken
+//     from the registrar's entity of the same handle. Other registrar
+//     entities (e.g. contacts) are added.
+//   - Events are added if the registry has no event of the same action. The
+//     registrar's "expiration" event is added as a "registrar expiration"
+//     event (see Domain.Expiration()), unless the registrar has one already.
+//   - Statuses, remarks, notices (by title and description), and links (by
+//     href) not in the registry's response are added.
+//   - The nameservers, DNSSEC data, and port 43 server are the registry's,
+//     unless the registry has none.
+//
+// The original responses are unchanged, and remain available as Object and
+// Referral.Object. If there's no registrar response, a copy of the registry's
+// Domain is returned.
+func (r *Response) MergedDomain() *Domain {
+	registry, ok := r.Object.(*Domain)
+	if !ok {
+		return nil
+	}
+
+	merged := *registry
+	merged.Entities = append([]Entity(nil), registry.Entities...)
+	merged.Events = append([]Event(nil), registry.Events...)
+	merged.Status = append([]string(nil), registry.Status...)
+	merged.Remarks = append([]Remark(nil), registry.Remarks...)
+	merged.Notices = append([]Notice(nil), registry.Notices...)
+	merged.Links = append([]Link(nil), registry.Links...)
+
+	if r.Referral == nil {
+		return &merged
+	}
+
+	registrar, ok := r.Referral.Object.(*Domain)
+	if !ok {
+		return &merged
+	}
+
+	for _, e := range registrar.Entities {
+		if i := entityIndex(merged.Entities, e.Handle); i >= 0 {
+			mergeEntity(&merged.Entities[i], &e)
+		} else {
+			merged.Entities = append(merged.Entities, e)
+		}
+	}
+
+	var hasRegistrarExpiration bool
+	for _, e := range registrar.Events {
+		if e.Action == EventRegistrarExpiration {
+			hasRegistrarExpiration = true
+		}
+	}
+
+	for _, e := range registrar.Events {
+		if e.Action == EventExpiration && !hasRegistrarExpiration {
+			e.Action = EventRegistrarExpiration
+		}
+
+		found := false
+		for _, existing := range merged.Events {
+			if existing.Action == e.Action {
+				found = true
+			}
+		}
+
+		if !found {
+			merged.Events = append(merged.Events, e)
+		}
+	}
+
+	for _, s := range registrar.Status {
+		if !containsString(merged.Status, s) {
+			merged.Status = append(merged.Status, s)
+		}
+	}
+
+	for _, rm := range registrar.Remarks {
+		found := false
+		for _, existing := range merged.Remarks {
+			if noticeText(existing.Title, existing.Description) == noticeText(rm.Title, rm.Description) {
+				found = true
+			}
+		}
+
+		if !found {
+			merged.Remarks = append(merged.Remarks, rm)
+		}
+	}
+
+	for _, n := range registrar.Notices {
+		found := false
+		for _, existing := range merged.Notices {
+			if noticeText(existing.Title, existing.Description) == noticeText(n.Title, n.Description) {
+				found = true
+			}
+		}
+
+		if !found {
+			merged.Notices = append(merged.Notices, n)
+		}
+	}
+
+	for _, l := range registrar.Links {
+		found := false
+		for _, existing := range merged.Links {
+			if existing.Href == l.Href {
+				found = true
+			}
+		}
+
+		if !found {
+			merged.Links = append(merged.Links, l)
+		}
+	}
+
+	if len(merged.Nameservers) == 0 {
+		merged.Nameservers = registrar.Nameservers
+	}
+
+	if merged.SecureDNS == nil {
+		merged.SecureDNS = registrar.SecureDNS
+	}
+
+	if merged.Port43 == "" {
+		merged.Port43 = registrar.Port43
+	}
+
+	return &merged
+}
+
+// entityIndex returns the index of the entity with handle |handle| in
+// |entities|, or -1 if there's none (or |handle| is empty).
+func entityIndex(entities []Entity, handle string) int {
+	if handle == "" {
+		return -1
+	}
+
+	for i, e := range entities {
+		if e.Handle == handle {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// mergeEntity fills in the empty fields of |dst| from |src|.
+func mergeEntity(dst *Entity, src *Entity) {
+	if dst.VCard == nil {
+		dst.VCard = src.VCard
+	}
+
+	if dst.JSCard() == nil {
+		if card := src.JSCard(); card != nil {
+			dst.DecodeData = dst.DecodeData.withExtension(JSContactConformance, card)
+		}
+	}
+
+	if len(dst.PublicIDs) == 0 {
+		dst.PublicIDs = src.PublicIDs
+	}
+
+	if len(dst.Entities) == 0 {
+		dst.Entities = src.Entities
+	}
+
+	if len(dst.Remarks) == 0 {
+		dst.Remarks = src.Remarks
+	}
+
+	if len(dst.Links) == 0 {
+		dst.Links = src.Links
+	}
+}
+
+// noticeText returns the text of a notice or remark, its |title| and
+// |description|, for comparing notices.
+func noticeText(title string, description []string) string {
+	return title + "\n" + strings.Join(description, "\n")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
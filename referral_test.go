@@ -0,0 +1,113 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestClientFollowReferrals(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	client := &Client{
+		FollowReferrals: true,
+		Verbose:         verboseFunc(),
+	}
+
+	resp, err := client.Do(NewDomainRequest("thin.cz"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if resp.Referral == nil || resp.ReferralError != nil {
+		t.Fatalf("Referral not followed: %v", resp.ReferralError)
+	}
+
+	d := resp.MergedDomain()
+
+	if len(d.Entities) != 2 || d.Entities[0].Handle != "REG-EXAMPLE" || d.Entities[1].Handle != "REGISTRANT-1" {
+		t.Fatalf("Unexpected entities %+v", d.Entities)
+	}
+
+	registrar := d.Entities[0]
+	if len(registrar.PublicIDs) != 1 || len(registrar.Entities) != 1 || registrar.Entities[0].VCard.Email() != "abuse@registrar.example" {
+		t.Errorf("Unexpected registrar entity %+v", registrar)
+	}
+
+	// The registrar's expiration event is kept as a "registrar expiration".
+	if len(d.Events) != 3 || d.Events[1].Date != "2030-02-03T04:05:06Z" ||
+		d.Events[2].Action != EventRegistrarExpiration || d.Events[2].Date != "2030-02-04T04:05:06Z" {
+		t.Errorf("Unexpected events %+v", d.Events)
+	}
+
+	if len(d.Nameservers) != 2 || len(d.Status) != 2 || len(d.Links) != 2 {
+		t.Errorf("Unexpected merged domain %+v", d)
+	}
+
+	// Original responses unchanged.
+	if registry := resp.Object.(*Domain); len(registry.Entities) != 1 || len(registry.Entities[0].Entities) != 0 {
+		t.Errorf("Registry response modified: %+v", registry.Entities)
+	}
+
+	// Referrals not followed by default.
+	client.FollowReferrals = false
+	resp, err = client.Do(NewDomainRequest("thin.cz"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if resp.Referral != nil {
+		t.Errorf("Unexpected referral")
+	} else if d := resp.MergedDomain(); d == nil || len(d.Entities) != 1 {
+		t.Errorf("Unexpected merged domain %+v", d)
+	}
+}
+
+func TestResponseMergedDomainNotices(t *testing.T) {
+	terms := Notice{Title: "Terms of Use", Description: []string{"Service subject to Terms of Use."}}
+	remark := Remark{Description: []string{"Example remark."}}
+
+	resp := &Response{
+		Object: &Domain{
+			Notices: []Notice{terms},
+			Remarks: []Remark{remark},
+		},
+		Referral: &Response{
+			Object: &Domain{
+				Notices: []Notice{terms, {Title: "Status Codes", Description: []string{"See https://icann.org/epp"}}},
+				Remarks: []Remark{remark},
+			},
+		},
+	}
+
+	d := resp.MergedDomain()
+	if len(d.Notices) != 2 || d.Notices[1].Title != "Status Codes" || len(d.Remarks) != 1 {
+		t.Errorf("Unexpected notices %+v, remarks %+v", d.Notices, d.Remarks)
+	}
+}
+
+func TestMergeEntityJSContact(t *testing.T) {
+	card, err := NewJSCard([]byte(`{"@type": "Card", "version": "1.0", "name": {"full": "Joe User"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := &Entity{Handle: "REG-1"}
+	src.DecodeData = src.DecodeData.withExtension(JSContactConformance, card)
+
+	dst := &Entity{Handle: "REG-1", DecodeData: &DecodeData{}}
+	original := dst.DecodeData
+	mergeEntity(dst, src)
+
+	if dst.JSCard() != card || NewContact(dst).Name != "Joe User" {
+		t.Errorf("JSContact card not merged")
+	}
+
+	if original.Extension(JSContactConformance) != nil {
+		t.Errorf("Original DecodeData modified")
+	}
+}
@@ -0,0 +1,89 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import "time"
+
+// ArtRecordConformance is the rdapConformance identifier of the artRecord
+// extension, describing a work of art associated with a domain (e.g. in .art).
+const ArtRecordConformance = "artRecord"
+
+// ArtRecord describes a work of art associated with a domain, from its
+// artRecord member. The fields follow the Object ID standard.
+type ArtRecord struct {
+	ObjectType   string `json:"objectType"`
+	Materials    string `json:"materials"`
+	Dimensions   string `json:"dimensions"`
+	Signature    string `json:"signature"`
+	Title        string `json:"title"`
+	Inscription  string `json:"inscription"`
+	Subject      string `json:"subject"`
+	Features     string `json:"features"`
+	Reference    string `json:"reference"`
+	Creator      string `json:"creator"`
+	DateOrPeriod string `json:"dateOrPeriod"`
+}
+
+func init() {
+	RegisterExtension(Extension{
+		Name:        ArtRecordConformance,
+		Conformance: ArtRecordConformance,
+		Members:     []string{"artRecord"},
+		Decode: func(src map[string]interface{}) (interface{}, error) {
+			return decodeMetadata(src, "artRecord", &ArtRecord{})
+		},
+		Print: printArtRecord,
+	})
+}
+
+func printArtRecord(value interface{}, p *ExtensionPrinter) {
+	a := value.(*ArtRecord)
+
+	h := p.Heading("Art Record")
+	h.Value("Object Type", a.ObjectType)
+	h.Value("Title", a.Title)
+	h.Value("Creator", a.Creator)
+	h.Value("Date or Period", a.DateOrPeriod)
+	h.Value("Materials", a.Materials)
+	h.Value("Dimensions", a.Dimensions)
+	h.Value("Signature", a.Signature)
+	h.Value("Inscription", a.Inscription)
+	h.Value("Subject", a.Subject)
+	h.Value("Features", a.Features)
+	h.Value("Reference", a.Reference)
+}
+
+// ArtRecord returns the domain's art record (artRecord extension), or nil if
+// it has none.
+func (d *Domain) ArtRecord() *ArtRecord {
+	if d.DecodeData == nil {
+		return nil
+	}
+
+	a, _ := d.DecodeData.Extension(ArtRecordConformance).(*ArtRecord)
+
+	return a
+}
+
+// RegistrarExpiration returns the registrar's expiration date for the domain,
+// which may differ from the registry's expiration date (e.g. during an
+// auto-renew grace period).
+//
+// The date is taken from the latest "registrar expiration" event (see
+// EventRegistrarExpiration). Returns false if there's none.
+func (d *Domain) RegistrarExpiration() (time.Time, bool) {
+	var latest time.Time
+	for _, e := range d.Events {
+		if e.Action != EventRegistrarExpiration {
+			continue
+		}
+
+		if t, err := e.Time(); err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+
+	return latest, !latest.IsZero()
+}
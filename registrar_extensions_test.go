@@ -0,0 +1,98 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDomainRegistrarExpiration(t *testing.T) {
+	tests := []struct {
+		JSON     string
+		Expected string
+	}{
+		{
+			`{"objectClassName": "domain", "ldhName": "example.com"}`,
+			"",
+		},
+		{
+			`{"objectClassName": "domain", "ldhName": "example.com",
+			  "events": [{"eventAction": "registrar expiration", "eventDate": "2031-02-03T04:05:06+01:00"}]}`,
+			"2031-02-03T03:05:06Z",
+		},
+		{
+			`{"objectClassName": "domain", "ldhName": "example.com",
+			  "events": [{"eventAction": "registrar expiration", "eventDate": "2031-02-03T04:05:06Z"},
+			             {"eventAction": "registrar expiration", "eventDate": "2032-01-01T00:00:00Z"}]}`,
+			"2032-01-01T00:00:00Z",
+		},
+		{
+			`{"objectClassName": "domain", "ldhName": "example.com",
+			  "events": [{"eventAction": "registrar expiration", "eventDate": "soon"}]}`,
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		obj, err := NewDecoder([]byte(test.JSON)).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d := obj.(*Domain)
+
+		result := ""
+		if exp, ok := d.RegistrarExpiration(); ok {
+			result = exp.UTC().Format(time.RFC3339)
+		}
+
+		if result != test.Expected {
+			t.Errorf("%s: got %q, expected %q", test.JSON, result, test.Expected)
+		}
+
+		if unknown := d.DecodeData.UnknownFields(); len(unknown) != 0 {
+			t.Errorf("%s: unexpected unknown fields %v", test.JSON, unknown)
+		}
+	}
+}
+
+func TestDomainArtRecord(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"rdapConformance": ["rdap_level_0", "artRecord"],
+		"ldhName": "example.art",
+		"artRecord": {
+			"objectType": "painting",
+			"title": "Example",
+			"creator": "A. Painter",
+			"dateOrPeriod": "1901"
+		}
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := obj.(*Domain)
+
+	a := d.ArtRecord()
+	if a == nil || a.ObjectType != "painting" || a.Title != "Example" || a.Creator != "A. Painter" || a.DateOrPeriod != "1901" {
+		t.Errorf("Unexpected art record %+v", a)
+	}
+
+	buf := &bytes.Buffer{}
+	printer := &Printer{Writer: buf}
+	printer.Print(d)
+
+	if !strings.Contains(buf.String(), "Art Record:\n    Object Type: painting\n    Title: Example\n") {
+		t.Errorf("Art record not printed:\n%s", buf.String())
+	}
+
+	if (&Domain{}).ArtRecord() != nil {
+		t.Errorf("Unexpected art record")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -37,6 +38,11 @@ const (
 
 	// RawRequest is a request with a fixed RDAP URL.
 	RawRequest
+
+	// RFC 9536 reverse searches, for objects related to entities.
+	DomainReverseSearchRequest
+	NameserverReverseSearchRequest
+	EntityReverseSearchRequest
 )
 
 // String returns the RequestType as a string.
@@ -73,6 +79,12 @@ func (r RequestType) String() string {
 		return "autnum-search"
 	case RawRequest:
 		return "url"
+	case DomainReverseSearchRequest:
+		return "domain-reverse-search"
+	case NameserverReverseSearchRequest:
+		return "nameserver-reverse-search"
+	case EntityReverseSearchRequest:
+		return "entity-reverse-search"
 	default:
 		panic("Unknown RequestType")
 	}
@@ -91,7 +103,7 @@ func (r RequestType) String() string {
 //	-------------------------------------------+---------------+-------------------------+----------------
 //	rdap.AutnumRequest                         | Yes           | autnum/QUERY            | AS2846
 //	rdap.DomainRequest                         | Yes           | domain/QUERY            | example.cz
-//	rdap.EntityRequest                         | Experimental  | entity/QUERY            | 86860670-VRSN
+//	rdap.EntityRequest                         | Yes (RFC 8521)| entity/QUERY            | 86860670-VRSN
 //	rdap.HelpRequest                           | No            | help                    | N/A
 //	rdap.IPRequest                             | Yes           | ip/QUERY                | 2001:db8::1
 //	rdap.NameserverRequest                     | No            | nameserver/QUERY        | ns1.skip.org
@@ -105,10 +117,15 @@ func (r RequestType) String() string {
 //	rdap.EntitySearchByHandleRequest           | No            | entities?handle=QUERY   | ABC*-VRSN
 //	rdap.AutnumSearchRequest                   | No            | autnums?handle=QUERY    | AS6006*
 //	                                           |               |                         |
+//	rdap.DomainReverseSearchRequest            | No            | domains/reverse_search/entity?PARAMS
+//	rdap.NameserverReverseSearchRequest        | No            | nameservers/reverse_search/entity?PARAMS
+//	rdap.EntityReverseSearchRequest            | No            | entities/reverse_search/entity?PARAMS
+//	                                           |               |                         |
 //	rdap.RawRequest                            | N/A           | N/A                     | N/A
 //
 // See https://tools.ietf.org/html/rfc7482 for more information on RDAP request
-// types.
+// types. Reverse searches (RFC 9536) take their search conditions from Params
+// instead of Query, see NewReverseSearchRequest().
 //
 // Requests are executed by a Client. To execute a Request, an RDAP server is
 // required. The servers for Autnum, IP, and Domain queries are determined
@@ -178,7 +195,22 @@ type Request struct {
 	// The default is no timeout.
 	Timeout time.Duration
 
+	// Optional HTTP client for this request, overriding Client.HTTP and
+	// Client.Transport.
+	HTTP *http.Client
+
+	// History requests the object's prior versions, rather than the object,
+	// from a server supporting the history extension (e.g. the HTTP request
+	// path history/domain/QUERY). See NewHistoryRequest().
+	//
+	// Only AutnumRequest, DomainRequest, EntityRequest, IPRequest, and
+	// NameserverRequest are supported.
+	History bool
+
 	ctx context.Context
+
+	// Access token for the RDAP server, see Client.OpenID.
+	openIDToken *OpenIDToken
 }
 
 func (r *Request) pathAndValues() (string, url.Values) {
@@ -223,15 +255,41 @@ func (r *Request) pathAndValues() (string, url.Values) {
 	case AutnumSearchRequest:
 		path = "autnums"
 		values["handle"] = []string{r.Query}
+	case DomainReverseSearchRequest:
+		path = "domains/reverse_search/entity"
+	case NameserverReverseSearchRequest:
+		path = "nameservers/reverse_search/entity"
+	case EntityReverseSearchRequest:
+		path = "entities/reverse_search/entity"
 	case RawRequest:
 		// Server URL(s) are the entire request.
 	default:
 		panic("unknown QueryType")
 	}
 
+	if r.History && r.Type != RawRequest {
+		path = "history/" + path
+	}
+
 	return path, values
 }
 
+// baseURL returns the base URL of the RDAP server the Request is for.
+//
+// For RawRequests, this is the URL's scheme and host only, since the base
+// URL can't be determined.
+func (r *Request) baseURL() string {
+	if r.Server == nil {
+		return ""
+	}
+
+	if r.Type == RawRequest {
+		return r.Server.Scheme + "://" + r.Server.Host
+	}
+
+	return r.Server.String()
+}
+
 // URL constructs and returns the RDAP Request URL.
 //
 // As an example:
@@ -262,7 +320,7 @@ func (r *Request) URL() *url.URL {
 		resultURL = new(url.URL)
 		*resultURL = *r.Server
 	} else {
-		tempURL := &*r.Server
+		tempURL := *r.Server
 		tempURL.RawQuery = ""
 		tempURL.Fragment = ""
 		tempURLString := tempURL.String()
@@ -417,6 +475,42 @@ func NewNameserverRequest(nameserver string) *Request {
 	}
 }
 
+// NewReverseSearchRequest creates a new RFC 9536 reverse search Request, for
+// objects related to entities matching |conditions|.
+//
+// |searchType| is one of DomainReverseSearchRequest,
+// NameserverReverseSearchRequest, or EntityReverseSearchRequest.
+// |conditions| maps reverse search properties (e.g. "fn", "handle", "email",
+// "role") to values, which may contain the "*" wildcard:
+//
+//	// Domains with the registrant contact CID-40*.
+//	req := rdap.NewReverseSearchRequest(rdap.DomainReverseSearchRequest, url.Values{
+//	  "handle": []string{"CID-40*"},
+//	  "role":   []string{"registrant"},
+//	})
+//
+// The RDAP server must be specified. See Help.SupportsReverseSearch().
+func NewReverseSearchRequest(searchType RequestType, conditions url.Values) *Request {
+	return &Request{
+		Type:   searchType,
+		Query:  conditions.Encode(),
+		Params: conditions,
+	}
+}
+
+// NewHistoryRequest creates a Request for the prior versions of the object
+// |query|, of type |requestType| (e.g. DomainRequest), from a server supporting
+// the history extension. The response is a *History.
+//
+// The server is bootstrapped as usual, if not specified.
+func NewHistoryRequest(requestType RequestType, query string) *Request {
+	return &Request{
+		Type:    requestType,
+		Query:   query,
+		History: true,
+	}
+}
+
 // NewRawRequest creates a Request from the URL |rdapURL|.
 //
 // When a client executes the Request, it will fetch |rdapURL|.
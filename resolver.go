@@ -0,0 +1,168 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Resolver resolves RDAP server hostnames to IP addresses. See
+// Client.Resolver.
+//
+// *net.Resolver implements Resolver, e.g. to use a specific DNS server:
+//
+//	client := &rdap.Client{
+//	  Resolver: &net.Resolver{
+//	    PreferGo: true,
+//	    Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+//	      return (&net.Dialer{}).DialContext(ctx, network, "10.0.0.53:53")
+//	    },
+//	  },
+//	}
+//
+// Other implementations can resolve using DNS over HTTPS, or a static
+// configuration.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// PinnedResolver is a Resolver with pinned (fixed) addresses for some
+// hostnames, e.g. for split-horizon DNS, or egress policies allowing only
+// known addresses.
+//
+//	resolver := &rdap.PinnedResolver{
+//	  Addresses: map[string][]net.IP{
+//	    "rdap.nic.cz": {net.ParseIP("192.0.2.1")},
+//	  },
+//	}
+//
+// Addresses can also be pinned by resolving them in advance, see Pin().
+//
+// A PinnedResolver is safe for concurrent use.
+type PinnedResolver struct {
+	// Pinned addresses, per hostname.
+	Addresses map[string][]net.IP
+
+	// Resolver for unpinned hostnames, and Pin(). The default (nil) is
+	// net.DefaultResolver.
+	Resolver Resolver
+
+	// Only resolve pinned hostnames. Other hostnames fail to resolve.
+	Strict bool
+
+	mu sync.Mutex
+}
+
+// LookupIPAddr returns the pinned addresses of |host|, or resolves it using
+// Resolver (unless Strict is set).
+func (p *PinnedResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	p.mu.Lock()
+	ips, ok := p.Addresses[host]
+	p.mu.Unlock()
+
+	if ok {
+		addrs := make([]net.IPAddr, len(ips))
+		for i, ip := range ips {
+			addrs[i] = net.IPAddr{IP: ip}
+		}
+
+		return addrs, nil
+	}
+
+	if p.Strict {
+		return nil, &net.DNSError{
+			Err:        "hostname not pinned",
+			Name:       host,
+			IsNotFound: true,
+		}
+	}
+
+	return p.resolver().LookupIPAddr(ctx, host)
+}
+
+// Pin resolves the hostnames |hosts| using Resolver, and pins their
+// addresses.
+func (p *PinnedResolver) Pin(ctx context.Context, hosts ...string) error {
+	for _, host := range hosts {
+		addrs, err := p.resolver().LookupIPAddr(ctx, host)
+		if err != nil {
+			return err
+		}
+
+		ips := make([]net.IP, len(addrs))
+		for i, addr := range addrs {
+			ips[i] = addr.IP
+		}
+
+		p.mu.Lock()
+		if p.Addresses == nil {
+			p.Addresses = map[string][]net.IP{}
+		}
+		p.Addresses[host] = ips
+		p.mu.Unlock()
+	}
+
+	return nil
+}
+
+func (p *PinnedResolver) resolver() Resolver {
+	if p.Resolver == nil {
+		return net.DefaultResolver
+	}
+
+	return p.Resolver
+}
+
+// dialWithResolver returns the http.Transport DialContext function |dial| (nil
+// meaning the default dialer), resolving hostnames using |resolver|.
+//
+// Each resolved address is tried in turn, until a connection succeeds.
+func dialWithResolver(dial func(ctx context.Context, network, addr string) (net.Conn, error), resolver Resolver) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dial == nil {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+		dial = dialer.DialContext
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+
+		addrs, err := resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(addrs) == 0 {
+			return nil, &net.DNSError{
+				Err:        "no addresses",
+				Name:       host,
+				IsNotFound: true,
+			}
+		}
+
+		for _, a := range addrs {
+			var conn net.Conn
+			conn, err = dial(ctx, network, net.JoinHostPort(a.IP.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		return nil, fmt.Errorf("dial %s (%d addresses): %w", host, len(addrs), err)
+	}
+}
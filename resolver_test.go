@@ -0,0 +1,98 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+// staticResolver resolves every hostname to its addresses, counting lookups.
+type staticResolver struct {
+	addrs   []net.IPAddr
+	lookups int
+}
+
+func (s *staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	s.lookups++
+	return s.addrs, nil
+}
+
+func TestClientResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	}))
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	serverURL, _ := url.Parse("http://rdap.example.invalid:" + port)
+
+	// The first address is unreachable, the second is the server.
+	resolver := &staticResolver{
+		addrs: []net.IPAddr{{IP: net.ParseIP("127.0.0.2")}, {IP: net.ParseIP("127.0.0.1")}},
+	}
+
+	client := &Client{
+		Resolver: resolver,
+		Verbose:  verboseFunc(),
+	}
+
+	if _, err := client.Do(NewDomainRequest("example.cz").WithServer(serverURL)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if resolver.lookups != 1 {
+		t.Errorf("Made %d lookups, expected 1", resolver.lookups)
+	}
+
+	// Strict pinning.
+	client = &Client{
+		Resolver: &PinnedResolver{
+			Addresses: map[string][]net.IP{"rdap.example.invalid": {net.ParseIP("127.0.0.1")}},
+			Strict:    true,
+		},
+		Verbose: verboseFunc(),
+	}
+
+	if _, err := client.Do(NewDomainRequest("example.cz").WithServer(serverURL)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	otherURL, _ := url.Parse("http://other.example.invalid:" + port)
+	resp, err := client.Do(NewDomainRequest("example.cz").WithServer(otherURL))
+
+	var dnsErr *net.DNSError
+	if err == nil || !errors.As(resp.HTTP[0].Error, &dnsErr) {
+		t.Errorf("Unexpected error %v", resp.HTTP[0].Error)
+	}
+}
+
+func TestPinnedResolverPin(t *testing.T) {
+	static := &staticResolver{addrs: []net.IPAddr{{IP: net.ParseIP("192.0.2.1")}}}
+	resolver := &PinnedResolver{Resolver: static}
+
+	if err := resolver.Pin(context.Background(), "rdap.example"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	static.addrs = nil
+
+	addrs, err := resolver.LookupIPAddr(context.Background(), "rdap.example")
+	if err != nil || len(addrs) != 1 || !addrs[0].IP.Equal(net.ParseIP("192.0.2.1")) {
+		t.Errorf("Unexpected addresses %v, error %v", addrs, err)
+	}
+
+	if static.lookups != 1 {
+		t.Errorf("Made %d lookups, expected 1", static.lookups)
+	}
+}
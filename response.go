@@ -5,28 +5,140 @@
 package rdap
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/openrdap/rdap/bootstrap"
 )
 
+// Response is the result of an RDAP query.
 type Response struct {
-	Object          RDAPObject
+	// Decoded RDAP response, e.g. *Domain.
+	Object RDAPObject
+
+	// Bootstrap lookup result, if the query was bootstrapped.
 	BootstrapAnswer *bootstrap.Answer
-	HTTP            []*HTTPResponse
+
+	// HTTP exchanges made, in order: one per RDAP server tried. The last is
+	// the one Object was decoded from. See FinalHTTP().
+	HTTP []*HTTPResponse
+
+	// WHOIS response, if the query fell back to WHOIS (see
+	// Client.WhoisFallback). Object is then converted from the WHOIS
+	// response, and is not RDAP data.
+	Whois *WhoisResponse
+
+	// Response from the registrar's RDAP server, if a registrar referral was
+	// followed (see Client.FollowReferrals). nil if there was no referral, or
+	// it failed.
+	Referral *Response
+
+	// Error encountered while following the referral, if any. A failed
+	// referral does not fail the query.
+	ReferralError error
 }
 
 type RDAPObject interface{}
 
+// FinalHTTP returns the last HTTP exchange made (the one Object was decoded
+// from, if the query succeeded), or nil if none were made.
+func (r *Response) FinalHTTP() *HTTPResponse {
+	if len(r.HTTP) == 0 {
+		return nil
+	}
+
+	return r.HTTP[len(r.HTTP)-1]
+}
+
+// HTTPResponse is the raw HTTP exchange with an RDAP server, e.g. for
+// archiving the original response, or debugging server specific quirks.
 type HTTPResponse struct {
-	URL      string
+	// Request URL.
+	URL string
+
+	// HTTP response, or nil if the request failed. The response body has
+	// been read into Body.
 	Response *http.Response
-	Body     []byte
+
+	// Raw response body.
+	Body []byte
+
 	Error    error
 	Duration time.Duration
+
+	// Number of attempts made (see RetryPolicy).
+	Attempts int
+
+	// True if the response was served from the Client's HTTPCache. This
+	// includes responses revalidated with the server (304 Not Modified).
+	Cached bool
+
+	// Stats about decoding the response body, if it was decoded.
+	DecodeStats *DecodeStats
+
+	// URLs of the HTTP redirects followed, in order. The last is the URL
+	// which returned Response. See RedirectPolicy.
+	Redirects []string
+
+	// True if the response was shared with an identical concurrent query,
+	// which made the HTTP request. See Client.CoalesceQueries.
+	Shared bool
+}
+
+// StatusCode returns the HTTP status code, or 0 if the request failed.
+func (h *HTTPResponse) StatusCode() int {
+	if h.Response == nil {
+		return 0
+	}
+
+	return h.Response.StatusCode
+}
+
+// Header returns the HTTP response headers, or nil if the request failed.
+func (h *HTTPResponse) Header() http.Header {
+	if h.Response == nil {
+		return nil
+	}
+
+	return h.Response.Header
+}
+
+// FinalURL returns the URL which returned the response, after following any
+// redirects.
+func (h *HTTPResponse) FinalURL() string {
+	if len(h.Redirects) > 0 {
+		return h.Redirects[len(h.Redirects)-1]
+	}
+
+	return h.URL
 }
 
+// Dump returns the HTTP response (status line, headers, and body) in HTTP/1.x
+// wire format, e.g. for archiving.
+//
+// Returns an error if the request failed.
+func (h *HTTPResponse) Dump() ([]byte, error) {
+	if h.Response == nil {
+		return nil, fmt.Errorf("no HTTP response for %s", h.URL)
+	}
+
+	resp := *h.Response
+	resp.Body = ioutil.NopCloser(bytes.NewReader(h.Body))
+	resp.ContentLength = int64(len(h.Body))
+	resp.TransferEncoding = nil
+
+	return httputil.DumpResponse(&resp, true)
+}
+
+// WhoisStyleResponse is an RDAP response rendered as classic WHOIS "Key:
+// value" fields, for humans and legacy scripts. See
+// Response.ToWhoisStyleResponse().
 type WhoisStyleResponse struct {
 	KeyDisplayOrder []string
 	Data            map[string][]string
@@ -45,6 +157,37 @@ func (w *WhoisStyleResponse) add(key string, value string) {
 	}
 }
 
+// String returns the fields as WHOIS text, one "Key: value" line per value.
+//
+// Newlines in values are replaced by spaces, and NUL characters removed, so
+// each value stays on its own line.
+func (w *WhoisStyleResponse) String() string {
+	var b strings.Builder
+
+	for _, key := range w.KeyDisplayOrder {
+		for _, value := range w.Data[key] {
+			fmt.Fprintf(&b, "%s: %s\n", key, whoisSafe(value))
+		}
+	}
+
+	return b.String()
+}
+
+// whoisSafe returns |v| with newlines replaced by spaces, and NUL characters
+// removed.
+func whoisSafe(v string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '\000':
+			return -1
+		case '\n':
+			return ' '
+		default:
+			return r
+		}
+	}, v)
+}
+
 func newWhoisStyleResponse() *WhoisStyleResponse {
 	w := &WhoisStyleResponse{}
 	w.Data = make(map[string][]string)
@@ -52,104 +195,336 @@ func newWhoisStyleResponse() *WhoisStyleResponse {
 	return w
 }
 
+// ToWhoisStyleResponse renders the response as classic WHOIS fields.
+//
+// Domains use OpenRDAP's original field names (Domain Name, Handle,
+// Expiration Date, Registrant Locality, Abuse Email, etc.), which scripts may
+// depend on. See ToICANNWhoisStyleResponse() for the ICANN gTLD field names.
+//
+// IP networks, autnums, and entities use the RIR style field names (NetRange,
+// CIDR, ASNumber, OrgName, etc.), and nameservers use Server Name and IP
+// Address. Other responses have no fields.
 func (r *Response) ToWhoisStyleResponse() *WhoisStyleResponse {
+	return r.toWhoisStyleResponse(false)
+}
+
+// ToICANNWhoisStyleResponse renders the response as classic WHOIS fields,
+// like ToWhoisStyleResponse(), but domains (and entities) use the ICANN gTLD
+// WHOIS field names (Registry Domain ID, Registry Expiry Date, Registrar
+// Abuse Contact Email, Registrant City, etc.).
+func (r *Response) ToICANNWhoisStyleResponse() *WhoisStyleResponse {
+	return r.toWhoisStyleResponse(true)
+}
+
+func (r *Response) toWhoisStyleResponse(icann bool) *WhoisStyleResponse {
 	w := newWhoisStyleResponse()
 
-	// Only support domain whois so far.
-	d, ok := r.Object.(*Domain)
-	if !ok {
-		return w
+	switch v := r.Object.(type) {
+	case *Domain:
+		if icann {
+			addICANNDomainFields(w, v)
+		} else {
+			addDomainFields(w, v)
+		}
+	case *IPNetwork:
+		addIPNetworkFields(w, v)
+	case *Autnum:
+		addAutnumFields(w, v)
+	case *Entity:
+		addEntityFields(w, "", v, icann)
+	case *Nameserver:
+		w.add("Server Name", v.LDHName)
+		w.add("Handle", v.Handle)
+		if addrs, _ := v.Addrs(); addrs != nil {
+			for _, addr := range addrs {
+				w.add("IP Address", addr.String())
+			}
+		}
+		for _, s := range v.Status {
+			w.add("Status", s)
+		}
+		addWhoisEvents(w, v.Events, "Creation Date", "Updated Date")
 	}
 
-	// "Domain Name"
-	w.add("Domain Name", d.LDHName)
+	return w
+}
 
-	// Registry Domain ID
+// addDomainFields adds the domain's fields, with OpenRDAP's original field
+// names.
+func addDomainFields(w *WhoisStyleResponse, d *Domain) {
+	w.add("Domain Name", d.LDHName)
 	w.add("Handle", d.Handle)
-
-	// "Registrar WHOIS Server"
 	w.add("Registrar WHOIS Server", d.Port43)
 
-	// Events.
 	for _, e := range d.Events {
 		switch e.Action {
-		case "last changed":
+		case EventLastChanged:
 			w.add("Updated Date", e.Date)
-		case "registration":
+		case EventRegistration:
 			w.add("Creation Date", e.Date)
-		case "expiration":
+		case EventExpiration:
 			w.add("Expiration Date", e.Date)
 		}
 	}
 
-	// Registrar fields.
-	registrar := findFirstEntity("registrar", d.Entities)
-	if registrar != nil {
-		vcard := registrar.VCard
-		if vcard != nil {
-			// "Registrar"
-			w.add("Registrar", vcard.Name())
+	// Only top level entities, as ToWhoisStyleResponse() always has.
+	if registrar := findFirstEntity(RoleRegistrar, d.Entities); registrar != nil {
+		if c := NewContact(registrar); c != nil {
+			w.add("Registrar", c.Name)
 		}
 
-		// "Registrar IANA ID"
 		for _, id := range registrar.PublicIDs {
 			if id.Type == "IANA Registrar ID" {
 				w.add("Registrar IANA ID", id.Identifier)
 			}
 		}
-
-		// "Registrar Abuse Contact Email"
-		// "Registrar Abuse Contact Phone"
 	}
 
-	// "Domain Status"
 	for _, s := range d.Status {
 		w.add("Domain Status", s)
 	}
 
-	addEntityFields(w, "Registrant", findFirstEntity("registrant", d.Entities))
-	addEntityFields(w, "Admin", findFirstEntity("administrative", d.Entities))
-	addEntityFields(w, "Tech", findFirstEntity("technical", d.Entities))
-	addEntityFields(w, "Abuse", findFirstEntity("abuse", d.Entities))
+	addEntityFields(w, "Registrant", findFirstEntity(RoleRegistrant, d.Entities), false)
+	addEntityFields(w, "Admin", findFirstEntity(RoleAdministrative, d.Entities), false)
+	addEntityFields(w, "Tech", findFirstEntity(RoleTechnical, d.Entities), false)
+	addEntityFields(w, "Abuse", findFirstEntity(RoleAbuse, d.Entities), false)
 
-	// "Name Server"
 	for _, n := range d.Nameservers {
 		w.add("Name Server", n.LDHName)
 	}
-
-	return w
 }
 
-func addEntityFields(w *WhoisStyleResponse, t string, e *Entity) {
-	if e == nil {
-		return
+// addICANNDomainFields adds the domain's fields, with the ICANN gTLD WHOIS
+// field names.
+func addICANNDomainFields(w *WhoisStyleResponse, d *Domain) {
+	w.add("Domain Name", d.LDHName)
+	w.add("Registry Domain ID", d.Handle)
+	w.add("Registrar WHOIS Server", d.Port43)
+
+	for _, e := range d.Events {
+		switch e.Action {
+		case EventLastChanged:
+			w.add("Updated Date", e.Date)
+		case EventRegistration:
+			w.add("Creation Date", e.Date)
+		case EventExpiration:
+			w.add("Registry Expiry Date", e.Date)
+		case EventRegistrarExpiration:
+			w.add("Registrar Registration Expiration Date", e.Date)
+		}
 	}
 
-	v := e.VCard
-	if v == nil {
-		return
+	registrar := addRegistrarFields(w, d)
+
+	// Only the registrar's own abuse contact, not the domain's.
+	if registrar != nil {
+		if abuse := NewContact(findEntityByRole(RoleAbuse, registrar.Entities)); abuse != nil {
+			w.add("Registrar Abuse Contact Email", abuse.Email)
+			w.add("Registrar Abuse Contact Phone", strings.TrimPrefix(abuse.Tel, "tel:"))
+		}
+	}
+
+	for _, s := range d.Status {
+		if code, ok := Status(strings.ToLower(s)).EPP(); ok {
+			w.add("Domain Status", code+" https://icann.org/epp#"+code)
+		} else {
+			w.add("Domain Status", s)
+		}
+	}
+
+	addEntityFields(w, "Registrant", findEntityByRole(RoleRegistrant, d.Entities), true)
+	addEntityFields(w, "Admin", findEntityByRole(RoleAdministrative, d.Entities), true)
+	addEntityFields(w, "Tech", findEntityByRole(RoleTechnical, d.Entities), true)
+
+	for _, n := range d.Nameservers {
+		w.add("Name Server", n.LDHName)
 	}
 
-	w.add(t+" Name", v.Name())
-	w.add(t+" PO Box", v.POBox())
-	w.add(t+" Extended Address", v.ExtendedAddress())
-	w.add(t+" Street", v.StreetAddress())
-	w.add(t+" Locality", v.Locality())
-	w.add(t+" Post Code", v.PostalCode())
-	w.add(t+" Country", v.Country())
-	w.add(t+" Tel", v.Tel())
-	w.add(t+" Fax", v.Fax())
-	w.add(t+" Email", v.Email())
+	if d.SecureDNS != nil && d.SecureDNS.DelegationSigned != nil {
+		if *d.SecureDNS.DelegationSigned {
+			w.add("DNSSEC", "signedDelegation")
+		} else {
+			w.add("DNSSEC", "unsigned")
+		}
+	}
+
+	for _, e := range d.Events {
+		if e.Action == EventLastUpdateOfRDAPDatabase {
+			w.add(">>> Last update of RDAP database", e.Date+" <<<")
+		}
+	}
 }
 
+// findFirstEntity returns the first top level entity with role |role| in
+// |entities|, or nil if there's none. Nested entities aren't searched, unlike
+// findEntityByRole().
 func findFirstEntity(role string, entities []Entity) *Entity {
-	for _, e := range entities {
-		for _, r := range e.Roles {
+	for i := range entities {
+		for _, r := range entities[i].Roles {
 			if r == role {
-				return &e
+				return &entities[i]
 			}
 		}
 	}
 
 	return nil
 }
+
+// addRegistrarFields adds the Registrar and Registrar IANA ID fields, and
+// returns the domain's registrar entity, or nil if it has none.
+func addRegistrarFields(w *WhoisStyleResponse, d *Domain) *Entity {
+	registrar := findEntityByRole(RoleRegistrar, d.Entities)
+	if registrar == nil {
+		return nil
+	}
+
+	if c := NewContact(registrar); c != nil {
+		w.add("Registrar", c.Name)
+	}
+
+	if id, ok := registrar.IANARegistrarID(); ok {
+		w.add("Registrar IANA ID", strconv.Itoa(id))
+	}
+
+	return registrar
+}
+
+func addIPNetworkFields(w *WhoisStyleResponse, n *IPNetwork) {
+	if n.StartAddress != "" || n.EndAddress != "" {
+		w.add("NetRange", n.StartAddress+" - "+n.EndAddress)
+	}
+
+	cidrs := n.CIDRs()
+	if cidrs == nil {
+		cidrs, _ = n.Prefixes()
+	}
+
+	var prefixes []string
+	for _, p := range cidrs {
+		prefixes = append(prefixes, p.String())
+	}
+	w.add("CIDR", strings.Join(prefixes, ", "))
+
+	w.add("NetName", n.Name)
+	w.add("NetHandle", n.Handle)
+	w.add("Parent", n.ParentHandle)
+	w.add("NetType", n.Type)
+
+	for _, asn := range n.OriginAutnums() {
+		w.add("OriginAS", fmt.Sprintf("AS%d", asn))
+	}
+
+	w.add("Country", n.Country)
+	addWhoisEvents(w, n.Events, "RegDate", "Updated")
+	addRIREntityFields(w, n.Entities)
+}
+
+func addAutnumFields(w *WhoisStyleResponse, a *Autnum) {
+	if start, end, err := a.Range(); err == nil {
+		if start == end {
+			w.add("ASNumber", strconv.FormatUint(uint64(start), 10))
+		} else {
+			w.add("ASNumber", fmt.Sprintf("%d - %d", start, end))
+		}
+	}
+
+	w.add("ASName", a.Name)
+	w.add("ASHandle", a.Handle)
+	w.add("Country", a.Country)
+	addWhoisEvents(w, a.Events, "RegDate", "Updated")
+	addRIREntityFields(w, a.Entities)
+}
+
+// addWhoisEvents adds the registration and last changed event dates, as the
+// fields |registered| and |updated|.
+func addWhoisEvents(w *WhoisStyleResponse, events []Event, registered string, updated string) {
+	for _, e := range events {
+		switch e.Action {
+		case EventRegistration:
+			w.add(registered, e.Date)
+		case EventLastChanged:
+			w.add(updated, e.Date)
+		}
+	}
+}
+
+// addRIREntityFields adds the registrant organisation, and the abuse and
+// technical contacts, as RIR style WHOIS fields.
+func addRIREntityFields(w *WhoisStyleResponse, entities []Entity) {
+	if org := NewContact(findEntityByRole(RoleRegistrant, entities)); org != nil {
+		w.add("OrgName", org.Name)
+		w.add("OrgId", org.Handle)
+		w.add("Address", org.StreetAddress)
+		w.add("City", org.Locality)
+		w.add("StateProv", org.Region)
+		w.add("PostalCode", org.PostalCode)
+		w.add("Country", org.Country)
+	}
+
+	for _, contact := range []struct {
+		prefix string
+		role   string
+	}{
+		{"OrgAbuse", RoleAbuse},
+		{"OrgTech", RoleTechnical},
+	} {
+		if c := NewContact(findEntityByRole(contact.role, entities)); c != nil {
+			w.add(contact.prefix+"Handle", c.Handle)
+			w.add(contact.prefix+"Name", c.Name)
+			w.add(contact.prefix+"Phone", strings.TrimPrefix(c.Tel, "tel:"))
+			w.add(contact.prefix+"Email", c.Email)
+		}
+	}
+}
+
+// addEntityFields adds the contact fields of the entity |e|, prefixed by |t|
+// (e.g. "Registrant"). |icann| selects the ICANN gTLD field names (City,
+// Postal Code, Phone, etc.) over OpenRDAP's original ones (Locality, Post Code,
+// Tel, etc.).
+func addEntityFields(w *WhoisStyleResponse, t string, e *Entity, icann bool) {
+	if e == nil {
+		return
+	}
+
+	if t == "" {
+		w.add("Handle", e.Handle)
+		for _, role := range e.Roles {
+			w.add("Role", role)
+		}
+	} else {
+		t += " "
+	}
+
+	v := e.ContactVCard()
+	if v == nil {
+		return
+	}
+
+	if !icann {
+		w.add(t+"Name", v.Name())
+		w.add(t+"PO Box", v.POBox())
+		w.add(t+"Extended Address", v.ExtendedAddress())
+		w.add(t+"Street", v.StreetAddress())
+		w.add(t+"Locality", v.Locality())
+		w.add(t+"Post Code", v.PostalCode())
+		w.add(t+"Country", v.Country())
+		w.add(t+"Tel", v.Tel())
+		w.add(t+"Fax", v.Fax())
+		w.add(t+"Email", v.Email())
+
+		return
+	}
+
+	w.add(t+"Name", v.Name())
+	w.add(t+"Organization", v.Org())
+	w.add(t+"PO Box", v.POBox())
+	w.add(t+"Extended Address", v.ExtendedAddress())
+	w.add(t+"Street", v.StreetAddress())
+	w.add(t+"City", v.Locality())
+	w.add(t+"State/Province", v.Region())
+	w.add(t+"Postal Code", v.PostalCode())
+	w.add(t+"Country", v.Country())
+	w.add(t+"Phone", v.Tel())
+	w.add(t+"Fax", v.Fax())
+	w.add(t+"Email", v.Email())
+}
@@ -0,0 +1,210 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestResponseRawHTTP(t *testing.T) {
+	body := test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old/domain/example.cz" {
+			http.Redirect(w, r, "/domain/example.cz", http.StatusMovedPermanently)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.Header().Set("X-Quirk", "1")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL + "/old")
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	resp, err := client.Do(NewDomainRequest("example.cz").WithServer(serverURL))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	h := resp.FinalHTTP()
+	if h == nil {
+		t.Fatalf("No HTTP response")
+	}
+
+	if h.StatusCode() != 200 || h.Header().Get("X-Quirk") != "1" {
+		t.Errorf("Unexpected status %d, headers %v", h.StatusCode(), h.Header())
+	}
+
+	if h.URL != server.URL+"/old/domain/example.cz" || h.FinalURL() != server.URL+"/domain/example.cz" {
+		t.Errorf("Unexpected URL %s, final URL %s", h.URL, h.FinalURL())
+	}
+
+	if !bytes.Equal(h.Body, body) {
+		t.Errorf("Unexpected body")
+	}
+
+	dump, err := h.Dump()
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.HasPrefix(dump, []byte("HTTP/1.1 200 OK\r\n")) || !bytes.HasSuffix(dump, body) ||
+		!bytes.Contains(dump, []byte("X-Quirk: 1\r\n")) {
+		t.Errorf("Unexpected dump %q", dump)
+	}
+
+	if (&Response{}).FinalHTTP() != nil || (&HTTPResponse{}).StatusCode() != 0 {
+		t.Errorf("Unexpected HTTP response for empty Response")
+	}
+}
+
+func TestResponseToICANNWhoisStyleResponse(t *testing.T) {
+	tests := []struct {
+		Filename string
+		Expected string
+	}{
+		{
+			"rdap/rdap.registry.example/domain-example.example.json",
+			"Domain Name: example.example\n" +
+				"Registry Domain ID: 1234_DOMAIN_EXAMPLE-EXAMPLE\n" +
+				"Creation Date: 2001-02-03T04:05:06Z\n" +
+				"Registry Expiry Date: 2031-02-03T04:05:06Z\n" +
+				"Updated Date: 2024-02-03T04:05:06Z\n" +
+				"Registrar: Example Registrar, Inc.\n" +
+				"Registrar IANA ID: 9999\n" +
+				"Registrar Abuse Contact Email: abuse@registrar.example\n" +
+				"Registrar Abuse Contact Phone: +1.5555555555\n" +
+				"Domain Status: clientTransferProhibited https://icann.org/epp#clientTransferProhibited\n" +
+				"Domain Status: ok https://icann.org/epp#ok\n" +
+				"Name Server: ns1.example.example\n" +
+				"Name Server: ns2.example.net\n" +
+				">>> Last update of RDAP database: 2024-06-01T00:00:00Z <<<\n",
+		},
+		{
+			"rdap/rdap.arin.net/ip-192.0.2.1.json",
+			"NetRange: 192.0.2.0 - 192.0.3.255\n" +
+				"CIDR: 192.0.2.0/24, 192.0.3.0/24\n" +
+				"NetName: TEST-NET-1\n" +
+				"NetHandle: NET-192-0-2-0-1\n" +
+				"Parent: NET-192-0-0-0-0\n" +
+				"NetType: IANA Special Use\n" +
+				"OriginAS: AS64496\n" +
+				"OriginAS: AS64511\n" +
+				"RegDate: 2010-09-23T14:24:33-04:00\n",
+		},
+		{
+			"rdap/rdap.arin.net/entity-ABC123-ARIN.json",
+			"Handle: ABC123-ARIN\n" +
+				"Role: technical\n" +
+				"Name: Example Contact\n",
+		},
+	}
+
+	for _, tt := range tests {
+		obj, err := NewDecoder(test.LoadFile(tt.Filename)).Decode()
+		if err != nil {
+			t.Fatalf("%s: decode error: %s", tt.Filename, err)
+		}
+
+		text := (&Response{Object: obj}).ToICANNWhoisStyleResponse().String()
+		if text != tt.Expected {
+			t.Errorf("%s: unexpected WHOIS text:\n%s", tt.Filename, text)
+		}
+	}
+}
+
+func TestResponseToWhoisStyleResponse(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.registry.example/domain-example.example.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Domain Name: example.example\n" +
+		"Handle: 1234_DOMAIN_EXAMPLE-EXAMPLE\n" +
+		"Creation Date: 2001-02-03T04:05:06Z\n" +
+		"Expiration Date: 2031-02-03T04:05:06Z\n" +
+		"Updated Date: 2024-02-03T04:05:06Z\n" +
+		"Registrar: Example Registrar, Inc.\n" +
+		"Registrar IANA ID: 9999\n" +
+		"Domain Status: client transfer prohibited\n" +
+		"Domain Status: active\n" +
+		"Name Server: ns1.example.example\n" +
+		"Name Server: ns2.example.net\n"
+
+	if text := (&Response{Object: obj}).ToWhoisStyleResponse().String(); text != expected {
+		t.Errorf("Unexpected WHOIS text:\n%s", text)
+	}
+
+	// The IANA Registrar ID is output as is.
+	d := &Domain{
+		Entities: []Entity{{
+			Roles:     []string{RoleRegistrar},
+			PublicIDs: []PublicID{{Type: "IANA Registrar ID", Identifier: "09999"}},
+		}},
+	}
+
+	if text := (&Response{Object: d}).ToWhoisStyleResponse().String(); text != "Registrar IANA ID: 09999\n" {
+		t.Errorf("Unexpected WHOIS text:\n%s", text)
+	}
+}
+
+func TestResponseToICANNWhoisStyleResponseAbuse(t *testing.T) {
+	d := &Domain{
+		LDHName: "example.example",
+		Entities: []Entity{
+			{
+				Roles: []string{RoleRegistrar},
+				VCard: &VCard{Properties: []*VCardProperty{{Name: "fn", Type: "text", Value: "Example Registrar"}}},
+			},
+			{
+				Roles: []string{RoleAbuse},
+				VCard: &VCard{Properties: []*VCardProperty{{Name: "email", Type: "text", Value: "abuse@domain.example"}}},
+			},
+		},
+	}
+
+	// The domain's own abuse contact isn't the registrar's.
+	expected := "Domain Name: example.example\n" +
+		"Registrar: Example Registrar\n"
+
+	if text := (&Response{Object: d}).ToICANNWhoisStyleResponse().String(); text != expected {
+		t.Errorf("Unexpected WHOIS text:\n%s", text)
+	}
+
+	w := newWhoisStyleResponse()
+	w.add("Remarks", "line 1\nline 2\000")
+	if text := w.String(); text != "Remarks: line 1 line 2\n" {
+		t.Errorf("Unexpected WHOIS text %q", text)
+	}
+}
+
+func TestResponseToWhoisStyleResponseAutnum(t *testing.T) {
+	start, end := uint32(64496), uint32(64511)
+	a := &Autnum{
+		Handle:      "AS64496-AS64511",
+		StartAutnum: &start,
+		EndAutnum:   &end,
+		Name:        "EXAMPLE-AS",
+		Country:     "AU",
+	}
+
+	expected := "ASNumber: 64496 - 64511\n" +
+		"ASName: EXAMPLE-AS\n" +
+		"ASHandle: AS64496-AS64511\n" +
+		"Country: AU\n"
+
+	if text := (&Response{Object: a}).ToWhoisStyleResponse().String(); text != expected {
+		t.Errorf("Unexpected WHOIS text:\n%s", text)
+	}
+}
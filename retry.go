@@ -0,0 +1,385 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy specifies how failed HTTP requests to an RDAP server are
+// retried, before moving on to the next server (if any).
+//
+// To retry transient errors from a flaky server:
+//
+//	client := &rdap.Client{
+//	  RetryPolicy: rdap.DefaultRetryPolicy(),
+//	}
+//
+// The delay before retry n (n=1, 2, ...) is InitialBackoff * Multiplier^(n-1),
+// capped at MaxBackoff, then randomised by Jitter.
+type RetryPolicy struct {
+	// Maximum number of attempts per RDAP server, including the first attempt.
+	//
+	// Values <= 1 disable retries.
+	MaxAttempts int
+
+	// Delay before the first retry.
+	InitialBackoff time.Duration
+
+	// Maximum delay between attempts. 0 means no maximum.
+	MaxBackoff time.Duration
+
+	// Backoff multiplier applied after each retry. Values < 1 are treated as 1
+	// (constant backoff).
+	Multiplier float64
+
+	// Fraction of each delay which is randomised, from 0.0 (no jitter) to 1.0
+	// (delay is random between 0 and the full backoff).
+	//
+	// Jitter prevents many clients retrying in lockstep.
+	Jitter float64
+
+	// HTTP status codes which are retried, e.g. 502, 503.
+	RetryableStatusCodes []int
+
+	// Decides whether a network error (e.g. connection refused) is retried.
+	//
+	// The default (nil) retries all network errors. Errors caused by the
+	// Request's context being cancelled or timing out are never retried.
+	RetryableError func(err error) bool
+
+	// Maximum Retry-After delay waited for.
+	//
+	// A 429 (Too Many Requests) or 503 response with a Retry-After header is
+	// retried no sooner than the server requests. If the server requests a
+	// longer delay than this, or the delay would exceed the Request's context
+	// deadline, the query isn't retried, and fails with a RateLimitedError.
+	//
+	// The default (0) is DefaultMaxRetryAfter.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultMaxRetryAfter is the default RetryPolicy.MaxRetryAfter.
+const DefaultMaxRetryAfter = time.Minute
+
+// ErrRateLimited matches (using errors.Is()) the RateLimitedError returned by
+// Client.Do() when RDAP servers rate limited the query.
+var ErrRateLimited = errors.New("rate limited by RDAP server")
+
+// RateLimitedError is returned by Client.Do() when the query failed because
+// an RDAP server rate limited it (HTTP 429 Too Many Requests, or 503 with a
+// Retry-After header), and any retries were exhausted.
+//
+// Check for it using errors.Is(err, rdap.ErrRateLimited), or errors.As() to
+// read the retry hint.
+type RateLimitedError struct {
+	// URL of the rate limited query.
+	URL string
+
+	// HTTP status code returned.
+	StatusCode int
+
+	// Delay requested by the server's Retry-After header. 0 if the server
+	// didn't specify one.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s (status-code=%d), retry after %s", ErrRateLimited, e.StatusCode, e.RetryAfter)
+	}
+
+	return fmt.Sprintf("%s (status-code=%d)", ErrRateLimited, e.StatusCode)
+}
+
+// Is returns true for ErrRateLimited.
+func (e *RateLimitedError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// rateLimitedError returns a RateLimitedError if |httpResponse| is a rate
+// limiting response, otherwise nil.
+func rateLimitedError(httpResponse *HTTPResponse) *RateLimitedError {
+	if httpResponse.Error != nil || httpResponse.Response == nil {
+		return nil
+	}
+
+	retryAfter, hasRetryAfter := retryAfterOf(httpResponse.Response, time.Now())
+	status := httpResponse.Response.StatusCode
+
+	if status != http.StatusTooManyRequests && !(status == http.StatusServiceUnavailable && hasRetryAfter) {
+		return nil
+	}
+
+	return &RateLimitedError{
+		URL:        httpResponse.URL,
+		StatusCode: status,
+		RetryAfter: retryAfter,
+	}
+}
+
+// retryAfterOf returns the Retry-After delay of the 429/503 response |resp|,
+// relative to |now|.
+//
+// Returns false if there's no valid Retry-After header.
+func retryAfterOf(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	// Delay in seconds, e.g. "120".
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	// HTTP date, e.g. "Wed, 21 Oct 2015 07:28:00 GMT".
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// retryAfterAllowed returns true if the Retry-After |delay| may be waited
+// for, as per the policy and |ctx|'s deadline.
+func (p *RetryPolicy) retryAfterAllowed(ctx context.Context, delay time.Duration) bool {
+	max := p.MaxRetryAfter
+	if max == 0 {
+		max = DefaultMaxRetryAfter
+	}
+
+	if delay > max {
+		return false
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	return true
+}
+
+// DefaultRetryPolicy returns a RetryPolicy suitable for most uses: 3 attempts
+// per server, with exponential backoff starting at 500ms, retrying network
+// errors and HTTP status codes 429, 502, 503, and 504.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       time.Millisecond * 500,
+		MaxBackoff:           time.Second * 10,
+		Multiplier:           2,
+		Jitter:               0.2,
+		RetryableStatusCodes: []int{429, 502, 503, 504},
+	}
+}
+
+// shouldRetry returns true if |httpResponse| (attempt number |attempt|,
+// starting from 1) should be retried.
+func (p *RetryPolicy) shouldRetry(ctx context.Context, httpResponse *HTTPResponse, attempt int) bool {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+
+	if httpResponse.Error != nil {
+		// Error reading the body of an otherwise OK response, or a network
+		// error.
+		if p.RetryableError == nil {
+			return true
+		}
+
+		return p.RetryableError(httpResponse.Error)
+	}
+
+	if httpResponse.Response != nil {
+		for _, code := range p.RetryableStatusCodes {
+			if httpResponse.Response.StatusCode == code {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// backoff returns the delay before retry number |retry| (starting from 1).
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		delay *= multiplier
+
+		if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+			break
+		}
+	}
+
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+
+		delay = delay*(1-jitter) + rand.Float64()*delay*jitter
+	}
+
+	return time.Duration(delay)
+}
+
+// getWithRetries makes the HTTP request for |rdapReq|, retrying as per the
+// Client's RetryPolicy (or the server's Politeness policy).
+func (c *Client) getWithRetries(rdapReq *Request) *HTTPResponse {
+	ctx := rdapReq.Context()
+
+	if c.Offline {
+		return c.getOffline(rdapReq)
+	}
+
+	// Fresh cached responses don't count towards rate limits.
+	if c.usesHTTPCache(rdapReq) {
+		url := rdapReq.URL().String()
+
+		if cached, fresh := c.HTTPCache.get(url); fresh {
+			c.Verbose(fmt.Sprintf("client: using cached response for %s", url))
+
+			httpResponse := &HTTPResponse{
+				URL:      url,
+				Response: cached.response(nil),
+				Body:     cached.body,
+				Cached:   true,
+			}
+			c.logHTTPAttempt(ctx, httpResponse)
+
+			if c.Metrics != nil {
+				c.Metrics.CacheLookup(CacheHit)
+			}
+
+			return httpResponse
+		}
+	}
+	retryPolicy := c.RetryPolicy
+
+	var politeness *Politeness
+	if c.Politeness != nil {
+		host := rdapReq.URL().Hostname()
+		policy := c.Politeness.For(host)
+		politeness = &policy
+
+		if retryPolicy == nil {
+			retryPolicy = policy.Retry
+		}
+
+		release, err := c.acquireSlot(ctx, host, policy)
+		if err != nil {
+			return &HTTPResponse{
+				URL:   rdapReq.URL().String(),
+				Error: err,
+			}
+		}
+		defer release()
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := c.waitForRateLimit(ctx, rdapReq, politeness); err != nil {
+			return &HTTPResponse{
+				URL:      rdapReq.URL().String(),
+				Error:    err,
+				Attempts: attempt - 1,
+			}
+		}
+
+		httpResponse := c.get(rdapReq)
+		httpResponse.Attempts = attempt
+		c.logHTTPAttempt(ctx, httpResponse)
+
+		if !retryPolicy.shouldRetry(ctx, httpResponse, attempt) {
+			return httpResponse
+		}
+
+		delay := retryPolicy.backoff(attempt)
+
+		// Wait at least as long as the server asked?
+		if retryAfter, ok := retryAfterOf(httpResponse.Response, time.Now()); ok {
+			if !retryPolicy.retryAfterAllowed(ctx, retryAfter) {
+				c.Verbose(fmt.Sprintf("client: attempt #%d rate limited, Retry-After %s too long, not retrying",
+					attempt, retryAfter))
+
+				return httpResponse
+			}
+
+			if retryAfter > delay {
+				delay = retryAfter
+			}
+		}
+
+		if c.DeadlineBudget.exhausted(ctx, delay) {
+			c.Verbose(fmt.Sprintf("client: attempt #%d failed, not retrying: %s",
+				attempt, ErrDeadlineBudgetExhausted))
+
+			return httpResponse
+		}
+
+		if httpResponse.Error != nil {
+			c.Verbose(fmt.Sprintf("client: attempt #%d failed (%s), retrying in %s",
+				attempt, httpResponse.Error, delay))
+		} else {
+			c.Verbose(fmt.Sprintf("client: attempt #%d failed (status-code=%d), retrying in %s",
+				attempt, httpResponse.Response.StatusCode, delay))
+		}
+
+		c.log(ctx, slog.LevelInfo, LogRetry,
+			slog.String("url", httpResponse.URL),
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return httpResponse
+		case <-timer.C:
+		}
+	}
+}
+
+// logHTTPAttempt logs the HTTP request |httpResponse|.
+func (c *Client) logHTTPAttempt(ctx context.Context, httpResponse *HTTPResponse) {
+	var status int
+	if httpResponse.Response != nil {
+		status = httpResponse.Response.StatusCode
+	}
+
+	c.log(ctx, slog.LevelDebug, LogHTTPAttempt,
+		slog.String("url", httpResponse.URL),
+		slog.Int("attempt", httpResponse.Attempts),
+		slog.Int("status", status),
+		slog.Bool("cached", httpResponse.Cached),
+		slog.Duration("duration", httpResponse.Duration),
+		errorAttr(httpResponse.Error))
+}
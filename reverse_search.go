@@ -0,0 +1,157 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// ReverseSearchConformance is the rdapConformance identifier of the RDAP
+// reverse search extension (RFC 9536).
+const ReverseSearchConformance = "reverse_search"
+
+// ReverseSearchProperty describes a reverse search supported by an RDAP
+// server, as advertised in its help response (RFC 9536 section 5).
+type ReverseSearchProperty struct {
+	// e.g. "domains".
+	SearchableResourceType string `json:"searchableResourceType"`
+
+	// e.g. "entity".
+	RelatedResourceType string `json:"relatedResourceType"`
+
+	// e.g. "fn".
+	Property string `json:"property"`
+
+	// JSONPath of the property in the related object.
+	PropertyPath string `json:"propertyPath"`
+}
+
+func init() {
+	RegisterExtension(Extension{
+		Name:        ReverseSearchConformance,
+		Conformance: ReverseSearchConformance,
+		Members:     []string{"reverse_search_properties"},
+		Decode:      decodeReverseSearch,
+		Print:       printReverseSearch,
+	})
+}
+
+func decodeReverseSearch(src map[string]interface{}) (interface{}, error) {
+	properties, ok := src["reverse_search_properties"]
+	if !ok {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ReverseSearchProperty
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid reverse_search_properties: %s", err)
+	}
+
+	return result, nil
+}
+
+func printReverseSearch(value interface{}, p *ExtensionPrinter) {
+	h := p.Heading("Reverse Search")
+
+	for _, property := range value.([]ReverseSearchProperty) {
+		h.Value("Property", fmt.Sprintf("%s/reverse_search/%s?%s",
+			property.SearchableResourceType, property.RelatedResourceType, property.Property))
+	}
+}
+
+// ReverseSearchProperties returns the reverse searches advertised in the help
+// response, or nil if there are none.
+func (h *Help) ReverseSearchProperties() []ReverseSearchProperty {
+	if h.DecodeData == nil {
+		return nil
+	}
+
+	properties, _ := h.DecodeData.Extension(ReverseSearchConformance).([]ReverseSearchProperty)
+	return properties
+}
+
+// SupportsReverseSearch returns true if the help response indicates support
+// for the reverse search |searchType| (e.g. DomainReverseSearchRequest) by
+// the property |property| (e.g. "handle").
+//
+// The server must list the reverse_search rdapConformance. If it doesn't
+// advertise its reverse search properties, all searches are assumed to be
+// supported.
+func (h *Help) SupportsReverseSearch(searchType RequestType, property string) bool {
+	supported := false
+	for _, c := range h.Conformance {
+		if c == ReverseSearchConformance {
+			supported = true
+		}
+	}
+
+	if !supported {
+		return false
+	}
+
+	properties := h.ReverseSearchProperties()
+	if len(properties) == 0 {
+		return true
+	}
+
+	resourceType := reverseSearchResourceType(searchType)
+	for _, p := range properties {
+		if p.SearchableResourceType == resourceType && p.RelatedResourceType == "entity" &&
+			p.Property == property {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reverseSearchResourceType returns the searchable resource type of the
+// reverse search |searchType|, e.g. "domains".
+func reverseSearchResourceType(searchType RequestType) string {
+	switch searchType {
+	case DomainReverseSearchRequest:
+		return "domains"
+	case NameserverReverseSearchRequest:
+		return "nameservers"
+	case EntityReverseSearchRequest:
+		return "entities"
+	default:
+		return ""
+	}
+}
+
+// ReverseSearchDomains runs an RFC 9536 reverse search for domains on the RDAP
+// server |server| (a base URL), e.g. to find the domains of a contact:
+//
+//	results, err := client.ReverseSearchDomains(ctx, "https://rdap.nic.cz", url.Values{
+//	  "handle": []string{"CID-40*"},
+//	  "role":   []string{"registrant"},
+//	})
+//
+// See NewReverseSearchRequest(). For nameserver and entity reverse searches,
+// use Client.Do(). The timeout is 30s, unless |ctx| has a deadline.
+func (c *Client) ReverseSearchDomains(ctx context.Context, server string, conditions url.Values) (*DomainSearchResults, error) {
+	resp, err := c.search(ctx, server, NewReverseSearchRequest(DomainReverseSearchRequest, conditions))
+	if err != nil {
+		return nil, err
+	}
+
+	if results, ok := resp.Object.(*DomainSearchResults); ok {
+		return results, nil
+	}
+
+	return nil, &ClientError{
+		Type: WrongResponseType,
+		Text: "The server returned a non-DomainSearchResults RDAP response",
+	}
+}
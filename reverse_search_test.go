@@ -0,0 +1,83 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestHelpReverseSearch(t *testing.T) {
+	helpJSON := `{
+		"rdapConformance": ["rdap_level_0", "reverse_search"],
+		"reverse_search_properties": [
+			{
+				"searchableResourceType": "domains",
+				"relatedResourceType": "entity",
+				"property": "handle",
+				"propertyPath": "$.entities[*].handle"
+			}
+		]
+	}`
+
+	obj, err := NewDecoder([]byte(helpJSON)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	help := obj.(*Help)
+
+	if properties := help.ReverseSearchProperties(); len(properties) != 1 || properties[0].PropertyPath != "$.entities[*].handle" {
+		t.Errorf("Unexpected properties %+v", properties)
+	}
+
+	if !help.SupportsReverseSearch(DomainReverseSearchRequest, "handle") {
+		t.Errorf("Expected domain reverse search by handle")
+	}
+
+	if help.SupportsReverseSearch(DomainReverseSearchRequest, "fn") || help.SupportsReverseSearch(NameserverReverseSearchRequest, "handle") {
+		t.Errorf("Unexpected reverse search support")
+	}
+
+	if unknown := help.DecodeData.UnknownFields(); len(unknown) != 0 {
+		t.Errorf("Unexpected unknown fields %v", unknown)
+	}
+
+	if (&Help{}).SupportsReverseSearch(DomainReverseSearchRequest, "handle") {
+		t.Errorf("Unexpected reverse search support without conformance")
+	}
+}
+
+func TestClientReverseSearchDomains(t *testing.T) {
+	var requestURL *url.URL
+	server := searchServer(`{
+		"rdapConformance": ["rdap_level_0", "reverse_search"],
+		"domainSearchResults": [
+			{"objectClassName": "domain", "ldhName": "example.cz"}
+		]
+	}`, &requestURL)
+	defer server.Close()
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	results, err := client.ReverseSearchDomains(context.Background(), server.URL, url.Values{
+		"handle": []string{"CID-40*"},
+		"role":   []string{"registrant"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(results.Domains) != 1 || results.Domains[0].LDHName != "example.cz" {
+		t.Errorf("Unexpected results %+v", results.Domains)
+	}
+
+	if requestURL.Path != "/domains/reverse_search/entity" || requestURL.RawQuery != "handle=CID-40%2A&role=registrant" {
+		t.Errorf("Unexpected request URL %s", requestURL)
+	}
+}
@@ -0,0 +1,317 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is a version of the OpenRDAP JSON output schema.
+//
+// The output schema is a JSON encoding of the decoded RDAP response (a Domain,
+// Entity, etc), rather than the RDAP server's own JSON response. Unlike the
+// server's response, it has a fixed format across RDAP servers: each field
+// appears once, with its RDAP name, and empty fields are omitted.
+//
+// Each schema version is frozen. When this package's types gain new fields,
+// they're only output by newer schema versions, so programs parsing the output
+// of an older schema version don't break when this package is upgraded.
+//
+// Output using MarshalSchema(), or with the rdap command's --schema option:
+//
+//	rdap --json --schema=v1 example.cz
+type SchemaVersion int
+
+const (
+	// Version 1 of the output schema.
+	SchemaV1 SchemaVersion = 1
+
+	// Version 2 of the output schema: adds "lang" to notices and remarks.
+	SchemaV2 SchemaVersion = 2
+
+	// The latest version of the output schema.
+	LatestSchema = SchemaV2
+)
+
+// String returns the schema version name, e.g. "v1".
+func (v SchemaVersion) String() string {
+	return fmt.Sprintf("v%d", int(v))
+}
+
+// ParseSchemaVersion parses the schema version name |name|, e.g. "v1", "1",
+// or "latest".
+func ParseSchemaVersion(name string) (SchemaVersion, error) {
+	if name == "latest" {
+		return LatestSchema, nil
+	}
+
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "v"))
+	if err != nil || n < 1 || SchemaVersion(n) > LatestSchema {
+		return 0, fmt.Errorf("unknown schema version '%s' (supported: v1-%s, latest)", name, LatestSchema)
+	}
+
+	return SchemaVersion(n), nil
+}
+
+// schemaFields lists the fields output by each schema version, per type.
+//
+// Fields are added here (to a new schema version) when added to the types, or
+// the output of the existing schema versions would change.
+var schemaFields = map[SchemaVersion]map[string][]string{
+	SchemaV1: {
+		"Domain":                  {"Lang", "Conformance", "ObjectClassName", "Notices", "Handle", "LDHName", "UnicodeName", "Variants", "Nameservers", "SecureDNS", "Entities", "Status", "PublicIDs", "Remarks", "Links", "Port43", "Events", "Network"},
+		"Variant":                 {"Lang", "Relation", "IDNTable", "VariantNames"},
+		"VariantName":             {"Lang", "LDHName", "UnicodeName"},
+		"SecureDNS":               {"Lang", "ZoneSigned", "DelegationSigned", "MaxSigLife", "DS", "Keys"},
+		"DSData":                  {"Lang", "KeyTag", "Algorithm", "Digest", "DigestType", "Events", "Links"},
+		"KeyData":                 {"Flags", "Protocol", "Algorithm", "PublicKey", "Events", "Links"},
+		"Entity":                  {"Lang", "Conformance", "ObjectClassName", "Notices", "Handle", "VCard", "Roles", "PublicIDs", "Entities", "Remarks", "Links", "Events", "AsEventActor", "Status", "Port43", "Networks", "Autnums"},
+		"Nameserver":              {"Lang", "Conformance", "ObjectClassName", "Notices", "Handle", "LDHName", "UnicodeName", "IPAddresses", "Entities", "Status", "Remarks", "Links", "Port43", "Events"},
+		"IPAddressSet":            {"Lang", "V6", "V4"},
+		"Autnum":                  {"Lang", "Conformance", "ObjectClassName", "Notices", "Handle", "StartAutnum", "EndAutnum", "IPVersion", "Name", "Type", "Status", "Country", "Entities", "Remarks", "Links", "Port43", "Events"},
+		"IPNetwork":               {"Lang", "Conformance", "ObjectClassName", "Notices", "Handle", "StartAddress", "EndAddress", "IPVersion", "Name", "Type", "Country", "ParentHandle", "Status", "Entities", "Remarks", "Links", "Port43", "Events"},
+		"Help":                    {"Lang", "Conformance", "Notices"},
+		"Error":                   {"Lang", "Conformance", "Notices", "ErrorCode", "Title", "Description"},
+		"DomainSearchResults":     {"Lang", "Conformance", "Notices", "Domains"},
+		"NameserverSearchResults": {"Lang", "Conformance", "Notices", "Nameservers"},
+		"EntitySearchResults":     {"Lang", "Conformance", "Notices", "Entities"},
+		"Link":                    {"Value", "Rel", "Href", "HrefLang", "Title", "Media", "Type"},
+		"Notice":                  {"Title", "Type", "Description", "Links"},
+		"Remark":                  {"Title", "Type", "Description", "Links"},
+		"Event":                   {"Action", "Actor", "Date", "Links"},
+		"PublicID":                {"Type", "Identifier"},
+	},
+}
+
+func init() {
+	schemaFields[SchemaV2] = extendSchema(schemaFields[SchemaV1], map[string][]string{
+		"Notice": {"Lang", "Title", "Type", "Description", "Links"},
+		"Remark": {"Lang", "Title", "Type", "Description", "Links"},
+	})
+}
+
+// extendSchema returns a copy of the schema fields |base|, with the fields of
+// the types in |changes| replaced.
+func extendSchema(base map[string][]string, changes map[string][]string) map[string][]string {
+	result := make(map[string][]string, len(base))
+	for t, fields := range base {
+		result[t] = fields
+	}
+
+	for t, fields := range changes {
+		result[t] = fields
+	}
+
+	return result
+}
+
+// schemaTypes maps the top level types to their output schema type names.
+var schemaTypes = map[string]string{
+	"Domain":                  "domain",
+	"Entity":                  "entity",
+	"Nameserver":              "nameserver",
+	"Autnum":                  "autnum",
+	"IPNetwork":               "ip network",
+	"Help":                    "help",
+	"Error":                   "error",
+	"DomainSearchResults":     "domain search results",
+	"NameserverSearchResults": "nameserver search results",
+	"EntitySearchResults":     "entity search results",
+}
+
+// MarshalSchema returns the JSON encoding of the RDAP object |obj| (e.g. a
+// *Domain), in the output schema version |version|.
+//
+// The output is a JSON object containing the schema version, the object type,
+// and the object itself:
+//
+//	{
+//	  "schema": "v1",
+//	  "type": "domain",
+//	  "object": {
+//	    "objectClassName": "domain",
+//	    "ldhName": "example.cz",
+//	    ...
+//	  }
+//	}
+func MarshalSchema(obj RDAPObject, version SchemaVersion) ([]byte, error) {
+	fields, ok := schemaFields[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema version %s", version)
+	}
+
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported object type %T", obj)
+	}
+
+	typeName, ok := schemaTypes[v.Elem().Type().Name()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported object type %T", obj)
+	}
+
+	object, err := schemaValue(v, fields, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(struct {
+		Schema string      `json:"schema"`
+		Type   string      `json:"type"`
+		Object interface{} `json:"object"`
+	}{version.String(), typeName, object})
+}
+
+// schemaValue converts |v| to a value for JSON encoding, outputting the struct
+// fields listed in |fields|.
+//
+// If |rawMembers| is set, all struct fields are output instead, plus the
+// decoded members not mapping to a struct field (e.g. extension members), as
+// found in each struct's DecodeData. See MarshalRDAP().
+//
+// Returns nil for empty values, which are omitted from the output.
+func schemaValue(v reflect.Value, fields map[string][]string, rawMembers bool) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		if vcard, ok := v.Interface().(*VCard); ok {
+			jcard, err := vcard.MarshalJSON()
+			return json.RawMessage(jcard), err
+		}
+
+		return schemaValue(v.Elem(), fields, rawMembers)
+	case reflect.Struct:
+		result := map[string]interface{}{}
+		if err := schemaStruct(v, fields, rawMembers, result); err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return nil, nil
+		}
+
+		result := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			// Empty strings are kept in arrays.
+			if v.Index(i).Kind() == reflect.String {
+				result = append(result, v.Index(i).String())
+				continue
+			}
+
+			item, err := schemaValue(v.Index(i), fields, rawMembers)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, item)
+		}
+
+		return result, nil
+	case reflect.String:
+		if v.Len() == 0 {
+			return nil, nil
+		}
+
+		return v.String(), nil
+	case reflect.Bool, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float64:
+		return v.Interface(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported field type %s", v.Type())
+}
+
+// schemaStruct adds the fields of the struct |v| listed in |fields| (or all
+// fields, if |rawMembers| is set) to |result|. Embedded structs (i.e. Common)
+// are flattened.
+func schemaStruct(v reflect.Value, fields map[string][]string, rawMembers bool, result map[string]interface{}) error {
+	allowed := map[string]bool{}
+	for _, name := range fields[v.Type().Name()] {
+		allowed[name] = true
+	}
+
+	isAllowed := func(sf reflect.StructField) bool {
+		return allowed[sf.Name] || (rawMembers && sf.Name != "DecodeData")
+	}
+
+	d := &Decoder{}
+
+	// RDAP names of the struct's fields, output or not.
+	names := map[string]bool{}
+
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			for j := 0; j < sf.Type.NumField(); j++ {
+				if name, ok := d.getFieldName(sf.Type.Field(j)); ok {
+					names[name] = true
+				}
+
+				if isAllowed(sf.Type.Field(j)) {
+					if err := schemaField(d, v.Field(i), j, fields, rawMembers, result); err != nil {
+						return err
+					}
+				}
+			}
+		} else {
+			if name, ok := d.getFieldName(sf); ok {
+				names[name] = true
+			}
+
+			if isAllowed(sf) {
+				if err := schemaField(d, v, i, fields, rawMembers, result); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !rawMembers {
+		return nil
+	}
+
+	f := v.FieldByName("DecodeData")
+	if !f.IsValid() {
+		return nil
+	}
+
+	if decodeData, ok := f.Interface().(*DecodeData); ok && decodeData != nil {
+		for name, value := range decodeData.values {
+			if !names[name] {
+				result[name] = value
+			}
+		}
+	}
+
+	return nil
+}
+
+// schemaField adds field number |i| of the struct |v| to |result|, if not
+// empty.
+func schemaField(d *Decoder, v reflect.Value, i int, fields map[string][]string, rawMembers bool, result map[string]interface{}) error {
+	name, ok := d.getFieldName(v.Type().Field(i))
+	if !ok {
+		return nil
+	}
+
+	value, err := schemaValue(v.Field(i), fields, rawMembers)
+	if err != nil {
+		return err
+	} else if value != nil {
+		result[name] = value
+	}
+
+	return nil
+}
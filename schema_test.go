@@ -0,0 +1,90 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestMarshalSchemaV1(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := MarshalSchema(obj, SchemaV1)
+	if err != nil {
+		t.Fatalf("MarshalSchema() error: %s", err)
+	}
+
+	// The v1 output must never change: update the schema version instead.
+	expected := &bytes.Buffer{}
+	json.Compact(expected, test.LoadFile("schema/domain-example.cz.v1.json"))
+
+	if !bytes.Equal(out, expected.Bytes()) {
+		t.Errorf("Unexpected v1 output:\n%s\nexpected:\n%s", out, expected)
+	}
+}
+
+func TestMarshalSchemaV2NoticeLang(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"objectClassName": "domain",
+		"ldhName": "example.ca",
+		"notices": [{"title": "Avis", "lang": "fr"}]
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range []SchemaVersion{SchemaV1, SchemaV2} {
+		out, err := MarshalSchema(obj, version)
+		if err != nil {
+			t.Fatalf("MarshalSchema(%s) error: %s", version, err)
+		}
+
+		hasLang := bytes.Contains(out, []byte(`"lang":"fr"`))
+		if hasLang != (version == SchemaV2) {
+			t.Errorf("Unexpected %s output: %s", version, out)
+		}
+	}
+}
+
+func TestMarshalSchemaUnsupported(t *testing.T) {
+	if _, err := MarshalSchema(&Domain{}, SchemaVersion(0)); err == nil {
+		t.Errorf("Unexpected success with unknown version")
+	}
+
+	if _, err := MarshalSchema(&VCard{}, SchemaV1); err == nil {
+		t.Errorf("Unexpected success with unsupported type")
+	}
+}
+
+func TestParseSchemaVersion(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Version SchemaVersion
+		IsError bool
+	}{
+		{"v1", SchemaV1, false},
+		{"1", SchemaV1, false},
+		{"v2", SchemaV2, false},
+		{"latest", LatestSchema, false},
+		{"v0", 0, true},
+		{"v999", 0, true},
+		{"x", 0, true},
+	}
+
+	for _, test := range tests {
+		version, err := ParseSchemaVersion(test.Name)
+
+		if (err != nil) != test.IsError || version != test.Version {
+			t.Errorf("ParseSchemaVersion(%q) = %s, %v", test.Name, version, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"fmt"
+)
+
+// SearchDomains runs a domain search on the RDAP server |server| (a base URL,
+// e.g. "https://rdap.nic.cz").
+//
+// |searchType| specifies the search parameter:
+//
+//	rdap.DomainSearchRequest               - domains?name=PATTERN, e.g. "exampl*.cz"
+//	rdap.DomainSearchByNameserverRequest   - domains?nsLdhName=PATTERN, e.g. "ns1.exampl*.cz"
+//	rdap.DomainSearchByNameserverIPRequest - domains?nsIp=PATTERN, e.g. "192.0.2.0"
+//
+// Patterns may contain the "*" wildcard (RFC 9082 section 4.1), if the server
+// supports it. Servers commonly limit the number of search results returned.
+//
+// The timeout is 30s, unless |ctx| has a deadline.
+func (c *Client) SearchDomains(ctx context.Context, server string, searchType RequestType, pattern string) (*DomainSearchResults, error) {
+	switch searchType {
+	case DomainSearchRequest, DomainSearchByNameserverRequest, DomainSearchByNameserverIPRequest:
+	default:
+		return nil, searchTypeError(searchType, "domain")
+	}
+
+	resp, err := c.search(ctx, server, NewRequest(searchType, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	if results, ok := resp.Object.(*DomainSearchResults); ok {
+		return results, nil
+	}
+
+	return nil, &ClientError{
+		Type: WrongResponseType,
+		Text: "The server returned a non-DomainSearchResults RDAP response",
+	}
+}
+
+// SearchNameservers runs a nameserver search on the RDAP server |server| (a
+// base URL, e.g. "https://rdap.nic.cz").
+//
+// |searchType| specifies the search parameter:
+//
+//	rdap.NameserverSearchRequest               - nameservers?name=PATTERN, e.g. "ns*.example.cz"
+//	rdap.NameserverSearchByNameserverIPRequest - nameservers?ip=PATTERN, e.g. "192.0.2.0"
+//
+// See SearchDomains() for wildcard patterns, and the timeout.
+func (c *Client) SearchNameservers(ctx context.Context, server string, searchType RequestType, pattern string) (*NameserverSearchResults, error) {
+	switch searchType {
+	case NameserverSearchRequest, NameserverSearchByNameserverIPRequest:
+	default:
+		return nil, searchTypeError(searchType, "nameserver")
+	}
+
+	resp, err := c.search(ctx, server, NewRequest(searchType, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	if results, ok := resp.Object.(*NameserverSearchResults); ok {
+		return results, nil
+	}
+
+	return nil, &ClientError{
+		Type: WrongResponseType,
+		Text: "The server returned a non-NameserverSearchResults RDAP response",
+	}
+}
+
+// SearchEntities runs an entity search on the RDAP server |server| (a base
+// URL, e.g. "https://rdap.arin.net/registry").
+//
+// |searchType| specifies the search parameter:
+//
+//	rdap.EntitySearchRequest         - entities?fn=PATTERN, e.g. "Example Org*"
+//	rdap.EntitySearchByHandleRequest - entities?handle=PATTERN, e.g. "ABC*-ARIN"
+//
+// See SearchDomains() for wildcard patterns, and the timeout.
+func (c *Client) SearchEntities(ctx context.Context, server string, searchType RequestType, pattern string) (*EntitySearchResults, error) {
+	switch searchType {
+	case EntitySearchRequest, EntitySearchByHandleRequest:
+	default:
+		return nil, searchTypeError(searchType, "entity")
+	}
+
+	resp, err := c.search(ctx, server, NewRequest(searchType, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	if results, ok := resp.Object.(*EntitySearchResults); ok {
+		return results, nil
+	}
+
+	return nil, &ClientError{
+		Type: WrongResponseType,
+		Text: "The server returned a non-EntitySearchResults RDAP response",
+	}
+}
+
+// search runs the search Request |req| on the RDAP server |server|.
+func (c *Client) search(ctx context.Context, server string, req *Request) (*Response, error) {
+	u, err := parseServerURL(server)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doQuickRequest(ctx, req.WithServer(u))
+	if err != nil {
+		return nil, err
+	}
+
+	if respError, ok := resp.Object.(*Error); ok {
+		return nil, clientErrorFromRDAPError(respError)
+	}
+
+	return resp, nil
+}
+
+func searchTypeError(searchType RequestType, object string) error {
+	return &ClientError{
+		Type: InputError,
+		Text: fmt.Sprintf("'%s' is not a %s search", searchType, object),
+	}
+}
@@ -42,3 +42,117 @@ type EntitySearchResults struct {
 
 	Entities []Entity `rdap:"entitySearchResults"`
 }
+
+// Results returns the search results, each decoded according to its
+// objectClassName. Results of the expected class (or without an
+// objectClassName) are the elements of Domains. Results of other classes (e.g.
+// a nameserver returned by a non-conforming server), which Domains holds as
+// mis-decoded domains, are decoded as their own class.
+//
+// Use the RDAPObjects methods to select results of one class.
+func (r *DomainSearchResults) Results() RDAPObjects {
+	return searchResults(r.DecodeData, "domainSearchResults", "domain", r.Conformance, len(r.Domains), func(i int) RDAPObject {
+		return &r.Domains[i]
+	})
+}
+
+// Results returns the search results, each decoded according to its
+// objectClassName. See DomainSearchResults.Results().
+func (r *NameserverSearchResults) Results() RDAPObjects {
+	return searchResults(r.DecodeData, "nameserverSearchResults", "nameserver", r.Conformance, len(r.Nameservers), func(i int) RDAPObject {
+		return &r.Nameservers[i]
+	})
+}
+
+// Results returns the search results, each decoded according to its
+// objectClassName. See DomainSearchResults.Results().
+func (r *EntitySearchResults) Results() RDAPObjects {
+	return searchResults(r.DecodeData, "entitySearchResults", "entity", r.Conformance, len(r.Entities), func(i int) RDAPObject {
+		return &r.Entities[i]
+	})
+}
+
+// searchResults returns the search results in the array |member|, decoding
+// those not of the class |class| by their objectClassName. |decoded| returns
+// the |i|th of the |n| results decoded as |class|.
+func searchResults(decodeData *DecodeData, member string, class string, conformance []string, n int, decoded func(i int) RDAPObject) RDAPObjects {
+	var raw []interface{}
+	if decodeData != nil {
+		raw, _ = decodeData.Value(member).([]interface{})
+	}
+
+	if raw == nil {
+		// Manually constructed, or the raw results are unavailable.
+		results := make(RDAPObjects, n)
+		for i := range results {
+			results[i] = decoded(i)
+		}
+
+		return results
+	}
+
+	var results RDAPObjects
+
+	// Non-object elements aren't decoded, so |i| indexes only the objects.
+	i := 0
+	for _, r := range raw {
+		src, ok := r.(map[string]interface{})
+		if !ok || i >= n {
+			continue
+		}
+
+		name, _ := src["objectClassName"].(string)
+		if name != "" && name != class {
+			if obj := decodeObjectClass(src, conformance); obj != nil {
+				results = append(results, obj)
+				i++
+				continue
+			}
+		}
+
+		results = append(results, decoded(i))
+		i++
+	}
+
+	return results
+}
+
+// RDAPObjects is a list of RDAP objects of mixed classes, e.g. search results
+// (see DomainSearchResults.Results()).
+type RDAPObjects []RDAPObject
+
+// Domains returns the domains in the list.
+func (o RDAPObjects) Domains() []*Domain {
+	return objectsOfType[*Domain](o)
+}
+
+// Nameservers returns the nameservers in the list.
+func (o RDAPObjects) Nameservers() []*Nameserver {
+	return objectsOfType[*Nameserver](o)
+}
+
+// Entities returns the entities in the list.
+func (o RDAPObjects) Entities() []*Entity {
+	return objectsOfType[*Entity](o)
+}
+
+// IPNetworks returns the IP networks in the list.
+func (o RDAPObjects) IPNetworks() []*IPNetwork {
+	return objectsOfType[*IPNetwork](o)
+}
+
+// Autnums returns the autnums in the list.
+func (o RDAPObjects) Autnums() []*Autnum {
+	return objectsOfType[*Autnum](o)
+}
+
+func objectsOfType[T RDAPObject](objects RDAPObjects) []T {
+	var result []T
+	for _, obj := range objects {
+		if v, ok := obj.(T); ok {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,215 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// searchServer returns a test RDAP server, which responds with |body|. The
+// last request URL is saved in |requestURL|.
+func searchServer(body string, requestURL **url.URL) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requestURL = r.URL
+
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestClientSearchDomains(t *testing.T) {
+	var requestURL *url.URL
+	server := searchServer(`{
+		"rdapConformance": ["rdap_level_0"],
+		"domainSearchResults": [
+			{"objectClassName": "domain", "ldhName": "example.cz"},
+			{"objectClassName": "domain", "ldhName": "example2.cz"}
+		]
+	}`, &requestURL)
+	defer server.Close()
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	tests := []struct {
+		SearchType RequestType
+		Pattern    string
+		Path       string
+		Param      string
+	}{
+		{DomainSearchRequest, "exampl*.cz", "/domains", "name"},
+		{DomainSearchByNameserverRequest, "ns1.exampl*.cz", "/domains", "nsLdhName"},
+		{DomainSearchByNameserverIPRequest, "192.0.2.0", "/domains", "nsIp"},
+	}
+
+	for _, test := range tests {
+		results, err := client.SearchDomains(context.Background(), server.URL, test.SearchType, test.Pattern)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.SearchType, err)
+			continue
+		}
+
+		if len(results.Domains) != 2 || results.Domains[1].LDHName != "example2.cz" {
+			t.Errorf("%s: unexpected results %+v", test.SearchType, results.Domains)
+		}
+
+		if requestURL.Path != test.Path || requestURL.Query().Get(test.Param) != test.Pattern {
+			t.Errorf("%s: unexpected request URL %s", test.SearchType, requestURL)
+		}
+	}
+
+	if _, err := client.SearchDomains(context.Background(), server.URL, NameserverSearchRequest, "ns1.*"); !isClientError(InputError, err) {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if _, err := client.SearchDomains(context.Background(), "", DomainSearchRequest, "exampl*.cz"); !isClientError(InputError, err) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestClientSearchNameservers(t *testing.T) {
+	var requestURL *url.URL
+	server := searchServer(`{
+		"rdapConformance": ["rdap_level_0"],
+		"nameserverSearchResults": [
+			{"objectClassName": "nameserver", "ldhName": "ns1.example.cz"}
+		]
+	}`, &requestURL)
+	defer server.Close()
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	tests := []struct {
+		SearchType RequestType
+		Pattern    string
+		Param      string
+	}{
+		{NameserverSearchRequest, "ns*.example.cz", "name"},
+		{NameserverSearchByNameserverIPRequest, "2001:db8::1", "ip"},
+	}
+
+	for _, test := range tests {
+		results, err := client.SearchNameservers(context.Background(), server.URL+"/rdap", test.SearchType, test.Pattern)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.SearchType, err)
+			continue
+		}
+
+		if len(results.Nameservers) != 1 || results.Nameservers[0].LDHName != "ns1.example.cz" {
+			t.Errorf("%s: unexpected results %+v", test.SearchType, results.Nameservers)
+		}
+
+		if requestURL.Path != "/rdap/nameservers" || requestURL.Query().Get(test.Param) != test.Pattern {
+			t.Errorf("%s: unexpected request URL %s", test.SearchType, requestURL)
+		}
+	}
+
+	if _, err := client.SearchNameservers(context.Background(), server.URL, DomainSearchRequest, "ns1.*"); !isClientError(InputError, err) {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	// Wrong response type.
+	if _, err := client.SearchDomains(context.Background(), server.URL, DomainSearchRequest, "ns1.*"); !isClientError(WrongResponseType, err) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestClientSearchEntities(t *testing.T) {
+	var requestURL *url.URL
+	server := searchServer(`{
+		"rdapConformance": ["rdap_level_0"],
+		"entitySearchResults": [
+			{"objectClassName": "entity", "handle": "ABC123-ARIN", "vcardArray": ["vcard", [
+				["version", {}, "text", "4.0"],
+				["fn", {}, "text", "Example Org"]
+			]]},
+			{"objectClassName": "entity", "handle": "ABC124-ARIN"}
+		]
+	}`, &requestURL)
+	defer server.Close()
+
+	client := &Client{
+		Verbose: verboseFunc(),
+	}
+
+	tests := []struct {
+		SearchType RequestType
+		Pattern    string
+		Param      string
+	}{
+		{EntitySearchRequest, "Example Org*", "fn"},
+		{EntitySearchByHandleRequest, "ABC*-ARIN", "handle"},
+	}
+
+	for _, test := range tests {
+		results, err := client.SearchEntities(context.Background(), server.URL, test.SearchType, test.Pattern)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.SearchType, err)
+			continue
+		}
+
+		if len(results.Entities) != 2 || results.Entities[0].VCard == nil || results.Entities[0].VCard.Name() != "Example Org" {
+			t.Errorf("%s: unexpected results %+v", test.SearchType, results.Entities)
+		}
+
+		if requestURL.Path != "/entities" || requestURL.Query().Get(test.Param) != test.Pattern {
+			t.Errorf("%s: unexpected request URL %s", test.SearchType, requestURL)
+		}
+	}
+
+	if _, err := client.SearchEntities(context.Background(), server.URL, EntityRequest, "ABC123-ARIN"); !isClientError(InputError, err) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestSearchResultsMixedClasses(t *testing.T) {
+	obj, err := NewDecoder([]byte(`{
+		"rdapConformance": ["rdap_level_0"],
+		"domainSearchResults": [
+			{"objectClassName": "domain", "ldhName": "a.example"},
+			"not an object",
+			{"objectClassName": "nameserver", "ldhName": "ns1.a.example", "ipAddresses": {"v4": ["192.0.2.1"]}},
+			{"ldhName": "b.example"},
+			{"objectClassName": "unknown", "ldhName": "c.example"}
+		]
+	}`)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sr := obj.(*DomainSearchResults)
+	results := sr.Results()
+
+	if len(results) != 4 {
+		t.Fatalf("Unexpected results %#v", results)
+	}
+
+	domains := results.Domains()
+	if len(domains) != 3 || domains[0] != &sr.Domains[0] || domains[1].LDHName != "b.example" || domains[2].LDHName != "c.example" {
+		t.Errorf("Unexpected domains %v", domains)
+	}
+
+	nameservers := results.Nameservers()
+	if len(nameservers) != 1 || nameservers[0].LDHName != "ns1.a.example" ||
+		nameservers[0].IPAddresses == nil || nameservers[0].IPAddresses.V4[0] != "192.0.2.1" {
+		t.Errorf("Unexpected nameservers %v", nameservers)
+	}
+
+	if results.Entities() != nil || results.IPNetworks() != nil || results.Autnums() != nil {
+		t.Errorf("Unexpected results of other classes")
+	}
+
+	manual := &EntitySearchResults{Entities: []Entity{{Handle: "A"}, {Handle: "B"}}}
+	if entities := manual.Results().Entities(); len(entities) != 2 || entities[1].Handle != "B" {
+		t.Errorf("Unexpected entities %v", entities)
+	}
+}
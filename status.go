@@ -0,0 +1,188 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import "strings"
+
+// Status is an RDAP status value, e.g. "client transfer prohibited".
+type Status string
+
+// RDAP status values, from the IANA "RDAP JSON Values" registry (RFC 9083
+// section 10.2.2, and the EPP status mapping of RFC 8056).
+//
+// https://www.iana.org/assignments/rdap-json-values/
+const (
+	StatusValidated                Status = "validated"
+	StatusRenewProhibited          Status = "renew prohibited"
+	StatusUpdateProhibited         Status = "update prohibited"
+	StatusTransferProhibited       Status = "transfer prohibited"
+	StatusDeleteProhibited         Status = "delete prohibited"
+	StatusProxy                    Status = "proxy"
+	StatusPrivate                  Status = "private"
+	StatusRemoved                  Status = "removed"
+	StatusObscured                 Status = "obscured"
+	StatusAssociated               Status = "associated"
+	StatusActive                   Status = "active"
+	StatusInactive                 Status = "inactive"
+	StatusLocked                   Status = "locked"
+	StatusPendingCreate            Status = "pending create"
+	StatusPendingRenew             Status = "pending renew"
+	StatusPendingTransfer          Status = "pending transfer"
+	StatusPendingUpdate            Status = "pending update"
+	StatusPendingDelete            Status = "pending delete"
+	StatusAddPeriod                Status = "add period"
+	StatusAutoRenewPeriod          Status = "auto renew period"
+	StatusClientDeleteProhibited   Status = "client delete prohibited"
+	StatusClientHold               Status = "client hold"
+	StatusClientRenewProhibited    Status = "client renew prohibited"
+	StatusClientTransferProhibited Status = "client transfer prohibited"
+	StatusClientUpdateProhibited   Status = "client update prohibited"
+	StatusPendingRestore           Status = "pending restore"
+	StatusRedemptionPeriod         Status = "redemption period"
+	StatusRenewPeriod              Status = "renew period"
+	StatusServerDeleteProhibited   Status = "server delete prohibited"
+	StatusServerRenewProhibited    Status = "server renew prohibited"
+	StatusServerTransferProhibited Status = "server transfer prohibited"
+	StatusServerUpdateProhibited   Status = "server update prohibited"
+	StatusServerHold               Status = "server hold"
+	StatusTransferPeriod           Status = "transfer period"
+)
+
+// eppStatuses maps RDAP status values to EPP status codes (RFC 8056 section
+// 2, including the RGP statuses of RFC 3915).
+var eppStatuses = map[Status]string{
+	StatusAddPeriod:                "addPeriod",
+	StatusAutoRenewPeriod:          "autoRenewPeriod",
+	StatusInactive:                 "inactive",
+	StatusActive:                   "ok",
+	StatusPendingCreate:            "pendingCreate",
+	StatusPendingDelete:            "pendingDelete",
+	StatusPendingRenew:             "pendingRenew",
+	StatusPendingRestore:           "pendingRestore",
+	StatusPendingTransfer:          "pendingTransfer",
+	StatusPendingUpdate:            "pendingUpdate",
+	StatusRedemptionPeriod:         "redemptionPeriod",
+	StatusRenewPeriod:              "renewPeriod",
+	StatusServerDeleteProhibited:   "serverDeleteProhibited",
+	StatusServerRenewProhibited:    "serverRenewProhibited",
+	StatusServerTransferProhibited: "serverTransferProhibited",
+	StatusServerUpdateProhibited:   "serverUpdateProhibited",
+	StatusServerHold:               "serverHold",
+	StatusTransferPeriod:           "transferPeriod",
+	StatusClientDeleteProhibited:   "clientDeleteProhibited",
+	StatusClientHold:               "clientHold",
+	StatusClientRenewProhibited:    "clientRenewProhibited",
+	StatusClientTransferProhibited: "clientTransferProhibited",
+	StatusClientUpdateProhibited:   "clientUpdateProhibited",
+	StatusAssociated:               "linked",
+}
+
+// IsRegistered returns true if the status is in the IANA registry (see
+// DefaultJSONValues()). The comparison is case insensitive.
+func (s Status) IsRegistered() bool {
+	return DefaultJSONValues().Has(JSONValueStatus, string(s))
+}
+
+// EPP returns the status's EPP status code, e.g. "clientTransferProhibited"
+// for "client transfer prohibited". Returns false if the status has no EPP
+// equivalent (e.g. "locked").
+func (s Status) EPP() (string, bool) {
+	code, ok := eppStatuses[s]
+	return code, ok
+}
+
+// StatusFromEPP returns the RDAP status value of the EPP status code |code|,
+// e.g. "client transfer prohibited" for "clientTransferProhibited". The
+// comparison is case insensitive.
+//
+// Returns false if |code| isn't a known EPP status code.
+func StatusFromEPP(code string) (Status, bool) {
+	for s, c := range eppStatuses {
+		if strings.EqualFold(c, code) {
+			return s, true
+		}
+	}
+
+	return "", false
+}
+
+// Statuses is a list of RDAP status values, e.g. of a Domain.
+type Statuses []Status
+
+// ParseStatuses returns the status values |values| (e.g. Domain.Status) as
+// Statuses.
+//
+// EPP status codes, which some servers return instead of RDAP status values,
+// are converted, e.g. "clientHold" becomes "client hold". Other values are
+// lowercased.
+func ParseStatuses(values []string) Statuses {
+	var result Statuses
+
+	for _, v := range values {
+		if s, ok := StatusFromEPP(v); ok {
+			result = append(result, s)
+		} else {
+			result = append(result, Status(strings.ToLower(v)))
+		}
+	}
+
+	return result
+}
+
+// Has returns true if the list contains any of the statuses |statuses|.
+func (s Statuses) Has(statuses ...Status) bool {
+	for _, have := range s {
+		for _, want := range statuses {
+			if have == want {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// EPP returns the EPP status codes of the statuses, skipping statuses without
+// an EPP equivalent.
+func (s Statuses) EPP() []string {
+	var result []string
+
+	for _, status := range s {
+		if code, ok := status.EPP(); ok {
+			result = append(result, code)
+		}
+	}
+
+	return result
+}
+
+// IsLocked returns true if the object is locked against transfer: its status
+// includes "locked", or a (client/server) transfer prohibited status.
+func (s Statuses) IsLocked() bool {
+	return s.Has(StatusLocked, StatusTransferProhibited, StatusClientTransferProhibited, StatusServerTransferProhibited)
+}
+
+// IsOnHold returns true if the object's status includes "client hold" or
+// "server hold", i.e. a domain which isn't published in the DNS.
+func (s Statuses) IsOnHold() bool {
+	return s.Has(StatusClientHold, StatusServerHold)
+}
+
+// IsPendingDelete returns true if the object's status includes "pending
+// delete".
+func (s Statuses) IsPendingDelete() bool {
+	return s.Has(StatusPendingDelete)
+}
+
+// IsInRedemption returns true if the domain is in the redemption grace period
+// (RFC 3915): its status includes "redemption period" or "pending restore".
+func (s Statuses) IsInRedemption() bool {
+	return s.Has(StatusRedemptionPeriod, StatusPendingRestore)
+}
+
+// Statuses returns the domain's status values. See ParseStatuses().
+func (d *Domain) Statuses() Statuses {
+	return ParseStatuses(d.Status)
+}
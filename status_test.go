@@ -0,0 +1,58 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStatusEPP(t *testing.T) {
+	if code, ok := StatusClientTransferProhibited.EPP(); !ok || code != "clientTransferProhibited" {
+		t.Errorf("Unexpected EPP code %q", code)
+	}
+
+	if code, ok := StatusActive.EPP(); !ok || code != "ok" {
+		t.Errorf("Unexpected EPP code %q", code)
+	}
+
+	if _, ok := StatusLocked.EPP(); ok {
+		t.Errorf("Unexpected EPP code for locked")
+	}
+
+	for s := range eppStatuses {
+		code, _ := s.EPP()
+		if back, ok := StatusFromEPP(code); !ok || back != s || !s.IsRegistered() {
+			t.Errorf("Status %q round trip via %q got %q", s, code, back)
+		}
+	}
+
+	if s, ok := StatusFromEPP("SERVERHOLD"); !ok || s != StatusServerHold {
+		t.Errorf("Unexpected status %q", s)
+	}
+
+	if _, ok := StatusFromEPP("client hold"); ok {
+		t.Errorf("Unexpected EPP match for RDAP value")
+	}
+}
+
+func TestDomainStatuses(t *testing.T) {
+	d := &Domain{Status: []string{"Client Transfer Prohibited", "pendingDelete", "ok", "example"}}
+
+	statuses := d.Statuses()
+
+	expected := Statuses{StatusClientTransferProhibited, StatusPendingDelete, StatusActive, "example"}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Fatalf("Got %v, expected %v", statuses, expected)
+	}
+
+	if !statuses.IsLocked() || !statuses.IsPendingDelete() || statuses.IsOnHold() || statuses.IsInRedemption() {
+		t.Errorf("Unexpected predicates for %v", statuses)
+	}
+
+	if epp := statuses.EPP(); !reflect.DeepEqual(epp, []string{"clientTransferProhibited", "pendingDelete", "ok"}) {
+		t.Errorf("Unexpected EPP codes %v", epp)
+	}
+}
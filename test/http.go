@@ -101,9 +101,15 @@ func loadTestDatasets() {
 
 	// RDAP responses.
 	load(Responses, 200, "https://rdap.nic.cz/domain/example.cz", "rdap/rdap.nic.cz/domain-example.cz.json")
+	load(Responses, 200, "https://rdap.nic.cz/help", "rdap/rdap.nic.cz/help.json")
 	load(Responses, 404, "https://rdap.nic.cz/domain/non-existent.cz", "misc/empty.html")
+	load(Responses, 404, "https://rdap.nic.cz/domain/deleted.cz", "rdap/rdap.nic.cz/error-404.json")
+	load(Responses, 400, "https://rdap.nic.cz/domain/bad-request.cz", "rdap/rdap.nic.cz/error-400.json")
 	load(Responses, 200, "https://rdap.nic.cz/domain/wrong-response-type.cz", "rdap/rdap.nic.cz/nameserver-ns2.pipni.cz.json")
 	load(Responses, 200, "https://rdap.nic.cz/domain/malformed.cz", "misc/malformed.json")
+	load(Responses, 200, "https://rdap.nic.cz/domain/thin.cz", "rdap/rdap.nic.cz/domain-thin.cz.json")
+	load(Responses, 200, "https://rdap.registrar.example/domain/thin.cz", "rdap/rdap.registrar.example/domain-thin.cz.json")
+	load(Responses, 200, "https://rdap.arin.net/registry/entity/ABC123-ARIN", "rdap/rdap.arin.net/entity-ABC123-ARIN.json")
 }
 
 func load(set TestDataset, status int, url string, filename string) {
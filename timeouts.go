@@ -0,0 +1,209 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Timeouts specifies timeouts for each phase of a query, in addition to the
+// overall timeout (Request.Timeout, or the Request's context).
+//
+// This stops a slow phase (e.g. a bootstrap download) consuming the whole
+// query's time budget. Zero values mean no phase timeout.
+type Timeouts struct {
+	// Bootstrap lookup, including any bootstrap file download.
+	Bootstrap time.Duration
+
+	// Each connection to an RDAP server: TCP connect and TLS handshake.
+	//
+	// Applies when the Client's HTTP client (or Transport) uses an
+	// *http.Transport, which is the default.
+	Connect time.Duration
+
+	// Reading each RDAP server's response body, after its headers are
+	// received.
+	Body time.Duration
+}
+
+// DeadlineBudget divides a query's deadline (Request.Timeout, or the
+// Request's context deadline) between the phases of the query, so one slow
+// phase can't use the whole deadline.
+//
+// For example, with a 10s deadline and the DefaultDeadlineBudget(), the
+// bootstrap lookup may take up to 2.5s, and the first HTTP request (including
+// its redirects) up to half of the remaining time. A retry, a request to the
+// next RDAP server, or a redirect is only started if at least MinRemaining is
+// left.
+//
+// Queries without a deadline aren't limited.
+type DeadlineBudget struct {
+	// Maximum share of the remaining time for the bootstrap lookup, between 0
+	// and 1. 0 means no limit.
+	Bootstrap float64
+
+	// Maximum share of the remaining time for each HTTP request (each attempt
+	// on each RDAP server, including redirects), between 0 and 1. 0 means no
+	// limit.
+	Attempt float64
+
+	// Minimum remaining time needed to start an HTTP request, retry, or
+	// redirect. Otherwise, they fail with ErrDeadlineBudgetExhausted.
+	MinRemaining time.Duration
+}
+
+// ErrDeadlineBudgetExhausted is the error for HTTP requests (and redirects) not
+// made because too little of the query's deadline remains. See
+// DeadlineBudget.
+var ErrDeadlineBudgetExhausted = errors.New("deadline budget exhausted")
+
+// DefaultDeadlineBudget returns a DeadlineBudget giving the bootstrap lookup up
+// to a quarter of the deadline, and each HTTP request up to half of the
+// remaining time. Requests aren't started with less than 500ms left.
+func DefaultDeadlineBudget() *DeadlineBudget {
+	return &DeadlineBudget{
+		Bootstrap:    0.25,
+		Attempt:      0.5,
+		MinRemaining: 500 * time.Millisecond,
+	}
+}
+
+// remaining returns the time left until |ctx|'s deadline, and false if it has
+// no deadline (or |b| is nil).
+func (b *DeadlineBudget) remaining(ctx context.Context) (time.Duration, bool) {
+	if b == nil {
+		return 0, false
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+
+	return time.Until(deadline), true
+}
+
+// share returns |fraction| of the time left until |ctx|'s deadline, or 0 (no
+// limit).
+func (b *DeadlineBudget) share(ctx context.Context, fraction float64) time.Duration {
+	remaining, ok := b.remaining(ctx)
+	if !ok || fraction <= 0 || fraction >= 1 {
+		return 0
+	}
+
+	if share := time.Duration(float64(remaining) * fraction); share > 0 {
+		return share
+	}
+
+	// Expire immediately.
+	return time.Nanosecond
+}
+
+// exhausted returns true if less than MinRemaining is left until |ctx|'s
+// deadline, after waiting |delay|.
+func (b *DeadlineBudget) exhausted(ctx context.Context, delay time.Duration) bool {
+	remaining, ok := b.remaining(ctx)
+
+	return ok && remaining-delay < b.MinRemaining
+}
+
+// bootstrapTimeout returns the bootstrap phase timeout for the query context
+// |ctx|: the Bootstrap timeout, or the budget's share (whichever is shorter).
+func (c *Client) bootstrapTimeout(ctx context.Context) time.Duration {
+	timeout := c.Timeouts.Bootstrap
+
+	if c.DeadlineBudget != nil {
+		if share := c.DeadlineBudget.share(ctx, c.DeadlineBudget.Bootstrap); share > 0 && (timeout <= 0 || share < timeout) {
+			timeout = share
+		}
+	}
+
+	return timeout
+}
+
+// attemptTimeout returns the timeout for an HTTP request, as per the
+// DeadlineBudget (0 if none).
+func (c *Client) attemptTimeout(ctx context.Context) time.Duration {
+	if c.DeadlineBudget == nil {
+		return 0
+	}
+
+	return c.DeadlineBudget.share(ctx, c.DeadlineBudget.Attempt)
+}
+
+// PhaseTimeoutError is returned when a query phase exceeds its timeout (see
+// Timeouts).
+type PhaseTimeoutError struct {
+	// Phase which timed out: "bootstrap", "attempt" (see DeadlineBudget), or
+	// "body".
+	Phase string
+
+	// The phase's timeout.
+	Limit time.Duration
+}
+
+func (e *PhaseTimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.Phase, e.Limit)
+}
+
+// Timeout returns true, for callers checking for timeout errors.
+func (e *PhaseTimeoutError) Timeout() bool {
+	return true
+}
+
+// withPhaseTimeout returns |ctx| with the timeout |timeout| (if non-zero), and
+// a function returning a *PhaseTimeoutError for |phase| if the timeout (and not
+// |ctx| itself) expired.
+func withPhaseTimeout(ctx context.Context, phase string, timeout time.Duration) (context.Context, context.CancelFunc, func() error) {
+	if timeout <= 0 {
+		return ctx, func() {}, func() error { return nil }
+	}
+
+	phaseCtx, cancel := context.WithTimeout(ctx, timeout)
+
+	expired := func() error {
+		if phaseCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return &PhaseTimeoutError{Phase: phase, Limit: timeout}
+		}
+
+		return nil
+	}
+
+	return phaseCtx, cancel, expired
+}
+
+// dialWithTimeout returns the http.Transport DialContext function |dial| (nil
+// meaning the default dialer), limited to |timeout|.
+func dialWithTimeout(dial func(ctx context.Context, network, addr string) (net.Conn, error), timeout time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dial == nil {
+		dialer := &net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+		}
+		dial = dialer.DialContext
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return dial(ctx, network, addr)
+	}
+}
+
+// applyConnectTimeout applies the Connect timeout to |transport|.
+func (t Timeouts) applyConnectTimeout(transport *http.Transport) {
+	if t.Connect <= 0 {
+		return
+	}
+
+	transport.DialContext = dialWithTimeout(transport.DialContext, t.Connect)
+	transport.TLSHandshakeTimeout = t.Connect
+}
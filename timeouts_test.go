@@ -0,0 +1,167 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openrdap/rdap/bootstrap"
+	"github.com/openrdap/rdap/test"
+)
+
+func TestTimeoutsBootstrap(t *testing.T) {
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	// The bootstrap download hangs, so the embedded bootstrap snapshot is
+	// used instead.
+	client := &Client{
+		Bootstrap: &bootstrap.Client{
+			HTTP:     &http.Client{Transport: blockingTransport{}},
+			Fallback: true,
+		},
+		Timeouts: Timeouts{Bootstrap: 50 * time.Millisecond},
+		Verbose:  verboseFunc(),
+	}
+
+	start := time.Now()
+	if _, err := client.QueryDomain("example.cz"); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	} else if time.Since(start) > 5*time.Second {
+		t.Errorf("Bootstrap timeout not applied")
+	}
+
+	// Bootstrap lookup timed out.
+	ctx, cancel, expired := withPhaseTimeout(context.Background(), "bootstrap", time.Millisecond)
+	defer cancel()
+
+	<-ctx.Done()
+
+	var timeoutErr *PhaseTimeoutError
+	if !errors.As(expired(), &timeoutErr) || timeoutErr.Phase != "bootstrap" {
+		t.Errorf("Unexpected error %v", expired())
+	}
+}
+
+func TestTimeoutsBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"objectClassName":`))
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{
+		Timeouts: Timeouts{Body: 50 * time.Millisecond},
+		Verbose:  verboseFunc(),
+	}
+
+	serverURL, _ := url.Parse(server.URL)
+	resp, err := client.Do(NewDomainRequest("example.cz").WithServer(serverURL))
+
+	if !isClientError(NoWorkingServers, err) {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	var timeoutErr *PhaseTimeoutError
+	if !errors.As(resp.HTTP[0].Error, &timeoutErr) || timeoutErr.Phase != "body" {
+		t.Errorf("Unexpected HTTP error %v", resp.HTTP[0].Error)
+	}
+}
+
+func TestTimeoutsConnect(t *testing.T) {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	Timeouts{Connect: 50 * time.Millisecond}.applyConnectTimeout(transport)
+
+	if transport.TLSHandshakeTimeout != 50*time.Millisecond {
+		t.Errorf("Unexpected TLSHandshakeTimeout %s", transport.TLSHandshakeTimeout)
+	}
+
+	start := time.Now()
+	if _, err := transport.DialContext(context.Background(), "tcp", "192.0.2.1:443"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error %v", err)
+	} else if time.Since(start) > time.Second {
+		t.Errorf("Connect timeout not applied")
+	}
+}
+
+func TestDeadlineBudget(t *testing.T) {
+	// The first request hangs, the retry succeeds.
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3},
+		DeadlineBudget: &DeadlineBudget{
+			Attempt:      0.5,
+			MinRemaining: 100 * time.Millisecond,
+		},
+		Verbose: verboseFunc(),
+	}
+
+	serverURL, _ := url.Parse(server.URL)
+	req := NewDomainRequest("example.cz").WithServer(serverURL)
+	req.Timeout = time.Second
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	var timeoutErr *PhaseTimeoutError
+	if len(resp.HTTP) != 1 || resp.HTTP[0].Attempts != 2 {
+		t.Errorf("Unexpected HTTP responses %+v", resp.HTTP)
+	}
+
+	// Too little time left to retry.
+	atomic.StoreInt32(&requests, 0)
+	client.DeadlineBudget.MinRemaining = 600 * time.Millisecond
+
+	resp, err = client.Do(req)
+	if err == nil {
+		t.Fatalf("Unexpected success")
+	} else if !errors.As(resp.HTTP[0].Error, &timeoutErr) || timeoutErr.Phase != "attempt" || resp.HTTP[0].Attempts != 1 {
+		t.Errorf("Unexpected HTTP response %+v", resp.HTTP[0])
+	}
+
+	// No time left to start a request.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	resp, _ = client.Do(req.WithContext(ctx))
+	if !errors.Is(resp.HTTP[0].Error, ErrDeadlineBudgetExhausted) {
+		t.Errorf("Unexpected HTTP error %v", resp.HTTP[0].Error)
+	}
+}
@@ -0,0 +1,284 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TermsOfService describes an RDAP server's terms of service, as seen in its
+// responses.
+//
+// RDAP servers publish their terms of service as notices (RFC 9083 section
+// 4.3), typically titled "Terms of Service" or "Terms of Use", often with a
+// link to the full text.
+type TermsOfService struct {
+	// RDAP server base URL.
+	BaseURL string
+
+	// Terms of service notices.
+	Notices []Notice
+
+	// When the terms of service were first seen, and last seen.
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	// When the terms of service were acknowledged. Zero if not acknowledged.
+	//
+	// Acknowledgments are reset when the terms of service change.
+	Acknowledged time.Time
+}
+
+// URL returns the link to the full terms of service text, or "" if none.
+func (t *TermsOfService) URL() string {
+	var fallback string
+
+	for _, n := range t.Notices {
+		for _, l := range n.Links {
+			if strings.EqualFold(l.Rel, "terms-of-service") {
+				return l.Href
+			}
+
+			if fallback == "" {
+				fallback = l.Href
+			}
+		}
+	}
+
+	return fallback
+}
+
+// String returns the terms of service as text.
+func (t *TermsOfService) String() string {
+	var b strings.Builder
+
+	for _, n := range t.Notices {
+		if n.Title != "" {
+			fmt.Fprintf(&b, "%s\n", n.Title)
+		}
+
+		for _, d := range n.Description {
+			fmt.Fprintf(&b, "%s\n", d)
+		}
+
+		for _, l := range n.Links {
+			if l.Href != "" {
+				fmt.Fprintf(&b, "%s\n", l.Href)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// fingerprint returns a string identifying the terms of service text, for
+// change detection.
+func (t *TermsOfService) fingerprint() string {
+	return t.String()
+}
+
+func (t *TermsOfService) clone() *TermsOfService {
+	copy := *t
+	copy.Notices = append([]Notice(nil), t.Notices...)
+
+	return &copy
+}
+
+// isTermsOfServiceNotice returns true if the Notice |n| looks like a terms of
+// service notice.
+func isTermsOfServiceNotice(n Notice) bool {
+	title := strings.ToLower(n.Title)
+
+	for _, keyword := range []string{"terms", "conditions", "acceptable use", "disclaimer"} {
+		if strings.Contains(title, keyword) {
+			return true
+		}
+	}
+
+	for _, l := range n.Links {
+		if strings.EqualFold(l.Rel, "terms-of-service") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// noticesOf returns the Notices of the topmost RDAP object |obj|.
+func noticesOf(obj RDAPObject) []Notice {
+	switch v := obj.(type) {
+	case *Domain:
+		return v.Notices
+	case *Entity:
+		return v.Notices
+	case *Nameserver:
+		return v.Notices
+	case *Autnum:
+		return v.Notices
+	case *IPNetwork:
+		return v.Notices
+	case *Help:
+		return v.Notices
+	case *Error:
+		return v.Notices
+	case *DomainSearchResults:
+		return v.Notices
+	case *EntitySearchResults:
+		return v.Notices
+	case *NameserverSearchResults:
+		return v.Notices
+	default:
+		return nil
+	}
+}
+
+// TermsOfService returns the terms of service seen for the RDAP server
+// |baseURL| (e.g. "https://rdap.nic.cz"), or nil if the server is unknown.
+//
+// The returned TermsOfService is a copy.
+func (c *Client) TermsOfService(baseURL string) *TermsOfService {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t, ok := c.tos[rateLimitKey(baseURL)]; ok {
+		return t.clone()
+	}
+
+	return nil
+}
+
+// AcknowledgeTermsOfService records that the terms of service of the RDAP
+// server |baseURL| are acknowledged, e.g. from an organization's records of
+// previous acceptance.
+//
+// See Client.TermsOfServiceGate.
+func (c *Client) AcknowledgeTermsOfService(baseURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := c.termsOfService(baseURL)
+	t.Acknowledged = time.Now()
+}
+
+// termsOfService returns the (possibly new) TermsOfService for |baseURL|. The
+// caller must hold c.mu.
+func (c *Client) termsOfService(baseURL string) *TermsOfService {
+	key := rateLimitKey(baseURL)
+
+	if c.tos == nil {
+		c.tos = map[string]*TermsOfService{}
+	}
+
+	t, ok := c.tos[key]
+	if !ok {
+		t = &TermsOfService{
+			BaseURL: baseURL,
+		}
+		c.tos[key] = t
+	}
+
+	return t
+}
+
+// recordTermsOfService records any terms of service notices in the response
+// |obj| from |rdapReq|'s server.
+func (c *Client) recordTermsOfService(rdapReq *Request, obj RDAPObject) {
+	var notices []Notice
+	for _, n := range noticesOf(obj) {
+		if isTermsOfServiceNotice(n) {
+			notices = append(notices, n)
+		}
+	}
+
+	if len(notices) == 0 {
+		return
+	}
+
+	baseURL := rdapReq.baseURL()
+	now := time.Now()
+
+	c.mu.Lock()
+	t := c.termsOfService(baseURL)
+
+	previous := t.fingerprint()
+	t.Notices = notices
+
+	changed := previous != "" && previous != t.fingerprint()
+	if t.FirstSeen.IsZero() || changed {
+		t.FirstSeen = now
+	}
+
+	if changed {
+		t.Acknowledged = time.Time{}
+	}
+
+	t.LastSeen = now
+	c.mu.Unlock()
+
+	if changed {
+		c.Verbose(fmt.Sprintf("client: terms of service for %s changed", baseURL))
+	}
+}
+
+// checkTermsOfService enforces the Client's TermsOfServiceGate before
+// querying |rdapReq|'s server.
+//
+// If the server's terms of service haven't been seen yet, they're fetched
+// using a help query.
+func (c *Client) checkTermsOfService(rdapReq *Request) error {
+	if c.TermsOfServiceGate == nil {
+		return nil
+	}
+
+	baseURL := rdapReq.baseURL()
+	key := rateLimitKey(baseURL)
+
+	c.mu.Lock()
+	t, seen := c.tos[key]
+	acknowledged := seen && !t.Acknowledged.IsZero()
+	c.mu.Unlock()
+
+	if acknowledged {
+		return nil
+	}
+
+	if !seen && rdapReq.Type != HelpRequest {
+		c.Verbose(fmt.Sprintf("client: fetching terms of service for %s", baseURL))
+
+		server, err := rdapReq.Server.Parse(baseURL)
+		if err == nil {
+			help := NewHelpRequest().WithServer(server).WithContext(rdapReq.Context())
+			help.HTTP = rdapReq.HTTP
+
+			httpResponse := c.getWithRetries(help)
+			if httpResponse.Error == nil && httpResponse.Response.StatusCode == 200 {
+				if obj, err := NewDecoder(httpResponse.Body).Decode(); err == nil {
+					c.recordTermsOfService(help, obj)
+				}
+			}
+		}
+	}
+
+	c.mu.Lock()
+	t = c.termsOfService(baseURL).clone()
+	c.mu.Unlock()
+
+	if !c.TermsOfServiceGate(t) {
+		return &ClientError{
+			Type: TermsOfServiceNotAcknowledged,
+			Text: fmt.Sprintf("Terms of service for %s not acknowledged", baseURL),
+		}
+	}
+
+	c.Verbose(fmt.Sprintf("client: terms of service for %s acknowledged", baseURL))
+
+	c.mu.Lock()
+	c.termsOfService(baseURL).Acknowledged = time.Now()
+	c.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,139 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+// tosTransport is a http.RoundTripper serving a help response with the terms
+// of service notice |notice|, and the example.cz domain.
+type tosTransport struct {
+	notice string
+	paths  []string
+}
+
+func (t *tosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.paths = append(t.paths, req.URL.Path)
+
+	body := string(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	if req.URL.Path == "/help" {
+		body = `{"rdapConformance": ["rdap_level_0"], "notices": [` + t.notice + `]}`
+	}
+
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestClientTermsOfService(t *testing.T) {
+	client := &Client{
+		HTTP:    &http.Client{Transport: &tosTransport{}},
+		Verbose: verboseFunc(),
+	}
+
+	if tos := client.TermsOfService("https://rdap.nic.cz"); tos != nil {
+		t.Fatalf("Unexpected terms of service %v", tos)
+	}
+
+	server, _ := url.Parse("https://rdap.nic.cz")
+	if _, err := client.Do(NewDomainRequest("example.cz").WithServer(server)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	tos := client.TermsOfService("https://RDAP.nic.cz/")
+	if tos == nil || len(tos.Notices) != 1 || tos.Notices[0].Title != "Disclaimer" {
+		t.Fatalf("Unexpected terms of service %v", tos)
+	}
+
+	if tos.FirstSeen.IsZero() || !tos.Acknowledged.IsZero() {
+		t.Errorf("Unexpected times FirstSeen=%s Acknowledged=%s", tos.FirstSeen, tos.Acknowledged)
+	}
+}
+
+func TestClientTermsOfServiceGate(t *testing.T) {
+	transport := &tosTransport{
+		notice: `{"title": "Terms of Use", "description": ["Be nice."],
+			"links": [{"rel": "terms-of-service", "href": "https://example.cz/tos"}]}`,
+	}
+
+	var gated []*TermsOfService
+	accept := false
+
+	client := &Client{
+		HTTP:    &http.Client{Transport: transport},
+		Verbose: verboseFunc(),
+		TermsOfServiceGate: func(tos *TermsOfService) bool {
+			gated = append(gated, tos)
+			return accept
+		},
+	}
+
+	server, _ := url.Parse("https://rdap.nic.cz")
+	req := NewDomainRequest("example.cz").WithServer(server)
+
+	_, err := client.Do(req)
+	if !isClientError(TermsOfServiceNotAcknowledged, err) {
+		t.Fatalf("Expected TermsOfServiceNotAcknowledged, got %v", err)
+	} else if len(gated) != 1 || gated[0].URL() != "https://example.cz/tos" {
+		t.Fatalf("Unexpected gate calls %v", gated)
+	} else if len(transport.paths) != 1 || transport.paths[0] != "/help" {
+		t.Fatalf("Unexpected requests %v", transport.paths)
+	}
+
+	accept = true
+	if _, err := client.Do(req); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if len(gated) != 2 {
+		t.Errorf("Unexpected number of gate calls %d", len(gated))
+	}
+
+	// The domain response's notice differs from the help response's (i.e. the
+	// terms of service changed), so a new acknowledgment is required.
+	if tos := client.TermsOfService("https://rdap.nic.cz"); !tos.Acknowledged.IsZero() {
+		t.Errorf("Expected acknowledgment reset after terms of service change")
+	}
+
+	if _, err := client.Do(req); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if len(gated) != 3 || gated[2].Notices[0].Title != "Disclaimer" {
+		t.Errorf("Unexpected gate calls %v", gated)
+	}
+
+	// Acknowledged and unchanged, so no further gate calls.
+	if _, err := client.Do(req); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if len(gated) != 3 {
+		t.Errorf("Unexpected number of gate calls %d", len(gated))
+	}
+}
+
+func TestClientAcknowledgeTermsOfService(t *testing.T) {
+	transport := &tosTransport{}
+	client := &Client{
+		HTTP:    &http.Client{Transport: transport},
+		Verbose: verboseFunc(),
+		TermsOfServiceGate: func(tos *TermsOfService) bool {
+			return false
+		},
+	}
+	client.AcknowledgeTermsOfService("https://rdap.nic.cz")
+
+	server, _ := url.Parse("https://rdap.nic.cz")
+	if _, err := client.Do(NewDomainRequest("example.cz").WithServer(server)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if len(transport.paths) != 1 {
+		t.Errorf("Unexpected requests %v", transport.paths)
+	}
+}
@@ -0,0 +1,94 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"strings"
+)
+
+// Span names, see Tracer.
+const (
+	// Whole query (Client.Do).
+	SpanQuery = "rdap.query"
+
+	// Bootstrap lookup.
+	SpanBootstrap = "rdap.bootstrap"
+
+	// Each HTTP request to an RDAP server, including retries.
+	SpanHTTP = "rdap.http"
+
+	// Decoding an RDAP response.
+	SpanDecode = "rdap.decode"
+)
+
+// Span attribute keys.
+const (
+	AttrQueryType  = "rdap.query_type"
+	AttrQuery      = "rdap.query"
+	AttrTLD        = "rdap.tld"
+	AttrRegistry   = "rdap.bootstrap.registry"
+	AttrBaseURL    = "rdap.base_url"
+	AttrURL        = "url.full"
+	AttrStatusCode = "http.response.status_code"
+	AttrBodySize   = "http.response.body.size"
+	AttrCached     = "rdap.cached"
+)
+
+// Tracer creates trace spans around the Client's bootstrap lookups, HTTP
+// requests, and response decoding, for distributed tracing. See
+// Client.Tracer.
+//
+// The github.com/openrdap/rdap/otel module provides an OpenTelemetry Tracer.
+type Tracer interface {
+	// Start starts the span |name| (e.g. SpanQuery), as a child of any span in
+	// |ctx|. Returns the span, and a context containing it.
+	Start(ctx context.Context, name string, attrs map[string]interface{}) (context.Context, Span)
+}
+
+// Span is a trace span started by a Tracer.
+type Span interface {
+	// SetAttribute sets the attribute |key| (e.g. AttrStatusCode). |value| is
+	// a string, int, or bool.
+	SetAttribute(key string, value interface{})
+
+	// End ends the span. |err| is the operation's error, or nil if the
+	// operation succeeded.
+	End(err error)
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) End(err error)                              {}
+
+// startSpan starts the span |name| using the Client's Tracer, if set.
+func (c *Client) startSpan(ctx context.Context, name string, attrs map[string]interface{}) (context.Context, Span) {
+	if c.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	return c.Tracer.Start(ctx, name, attrs)
+}
+
+// spanAttributes returns the span attributes describing the RDAP request
+// |req|.
+func spanAttributes(req *Request) map[string]interface{} {
+	attrs := map[string]interface{}{
+		AttrQueryType: req.Type.String(),
+		AttrQuery:     req.Query,
+	}
+
+	if req.Type == DomainRequest {
+		domain := strings.TrimSuffix(strings.ToLower(req.Query), ".")
+		attrs[AttrTLD] = domain[strings.LastIndexByte(domain, '.')+1:]
+	}
+
+	if req.Server != nil {
+		attrs[AttrBaseURL] = req.baseURL()
+	}
+
+	return attrs
+}
@@ -0,0 +1,100 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+type recordedSpan struct {
+	name   string
+	parent string
+	attrs  map[string]interface{}
+	ended  bool
+	err    error
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) {
+	s.attrs[key] = value
+}
+
+func (s *recordedSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type spanContextKey struct{}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, attrs map[string]interface{}) (context.Context, Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	span := &recordedSpan{name: name, attrs: attrs}
+	if parent, ok := ctx.Value(spanContextKey{}).(*recordedSpan); ok {
+		span.parent = parent.name
+	}
+
+	r.spans = append(r.spans, span)
+
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+func TestClientTracer(t *testing.T) {
+	test.Start(test.Bootstrap)
+	test.Start(test.Responses)
+	defer test.Finish()
+
+	tracer := &recordingTracer{}
+	client := &Client{
+		Tracer:  tracer,
+		Verbose: verboseFunc(),
+	}
+
+	if _, err := client.QueryDomain("example.cz"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var names []string
+	for _, span := range tracer.spans {
+		names = append(names, span.name+"<"+span.parent)
+
+		if !span.ended || span.err != nil {
+			t.Errorf("Span %s not ended successfully", span.name)
+		}
+	}
+
+	expected := []string{
+		SpanQuery + "<",
+		SpanBootstrap + "<" + SpanQuery,
+		SpanHTTP + "<" + SpanQuery,
+		SpanDecode + "<" + SpanQuery,
+	}
+
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Got spans %v, expected %v", names, expected)
+	}
+
+	if tld := tracer.spans[0].attrs[AttrTLD]; tld != "cz" {
+		t.Errorf("Unexpected TLD %v", tld)
+	}
+
+	if status := tracer.spans[2].attrs[AttrStatusCode]; status != 200 {
+		t.Errorf("Unexpected status code %v", status)
+	}
+
+	if baseURL := tracer.spans[3].attrs[AttrBaseURL]; baseURL != "https://rdap.nic.cz" {
+		t.Errorf("Unexpected base URL %v", baseURL)
+	}
+}
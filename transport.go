@@ -0,0 +1,248 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTP2Policy specifies whether HTTP/2 is used for RDAP servers. See
+// TransportOptions.
+type HTTP2Policy int
+
+const (
+	// HTTP2Default uses the http.Transport's setting. For the default
+	// transport, HTTP/2 is used if the server supports it.
+	HTTP2Default HTTP2Policy = iota
+
+	// HTTP2Force attempts HTTP/2 even when the transport has a custom dialer
+	// or TLS config (see http.Transport.ForceAttemptHTTP2).
+	HTTP2Force
+
+	// HTTP2Disable uses HTTP/1.1 only.
+	HTTP2Disable
+)
+
+// TransportOptions tunes the connections to RDAP servers, e.g. for bulk
+// queries, which are limited by the default connection pool sizes.
+//
+//	client := &rdap.Client{
+//	  TransportOptions: rdap.TransportOptions{
+//	    MaxIdleConnsPerHost: 16,
+//	    IdleConnTimeout:     time.Minute,
+//	  },
+//	}
+//
+// Zero values keep the http.Transport's settings. TransportOptions apply when
+// the Client's HTTP client (or Transport) uses an *http.Transport, which is
+// the default.
+type TransportOptions struct {
+	// Maximum idle (keep-alive) connections, across all servers. See
+	// http.Transport.MaxIdleConns.
+	MaxIdleConns int
+
+	// Maximum idle (keep-alive) connections to each server. The
+	// http.Transport default is 2. See http.Transport.MaxIdleConnsPerHost.
+	MaxIdleConnsPerHost int
+
+	// Maximum connections to each server, including active connections. See
+	// http.Transport.MaxConnsPerHost.
+	MaxConnsPerHost int
+
+	// How long idle connections are kept open. See
+	// http.Transport.IdleConnTimeout.
+	IdleConnTimeout time.Duration
+
+	// Disable keep-alives, using each connection for a single request.
+	DisableKeepAlives bool
+
+	// Whether HTTP/2 is used. The default is HTTP2Default.
+	HTTP2 HTTP2Policy
+}
+
+// isZero returns true if no options are set.
+func (o TransportOptions) isZero() bool {
+	return o == TransportOptions{}
+}
+
+// apply applies the options to |transport|.
+func (o TransportOptions) apply(transport *http.Transport) {
+	if o.MaxIdleConns > 0 {
+		transport.MaxIdleConns = o.MaxIdleConns
+	}
+
+	if o.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+
+	if o.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = o.MaxConnsPerHost
+	}
+
+	if o.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = o.IdleConnTimeout
+	}
+
+	if o.DisableKeepAlives {
+		transport.DisableKeepAlives = true
+	}
+
+	switch o.HTTP2 {
+	case HTTP2Force:
+		transport.ForceAttemptHTTP2 = true
+	case HTTP2Disable:
+		// A non-nil, empty TLSNextProto disables HTTP/2.
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+
+		if transport.TLSClientConfig != nil {
+			var protos []string
+			for _, p := range transport.TLSClientConfig.NextProtos {
+				if p != "h2" {
+					protos = append(protos, p)
+				}
+			}
+			transport.TLSClientConfig.NextProtos = protos
+		}
+	}
+}
+
+// ClientCertificates maps RDAP server hostnames to TLS client certificates,
+// for RDAP servers which require mutual TLS authentication (e.g. for access
+// to non-public registration data).
+//
+// A hostname matches itself and its subdomains, e.g. "nic.cz" matches
+// "rdap.nic.cz". The most specific match is used. The empty string key is the
+// default for unlisted servers.
+//
+//	cert, err := tls.LoadX509KeyPair("client.pem", "client.key")
+//
+//	client := &rdap.Client{
+//	  ClientCertificates: rdap.ClientCertificates{
+//	    "rdap.registry.example": cert,
+//	  },
+//	}
+//
+// The certificate is only sent if the server requests one. ClientCertificates
+// apply when the Client's HTTP client (or Transport) uses an *http.Transport,
+// which is the default.
+type ClientCertificates map[string]tls.Certificate
+
+// For returns the client certificate for the RDAP server hostname |host|
+// (without port number).
+//
+// Returns false if there's no match and no default.
+func (c ClientCertificates) For(host string) (tls.Certificate, bool) {
+	cert, ok := c[matchHost(host, func(h string) bool {
+		_, ok := c[h]
+		return ok
+	})]
+
+	return cert, ok
+}
+
+// hostTransport is a http.RoundTripper applying the Client's per server
+// settings (Proxies, ClientCertificates, Resolver, the Connect timeout, and
+// TransportOptions) to a http.Transport.
+type hostTransport struct {
+	client *Client
+	base   *http.Transport
+}
+
+// hostTransportKey identifies a configured copy of a http.Transport.
+type hostTransportKey struct {
+	base *http.Transport
+
+	// Hostname key of the ClientCertificates match, if any.
+	certHost string
+	hasCert  bool
+}
+
+func (h *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return h.client.transportForHost(h.base, req.URL.Hostname()).RoundTrip(req)
+}
+
+// configureTransport returns |rt| (nil meaning http.DefaultTransport), with
+// the Client's Proxies, ClientCertificates, Resolver, Connect timeout, and
+// TransportOptions applied.
+//
+// Returns |rt| unchanged if none are set, or |rt| isn't an *http.Transport.
+func (c *Client) configureTransport(rt http.RoundTripper) http.RoundTripper {
+	if c.Proxies == nil && c.ClientCertificates == nil && c.Timeouts.Connect == 0 &&
+		c.TransportOptions.isZero() && c.Resolver == nil {
+		return rt
+	}
+
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		c.Verbose(fmt.Sprintf("client: Proxies/ClientCertificates/Resolver/Timeouts/TransportOptions not applied to custom transport %T", rt))
+		return rt
+	}
+
+	return &hostTransport{
+		client: c,
+		base:   transport,
+	}
+}
+
+// transportForHost returns a copy of |base| configured for the RDAP server
+// hostname |host|.
+//
+// The copies are reused, so connections to each server are reused.
+func (c *Client) transportForHost(base *http.Transport, host string) *http.Transport {
+	key := hostTransportKey{base: base}
+
+	var cert tls.Certificate
+	if c.ClientCertificates != nil {
+		key.certHost = matchHost(host, func(h string) bool {
+			_, ok := c.ClientCertificates[h]
+			return ok
+		})
+		cert, key.hasCert = c.ClientCertificates[key.certHost]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if transport, ok := c.transports[key]; ok {
+		return transport
+	}
+
+	if c.transports == nil {
+		c.transports = map[hostTransportKey]*http.Transport{}
+	}
+
+	transport := base.Clone()
+
+	if c.Proxies != nil {
+		transport.Proxy = c.Proxies.proxyFunc(base.Proxy)
+	}
+
+	if c.Resolver != nil {
+		transport.DialContext = dialWithResolver(transport.DialContext, c.Resolver)
+	}
+
+	c.Timeouts.applyConnectTimeout(transport)
+	c.TransportOptions.apply(transport)
+
+	if key.hasCert {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	c.transports[key] = transport
+
+	return transport
+}
@@ -0,0 +1,124 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestClientCertificatesFor(t *testing.T) {
+	certs := ClientCertificates{
+		"nic.cz": tls.Certificate{OCSPStaple: []byte("nic.cz")},
+	}
+
+	if cert, ok := certs.For("rdap.nic.cz"); !ok || string(cert.OCSPStaple) != "nic.cz" {
+		t.Errorf("No certificate for rdap.nic.cz")
+	}
+
+	if _, ok := certs.For("rdap.example"); ok {
+		t.Errorf("Unexpected certificate for rdap.example")
+	}
+}
+
+func TestClientCertificates(t *testing.T) {
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(test.LoadFile("x509_auth/root.pem"))
+
+	// RDAP server requiring a client certificate.
+	var clientName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientName = r.TLS.PeerCertificates[0].Subject.CommonName
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  roots,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	req := NewDomainRequest("example.cz").WithServer(serverURL)
+
+	// No client certificate.
+	client := &Client{
+		HTTP:               server.Client(),
+		ClientCertificates: ClientCertificates{},
+		Verbose:            verboseFunc(),
+	}
+
+	if _, err := client.Do(req); !isClientError(NoWorkingServers, err) {
+		t.Fatalf("Unexpected err %v", err)
+	}
+
+	// Client certificate for the server.
+	cert, err := tls.X509KeyPair(test.LoadFile("x509_auth/client.pem"), test.LoadFile("x509_auth/client.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.ClientCertificates = ClientCertificates{
+		serverURL.Hostname(): cert,
+	}
+
+	if _, err := client.Do(req); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	} else if clientName != "OpenRDAP test client certificate" {
+		t.Errorf("Unexpected client certificate %q", clientName)
+	}
+}
+
+func TestTransportOptions(t *testing.T) {
+	var protos []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protos = append(protos, r.Proto)
+		w.Header().Set("Content-Type", MediaTypeRDAP)
+		w.Write(test.LoadFile("rdap/rdap.nic.cz/domain-example.cz.json"))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	req := NewDomainRequest("example.cz").WithServer(serverURL)
+
+	for _, test := range []struct {
+		HTTP2 HTTP2Policy
+		Proto string
+	}{
+		{HTTP2Default, "HTTP/2.0"},
+		{HTTP2Disable, "HTTP/1.1"},
+	} {
+		client := &Client{
+			HTTP: server.Client(),
+			TransportOptions: TransportOptions{
+				MaxIdleConnsPerHost: 8,
+				HTTP2:               test.HTTP2,
+			},
+			Verbose: verboseFunc(),
+		}
+
+		if _, err := client.Do(req); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if protos[len(protos)-1] != test.Proto {
+			t.Errorf("HTTP2Policy %d: got %s, expected %s", test.HTTP2, protos[len(protos)-1], test.Proto)
+		}
+
+		transport := client.transportForHost(server.Client().Transport.(*http.Transport), serverURL.Hostname())
+		if transport.MaxIdleConnsPerHost != 8 {
+			t.Errorf("MaxIdleConnsPerHost %d, expected 8", transport.MaxIdleConnsPerHost)
+		}
+	}
+}
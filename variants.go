@@ -0,0 +1,49 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+// Variant relations, from the IANA "RDAP JSON Values" registry (RFC 9083
+// section 10.2.5).
+//
+// https://www.iana.org/assignments/rdap-json-values/
+const (
+	VariantRegistered             = "registered"
+	VariantUnregistered           = "unregistered"
+	VariantRegistrationRestricted = "registration restricted"
+	VariantOpenRegistration       = "open registration"
+	VariantConjoined              = "conjoined"
+)
+
+// HasRelation returns true if the variant has the relation |relation|, e.g.
+// "registered".
+func (v *Variant) HasRelation(relation string) bool {
+	return containsString(v.Relation, relation)
+}
+
+// ALabel returns the variant name in ASCII (A-label) form. See
+// Domain.ALabel().
+func (v *VariantName) ALabel() string {
+	return aLabel(v.LDHName, v.UnicodeName)
+}
+
+// ULabel returns the variant name in Unicode (U-label) form. See
+// Domain.ULabel().
+func (v *VariantName) ULabel() string {
+	return uLabel(v.LDHName, v.UnicodeName)
+}
+
+// VariantNames returns the names of the domain's variants with the relation
+// |relation| (e.g. "registered"), or of all variants if |relation| is "".
+func (d *Domain) VariantNames(relation string) []VariantName {
+	var result []VariantName
+
+	for i := range d.Variants {
+		if relation == "" || d.Variants[i].HasRelation(relation) {
+			result = append(result, d.Variants[i].VariantNames...)
+		}
+	}
+
+	return result
+}
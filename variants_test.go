@@ -0,0 +1,47 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+func TestDomainVariants(t *testing.T) {
+	obj, err := NewDecoder(test.LoadFile("rdap/rdap.registry.example/domain-variants.json")).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := obj.(*Domain)
+
+	if len(d.Variants) != 2 {
+		t.Fatalf("Unexpected variants %+v", d.Variants)
+	}
+
+	v := d.Variants[0]
+	if v.IDNTable != "zh-Hans-CN" || !v.HasRelation(VariantRegistered) || !v.HasRelation(VariantConjoined) || v.HasRelation(VariantUnregistered) {
+		t.Errorf("Unexpected variant %+v", v)
+	}
+
+	registered := d.VariantNames(VariantRegistered)
+	if len(registered) != 1 || registered[0].ULabel() != "中國互聯網絡信息中心.中國" || registered[0].ALabel() != "xn--fiqa61au8b71szsnm8axz3l2ua72t.xn--fiqz9s" {
+		t.Errorf("Unexpected registered variants %+v", registered)
+	}
+
+	restricted := d.VariantNames(VariantRegistrationRestricted)
+	if len(restricted) != 1 || restricted[0].UnicodeName != "" || restricted[0].ULabel() != "中国互联网络信息中心.中國" {
+		t.Errorf("Unexpected restricted variants %+v", restricted)
+	}
+
+	if all := d.VariantNames(""); len(all) != 2 {
+		t.Errorf("Unexpected variant names %+v", all)
+	}
+
+	if names := d.VariantNames(VariantOpenRegistration); names != nil {
+		t.Errorf("Unexpected variant names %+v", names)
+	}
+}
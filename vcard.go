@@ -40,6 +40,23 @@ import (
 //	]
 type VCard struct {
 	Properties []*VCardProperty
+
+	// jCard encoding details of decoded properties, which VCardProperty does
+	// not represent. Used to re-encode decoded properties exactly.
+	encodings map[*VCardProperty]*jCardEncoding
+
+	options VCardOptions
+}
+
+// jCardEncoding records how a property was encoded in jCard, where this is
+// ambiguous from the VCardProperty alone.
+type jCardEncoding struct {
+	// The property had multiple values (i.e. the property array had more than
+	// four elements), rather than a single (possibly structured) value.
+	multiValued bool
+
+	// Parameters with a single value, encoded as a one element array.
+	arrayParameters map[string]bool
 }
 
 // VCardProperty represents a single vCard property.
@@ -70,6 +87,7 @@ type VCardProperty struct {
 	//   * []interface{}. Can contain a mixture of these five types.
 	//
 	// To retrieve the property value flattened into a []string, use Values().
+	// For structured values (e.g. "adr", "n", "org"), use StructuredValues().
 	Value interface{}
 }
 
@@ -79,6 +97,12 @@ type VCardOptions struct {
 	//
 	// Set IgnoreInvalidProperties to true to silently skip any invalid properties.
 	IgnoreInvalidProperties bool
+
+	// Maximum size in bytes of decoded binary data (e.g. a "photo" or "logo"),
+	// returned by Photo(), Logo() and Key().
+	//
+	// The default is DefaultMaxBinarySize.
+	MaxBinarySize int
 }
 
 // Values returns a simplified representation of the VCardProperty value.
@@ -95,6 +119,36 @@ func (p *VCardProperty) Values() []string {
 	return strings
 }
 
+// StructuredValues returns a representation of the VCardProperty value which
+// preserves its top level structure.
+//
+// This is convenient for accessing structured data (e.g. "adr", "n", "org"),
+// where Values() would lose track of which component each value belongs to.
+//
+// Each component of the value is flattened into a []string, so a component
+// with multiple values (e.g. two street address lines) is kept together. An
+// unstructured value is returned as a single component. For example:
+//
+//	["adr", {}, "text", ["", "", ["1 Main St", "Unit 2"], "Town", "", "", "Canada"]]
+//
+// returns [[""], [""], ["1 Main St", "Unit 2"], ["Town"], [""], [""], ["Canada"]].
+func (p *VCardProperty) StructuredValues() [][]string {
+	a, ok := p.Value.([]interface{})
+	if !ok {
+		return [][]string{p.Values()}
+	}
+
+	result := make([][]string, 0, len(a))
+	for _, component := range a {
+		values := make([]string, 0, 1)
+		p.appendValueStrings(component, &values)
+
+		result = append(result, values)
+	}
+
+	return result
+}
+
 func (p *VCardProperty) appendValueStrings(v interface{}, strings *[]string) {
 	switch v := v.(type) {
 	case nil:
@@ -188,11 +242,13 @@ func newVCardImpl(src interface{}, options VCardOptions) (*VCard, error) {
 
 	v := &VCard{
 		Properties: make([]*VCardProperty, 0, len(properties)),
+		encodings:  make(map[*VCardProperty]*jCardEncoding),
+		options:    options,
 	}
 
 	var p interface{}
 	for _, p = range top[1].([]interface{}) {
-		property, err := decodeVCardProperty(p)
+		property, encoding, err := decodeVCardProperty(p)
 
 		if err != nil {
 			if options.IgnoreInvalidProperties {
@@ -203,40 +259,45 @@ func newVCardImpl(src interface{}, options VCardOptions) (*VCard, error) {
 		}
 
 		v.Properties = append(v.Properties, property)
+		v.encodings[property] = encoding
 	}
 
 	return v, nil
 }
 
-func decodeVCardProperty(p interface{}) (*VCardProperty, error) {
+func decodeVCardProperty(p interface{}) (*VCardProperty, *jCardEncoding, error) {
 	var a []interface{}
 	var ok bool
 	a, ok = p.([]interface{})
 
 	if !ok {
-		return nil, vCardError("jCard property was not an array")
+		return nil, nil, vCardError("jCard property was not an array")
 	} else if len(a) < 4 {
-		return nil, vCardError("jCard property too short (>=4 array elements required)")
+		return nil, nil, vCardError("jCard property too short (>=4 array elements required)")
 	}
 
 	name, ok := a[0].(string)
 
 	if !ok {
-		return nil, vCardError("jCard property name invalid")
+		return nil, nil, vCardError("jCard property name invalid")
+	}
+
+	encoding := &jCardEncoding{
+		multiValued: len(a) > 4,
 	}
 
 	var parameters map[string][]string
 	var err error
-	parameters, err = readParameters(a[1])
+	parameters, encoding.arrayParameters, err = readParameters(a[1])
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	propertyType, ok := a[2].(string)
 
 	if !ok {
-		return nil, vCardError("jCard property type invalid")
+		return nil, nil, vCardError("jCard property type invalid")
 	}
 
 	var value interface{}
@@ -247,7 +308,7 @@ func decodeVCardProperty(p interface{}) (*VCardProperty, error) {
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	property := &VCardProperty{
@@ -257,7 +318,86 @@ func decodeVCardProperty(p interface{}) (*VCardProperty, error) {
 		Value:      value,
 	}
 
-	return property, nil
+	return property, encoding, nil
+}
+
+// MarshalJSON encodes the VCard in jCard format.
+//
+// The output is deterministic: the "version" property is always written first,
+// followed by the remaining properties in their original order. Parameter
+// names within each property are written in sorted order.
+//
+// A decoded VCard is re-encoded exactly: property order (for valid jCards,
+// which have version as the first property), property & parameter name
+// casing, groups (the "group" parameter), single vs array parameter values,
+// and single vs multiple property values are all preserved. This allows a
+// VCard to be decoded, modified, and written back without disturbing the
+// properties left unmodified.
+func (v *VCard) MarshalJSON() ([]byte, error) {
+	properties := make([]*VCardProperty, 0, len(v.Properties))
+
+	for _, p := range v.Properties {
+		if p.Name == "version" {
+			properties = append(properties, p)
+			break
+		}
+	}
+
+	for _, p := range v.Properties {
+		if len(properties) > 0 && p == properties[0] {
+			continue
+		}
+
+		properties = append(properties, p)
+	}
+
+	jcards := make([]interface{}, 0, len(properties))
+	for _, p := range properties {
+		jcards = append(jcards, p.jCard(v.encodings[p]))
+	}
+
+	return json.Marshal([]interface{}{"vcard", jcards})
+}
+
+// MarshalJSON encodes the VCardProperty as a jCard property array.
+//
+// Parameter names are written in sorted order.
+func (p *VCardProperty) MarshalJSON() ([]byte, error) {
+	// encoding/json writes map keys in sorted order.
+	return json.Marshal(p.jCard(nil))
+}
+
+// jCard returns the property in jCard array form, e.g.
+// ["tel", {"type": ["work", "voice"]}, "uri", "tel:+1-555-555-1234"].
+//
+// |encoding| is optional, and specifies how the property was originally
+// encoded.
+func (p *VCardProperty) jCard(encoding *jCardEncoding) []interface{} {
+	if encoding == nil {
+		encoding = &jCardEncoding{}
+	}
+
+	parameters := make(map[string]interface{}, len(p.Parameters))
+
+	for k, v := range p.Parameters {
+		if len(v) == 1 && !encoding.arrayParameters[k] {
+			parameters[k] = v[0]
+		} else {
+			values := make([]interface{}, 0, len(v))
+			for _, s := range v {
+				values = append(values, s)
+			}
+			parameters[k] = values
+		}
+	}
+
+	result := []interface{}{p.Name, parameters, p.Type}
+
+	if values, ok := p.Value.([]interface{}); ok && encoding.multiValued && len(values) > 0 {
+		return append(result, values...)
+	}
+
+	return append(result, p.Value)
 }
 
 // Get returns a list of the vCard Properties with VCardProperty name |name|.
@@ -290,11 +430,16 @@ func vCardError(e string) error {
 	return fmt.Errorf("jCard error: %s", e)
 }
 
-func readParameters(p interface{}) (map[string][]string, error) {
+// readParameters reads the jCard parameters object |p|.
+//
+// Also returns the set of single valued parameters which were encoded as an
+// array.
+func readParameters(p interface{}) (map[string][]string, map[string]bool, error) {
 	params := map[string][]string{}
+	var arrayParams map[string]bool
 
 	if _, ok := p.(map[string]interface{}); !ok {
-		return nil, vCardError("jCard parameters invalid")
+		return nil, nil, vCardError("jCard parameters invalid")
 	}
 
 	for k, v := range p.(map[string]interface{}) {
@@ -306,10 +451,17 @@ func readParameters(p interface{}) (map[string][]string, error) {
 					params[k] = append(params[k], s)
 				}
 			}
+
+			if len(params[k]) == 1 {
+				if arrayParams == nil {
+					arrayParams = map[string]bool{}
+				}
+				arrayParams[k] = true
+			}
 		}
 	}
 
-	return params, nil
+	return params, arrayParams, nil
 }
 
 func readValue(value interface{}, depth int) (interface{}, error) {
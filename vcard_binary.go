@@ -0,0 +1,220 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultMaxBinarySize is the default maximum size of decoded vCard binary
+// data (see VCardOptions.MaxBinarySize).
+const DefaultMaxBinarySize = 1024 * 1024
+
+// VCardBinary represents the binary data of a vCard property, such as a
+// "photo", "logo", "key", or "sound".
+//
+// vCard binary data is either inline, or a reference to an external resource:
+//
+//	["logo", {}, "uri", "data:image/png;base64,iVBORw0KGgo..."]
+//	["logo", {}, "uri", "https://example.com/logo.png"]
+//	["photo", {"encoding": "b", "type": "JPEG"}, "text", "/9j/4AAQSkZJRg..."]
+type VCardBinary struct {
+	// MIME type of the data, e.g. "image/png".
+	//
+	// This is taken from the data: URI or property parameters if present,
+	// otherwise it's detected from the data itself.
+	MediaType string
+
+	// Decoded data. nil for external references.
+	Data []byte
+
+	// URL of the external resource. Empty string for inline data.
+	URL string
+}
+
+// Binary decodes the property's binary data.
+//
+// Inline data (data: URIs, and base64 encoded values) is decoded. For
+// references to external resources, only the URL is returned.
+//
+// |maxSize| limits the size of decoded data in bytes. Use 0 for
+// DefaultMaxBinarySize.
+func (p *VCardProperty) Binary(maxSize int) (*VCardBinary, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxBinarySize
+	}
+
+	value, ok := p.Value.(string)
+	if !ok {
+		return nil, vCardError(fmt.Sprintf("%s value is not a string", p.Name))
+	}
+
+	value = strings.TrimSpace(value)
+
+	// vCard 3 style base64 value, e.g. ENCODING=b.
+	for _, encoding := range p.Parameters["encoding"] {
+		switch strings.ToLower(encoding) {
+		case "b", "base64":
+			data, err := decodeBase64(value, maxSize)
+			if err != nil {
+				return nil, err
+			}
+
+			return &VCardBinary{
+				MediaType: p.mediaType(data),
+				Data:      data,
+			}, nil
+		}
+	}
+
+	if len(value) >= 5 && strings.EqualFold(value[0:5], "data:") {
+		return decodeDataURI(value, maxSize, p)
+	}
+
+	return &VCardBinary{
+		MediaType: p.mediaType(nil),
+		URL:       value,
+	}, nil
+}
+
+// Photo returns the binary data of the VCard's first "photo" property.
+//
+// Returns nil if the VCard has no photo.
+func (v *VCard) Photo() (*VCardBinary, error) {
+	return v.getFirstBinary("photo")
+}
+
+// Logo returns the binary data of the VCard's first "logo" property.
+//
+// Returns nil if the VCard has no logo.
+func (v *VCard) Logo() (*VCardBinary, error) {
+	return v.getFirstBinary("logo")
+}
+
+// Key returns the binary data of the VCard's first "key" property, e.g. a PGP
+// public key.
+//
+// Returns nil if the VCard has no key.
+func (v *VCard) Key() (*VCardBinary, error) {
+	return v.getFirstBinary("key")
+}
+
+func (v *VCard) getFirstBinary(name string) (*VCardBinary, error) {
+	property := v.GetFirst(name)
+
+	if property == nil {
+		return nil, nil
+	}
+
+	return property.Binary(v.options.MaxBinarySize)
+}
+
+// mediaType returns the MIME type of the property's binary data.
+//
+// The "mediatype" parameter is used if present, then the vCard 3 style "type"
+// parameter (e.g. "JPEG"). Otherwise the type is detected from |data|.
+func (p *VCardProperty) mediaType(data []byte) string {
+	if mediaTypes := p.Parameters["mediatype"]; len(mediaTypes) > 0 {
+		return mediaTypes[0]
+	}
+
+	for _, t := range p.Parameters["type"] {
+		switch strings.ToLower(t) {
+		case "jpeg", "jpg":
+			return "image/jpeg"
+		case "png":
+			return "image/png"
+		case "gif":
+			return "image/gif"
+		case "pgp":
+			return "application/pgp-keys"
+		case "x509":
+			return "application/pkix-cert"
+		}
+	}
+
+	if data != nil {
+		return http.DetectContentType(data)
+	}
+
+	return ""
+}
+
+// decodeDataURI decodes the RFC 2397 data: URI |uri|.
+func decodeDataURI(uri string, maxSize int, p *VCardProperty) (*VCardBinary, error) {
+	comma := strings.IndexByte(uri, ',')
+	if comma == -1 {
+		return nil, vCardError("malformed data: URI")
+	}
+
+	header := uri[len("data:"):comma]
+	payload := uri[comma+1:]
+
+	isBase64 := false
+	if strings.HasSuffix(strings.ToLower(header), ";base64") {
+		isBase64 = true
+		header = header[0 : len(header)-len(";base64")]
+	}
+
+	var data []byte
+	var err error
+
+	if isBase64 {
+		data, err = decodeBase64(payload, maxSize)
+	} else {
+		var s string
+		s, err = url.PathUnescape(payload)
+		data = []byte(s)
+
+		if err == nil && len(data) > maxSize {
+			err = vCardError(fmt.Sprintf("binary data exceeds %d bytes", maxSize))
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType := header
+	if mediaType == "" {
+		mediaType = p.mediaType(data)
+	}
+
+	return &VCardBinary{
+		MediaType: mediaType,
+		Data:      data,
+	}, nil
+}
+
+// decodeBase64 decodes the base64 string |s|, which may contain whitespace or
+// be unpadded.
+func decodeBase64(s string, maxSize int) ([]byte, error) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		default:
+			return r
+		}
+	}, s)
+
+	// Without padding, the decoded size is exact.
+	s = strings.TrimRight(s, "=")
+
+	if base64.RawStdEncoding.DecodedLen(len(s)) > maxSize {
+		return nil, vCardError(fmt.Sprintf("binary data exceeds %d bytes", maxSize))
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, vCardError(fmt.Sprintf("invalid base64 data: %s", err))
+	}
+
+	return data, nil
+}
@@ -0,0 +1,170 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"sort"
+	"strings"
+)
+
+// VCardSelectOptions specifies which VCard property Select() should choose.
+//
+// For example, the best work email address in English:
+//
+//	email := vcard.Select(rdap.VCardSelectOptions{
+//	  Name:     "email",
+//	  Language: "en",
+//	  Types:    []string{"work"},
+//	})
+type VCardSelectOptions struct {
+	// Property name, e.g. "email", "tel", "adr", "fn". Required.
+	Name string
+
+	// Preferred language tag (RFC 5646), e.g. "en", "fr-CA".
+	//
+	// Properties with a matching LANGUAGE parameter are preferred, then
+	// properties with no LANGUAGE parameter, then properties in other
+	// languages. "en" matches "en-GB" (and vice versa), but an exact match is
+	// preferred.
+	Language string
+
+	// Preferred TYPE parameter values, e.g. "work", "voice".
+	//
+	// Properties with more of the requested types are preferred.
+	Types []string
+
+	// Set RequireTypes to true to only select properties having all of the
+	// requested Types.
+	RequireTypes bool
+}
+
+// Select returns the best VCard property matching |opts|, or nil if there is
+// no property named opts.Name.
+//
+// Properties are ranked following the RFC 6350 rules, in order of:
+//   - language match (see VCardSelectOptions.Language).
+//   - number of matching TYPE parameter values.
+//   - PREF parameter (1 is most preferred, an absent PREF is least preferred).
+//   - order in the VCard.
+func (v *VCard) Select(opts VCardSelectOptions) *VCardProperty {
+	properties := v.SelectAll(opts)
+
+	if len(properties) == 0 {
+		return nil
+	}
+
+	return properties[0]
+}
+
+// SelectAll returns all VCard properties matching |opts|, best first.
+//
+// See Select() for the ranking rules.
+func (v *VCard) SelectAll(opts VCardSelectOptions) []*VCardProperty {
+	type candidate struct {
+		property *VCardProperty
+		language int
+		types    int
+		pref     int
+	}
+
+	var candidates []candidate
+
+	for _, p := range v.Get(opts.Name) {
+		c := candidate{
+			property: p,
+			language: p.languageMatch(opts.Language),
+			types:    p.typeMatches(opts.Types),
+			pref:     p.pref(),
+		}
+
+		if opts.RequireTypes && c.types < len(opts.Types) {
+			continue
+		}
+
+		// PREF ranges from 1 (most preferred) to 100. Rank properties without a
+		// PREF last.
+		if c.pref <= 0 || c.pref > 100 {
+			c.pref = 101
+		}
+
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i int, j int) bool {
+		a, b := candidates[i], candidates[j]
+
+		if a.language != b.language {
+			return a.language > b.language
+		}
+
+		if a.types != b.types {
+			return a.types > b.types
+		}
+
+		return a.pref < b.pref
+	})
+
+	result := make([]*VCardProperty, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.property
+	}
+
+	return result
+}
+
+// languageMatch scores how well the property's LANGUAGE parameter matches
+// |language|. Higher is better.
+func (p *VCardProperty) languageMatch(language string) int {
+	if language == "" {
+		return 0
+	}
+
+	languages := p.Parameters["language"]
+	if len(languages) == 0 {
+		return 1
+	}
+
+	best := 0
+	for _, l := range languages {
+		switch {
+		case strings.EqualFold(l, language):
+			return 3
+		case isLanguagePrefix(l, language) || isLanguagePrefix(language, l):
+			best = 2
+		}
+	}
+
+	return best
+}
+
+// isLanguagePrefix returns true if the language tag |prefix| is a prefix of
+// |tag|, e.g. "en" is a prefix of "en-GB".
+func isLanguagePrefix(prefix string, tag string) bool {
+	return len(tag) > len(prefix) &&
+		strings.EqualFold(tag[0:len(prefix)], prefix) &&
+		tag[len(prefix)] == '-'
+}
+
+// typeMatches returns the number of |types| in the property's TYPE parameter.
+//
+// TYPE values are case insensitive, and may be comma separated
+// (e.g. "work,voice").
+func (p *VCardProperty) typeMatches(types []string) int {
+	count := 0
+
+	for _, want := range types {
+	values:
+		for _, value := range p.Parameters["type"] {
+			for _, t := range strings.Split(value, ",") {
+				if strings.EqualFold(strings.TrimSpace(t), want) {
+					count++
+					break values
+				}
+			}
+		}
+	}
+
+	return count
+}
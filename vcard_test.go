@@ -5,7 +5,9 @@
 package rdap
 
 import (
+	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/openrdap/rdap/test"
@@ -179,3 +181,290 @@ func TestVCardQuickAccessors(t *testing.T) {
 		t.Errorf("Got %v expected %v\n", got, expected)
 	}
 }
+
+func TestVCardMarshalDeterministic(t *testing.T) {
+	v := &VCard{
+		Properties: []*VCardProperty{
+			{Name: "fn", Parameters: map[string][]string{}, Type: "text", Value: "Joe Appleseed"},
+			{Name: "version", Parameters: map[string][]string{}, Type: "text", Value: "4.0"},
+			{
+				Name:       "tel",
+				Parameters: map[string][]string{"type": {"work", "voice"}, "pref": {"1"}, "altid": {"1"}},
+				Type:       "uri",
+				Value:      "tel:+1-555-555-1234",
+			},
+		},
+	}
+
+	expected := `["vcard",[["version",{},"text","4.0"],["fn",{},"text","Joe Appleseed"],` +
+		`["tel",{"altid":"1","pref":"1","type":["work","voice"]},"uri","tel:+1-555-555-1234"]]]`
+
+	for i := 0; i < 10; i++ {
+		got, err := json.Marshal(v)
+
+		if err != nil {
+			t.Fatalf("Marshal failed: %s", err)
+		} else if string(got) != expected {
+			t.Fatalf("Got %s expected %s", got, expected)
+		}
+	}
+}
+
+func TestVCardMarshalDecode(t *testing.T) {
+	j, err := NewVCard(test.LoadFile("jcard/example.json"))
+	if err != nil {
+		t.Fatalf("jCard parse failed %s\n", err)
+	}
+
+	jsonBlob, err := json.Marshal(j)
+	if err != nil {
+		t.Fatalf("Marshal failed: %s", err)
+	}
+
+	j2, err := NewVCard(jsonBlob)
+	if err != nil {
+		t.Fatalf("jCard reparse failed %s\n", err)
+	}
+
+	if !reflect.DeepEqual(j.Properties, j2.Properties) {
+		t.Errorf("Got %s expected %s", j2, j)
+	}
+}
+
+func TestVCardRoundTrip(t *testing.T) {
+	filenames := []string{
+		"jcard/example.json",
+		"jcard/mixed.json",
+		"jcard/roundtrip.json",
+	}
+
+	for _, filename := range filenames {
+		jsonBlob := test.LoadFile(filename)
+
+		j, err := NewVCard(jsonBlob)
+		if err != nil {
+			t.Errorf("%s: jCard parse failed %s\n", filename, err)
+			continue
+		}
+
+		encoded, err := json.Marshal(j)
+		if err != nil {
+			t.Errorf("%s: Marshal failed: %s", filename, err)
+			continue
+		}
+
+		var expected, got interface{}
+		json.Unmarshal(jsonBlob, &expected)
+		json.Unmarshal(encoded, &got)
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: round trip mismatch\ngot      %s\nexpected %v", filename, encoded, expected)
+		}
+	}
+}
+
+func TestVCardRoundTripRDAP(t *testing.T) {
+	filenames := []string{
+		"rdap/rdap-pilot.verisignlabs.com/entity-1-VRSN",
+	}
+
+	for _, filename := range filenames {
+		var doc interface{}
+		json.Unmarshal(test.LoadFile(filename), &doc)
+
+		for _, jcard := range findJCards(doc) {
+			v, err := newVCardImpl(jcard, VCardOptions{})
+			if err != nil {
+				t.Errorf("%s: jCard parse failed %s\n", filename, err)
+				continue
+			}
+
+			encoded, _ := json.Marshal(v)
+
+			var got interface{}
+			json.Unmarshal(encoded, &got)
+
+			if !reflect.DeepEqual(got, jcard) {
+				t.Errorf("%s: round trip mismatch\ngot      %s\nexpected %v", filename, encoded, jcard)
+			}
+		}
+	}
+}
+
+// findJCards returns all "vcardArray" values within the JSON document |doc|.
+func findJCards(doc interface{}) []interface{} {
+	var result []interface{}
+
+	switch doc := doc.(type) {
+	case map[string]interface{}:
+		for k, v := range doc {
+			if k == "vcardArray" {
+				result = append(result, v)
+			} else {
+				result = append(result, findJCards(v)...)
+			}
+		}
+	case []interface{}:
+		for _, v := range doc {
+			result = append(result, findJCards(v)...)
+		}
+	}
+
+	return result
+}
+
+func TestVCardBinary(t *testing.T) {
+	png := "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="
+
+	j, err := NewVCardWithOptions([]byte(`
+	["vcard", [
+		["version", {}, "text", "4.0"],
+		["logo", {}, "uri", "data:image/png;base64,`+png+`"],
+		["photo", {"encoding": "b", "type": "PNG"}, "text", "`+png+`"],
+		["key", {}, "uri", "https://example.com/key.asc"]
+	]]`), VCardOptions{MaxBinarySize: 100})
+
+	if err != nil {
+		t.Fatalf("jCard parse failed %s\n", err)
+	}
+
+	logo, err := j.Logo()
+	if err != nil {
+		t.Fatalf("Logo() failed: %s", err)
+	} else if logo.MediaType != "image/png" || len(logo.Data) != 70 || string(logo.Data[1:4]) != "PNG" {
+		t.Errorf("Bad logo %v", logo)
+	}
+
+	photo, err := j.Photo()
+	if err != nil {
+		t.Fatalf("Photo() failed: %s", err)
+	} else if photo.MediaType != "image/png" || len(photo.Data) != 70 {
+		t.Errorf("Bad photo %v", photo)
+	}
+
+	key, err := j.Key()
+	if err != nil {
+		t.Fatalf("Key() failed: %s", err)
+	} else if key.URL != "https://example.com/key.asc" || key.Data != nil {
+		t.Errorf("Bad key %v", key)
+	}
+
+	// Detected MIME type.
+	unlabelled := &VCardProperty{Name: "logo", Type: "uri", Value: "data:;base64," + png}
+	detected, err := unlabelled.Binary(0)
+	if err != nil || detected.MediaType != "image/png" {
+		t.Errorf("Bad detected MIME type %v %s", detected, err)
+	}
+
+	// Size limit.
+	_, err = j.GetFirst("logo").Binary(50)
+	if err == nil {
+		t.Errorf("Expected size limit error")
+	}
+
+	// Size limit exactly the data size, with and without padding.
+	for _, value := range []string{png, strings.TrimRight(png, "=")} {
+		p := &VCardProperty{Name: "photo", Parameters: map[string][]string{"encoding": {"b"}}, Type: "text", Value: value}
+
+		if b, err := p.Binary(70); err != nil || len(b.Data) != 70 {
+			t.Errorf("%q: unexpected result %v, error %v", value, b, err)
+		}
+
+		if _, err := p.Binary(69); err == nil {
+			t.Errorf("%q: expected size limit error", value)
+		}
+	}
+
+	// No photo.
+	j2, _ := NewVCard(test.LoadFile("jcard/mixed.json"))
+	if photo, err := j2.Photo(); photo != nil || err != nil {
+		t.Errorf("Expected no photo")
+	}
+}
+
+func TestVCardStructuredValues(t *testing.T) {
+	j, err := NewVCard(test.LoadFile("jcard/example.json"))
+	if j == nil || err != nil {
+		t.Fatalf("jCard parse failed %v %s\n", j, err)
+	}
+
+	expectedN := [][]string{
+		{"Perreault"},
+		{"Simon"},
+		{""},
+		{""},
+		{"ing. jr", "M.Sc."},
+	}
+
+	if got := j.GetFirst("n").StructuredValues(); !reflect.DeepEqual(got, expectedN) {
+		t.Errorf("n structured value incorrect %v", got)
+	}
+
+	expectedFn := [][]string{{"Simon Perreault"}}
+
+	if got := j.GetFirst("fn").StructuredValues(); !reflect.DeepEqual(got, expectedFn) {
+		t.Errorf("fn structured value incorrect %v", got)
+	}
+
+	adr := &VCardProperty{
+		Name:       "adr",
+		Parameters: make(map[string][]string),
+		Type:       "text",
+		Value:      []interface{}{"", "", []interface{}{"1 Main St", "Unit 2"}, "Town", "", "", "Canada"},
+	}
+
+	expectedAdr := [][]string{{""}, {""}, {"1 Main St", "Unit 2"}, {"Town"}, {""}, {""}, {"Canada"}}
+
+	if got := adr.StructuredValues(); !reflect.DeepEqual(got, expectedAdr) {
+		t.Errorf("adr structured value incorrect %v", got)
+	}
+}
+
+func TestVCardSelect(t *testing.T) {
+	j, err := NewVCard([]byte(`["vcard", [
+		["version", {}, "text", "4.0"],
+		["fn", {"language": "fr"}, "text", "Jean Exemple"],
+		["fn", {"language": "en-GB"}, "text", "John Example"],
+		["email", {"type": "home"}, "text", "home@example.com"],
+		["email", {"type": "work", "pref": "2"}, "text", "work2@example.com"],
+		["email", {"type": ["WORK", "internet"], "pref": "1"}, "text", "work1@example.com"],
+		["tel", {"type": "work,voice"}, "uri", "tel:+1-555-555-0100"],
+		["tel", {"type": "fax"}, "uri", "tel:+1-555-555-0101"]
+	]]`))
+
+	if err != nil {
+		t.Fatalf("jCard parse failed %s", err)
+	}
+
+	tests := []struct {
+		Opts     VCardSelectOptions
+		Expected string
+	}{
+		{VCardSelectOptions{Name: "email"}, "work1@example.com"},
+		{VCardSelectOptions{Name: "email", Types: []string{"home"}}, "home@example.com"},
+		{VCardSelectOptions{Name: "email", Types: []string{"work"}}, "work1@example.com"},
+		{VCardSelectOptions{Name: "fn", Language: "en"}, "John Example"},
+		{VCardSelectOptions{Name: "fn", Language: "fr-CA"}, "Jean Exemple"},
+		{VCardSelectOptions{Name: "fn", Language: "de"}, "Jean Exemple"},
+		{VCardSelectOptions{Name: "tel", Types: []string{"voice"}}, "tel:+1-555-555-0100"},
+		{VCardSelectOptions{Name: "tel", Types: []string{"fax"}}, "tel:+1-555-555-0101"},
+		{VCardSelectOptions{Name: "tel", Types: []string{"cell"}, RequireTypes: true}, ""},
+		{VCardSelectOptions{Name: "photo"}, ""},
+	}
+
+	for _, test := range tests {
+		got := ""
+		if p := j.Select(test.Opts); p != nil {
+			got = p.Values()[0]
+		}
+
+		if got != test.Expected {
+			t.Errorf("Select(%+v) got %q, expected %q", test.Opts, got, test.Expected)
+		}
+	}
+
+	all := j.SelectAll(VCardSelectOptions{Name: "email", Types: []string{"work"}, RequireTypes: true})
+	if len(all) != 2 || all[0].Values()[0] != "work1@example.com" || all[1].Values()[0] != "work2@example.com" {
+		t.Errorf("Bad SelectAll result %v", all)
+	}
+}
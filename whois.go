@@ -0,0 +1,320 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWhoisIANAServer is the WHOIS server used to find the WHOIS server of
+// each TLD.
+const DefaultWhoisIANAServer = "whois.iana.org"
+
+// maxWhoisResponseSize is the maximum size of WHOIS response read, in bytes.
+const maxWhoisResponseSize = 1 << 20
+
+// WhoisClient queries legacy WHOIS (port 43) servers, for TLDs without an RDAP
+// service. See Client.WhoisFallback.
+//
+// A WhoisClient is safe for concurrent use.
+type WhoisClient struct {
+	// Optional WHOIS servers, per TLD, e.g. {"example": "whois.nic.example"}.
+	// Servers may include a port (the default is 43).
+	//
+	// The WHOIS servers of other TLDs are found using IANAServer.
+	Servers map[string]string
+
+	// WHOIS server for finding the WHOIS server of each TLD. The default is
+	// DefaultWhoisIANAServer.
+	IANAServer string
+
+	// Timeout for each WHOIS query. The default (0) is 30s.
+	Timeout time.Duration
+
+	// Optional dial function, e.g. for proxies. The default is a net.Dialer.
+	DialContext func(ctx context.Context, network string, address string) (net.Conn, error)
+
+	mu       sync.Mutex
+	referral map[string]string
+}
+
+// WhoisResponse is a raw WHOIS response.
+type WhoisResponse struct {
+	// WHOIS server queried, e.g. "whois.nic.example".
+	Server string
+
+	// Query sent, e.g. "example.example".
+	Query string
+
+	// Response text.
+	Text string
+}
+
+// Query queries the WHOIS server of the domain name |domain|'s TLD.
+func (w *WhoisClient) Query(ctx context.Context, domain string) (*WhoisResponse, error) {
+	domain = strings.TrimSuffix(domain, ".")
+	tld := strings.ToLower(domain[strings.LastIndex(domain, ".")+1:])
+
+	server, err := w.serverFor(ctx, tld)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := w.query(ctx, server, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WhoisResponse{
+		Server: server,
+		Query:  domain,
+		Text:   text,
+	}, nil
+}
+
+// serverFor returns the WHOIS server for |tld|.
+func (w *WhoisClient) serverFor(ctx context.Context, tld string) (string, error) {
+	if server, ok := w.Servers[tld]; ok {
+		return server, nil
+	}
+
+	w.mu.Lock()
+	server, ok := w.referral[tld]
+	w.mu.Unlock()
+
+	if ok {
+		return server, nil
+	}
+
+	ianaServer := w.IANAServer
+	if ianaServer == "" {
+		ianaServer = DefaultWhoisIANAServer
+	}
+
+	text, err := w.query(ctx, ianaServer, tld)
+	if err != nil {
+		return "", err
+	}
+
+	for _, field := range parseWhoisFields(text) {
+		if field.key == "refer" || field.key == "whois" {
+			server = field.value
+			break
+		}
+	}
+
+	if server == "" {
+		return "", &ClientError{
+			Type: BootstrapNoMatch,
+			Text: fmt.Sprintf("No RDAP or WHOIS servers found for '%s'", tld),
+		}
+	}
+
+	w.mu.Lock()
+	if w.referral == nil {
+		w.referral = map[string]string{}
+	}
+	w.referral[tld] = server
+	w.mu.Unlock()
+
+	return server, nil
+}
+
+// query sends |query| to the WHOIS server |server|, returning the response.
+func (w *WhoisClient) query(ctx context.Context, server string, query string) (string, error) {
+	timeout := w.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	address := server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		address = net.JoinHostPort(server, "43")
+	}
+
+	dial := w.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	conn, err := dial(ctx, "tcp", address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// Unblock reads if |ctx| is cancelled.
+	go func() {
+		<-ctx.Done()
+		conn.SetDeadline(time.Now())
+	}()
+
+	if _, err := fmt.Fprintf(conn, "%s\r\n", query); err != nil {
+		return "", err
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(conn, maxWhoisResponseSize+1))
+	if err != nil {
+		return "", err
+	} else if len(body) > maxWhoisResponseSize {
+		return "", fmt.Errorf("WHOIS response from %s larger than %d bytes", address, maxWhoisResponseSize)
+	}
+
+	return string(body), nil
+}
+
+type whoisField struct {
+	key   string
+	value string
+}
+
+// parseWhoisFields returns the "Key: Value" fields of the WHOIS response
+// |text|, with lowercased keys.
+func parseWhoisFields(text string) []whoisField {
+	var fields []whoisField
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "%") || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ">>>") {
+			continue
+		}
+
+		i := strings.Index(line, ":")
+		if i <= 0 {
+			continue
+		}
+
+		key := strings.ToLower(strings.TrimSpace(line[:i]))
+		value := strings.TrimSpace(line[i+1:])
+
+		if value != "" {
+			fields = append(fields, whoisField{key, value})
+		}
+	}
+
+	return fields
+}
+
+// Domain returns a best effort conversion of the WHOIS response to a Domain,
+// or nil if no domain name was found in it.
+//
+// WHOIS responses have no standard format, so only common fields are
+// converted: the domain name, handle, status, nameservers, registration,
+// expiration and last changed dates, and the registrar. The Domain has a
+// remark titled "WHOIS" noting it isn't RDAP data, and Port43 is set to the
+// WHOIS server.
+func (w *WhoisResponse) Domain() *Domain {
+	d := &Domain{
+		ObjectClassName: "domain",
+		Port43:          w.Server,
+		Remarks: []Remark{
+			{
+				Title:       "WHOIS",
+				Description: []string{"This object was converted from a WHOIS (port 43) response, and is not RDAP data."},
+			},
+		},
+	}
+
+	var registrar, registrarID string
+
+	for _, f := range parseWhoisFields(w.Text) {
+		switch f.key {
+		case "domain name", "domain":
+			if d.LDHName == "" {
+				d.LDHName = strings.ToLower(strings.TrimSuffix(f.value, "."))
+			}
+		case "registry domain id":
+			d.Handle = f.value
+		case "domain status", "status":
+			d.Status = append(d.Status, strings.Fields(f.value)[0])
+		case "name server", "nameserver", "nserver":
+			d.Nameservers = append(d.Nameservers, Nameserver{
+				ObjectClassName: "nameserver",
+				LDHName:         strings.ToLower(strings.TrimSuffix(strings.Fields(f.value)[0], ".")),
+			})
+		case "creation date", "created", "registered":
+			d.Events = append(d.Events, Event{Action: EventRegistration, Date: f.value})
+		case "registry expiry date", "registrar registration expiration date", "expiration date", "expire", "expires":
+			d.Events = append(d.Events, Event{Action: EventExpiration, Date: f.value})
+		case "updated date", "changed", "last updated", "last-update":
+			d.Events = append(d.Events, Event{Action: EventLastChanged, Date: f.value})
+		case "registrar", "sponsoring registrar":
+			if registrar == "" {
+				registrar = f.value
+			}
+		case "registrar iana id":
+			registrarID = f.value
+		}
+	}
+
+	if d.LDHName == "" {
+		return nil
+	}
+
+	if registrar != "" {
+		entity := Entity{
+			ObjectClassName: "entity",
+			Roles:           []string{"registrar"},
+		}
+
+		jCard, _ := json.Marshal([]interface{}{"vcard", []interface{}{
+			[]interface{}{"version", map[string]interface{}{}, "text", "4.0"},
+			[]interface{}{"fn", map[string]interface{}{}, "text", registrar},
+		}})
+		entity.VCard, _ = NewVCard(jCard)
+
+		if registrarID != "" {
+			entity.PublicIDs = []PublicID{{Type: PublicIDIANARegistrarID, Identifier: registrarID}}
+		}
+
+		d.Entities = append(d.Entities, entity)
+	}
+
+	return d
+}
+
+// queryWhois runs the domain query |req| on the WhoisFallback WHOIS client,
+// for a TLD without an RDAP service.
+func (c *Client) queryWhois(req *Request, resp *Response) (*Response, error) {
+	c.Verbose(fmt.Sprintf("client: No RDAP servers for '%s', falling back to WHOIS", req.Query))
+
+	whois, err := c.WhoisFallback.Query(req.Context(), req.Query)
+	if err != nil {
+		return resp, err
+	}
+
+	c.Verbose(fmt.Sprintf("client: WHOIS server %s returned %d bytes", whois.Server, len(whois.Text)))
+
+	resp.Whois = whois
+
+	domain := whois.Domain()
+	if domain == nil {
+		return resp, &ClientError{
+			Type: ObjectDoesNotExist,
+			Text: fmt.Sprintf("WHOIS server %s returned no domain data", whois.Server),
+		}
+	}
+
+	resp.Object = domain
+
+	return resp, nil
+}
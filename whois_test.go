@@ -0,0 +1,136 @@
+// OpenRDAP
+// Copyright 2017 Tom Harwood
+// MIT License, see the LICENSE file.
+
+package rdap
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/openrdap/rdap/test"
+)
+
+// whoisServer runs a WHOIS server on localhost, which responds to each query
+// using |respond|.
+func whoisServer(t *testing.T, respond func(query string) string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			query, _ := bufio.NewReader(conn).ReadString('\n')
+			conn.Write([]byte(respond(strings.TrimSpace(query))))
+			conn.Close()
+		}
+	}()
+
+	return l
+}
+
+func TestClientWhoisFallback(t *testing.T) {
+	test.Start(test.Bootstrap)
+	defer test.Finish()
+
+	tldServer := whoisServer(t, func(query string) string {
+		if query != "example.example" {
+			return "No match for \"" + query + "\".\r\n"
+		}
+
+		return "Domain Name: EXAMPLE.EXAMPLE\r\n" +
+			"Registry Domain ID: D1234-EXAMPLE\r\n" +
+			"Creation Date: 2001-02-03T04:05:06Z\r\n" +
+			"Registrar: Example Registrar, Inc.\r\n" +
+			"Registrar IANA ID: 9999\r\n" +
+			"Domain Status: clientTransferProhibited https://icann.org/epp#clientTransferProhibited\r\n" +
+			"Name Server: NS1.EXAMPLE.EXAMPLE\r\n" +
+			"Name Server: ns2.example.example.\r\n" +
+			">>> Last update of WHOIS database: 2024-01-01T00:00:00Z <<<\r\n"
+	})
+	defer tldServer.Close()
+
+	ianaServer := whoisServer(t, func(query string) string {
+		if query != "example" {
+			return "% No match\n"
+		}
+
+		return "% IANA WHOIS server\n\ndomain:       EXAMPLE\nrefer:        " + tldServer.Addr().String() + "\n"
+	})
+	defer ianaServer.Close()
+
+	client := &Client{
+		WhoisFallback: &WhoisClient{IANAServer: ianaServer.Addr().String()},
+		Verbose:       verboseFunc(),
+	}
+
+	resp, err := client.Do(NewDomainRequest("example.example"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if resp.Whois == nil || resp.Whois.Server != tldServer.Addr().String() || !strings.Contains(resp.Whois.Text, "D1234-EXAMPLE") {
+		t.Fatalf("Unexpected WHOIS response %+v", resp.Whois)
+	}
+
+	d := resp.Object.(*Domain)
+	if d.LDHName != "example.example" || d.Handle != "D1234-EXAMPLE" || len(d.Remarks) != 1 {
+		t.Errorf("Unexpected domain %+v", d)
+	}
+
+	if len(d.Status) != 1 || d.Status[0] != "clientTransferProhibited" {
+		t.Errorf("Unexpected status %v", d.Status)
+	}
+
+	if len(d.Nameservers) != 2 || d.Nameservers[0].LDHName != "ns1.example.example" || d.Nameservers[1].LDHName != "ns2.example.example" {
+		t.Errorf("Unexpected nameservers %+v", d.Nameservers)
+	}
+
+	if len(d.Events) != 1 || d.Events[0].Action != "registration" || d.Events[0].Date != "2001-02-03T04:05:06Z" {
+		t.Errorf("Unexpected events %+v", d.Events)
+	}
+
+	if len(d.Entities) != 1 || d.Entities[0].VCard.Name() != "Example Registrar, Inc." || d.Entities[0].PublicIDs[0].Identifier != "9999" {
+		t.Errorf("Unexpected registrar %+v", d.Entities)
+	}
+
+	// Domain not found.
+	_, err = client.Do(NewDomainRequest("missing.example"))
+	if !isClientError(ObjectDoesNotExist, err) {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	// TLD without a WHOIS server.
+	_, err = client.Do(NewDomainRequest("example.invalid"))
+	if !isClientError(BootstrapNoMatch, err) {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	// No fallback.
+	client.WhoisFallback = nil
+	_, err = client.Do(NewDomainRequest("example.example"))
+	if !isClientError(BootstrapNoMatch, err) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestWhoisClientResponseSize(t *testing.T) {
+	server := whoisServer(t, func(query string) string {
+		return strings.Repeat("% Comment.\n", maxWhoisResponseSize/10)
+	})
+	defer server.Close()
+
+	w := &WhoisClient{}
+	if _, err := w.query(context.Background(), server.Addr().String(), "example.example"); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("Unexpected error %v for a response over maxWhoisResponseSize", err)
+	}
+}